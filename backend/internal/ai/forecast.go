@@ -86,6 +86,16 @@ func Predict(artifact ModelArtifact, categoryID uint, periods int, start time.Ti
 	return result
 }
 
+// PriceElasticity is the assumed demand response to a relative price change:
+// a 10% price cut lifts expected demand by about 8%.
+const PriceElasticity = -0.8
+
+// PriceAdjustment returns the demand multiplier for a relative price change,
+// bounded so that a single repricing cannot swing the forecast wildly.
+func PriceAdjustment(priceChange float64) float64 {
+	return math.Min(1.5, math.Max(0.5, 1+PriceElasticity*priceChange))
+}
+
 func Save(path string, artifact ModelArtifact) error {
 	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
 		return err
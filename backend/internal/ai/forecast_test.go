@@ -60,3 +60,15 @@ func TestLoadMissingFile(t *testing.T) {
 		t.Fatalf("expected not-exist error")
 	}
 }
+
+func TestPriceAdjustment(t *testing.T) {
+	if got := PriceAdjustment(0); got != 1 {
+		t.Fatalf("expected neutral adjustment, got %f", got)
+	}
+	if got := PriceAdjustment(-0.1); got <= 1 {
+		t.Fatalf("expected price cut to lift demand, got %f", got)
+	}
+	if got := PriceAdjustment(5); got != 0.5 {
+		t.Fatalf("expected lower bound 0.5, got %f", got)
+	}
+}
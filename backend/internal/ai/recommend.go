@@ -0,0 +1,86 @@
+package ai
+
+import (
+	"math"
+	"sort"
+)
+
+// ItemScore says how strongly RelatedID goes together with ProductID.
+type ItemScore struct {
+	ProductID string
+	RelatedID string
+	Score     float64
+	Support   int
+}
+
+// ScoreCooccurrence mines item-to-item similarity from baskets of product ids
+// bought together. The score is the cosine similarity of the two products'
+// order vectors: co-occurrences / sqrt(orders(a) * orders(b)), so that best
+// sellers do not dominate every list. Pairs seen together fewer than
+// minSupport times are dropped, and at most topN scores are kept per product.
+func ScoreCooccurrence(baskets [][]string, minSupport, topN int) []ItemScore {
+	if minSupport < 1 {
+		minSupport = 1
+	}
+
+	orders := map[string]int{}
+	pairs := map[[2]string]int{}
+	for _, basket := range baskets {
+		items := uniqueSorted(basket)
+		for i, a := range items {
+			orders[a]++
+			for _, b := range items[i+1:] {
+				pairs[[2]string{a, b}]++
+			}
+		}
+	}
+
+	byProduct := map[string][]ItemScore{}
+	for pair, count := range pairs {
+		if count < minSupport {
+			continue
+		}
+		score := float64(count) / math.Sqrt(float64(orders[pair[0]]*orders[pair[1]]))
+		byProduct[pair[0]] = append(byProduct[pair[0]], ItemScore{ProductID: pair[0], RelatedID: pair[1], Score: score, Support: count})
+		byProduct[pair[1]] = append(byProduct[pair[1]], ItemScore{ProductID: pair[1], RelatedID: pair[0], Score: score, Support: count})
+	}
+
+	productIDs := make([]string, 0, len(byProduct))
+	for id := range byProduct {
+		productIDs = append(productIDs, id)
+	}
+	sort.Strings(productIDs)
+
+	result := []ItemScore{}
+	for _, id := range productIDs {
+		scores := byProduct[id]
+		sort.Slice(scores, func(i, j int) bool {
+			if scores[i].Score != scores[j].Score {
+				return scores[i].Score > scores[j].Score
+			}
+			if scores[i].Support != scores[j].Support {
+				return scores[i].Support > scores[j].Support
+			}
+			return scores[i].RelatedID < scores[j].RelatedID
+		})
+		if topN > 0 && len(scores) > topN {
+			scores = scores[:topN]
+		}
+		result = append(result, scores...)
+	}
+	return result
+}
+
+func uniqueSorted(items []string) []string {
+	seen := map[string]bool{}
+	result := make([]string, 0, len(items))
+	for _, item := range items {
+		if item == "" || seen[item] {
+			continue
+		}
+		seen[item] = true
+		result = append(result, item)
+	}
+	sort.Strings(result)
+	return result
+}
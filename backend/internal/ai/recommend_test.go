@@ -0,0 +1,56 @@
+package ai
+
+import "testing"
+
+func TestScoreCooccurrenceRanksFrequentPairsFirst(t *testing.T) {
+	baskets := [][]string{
+		{"sofa", "rug"},
+		{"sofa", "rug", "lamp"},
+		{"sofa", "rug", "rug"},
+		{"sofa", "lamp"},
+		{"desk"},
+	}
+
+	scores := ScoreCooccurrence(baskets, 1, 0)
+	var sofa []ItemScore
+	for _, s := range scores {
+		if s.ProductID == "sofa" {
+			sofa = append(sofa, s)
+		}
+		if s.ProductID == "desk" || s.RelatedID == "desk" {
+			t.Fatalf("expected no scores for a product never bought with others")
+		}
+	}
+	if len(sofa) != 2 || sofa[0].RelatedID != "rug" || sofa[0].Support != 3 {
+		t.Fatalf("expected rug as the top pick for sofa, got %+v", sofa)
+	}
+	if sofa[0].Score <= sofa[1].Score {
+		t.Fatalf("expected scores in descending order, got %+v", sofa)
+	}
+}
+
+func TestScoreCooccurrenceAppliesSupportAndTopN(t *testing.T) {
+	baskets := [][]string{
+		{"sofa", "rug"},
+		{"sofa", "rug"},
+		{"sofa", "lamp"},
+		{"sofa", "table"},
+		{"sofa", "table"},
+	}
+
+	for _, s := range ScoreCooccurrence(baskets, 2, 0) {
+		if s.RelatedID == "lamp" || s.ProductID == "lamp" {
+			t.Fatalf("expected pairs below min support to be dropped, got %+v", s)
+		}
+	}
+
+	count := 0
+	for _, s := range ScoreCooccurrence(baskets, 1, 1) {
+		if s.ProductID == "sofa" {
+			count++
+		}
+	}
+	if count != 1 {
+		t.Fatalf("expected at most one score per product, got %d", count)
+	}
+}
@@ -2,14 +2,91 @@ package config
 
 import (
 	"fmt"
+	"net/url"
 	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"backend/internal/models"
 )
 
+var currencyPattern = regexp.MustCompile(`^[A-Z]{3}$`)
+
+// RateLimitRule allows Limit requests per client IP in each Window. A zero
+// Limit disables the limit.
+type RateLimitRule struct {
+	Limit  int
+	Window time.Duration
+}
+
+// OIDCRoleMapping gives the members of an identity provider group a role.
+type OIDCRoleMapping struct {
+	Group string
+	Role  models.RoleName
+}
+
 type Config struct {
 	AppHost string
 	AppPort string
+	// TrustedProxies may set ProxyHeader to the client IP used for rate
+	// limits and lockouts; from anyone else the header is ignored.
+	TrustedProxies []string
+	ProxyHeader    string
 
 	AppSecret string
+	// AppPreviousSecrets still verify tokens signed before APP_SECRET was rotated.
+	AppPreviousSecrets []string
+
+	JWTIssuer       string
+	JWTAudience     string
+	JWTKeysDir      string
+	JWTSigningKeyID string
+
+	AccessTokenTTL  time.Duration
+	RefreshTokenTTL time.Duration
+	// RefreshReuseGrace is how long a rotated refresh token may be presented
+	// again, as when two tabs refresh at once, before it counts as reuse.
+	RefreshReuseGrace    time.Duration
+	PasswordResetTTL     time.Duration
+	EmailVerificationTTL time.Duration
+	APIKeyTTL            time.Duration
+	PermissionCacheTTL   time.Duration
+
+	SchedulerInterval       time.Duration
+	RecommendationsInterval time.Duration
+
+	// RateLimitStore is "memory", or "database" to share rate-limit and
+	// login-failure counters between instances.
+	RateLimitStore   string
+	RateLimitAuth    RateLimitRule
+	RateLimitOrders  RateLimitRule
+	RateLimitCatalog RateLimitRule
+
+	// BaseCurrency is the ISO 4217 code product prices and order totals are
+	// stored in.
+	BaseCurrency string
+	// PublicURL is the storefront address that mailed links, order QR codes
+	// and the single sign-on callback point to, without a trailing slash.
+	PublicURL string
+	// MailDir is where outgoing mail is written as .eml files; empty logs
+	// it instead.
+	MailDir string
+
+	// OIDC* describe the identity provider staff sign in with; single
+	// sign-on is off unless OIDCIssuer and OIDCClientID are set.
+	OIDCIssuer       string
+	OIDCClientID     string
+	OIDCClientSecret string
+	OIDCRedirectURL  string
+	OIDCScopes       []string
+	OIDCGroupsClaim  string
+	// OIDCRoleMap is checked in order and the first group the user is in
+	// picks the role; OIDCDefaultRole applies when none matches and may be
+	// empty to refuse such users.
+	OIDCRoleMap     []OIDCRoleMapping
+	OIDCDefaultRole models.RoleName
 
 	DBHost     string
 	DBPort     string
@@ -19,12 +96,48 @@ type Config struct {
 	DBSSLMode  string
 }
 
-func Load() Config {
-	return Config{
+// Load reads the configuration from the environment and reports settings
+// that are present but invalid.
+func Load() (Config, error) {
+	cfg := Config{
 		AppHost: getenv("APP_HOST", "0.0.0.0"),
 		AppPort: getenv("APP_PORT", "8080"),
 
-		AppSecret: getenv("APP_SECRET", "dev-secret-change-me"),
+		TrustedProxies: getenvList("TRUSTED_PROXIES"),
+		ProxyHeader:    getenv("PROXY_HEADER", "X-Real-IP"),
+
+		AppSecret:          getenv("APP_SECRET", "dev-secret-change-me"),
+		AppPreviousSecrets: getenvList("APP_SECRET_PREVIOUS"),
+
+		JWTIssuer:       getenv("JWT_ISSUER", "furniture-store"),
+		JWTAudience:     getenv("JWT_AUDIENCE", "furniture-store-api"),
+		JWTKeysDir:      getenv("JWT_KEYS_DIR", ""),
+		JWTSigningKeyID: getenv("JWT_SIGNING_KID", ""),
+
+		AccessTokenTTL:       getenvDuration("ACCESS_TOKEN_TTL", 15*time.Minute),
+		RefreshTokenTTL:      getenvDuration("REFRESH_TOKEN_TTL", 30*24*time.Hour),
+		RefreshReuseGrace:    getenvDuration("REFRESH_TOKEN_REUSE_GRACE", 30*time.Second),
+		PasswordResetTTL:     getenvDuration("PASSWORD_RESET_TTL", time.Hour),
+		EmailVerificationTTL: getenvDuration("EMAIL_VERIFICATION_TTL", 48*time.Hour),
+		APIKeyTTL:            getenvDuration("API_KEY_TTL", 90*24*time.Hour),
+		PermissionCacheTTL:   getenvDuration("PERMISSION_CACHE_TTL", time.Minute),
+
+		SchedulerInterval:       getenvDuration("SCHEDULER_INTERVAL", time.Minute),
+		RecommendationsInterval: getenvDuration("RECOMMENDATIONS_INTERVAL", time.Hour),
+
+		RateLimitStore: strings.ToLower(strings.TrimSpace(getenv("RATE_LIMIT_STORE", "memory"))),
+
+		BaseCurrency: strings.ToUpper(strings.TrimSpace(getenv("BASE_CURRENCY", "RUB"))),
+		PublicURL:    strings.TrimRight(strings.TrimSpace(getenv("PUBLIC_URL", "http://localhost:3000")), "/"),
+		MailDir:      strings.TrimSpace(os.Getenv("MAIL_DIR")),
+
+		OIDCIssuer:       strings.TrimRight(strings.TrimSpace(os.Getenv("OIDC_ISSUER")), "/"),
+		OIDCClientID:     strings.TrimSpace(os.Getenv("OIDC_CLIENT_ID")),
+		OIDCClientSecret: strings.TrimSpace(os.Getenv("OIDC_CLIENT_SECRET")),
+		OIDCRedirectURL:  strings.TrimSpace(os.Getenv("OIDC_REDIRECT_URL")),
+		OIDCScopes:       strings.Fields(getenv("OIDC_SCOPES", "openid email profile")),
+		OIDCGroupsClaim:  strings.TrimSpace(getenv("OIDC_GROUPS_CLAIM", "groups")),
+		OIDCDefaultRole:  models.RoleName(strings.TrimSpace(os.Getenv("OIDC_DEFAULT_ROLE"))),
 
 		DBHost:     getenv("DB_HOST", "localhost"),
 		DBPort:     getenv("DB_PORT", "5432"),
@@ -33,15 +146,108 @@ func Load() Config {
 		DBName:     getenv("DB_NAME", "furniture"),
 		DBSSLMode:  getenv("DB_SSLMODE", "disable"),
 	}
+	if !currencyPattern.MatchString(cfg.BaseCurrency) {
+		return Config{}, fmt.Errorf("BASE_CURRENCY must be a 3-letter ISO 4217 code, got %q", cfg.BaseCurrency)
+	}
+	if cfg.RateLimitStore != "memory" && cfg.RateLimitStore != "database" {
+		return Config{}, fmt.Errorf("RATE_LIMIT_STORE must be memory or database, got %q", cfg.RateLimitStore)
+	}
+	var err error
+	if cfg.RateLimitAuth, err = getenvRateLimit("RATE_LIMIT_AUTH", RateLimitRule{Limit: 60, Window: time.Minute}); err != nil {
+		return Config{}, err
+	}
+	if cfg.RateLimitOrders, err = getenvRateLimit("RATE_LIMIT_ORDERS", RateLimitRule{Limit: 20, Window: time.Minute}); err != nil {
+		return Config{}, err
+	}
+	if cfg.RateLimitCatalog, err = getenvRateLimit("RATE_LIMIT_CATALOG", RateLimitRule{Limit: 600, Window: time.Minute}); err != nil {
+		return Config{}, err
+	}
+	if !absoluteURL(cfg.PublicURL) {
+		return Config{}, fmt.Errorf("PUBLIC_URL must be an absolute http(s) URL, got %q", cfg.PublicURL)
+	}
+	if cfg.OIDCRedirectURL == "" {
+		cfg.OIDCRedirectURL = cfg.PublicURL + "/sso/callback"
+	}
+	roleMap, err := parseOIDCRoleMap(os.Getenv("OIDC_ROLE_MAP"))
+	if err != nil {
+		return Config{}, err
+	}
+	cfg.OIDCRoleMap = roleMap
+	// Single sign-on is for staff, so it never hands out the Client role.
+	if cfg.OIDCDefaultRole == models.RoleClient {
+		return Config{}, fmt.Errorf("OIDC_DEFAULT_ROLE cannot be %s, single sign-on is for staff", models.RoleClient)
+	}
+	return cfg, nil
 }
 
 func (c Config) AppAddress() string {
 	return fmt.Sprintf("%s:%s", c.AppHost, c.AppPort)
 }
 
+// parseOIDCRoleMap reads a comma separated list of group=Role pairs.
+func parseOIDCRoleMap(value string) ([]OIDCRoleMapping, error) {
+	mappings := []OIDCRoleMapping{}
+	for _, entry := range strings.Split(value, ",") {
+		if strings.TrimSpace(entry) == "" {
+			continue
+		}
+		group, role, ok := strings.Cut(entry, "=")
+		mapping := OIDCRoleMapping{Group: strings.TrimSpace(group), Role: models.RoleName(strings.TrimSpace(role))}
+		if !ok || mapping.Group == "" || mapping.Role == "" {
+			return nil, fmt.Errorf("OIDC_ROLE_MAP entry %q must be group=Role", strings.TrimSpace(entry))
+		}
+		if mapping.Role == models.RoleClient {
+			return nil, fmt.Errorf("OIDC_ROLE_MAP cannot map %q to %s, single sign-on is for staff", mapping.Group, models.RoleClient)
+		}
+		mappings = append(mappings, mapping)
+	}
+	return mappings, nil
+}
+
+func absoluteURL(value string) bool {
+	parsed, err := url.Parse(value)
+	return err == nil && (parsed.Scheme == "http" || parsed.Scheme == "https") && parsed.Host != ""
+}
+
 func getenv(key, fallback string) string {
 	if value := os.Getenv(key); value != "" {
 		return value
 	}
 	return fallback
 }
+
+func getenvList(key string) []string {
+	items := []string{}
+	for _, item := range strings.Split(os.Getenv(key), ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// getenvRateLimit reads a rule written as "<limit>/<window>", e.g. "30/1m",
+// or "off".
+func getenvRateLimit(key string, fallback RateLimitRule) (RateLimitRule, error) {
+	value := strings.TrimSpace(os.Getenv(key))
+	if value == "" {
+		return fallback, nil
+	}
+	if strings.EqualFold(value, "off") {
+		return RateLimitRule{}, nil
+	}
+	limit, window, ok := strings.Cut(value, "/")
+	n, err := strconv.Atoi(strings.TrimSpace(limit))
+	d, derr := time.ParseDuration(strings.TrimSpace(window))
+	if !ok || err != nil || derr != nil || n < 0 || d <= 0 {
+		return RateLimitRule{}, fmt.Errorf("%s must be <limit>/<window>, e.g. 30/1m, or off, got %q", key, value)
+	}
+	return RateLimitRule{Limit: n, Window: d}, nil
+}
+
+func getenvDuration(key string, fallback time.Duration) time.Duration {
+	if value, err := time.ParseDuration(strings.TrimSpace(os.Getenv(key))); err == nil && value > 0 {
+		return value
+	}
+	return fallback
+}
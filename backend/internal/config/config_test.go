@@ -0,0 +1,79 @@
+package config
+
+import (
+	"testing"
+	"time"
+)
+
+func TestLoadNormalizesAndValidatesBaseCurrency(t *testing.T) {
+	t.Setenv("BASE_CURRENCY", " eur ")
+	cfg, err := Load()
+	if err != nil || cfg.BaseCurrency != "EUR" {
+		t.Fatalf("expected EUR, got %q: %v", cfg.BaseCurrency, err)
+	}
+
+	t.Setenv("BASE_CURRENCY", "euro")
+	if _, err := Load(); err == nil {
+		t.Fatalf("expected an invalid base currency to be reported")
+	}
+}
+
+func TestLoadTrimsAndValidatesPublicURL(t *testing.T) {
+	t.Setenv("PUBLIC_URL", "https://shop.example.com/")
+	cfg, err := Load()
+	if err != nil || cfg.PublicURL != "https://shop.example.com" {
+		t.Fatalf("expected the URL without a trailing slash, got %q: %v", cfg.PublicURL, err)
+	}
+
+	t.Setenv("PUBLIC_URL", "shop.example.com")
+	if _, err := Load(); err == nil {
+		t.Fatalf("expected a relative public URL to be reported")
+	}
+}
+
+func TestLoadReportsInvalidSingleSignOnRoles(t *testing.T) {
+	t.Setenv("OIDC_ROLE_MAP", " store-managers=Manager, ,warehouse = Warehouse")
+	cfg, err := Load()
+	if err != nil || len(cfg.OIDCRoleMap) != 2 || cfg.OIDCRoleMap[1].Group != "warehouse" || cfg.OIDCRoleMap[1].Role != "Warehouse" {
+		t.Fatalf("expected two role mappings, got %+v: %v", cfg.OIDCRoleMap, err)
+	}
+	if cfg.OIDCRedirectURL != "http://localhost:3000/sso/callback" {
+		t.Fatalf("expected the callback under the public URL, got %q", cfg.OIDCRedirectURL)
+	}
+
+	for _, roleMap := range []string{"store-managers", "=Manager", "customers=Client"} {
+		t.Setenv("OIDC_ROLE_MAP", roleMap)
+		if _, err := Load(); err == nil {
+			t.Fatalf("expected role map %q to be reported", roleMap)
+		}
+	}
+
+	t.Setenv("OIDC_ROLE_MAP", "")
+	t.Setenv("OIDC_DEFAULT_ROLE", "Client")
+	if _, err := Load(); err == nil {
+		t.Fatalf("expected a Client default role to be reported")
+	}
+}
+
+func TestLoadReportsInvalidRateLimits(t *testing.T) {
+	t.Setenv("RATE_LIMIT_STORE", " Database ")
+	t.Setenv("RATE_LIMIT_AUTH", "off")
+	t.Setenv("RATE_LIMIT_CATALOG", "3/30s")
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	if cfg.RateLimitStore != "database" || cfg.RateLimitAuth != (RateLimitRule{}) || cfg.RateLimitCatalog != (RateLimitRule{Limit: 3, Window: 30 * time.Second}) || cfg.RateLimitOrders != (RateLimitRule{Limit: 20, Window: time.Minute}) {
+		t.Fatalf("unexpected rate limits: %+v", cfg)
+	}
+
+	t.Setenv("RATE_LIMIT_CATALOG", "3 per minute")
+	if _, err := Load(); err == nil {
+		t.Fatalf("expected an invalid rule to be reported")
+	}
+	t.Setenv("RATE_LIMIT_CATALOG", "")
+	t.Setenv("RATE_LIMIT_STORE", "redis")
+	if _, err := Load(); err == nil {
+		t.Fatalf("expected an unknown store to be reported")
+	}
+}
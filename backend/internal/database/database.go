@@ -50,6 +50,9 @@ func Connect(cfg config.Config) (*gorm.DB, error) {
 }
 
 func autoMigrate(db *gorm.DB) error {
+	if err := resolveDuplicateStockAlerts(db); err != nil {
+		return err
+	}
 	return db.AutoMigrate(
 		&models.Role{},
 		&models.Permission{},
@@ -63,6 +66,34 @@ func autoMigrate(db *gorm.DB) error {
 		&models.OrderItem{},
 		&models.AuditLog{},
 		&models.MLDataset{},
+		&models.ProductImportJob{},
+		&models.PriceHistory{},
+		&models.ScheduledPrice{},
+		&models.Review{},
+		&models.BundleComponent{},
+		&models.ProductLink{},
+		&models.ProductRecommendation{},
+		&models.ProductTranslation{},
+		&models.CategoryTranslation{},
+		&models.ExchangeRate{},
+		&models.StockSubscription{},
+		&models.Supplier{},
+		&models.PurchaseOrder{},
+		&models.PurchaseOrderLine{},
+		&models.StockMovement{},
+		&models.StockCount{},
+		&models.StockCountLine{},
+		&models.StockAlert{},
+		&models.Session{},
+		&models.RefreshToken{},
+		&models.AccountToken{},
+		&models.UserTOTP{},
+		&models.RecoveryCode{},
+		&models.LoginChallenge{},
+		&models.RateCounter{},
+		&models.APIKey{},
+		&models.OIDCLogin{},
+		&models.UserIdentity{},
 	)
 }
 
@@ -72,3 +103,17 @@ func ConnectSeedOnlyForTests(db *gorm.DB, _ config.Config) error {
 	}
 	return seed(db)
 }
+
+// resolveDuplicateStockAlerts keeps the newest open alert of each product so
+// the unique index on open alerts can be created on databases where
+// concurrent checks raised the same alert twice.
+func resolveDuplicateStockAlerts(db *gorm.DB) error {
+	if !db.Migrator().HasTable(&models.StockAlert{}) {
+		return nil
+	}
+	now := time.Now().UTC()
+	return db.Model(&models.StockAlert{}).
+		Where("status = ? AND id NOT IN (?)", models.StockAlertOpen,
+			db.Model(&models.StockAlert{}).Select("MAX(id)").Where("status = ?", models.StockAlertOpen).Group("product_id")).
+		Updates(map[string]any{"status": models.StockAlertResolved, "resolved_at": now, "updated_at": now}).Error
+}
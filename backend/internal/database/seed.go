@@ -1,13 +1,12 @@
 package database
 
 import (
-	"crypto/sha256"
-	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"time"
 
 	"backend/internal/models"
+	"backend/internal/security"
 
 	"gorm.io/gorm"
 )
@@ -74,6 +73,15 @@ func seed(db *gorm.DB) error {
 	if err := seedProducts(db); err != nil {
 		return err
 	}
+	if err := seedTranslations(db); err != nil {
+		return err
+	}
+	if err := seedSuppliers(db); err != nil {
+		return err
+	}
+	if err := seedPriceHistory(db); err != nil {
+		return err
+	}
 	if err := seedCustomersOrdersAndItems(db); err != nil {
 		return err
 	}
@@ -109,11 +117,12 @@ func seedRolesAndUsers(db *gorm.DB) error {
 		roleID[role.Name] = role.ID
 	}
 
+	verifiedAt := time.Now().UTC()
 	users := []models.User{
-		{ID: seedAdminUserID, Email: "admin@maison.co", Name: "Администратор", RoleID: roleID[models.RoleAdmin], PasswordHash: hashPassword("admin123"), IsBlocked: false},
-		{ID: seedManagerUserID, Email: "manager@maison.co", Name: "Менеджер", RoleID: roleID[models.RoleManager], PasswordHash: hashPassword("manager123"), IsBlocked: false},
-		{ID: seedWarehouseUserID, Email: "warehouse@maison.co", Name: "Кладовщик", RoleID: roleID[models.RoleWarehouse], PasswordHash: hashPassword("warehouse123"), IsBlocked: false},
-		{ID: seedExecutiveUserID, Email: "executive@maison.co", Name: "Руководитель", RoleID: roleID[models.RoleExecutive], PasswordHash: hashPassword("executive123"), IsBlocked: false},
+		{ID: seedAdminUserID, Email: "admin@maison.co", Name: "Администратор", RoleID: roleID[models.RoleAdmin], PasswordHash: security.HashPassword("admin123"), IsBlocked: false, EmailVerifiedAt: &verifiedAt},
+		{ID: seedManagerUserID, Email: "manager@maison.co", Name: "Менеджер", RoleID: roleID[models.RoleManager], PasswordHash: security.HashPassword("manager123"), IsBlocked: false, EmailVerifiedAt: &verifiedAt},
+		{ID: seedWarehouseUserID, Email: "warehouse@maison.co", Name: "Кладовщик", RoleID: roleID[models.RoleWarehouse], PasswordHash: security.HashPassword("warehouse123"), IsBlocked: false, EmailVerifiedAt: &verifiedAt},
+		{ID: seedExecutiveUserID, Email: "executive@maison.co", Name: "Руководитель", RoleID: roleID[models.RoleExecutive], PasswordHash: security.HashPassword("executive123"), IsBlocked: false, EmailVerifiedAt: &verifiedAt},
 	}
 
 	for _, user := range users {
@@ -136,29 +145,81 @@ func seedCategories(db *gorm.DB) error {
 	return nil
 }
 
+// permissionCatalogueMarker is the first permission of the catalogue that
+// routes are authorized against. Databases seeded before it existed hold an
+// informational role mapping that is replaced with the enforced defaults.
+const permissionCatalogueMarker = "read:own_order"
+
 func seedPermissions(db *gorm.DB) error {
 	permissions := []models.Permission{
-		{Action: "create", Resource: "user", Effect: "allow", Description: "Создание внутренних и клиентских аккаунтов"},
-		{Action: "read", Resource: "user", Effect: "allow", Description: "Просмотр пользователей и профилей клиентов"},
-		{Action: "block", Resource: "user", Effect: "allow", Description: "Блокировка и разблокировка пользователей"},
-		{Action: "create", Resource: "product", Effect: "allow", Description: "Добавление товаров в каталог"},
-		{Action: "read", Resource: "product", Effect: "allow", Description: "Просмотр каталога товаров"},
-		{Action: "update", Resource: "product", Effect: "allow", Description: "Редактирование карточек товаров"},
-		{Action: "delete", Resource: "product", Effect: "allow", Description: "Удаление товаров из каталога"},
-		{Action: "create", Resource: "order", Effect: "allow", Description: "Оформление заказов"},
-		{Action: "read", Resource: "order", Effect: "allow", Description: "Просмотр заказов"},
-		{Action: "update", Resource: "order", Effect: "allow", Description: "Редактирование данных заказа"},
-		{Action: "status", Resource: "order", Effect: "allow", Description: "Изменение статуса заказа"},
-		{Action: "read", Resource: "audit_log", Effect: "allow", Description: "Просмотр журнала аудита"},
-		{Action: "create", Resource: "audit_log", Effect: "allow", Description: "Создание записей аудита"},
-		{Action: "train", Resource: "forecast", Effect: "allow", Description: "Обучение модели прогноза"},
-		{Action: "read", Resource: "forecast", Effect: "allow", Description: "Просмотр результатов прогноза"},
-	}
-
+		{Action: "create", Resource: "user", Effect: models.PermissionAllow, Description: "Создание внутренних и клиентских аккаунтов"},
+		{Action: "read", Resource: "user", Effect: models.PermissionAllow, Description: "Просмотр пользователей и профилей клиентов"},
+		{Action: "block", Resource: "user", Effect: models.PermissionAllow, Description: "Блокировка и разблокировка пользователей"},
+		{Action: "reset_two_factor", Resource: "user", Effect: models.PermissionAllow, Description: "Сброс двухфакторной аутентификации пользователя"},
+		{Action: "read", Resource: "two_factor_policy", Effect: models.PermissionAllow, Description: "Просмотр политики двухфакторной аутентификации"},
+		{Action: "update", Resource: "two_factor_policy", Effect: models.PermissionAllow, Description: "Изменение политики двухфакторной аутентификации"},
+		{Action: "read", Resource: "role", Effect: models.PermissionAllow, Description: "Просмотр ролей и их прав"},
+		{Action: "create", Resource: "role", Effect: models.PermissionAllow, Description: "Создание ролей"},
+		{Action: "delete", Resource: "role", Effect: models.PermissionAllow, Description: "Удаление ролей"},
+		{Action: models.ManageAccessAction, Resource: models.ManageAccessResource, Effect: models.PermissionAllow, Description: "Выдача и отзыв прав ролей"},
+		{Action: "read", Resource: "permission", Effect: models.PermissionAllow, Description: "Просмотр прав"},
+		{Action: "create", Resource: "permission", Effect: models.PermissionAllow, Description: "Создание прав"},
+		{Action: "delete", Resource: "permission", Effect: models.PermissionAllow, Description: "Удаление прав"},
+		{Action: "read", Resource: "api_key", Effect: models.PermissionAllow, Description: "Просмотр API-ключей интеграций"},
+		{Action: "create", Resource: "api_key", Effect: models.PermissionAllow, Description: "Выпуск API-ключей интеграций"},
+		{Action: "revoke", Resource: "api_key", Effect: models.PermissionAllow, Description: "Отзыв API-ключей интеграций"},
+		{Action: "create", Resource: "product", Effect: models.PermissionAllow, Description: "Добавление товаров в каталог"},
+		{Action: "read", Resource: "product", Effect: models.PermissionAllow, Description: "Просмотр каталога товаров"},
+		{Action: "update", Resource: "product", Effect: models.PermissionAllow, Description: "Редактирование карточек товаров"},
+		{Action: "delete", Resource: "product", Effect: models.PermissionAllow, Description: "Удаление товаров из каталога"},
+		{Action: "export", Resource: "product", Effect: models.PermissionAllow, Description: "Выгрузка каталога товаров"},
+		{Action: "subscribe", Resource: "product", Effect: models.PermissionAllow, Description: "Подписка на поступление товара"},
+		{Action: "read", Resource: "price", Effect: models.PermissionAllow, Description: "Просмотр истории цен"},
+		{Action: "update", Resource: "price", Effect: models.PermissionAllow, Description: "Планирование изменения цен"},
+		{Action: "read", Resource: "translation", Effect: models.PermissionAllow, Description: "Просмотр переводов каталога"},
+		{Action: "update", Resource: "translation", Effect: models.PermissionAllow, Description: "Редактирование переводов каталога"},
+		{Action: "read", Resource: "stock_subscription", Effect: models.PermissionAllow, Description: "Просмотр подписок на поступление"},
+		{Action: "create", Resource: "review", Effect: models.PermissionAllow, Description: "Публикация отзывов"},
+		{Action: "moderate", Resource: "review", Effect: models.PermissionAllow, Description: "Модерация отзывов"},
+		{Action: "create", Resource: "order", Effect: models.PermissionAllow, Description: "Оформление заказов"},
+		{Action: "read", Resource: "order", Effect: models.PermissionAllow, Description: "Просмотр заказов"},
+		{Action: "read", Resource: "own_order", Effect: models.PermissionAllow, Description: "Просмотр собственных заказов"},
+		{Action: "update", Resource: "order", Effect: models.PermissionAllow, Description: "Редактирование данных заказа"},
+		{Action: "status", Resource: "order", Effect: models.PermissionAllow, Description: "Изменение статуса заказа"},
+		{Action: "fulfil", Resource: "order", Effect: models.PermissionAllow, Description: "Сборка и упаковка заказов"},
+		{Action: "read", Resource: "barcode", Effect: models.PermissionAllow, Description: "Печать и сканирование штрихкодов"},
+		{Action: "read", Resource: "audit_log", Effect: models.PermissionAllow, Description: "Просмотр журнала аудита"},
+		{Action: "create", Resource: "audit_log", Effect: models.PermissionAllow, Description: "Создание записей аудита"},
+		{Action: "create", Resource: "category", Effect: models.PermissionAllow, Description: "Создание категорий"},
+		{Action: "read", Resource: "customer", Effect: models.PermissionAllow, Description: "Просмотр клиентов"},
+		{Action: "create", Resource: "customer", Effect: models.PermissionAllow, Description: "Добавление клиентов"},
+		{Action: "update", Resource: "exchange_rate", Effect: models.PermissionAllow, Description: "Управление курсами валют"},
+		{Action: "read", Resource: "supplier", Effect: models.PermissionAllow, Description: "Просмотр поставщиков"},
+		{Action: "create", Resource: "supplier", Effect: models.PermissionAllow, Description: "Добавление поставщиков"},
+		{Action: "update", Resource: "supplier", Effect: models.PermissionAllow, Description: "Редактирование поставщиков"},
+		{Action: "read", Resource: "purchase_order", Effect: models.PermissionAllow, Description: "Просмотр заказов поставщикам"},
+		{Action: "create", Resource: "purchase_order", Effect: models.PermissionAllow, Description: "Создание заказов поставщикам"},
+		{Action: "update", Resource: "purchase_order", Effect: models.PermissionAllow, Description: "Редактирование, отправка и отмена заказов поставщикам"},
+		{Action: "receive", Resource: "purchase_order", Effect: models.PermissionAllow, Description: "Приёмка поставок"},
+		{Action: "read", Resource: "stock", Effect: models.PermissionAllow, Description: "Просмотр остатков и скрытых товаров каталога"},
+		{Action: "read", Resource: "stock_movement", Effect: models.PermissionAllow, Description: "Просмотр движения остатков"},
+		{Action: "read", Resource: "stock_count", Effect: models.PermissionAllow, Description: "Просмотр инвентаризаций"},
+		{Action: "update", Resource: "stock_count", Effect: models.PermissionAllow, Description: "Проведение инвентаризаций"},
+		{Action: "read", Resource: "stock_alert", Effect: models.PermissionAllow, Description: "Просмотр складских оповещений"},
+		{Action: "update", Resource: "reorder_point", Effect: models.PermissionAllow, Description: "Пересчёт точек заказа"},
+		{Action: "train", Resource: "forecast", Effect: models.PermissionAllow, Description: "Обучение модели прогноза"},
+		{Action: "read", Resource: "forecast", Effect: models.PermissionAllow, Description: "Просмотр результатов прогноза"},
+	}
+
+	created := map[string]bool{}
 	for _, permission := range permissions {
 		item := permission
-		if err := db.Where("action = ? AND resource = ? AND effect = ?", permission.Action, permission.Resource, permission.Effect).FirstOrCreate(&item).Error; err != nil {
-			return err
+		res := db.Where("action = ? AND resource = ? AND effect = ?", permission.Action, permission.Resource, permission.Effect).FirstOrCreate(&item)
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected > 0 {
+			created[permission.Action+":"+permission.Resource] = true
 		}
 	}
 
@@ -167,7 +228,7 @@ func seedPermissions(db *gorm.DB) error {
 		return err
 	}
 	var storedPermissions []models.Permission
-	if err := db.Find(&storedPermissions).Error; err != nil {
+	if err := db.Where("effect = ?", models.PermissionAllow).Find(&storedPermissions).Error; err != nil {
 		return err
 	}
 
@@ -182,38 +243,66 @@ func seedPermissions(db *gorm.DB) error {
 
 	assignments := map[models.RoleName][]string{
 		models.RoleAdmin: {
-			"create:user", "read:user", "block:user",
-			"create:product", "read:product", "update:product", "delete:product",
-			"create:order", "read:order", "update:order", "status:order",
+			"create:user", "read:user", "block:user", "reset_two_factor:user",
+			"read:two_factor_policy", "update:two_factor_policy",
+			"read:role", "create:role", "delete:role", "grant:role",
+			"read:permission", "create:permission", "delete:permission",
+			"read:api_key", "create:api_key", "revoke:api_key",
+			"create:product", "read:product", "update:product", "delete:product", "export:product",
+			"read:price", "update:price", "read:translation", "update:translation",
+			"read:stock_subscription", "moderate:review",
+			"create:order", "read:order", "update:order", "status:order", "fulfil:order", "read:barcode",
 			"read:audit_log", "create:audit_log",
+			"create:category", "read:customer", "create:customer", "update:exchange_rate",
+			"read:supplier", "create:supplier", "update:supplier",
+			"read:purchase_order", "create:purchase_order", "update:purchase_order", "receive:purchase_order",
+			"read:stock", "read:stock_movement", "read:stock_count", "update:stock_count", "read:stock_alert", "update:reorder_point",
 			"train:forecast", "read:forecast",
 		},
 		models.RoleManager: {
-			"read:user",
-			"create:product", "read:product", "update:product",
-			"read:order", "update:order", "status:order",
+			"create:product", "read:product", "update:product", "export:product",
+			"read:price", "update:price", "read:translation", "update:translation",
+			"read:stock_subscription", "moderate:review",
+			"read:order", "update:order", "status:order", "fulfil:order", "read:barcode",
 			"read:audit_log", "create:audit_log",
+			"create:category", "read:customer", "create:customer",
+			"read:supplier", "create:supplier", "update:supplier",
+			"read:purchase_order", "create:purchase_order", "update:purchase_order", "receive:purchase_order",
+			"read:stock", "read:stock_movement", "read:stock_count", "update:stock_count", "read:stock_alert", "update:reorder_point",
 			"read:forecast",
 		},
 		models.RoleWarehouse: {
 			"read:product",
-			"read:order", "update:order", "status:order",
+			"read:order", "update:order", "status:order", "fulfil:order", "read:barcode",
 			"read:audit_log",
+			"read:purchase_order", "receive:purchase_order",
+			"read:stock", "read:stock_movement", "read:stock_count", "update:stock_count", "read:stock_alert",
 		},
 		models.RoleExecutive: {
+			"read:stock", "read:price",
 			"read:order",
 			"read:audit_log",
 			"train:forecast", "read:forecast",
 		},
 		models.RoleClient: {
-			"create:user", "read:user",
-			"read:product",
-			"create:order", "read:order",
+			"read:product", "subscribe:product",
+			"create:review",
+			"create:order", "read:own_order",
 		},
 	}
 
+	// Role mappings are seeded only for permissions created in this run, so
+	// access changed by an administrator survives restarts.
+	if created[permissionCatalogueMarker] {
+		if err := db.Where("1 = 1").Delete(&models.RolePermission{}).Error; err != nil {
+			return err
+		}
+	}
 	for roleName, items := range assignments {
 		for _, item := range items {
+			if !created[item] && !created[permissionCatalogueMarker] {
+				continue
+			}
 			rolePermission := models.RolePermission{
 				RoleID:       roleMap[roleName],
 				PermissionID: permMap[item],
@@ -263,14 +352,14 @@ func seedProducts(db *gorm.DB) error {
 	}
 
 	products := []models.Product{
-		{ID: seedSofaProductID, Name: "Модульный диван «Гавань»", CategoryID: catID["Гостиная"], Price: 56990, OriginalPrice: int64Ptr(70000), Image: "/images/prod-sofa-1.jpg", Description: "Просторный модульный диван для гостиной с мягкой глубокой посадкой", Dimensions: "Ш 280 см × Г 180 см × В 86 см", Material: "Бельгийский лён", StockQty: 12, SKU: "SOF-HVNS-BEI", IsActive: true, Featured: true, Rating: 4.8, Reviews: 124},
-		{ID: seedChairProductID, Name: "Акцентное кресло «Ария»", CategoryID: catID["Гостиная"], Price: 14990, Image: "/images/prod-chair-1.jpg", Description: "Мягкое кресло для зоны отдыха или чтения", Dimensions: "Ш 76 см × Г 82 см × В 84 см", Material: "Велюр", StockQty: 28, SKU: "CHR-ARIA-TER", IsActive: true, Featured: true, Rating: 4.7, Reviews: 89},
-		{ID: seedTableProductID, Name: "Обеденный стол «Страта» из ореха", CategoryID: catID["Столовая"], Price: 4500, OriginalPrice: int64Ptr(6800), Image: "/images/prod-table-1.jpg", Description: "Обеденный стол из натурального ореха для семьи из 6–8 человек", Dimensions: "Ш 200 см × Г 95 см × В 76 см", Material: "Массив ореха", StockQty: 8, SKU: "TBL-STRW-WAL", IsActive: true, Featured: true, Rating: 4.9, Reviews: 56},
-		{ID: seedBedProductID, Name: "Кровать-платформа «Облако»", CategoryID: catID["Спальня"], Price: 66990, Image: "/images/prod-bed-1.jpg", Description: "Кровать с мягким изголовьем и устойчивым основанием", Dimensions: "King Size", Material: "Лён", StockQty: 15, SKU: "BED-CLPL-CRM", IsActive: true, Featured: true, Rating: 4.9, Reviews: 201},
-		{ID: seedBookshelfProductID, Name: "Стеллаж «Латтис» из дуба", CategoryID: catID["Хранение"], Price: 19990, Image: "/images/prod-shelf-1.jpg", Description: "Открытый дубовый стеллаж для книг и декора", Dimensions: "Ш 90 см", Material: "Дуб", StockQty: 20, SKU: "SHF-LTOK-NAT", IsActive: true, Featured: false, Rating: 4.6, Reviews: 43},
-		{ID: seedDeskProductID, Name: "Письменный стол «Студио»", CategoryID: catID["Домашний офис"], Price: 5990, Image: "/images/prod-desk-1.jpg", Description: "Компактный письменный стол для домашнего кабинета", Dimensions: "Ш 140 см", Material: "МДФ", StockQty: 35, SKU: "DSK-STUD-WHT", IsActive: true, Featured: false, Rating: 4.5, Reviews: 67},
-		{ID: seedLampProductID, Name: "Торшер «Солей»", CategoryID: catID["Освещение"], Price: 3800, Image: "/images/prod-lamp-1.jpg", Description: "Напольный светильник с тёплым рассеянным светом", Dimensions: "В 165 см", Material: "Латунь", StockQty: 42, SKU: "LMP-SOLB-BRS", IsActive: true, Featured: false, Rating: 4.7, Reviews: 98},
-		{ID: seedRugProductID, Name: "Шерстяной ковёр «Марракеш»", CategoryID: catID["Ковры и текстиль"], Price: 6500, OriginalPrice: int64Ptr(8000), Image: "/images/prod-rug-1.jpg", Description: "Плотный шерстяной ковёр с геометрическим орнаментом", Dimensions: "250 см × 350 см", Material: "Шерсть", StockQty: 18, SKU: "RUG-MRKW-CRM", IsActive: true, Featured: false, Rating: 4.8, Reviews: 77},
+		{ID: seedSofaProductID, Name: "Модульный диван «Гавань»", CategoryID: catID["Гостиная"], Price: 56990, OriginalPrice: int64Ptr(70000), Image: "/images/prod-sofa-1.jpg", Description: "Просторный модульный диван для гостиной с мягкой глубокой посадкой", Dimensions: "Ш 280 см × Г 180 см × В 86 см", Material: "Бельгийский лён", StockQty: 12, SKU: "SOF-HVNS-BEI", Location: "A-01", IsActive: true, Featured: true, Rating: 4.8, Reviews: 124},
+		{ID: seedChairProductID, Name: "Акцентное кресло «Ария»", CategoryID: catID["Гостиная"], Price: 14990, Image: "/images/prod-chair-1.jpg", Description: "Мягкое кресло для зоны отдыха или чтения", Dimensions: "Ш 76 см × Г 82 см × В 84 см", Material: "Велюр", StockQty: 28, SKU: "CHR-ARIA-TER", Location: "A-01", IsActive: true, Featured: true, Rating: 4.7, Reviews: 89},
+		{ID: seedTableProductID, Name: "Обеденный стол «Страта» из ореха", CategoryID: catID["Столовая"], Price: 4500, OriginalPrice: int64Ptr(6800), Image: "/images/prod-table-1.jpg", Description: "Обеденный стол из натурального ореха для семьи из 6–8 человек", Dimensions: "Ш 200 см × Г 95 см × В 76 см", Material: "Массив ореха", StockQty: 8, SKU: "TBL-STRW-WAL", Location: "A-02", IsActive: true, Featured: true, Rating: 4.9, Reviews: 56},
+		{ID: seedBedProductID, Name: "Кровать-платформа «Облако»", CategoryID: catID["Спальня"], Price: 66990, Image: "/images/prod-bed-1.jpg", Description: "Кровать с мягким изголовьем и устойчивым основанием", Dimensions: "King Size", Material: "Лён", StockQty: 15, SKU: "BED-CLPL-CRM", Location: "A-03", IsActive: true, Featured: true, Rating: 4.9, Reviews: 201},
+		{ID: seedBookshelfProductID, Name: "Стеллаж «Латтис» из дуба", CategoryID: catID["Хранение"], Price: 19990, Image: "/images/prod-shelf-1.jpg", Description: "Открытый дубовый стеллаж для книг и декора", Dimensions: "Ш 90 см", Material: "Дуб", StockQty: 20, SKU: "SHF-LTOK-NAT", Location: "B-01", IsActive: true, Featured: false, Rating: 4.6, Reviews: 43},
+		{ID: seedDeskProductID, Name: "Письменный стол «Студио»", CategoryID: catID["Домашний офис"], Price: 5990, Image: "/images/prod-desk-1.jpg", Description: "Компактный письменный стол для домашнего кабинета", Dimensions: "Ш 140 см", Material: "МДФ", StockQty: 35, SKU: "DSK-STUD-WHT", Location: "B-01", IsActive: true, Featured: false, Rating: 4.5, Reviews: 67},
+		{ID: seedLampProductID, Name: "Торшер «Солей»", CategoryID: catID["Освещение"], Price: 3800, Image: "/images/prod-lamp-1.jpg", Description: "Напольный светильник с тёплым рассеянным светом", Dimensions: "В 165 см", Material: "Латунь", StockQty: 42, SKU: "LMP-SOLB-BRS", Location: "C-01", IsActive: true, Featured: false, Rating: 4.7, Reviews: 98},
+		{ID: seedRugProductID, Name: "Шерстяной ковёр «Марракеш»", CategoryID: catID["Ковры и текстиль"], Price: 6500, OriginalPrice: int64Ptr(8000), Image: "/images/prod-rug-1.jpg", Description: "Плотный шерстяной ковёр с геометрическим орнаментом", Dimensions: "250 см × 350 см", Material: "Шерсть", StockQty: 18, SKU: "RUG-MRKW-CRM", Location: "C-02", IsActive: true, Featured: false, Rating: 4.8, Reviews: 77},
 	}
 
 	for i := range products {
@@ -280,6 +369,100 @@ func seedProducts(db *gorm.DB) error {
 	return db.Create(&products).Error
 }
 
+func seedTranslations(db *gorm.DB) error {
+	var count int64
+	if err := db.Model(&models.ProductTranslation{}).Count(&count).Error; err != nil {
+		return err
+	}
+	if count > 0 {
+		return nil
+	}
+
+	categoryNames := map[string]string{
+		"Гостиная":         "Living Room",
+		"Столовая":         "Dining Room",
+		"Спальня":          "Bedroom",
+		"Хранение":         "Storage",
+		"Домашний офис":    "Home Office",
+		"Освещение":        "Lighting",
+		"Ковры и текстиль": "Rugs & Textiles",
+	}
+	var categories []models.Category
+	if err := db.Find(&categories).Error; err != nil {
+		return err
+	}
+	categoryTranslations := []models.CategoryTranslation{}
+	for _, c := range categories {
+		if name, ok := categoryNames[c.Name]; ok {
+			categoryTranslations = append(categoryTranslations, models.CategoryTranslation{CategoryID: c.ID, Locale: "en", Name: name, UpdatedBy: "system"})
+		}
+	}
+	if len(categoryTranslations) > 0 {
+		if err := db.Create(&categoryTranslations).Error; err != nil {
+			return err
+		}
+	}
+
+	productTranslations := []models.ProductTranslation{
+		{ProductID: seedSofaProductID, Locale: "en", Name: "Haven Modular Sofa", Description: "A spacious modular living room sofa with soft, deep seating", Material: "Belgian linen"},
+		{ProductID: seedChairProductID, Locale: "en", Name: "Aria Accent Chair", Description: "A soft armchair for a lounge or reading corner", Material: "Velour"},
+		{ProductID: seedTableProductID, Locale: "en", Name: "Strata Walnut Dining Table", Description: "A solid walnut dining table for a family of 6–8", Material: "Solid walnut"},
+		{ProductID: seedBedProductID, Locale: "en", Name: "Cloud Platform Bed", Description: "A bed with a padded headboard and a sturdy base", Material: "Linen"},
+		{ProductID: seedBookshelfProductID, Locale: "en", Name: "Lattice Oak Bookshelf", Description: "An open oak shelving unit for books and decor", Material: "Oak"},
+		{ProductID: seedDeskProductID, Locale: "en", Name: "Studio Writing Desk", Description: "A compact desk for a home office", Material: "MDF"},
+		{ProductID: seedLampProductID, Locale: "en", Name: "Soleil Floor Lamp", Description: "A floor lamp with warm, diffused light", Material: "Brass"},
+		{ProductID: seedRugProductID, Locale: "en", Name: "Marrakesh Wool Rug", Description: "A dense wool rug with a geometric pattern", Material: "Wool"},
+	}
+	for i := range productTranslations {
+		productTranslations[i].UpdatedBy = "system"
+	}
+	return db.Create(&productTranslations).Error
+}
+
+func seedSuppliers(db *gorm.DB) error {
+	suppliers := []models.Supplier{
+		{Name: "ООО «Мебельная фабрика Север»", Email: "orders@sever-mebel.ru", Phone: "+7 812 555-01-10", LeadTimeDays: 21, IsActive: true},
+		{Name: "ООО «Текстиль и свет»", Email: "sales@textile-light.ru", Phone: "+7 495 555-02-20", LeadTimeDays: 10, IsActive: true},
+	}
+	for _, supplier := range suppliers {
+		if err := db.Where("name = ?", supplier.Name).FirstOrCreate(&supplier).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func seedPriceHistory(db *gorm.DB) error {
+	var count int64
+	if err := db.Model(&models.PriceHistory{}).Count(&count).Error; err != nil {
+		return err
+	}
+	if count > 0 {
+		return nil
+	}
+
+	var products []models.Product
+	if err := db.Find(&products).Error; err != nil {
+		return err
+	}
+	if len(products) == 0 {
+		return nil
+	}
+
+	entries := make([]models.PriceHistory, 0, len(products))
+	for _, p := range products {
+		entries = append(entries, models.PriceHistory{
+			ProductID:     p.ID,
+			Price:         p.Price,
+			OriginalPrice: p.OriginalPrice,
+			Source:        models.PriceSourceCreate,
+			ChangedBy:     "admin@maison.co",
+			ChangedAt:     p.CreatedAt,
+		})
+	}
+	return db.Create(&entries).Error
+}
+
 func seedCustomersOrdersAndItems(db *gorm.DB) error {
 	var count int64
 	if err := db.Model(&models.Order{}).Count(&count).Error; err != nil {
@@ -419,11 +602,6 @@ func int64Ptr(value int64) *int64 {
 	return &value
 }
 
-func hashPassword(password string) string {
-	hash := sha256.Sum256([]byte("maison-salt:" + password))
-	return hex.EncodeToString(hash[:])
-}
-
 func seedID(prefix, ts string) string {
 	return fmt.Sprintf("%s-%d", prefix, parseTime(ts).UnixNano())
 }
@@ -0,0 +1,118 @@
+package handlers
+
+import (
+	"strings"
+
+	"backend/internal/config"
+	"backend/internal/middleware"
+	"backend/internal/models"
+	"backend/internal/repositories"
+	"backend/internal/services"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+type APIKeyHandler struct {
+	service      *services.APIKeyService
+	auditService *services.AuditService
+}
+
+func NewAPIKeyHandler(db *gorm.DB, cfg config.Config, permissions *services.PermissionService) *APIKeyHandler {
+	return &APIKeyHandler{
+		service:      services.NewAPIKeyService(repositories.NewAPIKeyRepository(db), repositories.NewPermissionRepository(db), repositories.NewUserRepository(db), permissions, cfg.APIKeyTTL),
+		auditService: services.NewAuditService(repositories.NewAuditRepository(db)),
+	}
+}
+
+// Authenticator lets RequireAuth accept the keys issued here.
+func (h *APIKeyHandler) Authenticator() middleware.APIKeyAuthenticator {
+	return h.service
+}
+
+// List returns every API key, including revoked and expired ones.
+// @Summary List API keys
+// @Tags api-keys
+// @Produce json
+// @Security BearerAuth
+// @Security OAuth2Password
+// @Success 200 {array} models.APIKey
+// @Failure 401 {object} handlers.errorResponse
+// @Failure 403 {object} handlers.errorResponse
+// @Router /api-keys [get]
+func (h *APIKeyHandler) List(c *fiber.Ctx) error {
+	keys, err := h.service.List()
+	if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, "failed to fetch api keys")
+	}
+	return c.JSON(keys)
+}
+
+// Create issues a scoped API key. The key is returned only in this response.
+// @Summary Create API key
+// @Tags api-keys
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Security OAuth2Password
+// @Param payload body services.APIKeyInput true "API key payload"
+// @Success 201 {object} models.APIKeyCreated
+// @Failure 400 {object} handlers.errorResponse
+// @Failure 401 {object} handlers.errorResponse
+// @Failure 403 {object} handlers.errorResponse
+// @Router /api-keys [post]
+func (h *APIKeyHandler) Create(c *fiber.Ctx) error {
+	var payload services.APIKeyInput
+	if err := c.BodyParser(&payload); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "invalid json")
+	}
+	claims, _ := middleware.ClaimsFromCtx(c)
+	created, err := h.service.Create(payload, claims.Email, claims.Role)
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+	_, _ = h.auditService.Create(models.AuditLog{
+		Action:   "API Key Created",
+		Category: models.AuditCategorySystem,
+		User:     claims.Email,
+		Details:  "Created API key " + created.APIKey.Name + " (" + created.APIKey.Prefix + ") with scopes " + strings.Join(created.APIKey.Scopes, ", "),
+		Severity: models.AuditSeverityWarning,
+		Entity:   "api_key",
+		EntityID: created.APIKey.ID,
+		Result:   "ok",
+	})
+	return c.Status(fiber.StatusCreated).JSON(created)
+}
+
+// Revoke stops an API key from authenticating.
+// @Summary Revoke API key
+// @Tags api-keys
+// @Security BearerAuth
+// @Security OAuth2Password
+// @Param id path string true "API key ID"
+// @Success 204
+// @Failure 401 {object} handlers.errorResponse
+// @Failure 403 {object} handlers.errorResponse
+// @Failure 404 {object} handlers.errorResponse
+// @Router /api-keys/{id} [delete]
+func (h *APIKeyHandler) Revoke(c *fiber.Ctx) error {
+	key, err := h.service.Revoke(c.Params("id"))
+	if err != nil {
+		if services.IsNotFound(err) {
+			return fiber.NewError(fiber.StatusNotFound, "api key not found")
+		}
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+	claims, _ := middleware.ClaimsFromCtx(c)
+	_, _ = h.auditService.Create(models.AuditLog{
+		Action:   "API Key Revoked",
+		Category: models.AuditCategorySystem,
+		User:     claims.Email,
+		Details:  "Revoked API key " + key.Name + " (" + key.Prefix + ")",
+		Severity: models.AuditSeverityWarning,
+		Entity:   "api_key",
+		EntityID: key.ID,
+		Result:   "ok",
+	})
+	return c.SendStatus(fiber.StatusNoContent)
+}
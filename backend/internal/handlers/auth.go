@@ -1,11 +1,16 @@
 package handlers
 
 import (
+	"errors"
+	"log"
 	"strings"
+	"time"
 
+	"backend/internal/config"
 	"backend/internal/middleware"
 	"backend/internal/models"
 	"backend/internal/repositories"
+	"backend/internal/security"
 	"backend/internal/services"
 
 	"github.com/gofiber/fiber/v2"
@@ -13,16 +18,26 @@ import (
 )
 
 type AuthHandler struct {
-	authService  *services.AuthService
-	auditService *services.AuditService
+	keys             *security.KeySet
+	authService      *services.AuthService
+	accountService   *services.AccountService
+	twoFactorService *services.TwoFactorService
+	throttle         *services.LoginThrottle
+	auditService     *services.AuditService
 }
 
-func NewAuthHandler(db *gorm.DB, appSecret string) *AuthHandler {
+func NewAuthHandler(db *gorm.DB, keys *security.KeySet, cfg config.Config, limits services.CounterStore) *AuthHandler {
 	userRepo := repositories.NewUserRepository(db)
 	auditRepo := repositories.NewAuditRepository(db)
+	twoFactorRepo := repositories.NewTwoFactorRepository(db)
+	authService := newAuthService(db, keys, cfg)
 	return &AuthHandler{
-		authService:  services.NewAuthService(userRepo, appSecret),
-		auditService: services.NewAuditService(auditRepo),
+		keys:             keys,
+		authService:      authService,
+		accountService:   services.NewAccountService(userRepo, repositories.NewAccountTokenRepository(db), authService, services.NewMailer(cfg.MailDir), cfg.PasswordResetTTL, cfg.EmailVerificationTTL, cfg.PublicURL),
+		twoFactorService: services.NewTwoFactorService(userRepo, twoFactorRepo, authService),
+		throttle:         services.NewLoginThrottle(limits),
+		auditService:     services.NewAuditService(auditRepo),
 	}
 }
 
@@ -36,6 +51,34 @@ type tokenRequest struct {
 	Email     string `form:"email" json:"email"`
 	Password  string `form:"password" json:"password"`
 	GrantType string `form:"grant_type" json:"grant_type"`
+	// OTP is the second factor for accounts with two-factor authentication.
+	OTP string `form:"otp" json:"otp"`
+}
+
+type twoFactorLoginRequest struct {
+	Challenge string `json:"challenge"`
+	Code      string `json:"code"`
+}
+
+type refreshRequest struct {
+	RefreshToken string `json:"refresh_token"`
+}
+
+type forgotPasswordRequest struct {
+	Email string `json:"email"`
+}
+
+type resetPasswordRequest struct {
+	Token    string `json:"token"`
+	Password string `json:"password"`
+}
+
+type ssoLinkRequest struct {
+	Allowed bool `json:"allowed"`
+}
+
+type verifyEmailRequest struct {
+	Token string `json:"token"`
 }
 
 type registerRequest struct {
@@ -51,7 +94,9 @@ type signupRequest struct {
 	Name     string `json:"name"`
 }
 
-// Login authenticates a user and returns a bearer token.
+// Login authenticates a user and returns a bearer token. Accounts with
+// two-factor authentication get a challenge instead, to be completed at
+// /auth/login/2fa or, when enrollment is required, /auth/2fa/enable.
 // @Summary Login
 // @Tags auth
 // @Accept json
@@ -60,23 +105,67 @@ type signupRequest struct {
 // @Success 200 {object} models.LoginResponse
 // @Failure 400 {object} handlers.errorResponse
 // @Failure 401 {object} handlers.errorResponse
+// @Failure 429 {object} handlers.errorResponse
 // @Router /auth/login [post]
 func (h *AuthHandler) Login(c *fiber.Ctx) error {
 	var payload loginRequest
 	if err := c.BodyParser(&payload); err != nil {
 		return fiber.NewError(fiber.StatusBadRequest, "invalid json")
 	}
+	if err := h.checkLockout(c, payload.Email); err != nil {
+		return err
+	}
 
 	response, err := h.authService.Login(payload.Email, payload.Password)
 	if err != nil {
 		_ = h.createAudit("Failed Login Attempt", models.AuditCategoryUser, strings.TrimSpace(payload.Email), "Failed login attempt", models.AuditSeverityWarning, "user", "", "failed")
+		h.loginFailed(c, payload.Email)
 		return fiber.NewError(fiber.StatusUnauthorized, err.Error())
 	}
+	if response.Challenge != "" {
+		_ = h.createAudit("Two-Factor Challenge", models.AuditCategoryUser, response.User.Email, "Password accepted, second factor "+response.TwoFactor, models.AuditSeverityInfo, "user", response.User.ID, "ok")
+		return c.JSON(response)
+	}
 
+	h.loginSucceeded(response.User.Email)
 	_ = h.createAudit("User Login", models.AuditCategoryUser, response.User.Email, "Successful login", models.AuditSeverityInfo, "user", response.User.ID, "ok")
 	return c.JSON(response)
 }
 
+// LoginTwoFactor completes a login challenge with a TOTP or recovery code.
+// @Summary Second login step
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param payload body twoFactorLoginRequest true "Challenge and code"
+// @Success 200 {object} models.LoginResponse
+// @Failure 400 {object} handlers.errorResponse
+// @Failure 401 {object} handlers.errorResponse
+// @Failure 429 {object} handlers.errorResponse
+// @Router /auth/login/2fa [post]
+func (h *AuthHandler) LoginTwoFactor(c *fiber.Ctx) error {
+	var payload twoFactorLoginRequest
+	if err := c.BodyParser(&payload); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "invalid json")
+	}
+	// The account is only known from the challenge. An unknown challenge
+	// fails below and is throttled by IP alone.
+	account, _ := h.twoFactorService.ChallengeUser(payload.Challenge)
+	if err := h.checkLockout(c, account.Email); err != nil {
+		return err
+	}
+
+	response, err := h.twoFactorService.CompleteLogin(payload.Challenge, payload.Code)
+	if err != nil {
+		_ = h.createAudit("Failed Two-Factor Attempt", models.AuditCategoryUser, response.User.Email, "Failed second login step", models.AuditSeverityWarning, "user", response.User.ID, "failed")
+		h.loginFailed(c, response.User.Email)
+		return fiber.NewError(fiber.StatusUnauthorized, err.Error())
+	}
+	h.loginSucceeded(response.User.Email)
+	_ = h.createAudit("User Login", models.AuditCategoryUser, response.User.Email, "Successful login with two-factor authentication", models.AuditSeverityInfo, "user", response.User.ID, "ok")
+	return c.JSON(response)
+}
+
 // Token authenticates a user for Swagger OAuth2 password flow.
 // Use the `username` field to pass the email address.
 // @Summary OAuth2 token
@@ -88,9 +177,11 @@ func (h *AuthHandler) Login(c *fiber.Ctx) error {
 // @Param email formData string false "Alternative email field"
 // @Param password formData string true "Password"
 // @Param grant_type formData string false "OAuth2 grant type"
+// @Param otp formData string false "TOTP or recovery code for accounts with two-factor authentication"
 // @Success 200 {object} models.TokenResponse
 // @Failure 400 {object} handlers.errorResponse
 // @Failure 401 {object} handlers.errorResponse
+// @Failure 429 {object} handlers.errorResponse
 // @Router /auth/token [post]
 func (h *AuthHandler) Token(c *fiber.Ctx) error {
 	var payload tokenRequest
@@ -102,21 +193,112 @@ func (h *AuthHandler) Token(c *fiber.Ctx) error {
 	if email == "" {
 		email = strings.TrimSpace(payload.Username)
 	}
+	if err := h.checkLockout(c, email); err != nil {
+		return err
+	}
 
 	response, err := h.authService.Login(email, payload.Password)
 	if err != nil {
 		_ = h.createAudit("Failed Token Request", models.AuditCategoryUser, email, "Failed OAuth2 token request", models.AuditSeverityWarning, "user", "", "failed")
+		h.loginFailed(c, email)
 		return fiber.NewError(fiber.StatusUnauthorized, err.Error())
 	}
+	switch response.TwoFactor {
+	case models.TwoFactorEnrollmentRequired:
+		return fiber.NewError(fiber.StatusUnauthorized, "two-factor enrollment required, sign in through /api/auth/login first")
+	case models.TwoFactorRequired:
+		if strings.TrimSpace(payload.OTP) == "" {
+			return fiber.NewError(fiber.StatusUnauthorized, "two-factor code required in the otp field")
+		}
+		response, err = h.twoFactorService.CompleteLogin(response.Challenge, payload.OTP)
+		if err != nil {
+			_ = h.createAudit("Failed Token Request", models.AuditCategoryUser, email, "Invalid second factor in OAuth2 token request", models.AuditSeverityWarning, "user", response.User.ID, "failed")
+			h.loginFailed(c, email)
+			return fiber.NewError(fiber.StatusUnauthorized, err.Error())
+		}
+	}
+	h.loginSucceeded(response.User.Email)
 
 	_ = h.createAudit("OAuth2 Token Issued", models.AuditCategoryUser, response.User.Email, "OAuth2 password flow token issued", models.AuditSeverityInfo, "user", response.User.ID, "ok")
 	return c.JSON(models.TokenResponse{
-		AccessToken: response.Token,
-		TokenType:   "Bearer",
-		ExpiresIn:   43200,
+		AccessToken:  response.Token,
+		TokenType:    "Bearer",
+		ExpiresIn:    response.ExpiresIn,
+		RefreshToken: response.RefreshToken,
 	})
 }
 
+// Refresh exchanges a refresh token for a new token pair. The presented
+// refresh token stops working; presenting it again after the reuse grace
+// revokes the session.
+// @Summary Refresh tokens
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param payload body refreshRequest true "Refresh payload"
+// @Success 200 {object} models.LoginResponse
+// @Failure 400 {object} handlers.errorResponse
+// @Failure 401 {object} handlers.errorResponse
+// @Router /auth/refresh [post]
+func (h *AuthHandler) Refresh(c *fiber.Ctx) error {
+	var payload refreshRequest
+	if err := c.BodyParser(&payload); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "invalid json")
+	}
+
+	response, err := h.authService.Refresh(payload.RefreshToken)
+	if err != nil {
+		if errors.Is(err, services.ErrRefreshTokenReused) {
+			_ = h.createAudit("Refresh Token Reuse", models.AuditCategoryUser, response.User.Email, "Used refresh token presented again, session revoked", models.AuditSeverityWarning, "user", response.User.ID, "failed")
+		}
+		return fiber.NewError(fiber.StatusUnauthorized, err.Error())
+	}
+	return c.JSON(response)
+}
+
+// Logout revokes the session of the given refresh token, or of the bearer
+// token when the body has none.
+// @Summary Logout
+// @Tags auth
+// @Accept json
+// @Param payload body refreshRequest false "Logout payload"
+// @Success 204
+// @Failure 400 {object} handlers.errorResponse
+// @Failure 401 {object} handlers.errorResponse
+// @Router /auth/logout [post]
+func (h *AuthHandler) Logout(c *fiber.Ctx) error {
+	var payload refreshRequest
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(&payload); err != nil {
+			return fiber.NewError(fiber.StatusBadRequest, "invalid json")
+		}
+	}
+	sessionID := ""
+	if claims, ok := middleware.ClaimsFromCtx(c); ok {
+		sessionID = claims.SessionID
+	}
+
+	user, err := h.authService.Logout(payload.RefreshToken, sessionID)
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidRefreshToken) || errors.Is(err, services.ErrSessionRevoked) {
+			return fiber.NewError(fiber.StatusUnauthorized, err.Error())
+		}
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+	_ = h.createAudit("User Logout", models.AuditCategoryUser, user.Email, "Session revoked on logout", models.AuditSeverityInfo, "user", user.ID, "ok")
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+// Checker exposes the session check used by the auth middleware.
+func (h *AuthHandler) Checker() middleware.SessionChecker {
+	return h.authService
+}
+
+// Verifier exposes the email verification check used by the auth middleware.
+func (h *AuthHandler) Verifier() middleware.EmailVerifier {
+	return h.authService
+}
+
 // Register creates a new internal user account.
 // @Summary Register user
 // @Tags auth
@@ -165,9 +347,139 @@ func (h *AuthHandler) Signup(c *fiber.Ctx) error {
 	}
 
 	_ = h.createAudit("Client Registered", models.AuditCategoryUser, user.Email, "Client account created", models.AuditSeverityInfo, "user", user.ID, "ok")
+	if err := h.accountService.SendVerification(user); err != nil {
+		log.Printf("auth: send verification to %s: %v", user.Email, err)
+	}
 	return c.Status(fiber.StatusCreated).JSON(models.AdminUser{ID: user.ID, Email: user.Email, Name: user.Name, Role: user.Role.Name})
 }
 
+// ForgotPassword mails a password reset link. The response is the same
+// whether or not the address belongs to an account.
+// @Summary Request password reset
+// @Tags auth
+// @Accept json
+// @Param payload body forgotPasswordRequest true "Forgot password payload"
+// @Success 202
+// @Failure 400 {object} handlers.errorResponse
+// @Router /auth/password/forgot [post]
+func (h *AuthHandler) ForgotPassword(c *fiber.Ctx) error {
+	var payload forgotPasswordRequest
+	if err := c.BodyParser(&payload); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "invalid json")
+	}
+
+	user, err := h.accountService.ForgotPassword(payload.Email)
+	if err != nil {
+		if user.ID == "" {
+			return fiber.NewError(fiber.StatusBadRequest, err.Error())
+		}
+		log.Printf("auth: send password reset to %s: %v", user.Email, err)
+	}
+	if user.ID != "" {
+		_ = h.createAudit("Password Reset Requested", models.AuditCategoryUser, user.Email, "Password reset link sent", models.AuditSeverityInfo, "user", user.ID, "ok")
+	}
+	return c.SendStatus(fiber.StatusAccepted)
+}
+
+// ResetPassword sets a new password with a mailed reset token. Existing
+// sessions are revoked.
+// @Summary Reset password
+// @Tags auth
+// @Accept json
+// @Param payload body resetPasswordRequest true "Reset password payload"
+// @Success 204
+// @Failure 400 {object} handlers.errorResponse
+// @Router /auth/password/reset [post]
+func (h *AuthHandler) ResetPassword(c *fiber.Ctx) error {
+	var payload resetPasswordRequest
+	if err := c.BodyParser(&payload); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "invalid json")
+	}
+
+	user, err := h.accountService.ResetPassword(payload.Token, payload.Password)
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+	_ = h.createAudit("Password Reset", models.AuditCategoryUser, user.Email, "Password changed with reset link, sessions revoked", models.AuditSeverityWarning, "user", user.ID, "ok")
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+// VerifyEmail confirms an email address with a mailed verification token.
+// @Summary Verify email
+// @Tags auth
+// @Accept json
+// @Param payload body verifyEmailRequest true "Verification payload"
+// @Success 204
+// @Failure 400 {object} handlers.errorResponse
+// @Router /auth/verify-email [post]
+func (h *AuthHandler) VerifyEmail(c *fiber.Ctx) error {
+	var payload verifyEmailRequest
+	if err := c.BodyParser(&payload); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "invalid json")
+	}
+
+	user, err := h.accountService.VerifyEmail(payload.Token)
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+	_ = h.createAudit("Email Verified", models.AuditCategoryUser, user.Email, "Email address verified", models.AuditSeverityInfo, "user", user.ID, "ok")
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+// ResendVerification mails a new verification link to the current user.
+// @Summary Resend verification email
+// @Tags auth
+// @Security BearerAuth
+// @Security OAuth2Password
+// @Success 202
+// @Failure 400 {object} handlers.errorResponse
+// @Failure 401 {object} handlers.errorResponse
+// @Router /auth/verify-email/resend [post]
+func (h *AuthHandler) ResendVerification(c *fiber.Ctx) error {
+	claims, ok := middleware.ClaimsFromCtx(c)
+	if !ok {
+		return fiber.NewError(fiber.StatusUnauthorized, "unauthorized")
+	}
+	if _, err := h.accountService.ResendVerification(claims.UserID); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+	return c.SendStatus(fiber.StatusAccepted)
+}
+
+// AllowSSOLink lets the current user allow or forbid single sign-on to link
+// their account, which it refuses for Admin and Client accounts otherwise.
+// @Summary Allow single sign-on linking
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Security OAuth2Password
+// @Param payload body ssoLinkRequest true "Whether linking is allowed"
+// @Success 200 {object} models.User
+// @Failure 400 {object} handlers.errorResponse
+// @Failure 401 {object} handlers.errorResponse
+// @Router /auth/oidc/link [put]
+func (h *AuthHandler) AllowSSOLink(c *fiber.Ctx) error {
+	claims, ok := middleware.ClaimsFromCtx(c)
+	if !ok {
+		return fiber.NewError(fiber.StatusUnauthorized, "unauthorized")
+	}
+	var payload ssoLinkRequest
+	if err := c.BodyParser(&payload); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "invalid json")
+	}
+	user, err := h.accountService.AllowSingleSignOnLink(claims.UserID, payload.Allowed)
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+	details := "Single sign-on linking forbidden"
+	if payload.Allowed {
+		details = "Single sign-on linking allowed"
+	}
+	_ = h.createAudit("SSO Link Setting Changed", models.AuditCategoryUser, user.Email, details, models.AuditSeverityWarning, "user", user.ID, "ok")
+	return c.JSON(user)
+}
+
 // Me returns current authenticated user.
 // @Summary Current user
 // @Tags auth
@@ -185,7 +497,56 @@ func (h *AuthHandler) Me(c *fiber.Ctx) error {
 	return c.JSON(models.AdminUser{ID: claims.UserID, Email: claims.Email, Role: claims.Role, Name: claims.Email})
 }
 
+// checkLockout refuses a login while the account or the client IP is locked
+// out after repeated failures.
+func (h *AuthHandler) checkLockout(c *fiber.Ctx, email string) error {
+	wait, err := h.throttle.Locked(email, c.IP(), time.Now().UTC())
+	if err != nil {
+		log.Printf("auth: login throttle: %v", err)
+		return nil
+	}
+	if wait > 0 {
+		middleware.SetRetryAfter(c, wait)
+		return fiber.NewError(fiber.StatusTooManyRequests, "too many failed login attempts, try again later")
+	}
+	return nil
+}
+
+func (h *AuthHandler) loginFailed(c *fiber.Ctx, email string) {
+	wait, err := h.throttle.Failed(email, c.IP(), time.Now().UTC())
+	if err != nil {
+		log.Printf("auth: login throttle: %v", err)
+		return
+	}
+	if wait > 0 {
+		_ = h.createAudit("Login Lockout", models.AuditCategoryUser, strings.TrimSpace(email), "Logins locked for "+wait.String()+" after repeated failures from "+c.IP(), models.AuditSeverityCritical, "user", "", "failed")
+	}
+}
+
+func (h *AuthHandler) loginSucceeded(email string) {
+	if err := h.throttle.Succeeded(email); err != nil {
+		log.Printf("auth: login throttle: %v", err)
+	}
+}
+
 func (h *AuthHandler) createAudit(action string, category models.AuditCategory, user, details string, severity models.AuditSeverity, entity, entityID, result string) error {
 	_, err := h.auditService.Create(models.AuditLog{Action: action, Category: category, User: user, Details: details, Severity: severity, Entity: entity, EntityID: entityID, Result: result})
 	return err
 }
+
+// JWKS publishes the public keys that verify access tokens. It is only
+// available when RS256 or EdDSA keys are configured.
+// @Summary JSON Web Key Set
+// @Tags auth
+// @Produce json
+// @Success 200 {object} security.JWKSet
+// @Failure 404 {object} handlers.errorResponse
+// @Router /auth/jwks.json [get]
+func (h *AuthHandler) JWKS(c *fiber.Ctx) error {
+	set := h.keys.JWKS()
+	if len(set.Keys) == 0 {
+		return fiber.NewError(fiber.StatusNotFound, "no asymmetric keys configured")
+	}
+	c.Set(fiber.HeaderCacheControl, "public, max-age=300")
+	return c.JSON(set)
+}
@@ -0,0 +1,139 @@
+package handlers
+
+import (
+	"fmt"
+	"net/url"
+
+	"backend/internal/config"
+	"backend/internal/repositories"
+	"backend/internal/services"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+type BarcodeHandler struct {
+	products *repositories.ProductRepository
+	orders   *repositories.OrderRepository
+	lookup   *services.LookupService
+	// publicURL is the storefront address order QR codes link to.
+	publicURL string
+}
+
+func NewBarcodeHandler(db *gorm.DB, cfg config.Config) *BarcodeHandler {
+	products := repositories.NewProductRepository(db)
+	orders := repositories.NewOrderRepository(db)
+	return &BarcodeHandler{products: products, orders: orders, lookup: services.NewLookupService(products, orders, cfg.BaseCurrency), publicURL: cfg.PublicURL}
+}
+
+// ProductBarcode renders the product barcode, or its SKU as Code128 when it has none.
+// @Summary Product barcode image
+// @Tags barcodes
+// @Produce image/png
+// @Security BearerAuth
+// @Security OAuth2Password
+// @Param id path string true "Product ID"
+// @Param width query int false "Width in pixels" default(300)
+// @Param height query int false "Height in pixels" default(100)
+// @Success 200 {file} file
+// @Failure 400 {object} handlers.errorResponse
+// @Failure 401 {object} handlers.errorResponse
+// @Failure 403 {object} handlers.errorResponse
+// @Failure 404 {object} handlers.errorResponse
+// @Router /products/{id}/barcode [get]
+func (h *BarcodeHandler) ProductBarcode(c *fiber.Ctx) error {
+	product, err := h.products.GetByID(c.Params("id"))
+	if err != nil {
+		if services.IsNotFound(err) {
+			return fiber.NewError(fiber.StatusNotFound, "product not found")
+		}
+		return fiber.NewError(fiber.StatusInternalServerError, err.Error())
+	}
+	width, err := queryPixels(c, "width", 300)
+	if err != nil {
+		return err
+	}
+	height, err := queryPixels(c, "height", 100)
+	if err != nil {
+		return err
+	}
+	value, typ := services.ProductBarcodeValue(product)
+	data, err := services.RenderBarcodePNG(value, typ, width, height)
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+	c.Set(fiber.HeaderContentType, "image/png")
+	return c.Send(data)
+}
+
+// OrderQR renders a QR code linking to the order.
+// @Summary Order QR code image
+// @Tags barcodes
+// @Produce image/png
+// @Security BearerAuth
+// @Security OAuth2Password
+// @Param id path string true "Order ID"
+// @Param size query int false "Size in pixels" default(256)
+// @Success 200 {file} file
+// @Failure 400 {object} handlers.errorResponse
+// @Failure 401 {object} handlers.errorResponse
+// @Failure 403 {object} handlers.errorResponse
+// @Failure 404 {object} handlers.errorResponse
+// @Router /orders/{id}/qr [get]
+func (h *BarcodeHandler) OrderQR(c *fiber.Ctx) error {
+	order, err := h.orders.GetByID(c.Params("id"))
+	if err != nil {
+		if services.IsNotFound(err) {
+			return fiber.NewError(fiber.StatusNotFound, "order not found")
+		}
+		return fiber.NewError(fiber.StatusInternalServerError, err.Error())
+	}
+	size, err := queryPixels(c, "size", 256)
+	if err != nil {
+		return err
+	}
+	data, err := services.RenderQRPNG(services.OrderURL(h.publicURL, order.ID), size)
+	if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, err.Error())
+	}
+	c.Set(fiber.HeaderContentType, "image/png")
+	return c.Send(data)
+}
+
+// Lookup resolves a scanned barcode or QR code to a product or an order.
+// @Summary Look up scanned code
+// @Tags barcodes
+// @Produce json
+// @Security BearerAuth
+// @Security OAuth2Password
+// @Param code path string true "Barcode, SKU, product ID, order ID or order link (URL-encoded)"
+// @Success 200 {object} models.LookupResult
+// @Failure 401 {object} handlers.errorResponse
+// @Failure 403 {object} handlers.errorResponse
+// @Failure 404 {object} handlers.errorResponse
+// @Router /lookup/{code} [get]
+func (h *BarcodeHandler) Lookup(c *fiber.Ctx) error {
+	code, err := url.PathUnescape(c.Params("code"))
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "invalid code")
+	}
+	result, err := h.lookup.Lookup(code)
+	if err != nil {
+		if services.IsNotFound(err) {
+			return fiber.NewError(fiber.StatusNotFound, "nothing found for this code")
+		}
+		return fiber.NewError(fiber.StatusInternalServerError, err.Error())
+	}
+	return c.JSON(result)
+}
+
+const maxImageSize = 2000
+
+// queryPixels reads an image size in pixels from the query.
+func queryPixels(c *fiber.Ctx, key string, fallback int) (int, error) {
+	value := c.QueryInt(key, fallback)
+	if value <= 0 || value > maxImageSize {
+		return 0, fiber.NewError(fiber.StatusBadRequest, fmt.Sprintf("%s must be between 1 and %d pixels", key, maxImageSize))
+	}
+	return value, nil
+}
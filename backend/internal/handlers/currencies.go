@@ -0,0 +1,151 @@
+package handlers
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"backend/internal/config"
+	"backend/internal/middleware"
+	"backend/internal/models"
+	"backend/internal/repositories"
+	"backend/internal/services"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+type CurrencyHandler struct {
+	service      *services.CurrencyService
+	auditService *services.AuditService
+}
+
+func NewCurrencyHandler(db *gorm.DB, cfg config.Config) *CurrencyHandler {
+	return &CurrencyHandler{
+		service:      newCurrencyService(db, cfg),
+		auditService: services.NewAuditService(repositories.NewAuditRepository(db)),
+	}
+}
+
+type setExchangeRatesRequest struct {
+	Rates []services.ExchangeRateInput `json:"rates"`
+}
+
+// List returns the base currency and all exchange rates.
+// @Summary List exchange rates
+// @Tags currencies
+// @Produce json
+// @Success 200 {array} models.ExchangeRate
+// @Failure 500 {object} handlers.errorResponse
+// @Router /exchange-rates [get]
+func (h *CurrencyHandler) List(c *fiber.Ctx) error {
+	rates, err := h.service.Rates()
+	if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, "failed to fetch exchange rates")
+	}
+	return c.JSON(rates)
+}
+
+// Set creates or updates exchange rates. A rate is the number of currency
+// units per one unit of the base currency.
+// @Summary Set exchange rates
+// @Tags currencies
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Security OAuth2Password
+// @Param payload body setExchangeRatesRequest true "Exchange rates"
+// @Success 200 {array} models.ExchangeRate
+// @Failure 400 {object} handlers.errorResponse
+// @Failure 401 {object} handlers.errorResponse
+// @Failure 403 {object} handlers.errorResponse
+// @Router /exchange-rates [put]
+func (h *CurrencyHandler) Set(c *fiber.Ctx) error {
+	var payload setExchangeRatesRequest
+	if err := c.BodyParser(&payload); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "invalid json")
+	}
+
+	claims, _ := middleware.ClaimsFromCtx(c)
+	rates, err := h.service.SetRates(payload.Rates, claims.Email)
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+	_ = h.audit("Exchange Rates Updated", claims.Email, fmt.Sprintf("Exchange rates updated: %s", describeRates(rates)))
+	return c.JSON(rates)
+}
+
+// Import uploads a CSV file with currency, rate and optional rounding columns.
+// @Summary Import exchange rates
+// @Tags currencies
+// @Accept multipart/form-data
+// @Produce json
+// @Security BearerAuth
+// @Security OAuth2Password
+// @Param file formData file true "CSV file"
+// @Success 200 {array} models.ExchangeRate
+// @Failure 400 {object} handlers.errorResponse
+// @Failure 401 {object} handlers.errorResponse
+// @Failure 403 {object} handlers.errorResponse
+// @Router /exchange-rates/import [post]
+func (h *CurrencyHandler) Import(c *fiber.Ctx) error {
+	header, err := c.FormFile("file")
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "file is required")
+	}
+	file, err := header.Open()
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "failed to read file")
+	}
+	defer file.Close()
+	data, err := io.ReadAll(file)
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "failed to read file")
+	}
+
+	claims, _ := middleware.ClaimsFromCtx(c)
+	rates, err := h.service.Import(data, claims.Email)
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+	_ = h.audit("Exchange Rates Imported", claims.Email, fmt.Sprintf("Exchange rates imported from %s: %s", header.Filename, describeRates(rates)))
+	return c.JSON(rates)
+}
+
+// Delete removes an exchange rate; prices can no longer be shown in that currency.
+// @Summary Delete exchange rate
+// @Tags currencies
+// @Security BearerAuth
+// @Security OAuth2Password
+// @Param currency path string true "Currency code"
+// @Success 204
+// @Failure 400 {object} handlers.errorResponse
+// @Failure 401 {object} handlers.errorResponse
+// @Failure 403 {object} handlers.errorResponse
+// @Failure 404 {object} handlers.errorResponse
+// @Router /exchange-rates/{currency} [delete]
+func (h *CurrencyHandler) Delete(c *fiber.Ctx) error {
+	currency := strings.ToUpper(strings.TrimSpace(c.Params("currency")))
+	if err := h.service.Delete(currency); err != nil {
+		if services.IsNotFound(err) {
+			return fiber.NewError(fiber.StatusNotFound, "exchange rate not found")
+		}
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+	claims, _ := middleware.ClaimsFromCtx(c)
+	_ = h.audit("Exchange Rate Deleted", claims.Email, fmt.Sprintf("Exchange rate %s deleted", currency))
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+func (h *CurrencyHandler) audit(action, user, details string) error {
+	_, err := h.auditService.Create(models.AuditLog{Action: action, Category: models.AuditCategorySystem, User: user, Details: details, Severity: models.AuditSeverityInfo, Entity: "exchange_rate", Result: "ok"})
+	return err
+}
+
+func describeRates(rates []models.ExchangeRate) string {
+	parts := make([]string, 0, len(rates))
+	for _, r := range rates {
+		parts = append(parts, fmt.Sprintf("%s=%g", r.Currency, r.Rate))
+	}
+	return strings.Join(parts, ", ")
+}
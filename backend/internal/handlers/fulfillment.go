@@ -0,0 +1,184 @@
+package handlers
+
+import (
+	"fmt"
+	"strings"
+
+	"backend/internal/config"
+	"backend/internal/middleware"
+	"backend/internal/models"
+	"backend/internal/repositories"
+	"backend/internal/services"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+type FulfillmentHandler struct {
+	service      *services.FulfillmentService
+	auditService *services.AuditService
+}
+
+func NewFulfillmentHandler(db *gorm.DB, cfg config.Config) *FulfillmentHandler {
+	return &FulfillmentHandler{
+		service:      services.NewFulfillmentService(repositories.NewFulfillmentRepository(db), repositories.NewOrderRepository(db), cfg.PublicURL),
+		auditService: services.NewAuditService(repositories.NewAuditRepository(db)),
+	}
+}
+
+type fulfillmentLinesRequest struct {
+	// LineIDs selects order lines; empty means all lines of the order.
+	LineIDs []uint `json:"line_ids"`
+}
+
+// PickList batches processing orders by product and location.
+// @Summary Get pick list
+// @Tags warehouse
+// @Produce json
+// @Security BearerAuth
+// @Security OAuth2Password
+// @Param location query string false "Only products stored at this location"
+// @Success 200 {object} models.PickList
+// @Failure 401 {object} handlers.errorResponse
+// @Failure 403 {object} handlers.errorResponse
+// @Router /warehouse/pick-list [get]
+func (h *FulfillmentHandler) PickList(c *fiber.Ctx) error {
+	list, err := h.service.PickList(c.Query("location"))
+	if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, err.Error())
+	}
+	return c.JSON(list)
+}
+
+// Get returns the order lines with their picking and packing state.
+// @Summary Get order fulfillment
+// @Tags warehouse
+// @Produce json
+// @Security BearerAuth
+// @Security OAuth2Password
+// @Param id path string true "Order ID"
+// @Success 200 {object} models.Fulfillment
+// @Failure 401 {object} handlers.errorResponse
+// @Failure 403 {object} handlers.errorResponse
+// @Failure 404 {object} handlers.errorResponse
+// @Router /orders/{id}/fulfillment [get]
+func (h *FulfillmentHandler) Get(c *fiber.Ctx) error {
+	fulfillment, err := h.service.Get(c.Params("id"))
+	if err != nil {
+		return fulfillmentError(err)
+	}
+	return c.JSON(fulfillment)
+}
+
+// Pick records order lines as picked.
+// @Summary Pick order lines
+// @Tags warehouse
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Security OAuth2Password
+// @Param id path string true "Order ID"
+// @Param payload body fulfillmentLinesRequest false "Lines to pick, all lines if empty"
+// @Success 200 {object} models.Fulfillment
+// @Failure 400 {object} handlers.errorResponse
+// @Failure 401 {object} handlers.errorResponse
+// @Failure 403 {object} handlers.errorResponse
+// @Failure 404 {object} handlers.errorResponse
+// @Router /orders/{id}/pick [post]
+func (h *FulfillmentHandler) Pick(c *fiber.Ctx) error {
+	payload, err := parseFulfillmentLines(c)
+	if err != nil {
+		return err
+	}
+	claims, _ := middleware.ClaimsFromCtx(c)
+	fulfillment, err := h.service.Pick(c.Params("id"), payload.LineIDs, claims.Email)
+	if err != nil {
+		return fulfillmentError(err)
+	}
+	_, _ = h.auditService.Create(models.AuditLog{Action: "Order Picked", Category: models.AuditCategoryOrder, User: claims.Email, Details: fmt.Sprintf("Order %s: %s picked", fulfillment.OrderID, describeLines(payload.LineIDs)), Severity: models.AuditSeverityInfo, Entity: "order", EntityID: fulfillment.OrderID, Result: "ok"})
+	return c.JSON(fulfillment)
+}
+
+// Pack records picked order lines as packed. Packing the last line confirms
+// the order and moves it to shipped.
+// @Summary Pack order lines
+// @Tags warehouse
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Security OAuth2Password
+// @Param id path string true "Order ID"
+// @Param payload body fulfillmentLinesRequest false "Lines to pack, all lines if empty"
+// @Success 200 {object} models.Fulfillment
+// @Failure 400 {object} handlers.errorResponse
+// @Failure 401 {object} handlers.errorResponse
+// @Failure 403 {object} handlers.errorResponse
+// @Failure 404 {object} handlers.errorResponse
+// @Router /orders/{id}/pack [post]
+func (h *FulfillmentHandler) Pack(c *fiber.Ctx) error {
+	payload, err := parseFulfillmentLines(c)
+	if err != nil {
+		return err
+	}
+	claims, _ := middleware.ClaimsFromCtx(c)
+	fulfillment, shipped, err := h.service.Pack(c.Params("id"), payload.LineIDs, claims.Email)
+	if err != nil {
+		return fulfillmentError(err)
+	}
+	_, _ = h.auditService.Create(models.AuditLog{Action: "Order Packed", Category: models.AuditCategoryOrder, User: claims.Email, Details: fmt.Sprintf("Order %s: %s packed", fulfillment.OrderID, describeLines(payload.LineIDs)), Severity: models.AuditSeverityInfo, Entity: "order", EntityID: fulfillment.OrderID, Result: "ok"})
+	if shipped {
+		_, _ = h.auditService.Create(models.AuditLog{Action: "Order Status Changed", Category: models.AuditCategoryOrder, User: claims.Email, Details: fmt.Sprintf("Order %s status changed from '%s' to '%s' after packing", fulfillment.OrderID, models.OrderStatusProcessing, models.OrderStatusShipped), Severity: models.AuditSeverityInfo, Entity: "order", EntityID: fulfillment.OrderID, Result: "ok"})
+	}
+	return c.JSON(fulfillment)
+}
+
+// PackingSlip downloads the packing slip of an order as PDF.
+// @Summary Download packing slip
+// @Tags warehouse
+// @Produce application/pdf
+// @Security BearerAuth
+// @Security OAuth2Password
+// @Param id path string true "Order ID"
+// @Success 200 {file} file
+// @Failure 401 {object} handlers.errorResponse
+// @Failure 403 {object} handlers.errorResponse
+// @Failure 404 {object} handlers.errorResponse
+// @Router /orders/{id}/packing-slip [get]
+func (h *FulfillmentHandler) PackingSlip(c *fiber.Ctx) error {
+	fulfillment, data, err := h.service.PackingSlip(c.Params("id"))
+	if err != nil {
+		return fulfillmentError(err)
+	}
+	c.Set(fiber.HeaderContentType, "application/pdf")
+	c.Set(fiber.HeaderContentDisposition, fmt.Sprintf("attachment; filename=\"packing-slip-%s.pdf\"", fulfillment.OrderID))
+	return c.Send(data)
+}
+
+func parseFulfillmentLines(c *fiber.Ctx) (fulfillmentLinesRequest, error) {
+	var payload fulfillmentLinesRequest
+	if len(c.Body()) == 0 {
+		return payload, nil
+	}
+	if err := c.BodyParser(&payload); err != nil {
+		return payload, fiber.NewError(fiber.StatusBadRequest, "invalid json")
+	}
+	return payload, nil
+}
+
+func describeLines(ids []uint) string {
+	if len(ids) == 0 {
+		return "all lines"
+	}
+	parts := make([]string, 0, len(ids))
+	for _, id := range ids {
+		parts = append(parts, fmt.Sprint(id))
+	}
+	return "lines " + strings.Join(parts, ", ")
+}
+
+func fulfillmentError(err error) error {
+	if services.IsNotFound(err) {
+		return fiber.NewError(fiber.StatusNotFound, "order not found")
+	}
+	return fiber.NewError(fiber.StatusBadRequest, err.Error())
+}
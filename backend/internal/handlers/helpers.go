@@ -5,8 +5,13 @@ import (
 	"strings"
 	"time"
 
+	"backend/internal/config"
 	"backend/internal/models"
+	"backend/internal/repositories"
+	"backend/internal/security"
+	"backend/internal/services"
 
+	"github.com/gofiber/fiber/v2"
 	"gorm.io/gorm"
 )
 
@@ -28,3 +33,37 @@ func createAuditLog(db *gorm.DB, entry models.AuditLog) error {
 func generateID(prefix string) string {
 	return fmt.Sprintf("%s-%d", prefix, time.Now().UnixNano())
 }
+
+// requestLocales returns the caller's preferred content locales from ?lang=
+// and Accept-Language, and marks the response as varying by language.
+func requestLocales(c *fiber.Ctx) []string {
+	c.Vary(fiber.HeaderAcceptLanguage)
+	return services.ParseLocales(c.Query("lang"), c.Get(fiber.HeaderAcceptLanguage))
+}
+
+func newTranslationService(db *gorm.DB) *services.TranslationService {
+	return services.NewTranslationService(repositories.NewTranslationRepository(db), repositories.NewProductRepository(db), repositories.NewCategoryRepository(db))
+}
+
+// requestCurrency resolves ?currency= to an exchange rate; without it amounts
+// stay in the base currency.
+func requestCurrency(c *fiber.Ctx, currencies *services.CurrencyService) (models.ExchangeRate, error) {
+	rate, err := currencies.Rate(c.Query("currency"))
+	if err != nil {
+		return models.ExchangeRate{}, fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+	return rate, nil
+}
+
+func newCurrencyService(db *gorm.DB, cfg config.Config) *services.CurrencyService {
+	return services.NewCurrencyService(repositories.NewExchangeRateRepository(db), cfg.BaseCurrency)
+}
+
+func newInventoryService(db *gorm.DB) *services.InventoryService {
+	return services.NewInventoryService(repositories.NewStockAlertRepository(db), repositories.NewProductRepository(db), services.NewAuditService(repositories.NewAuditRepository(db)))
+}
+
+func newAuthService(db *gorm.DB, keys *security.KeySet, cfg config.Config) *services.AuthService {
+	lifetimes := services.SessionLifetimes{AccessToken: cfg.AccessTokenTTL, RefreshToken: cfg.RefreshTokenTTL, ReuseGrace: cfg.RefreshReuseGrace}
+	return services.NewAuthService(repositories.NewUserRepository(db), repositories.NewSessionRepository(db), repositories.NewTwoFactorRepository(db), keys, lifetimes)
+}
@@ -0,0 +1,84 @@
+package handlers
+
+import (
+	"fmt"
+	"strings"
+
+	"backend/internal/middleware"
+	"backend/internal/models"
+	"backend/internal/repositories"
+	"backend/internal/services"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+type InventoryHandler struct {
+	service      *services.InventoryService
+	procurement  *services.ProcurementService
+	auditService *services.AuditService
+}
+
+func NewInventoryHandler(db *gorm.DB, modelPath string) *InventoryHandler {
+	return &InventoryHandler{
+		service: newInventoryService(db),
+		procurement: services.NewProcurementService(
+			repositories.NewProcurementRepository(db),
+			repositories.NewProductRepository(db),
+			services.NewForecastService(repositories.NewForecastRepository(db), modelPath),
+		),
+		auditService: services.NewAuditService(repositories.NewAuditRepository(db)),
+	}
+}
+
+// Alerts returns low-stock alerts, open ones by default.
+// @Summary List stock alerts
+// @Tags inventory
+// @Produce json
+// @Security BearerAuth
+// @Security OAuth2Password
+// @Param status query string false "open, resolved or all" default(open)
+// @Success 200 {array} models.StockAlert
+// @Failure 400 {object} handlers.errorResponse
+// @Failure 401 {object} handlers.errorResponse
+// @Failure 403 {object} handlers.errorResponse
+// @Router /inventory/alerts [get]
+func (h *InventoryHandler) Alerts(c *fiber.Ctx) error {
+	status := models.StockAlertStatus(strings.TrimSpace(c.Query("status", string(models.StockAlertOpen))))
+	if status == "all" {
+		status = ""
+	}
+	items, err := h.service.Alerts(status)
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+	return c.JSON(items)
+}
+
+// ReorderPointsFromForecast sets product reorder points and safety stock from
+// forecast demand.
+// @Summary Derive reorder points from forecast
+// @Tags inventory
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Security OAuth2Password
+// @Param payload body services.ReorderForecastInput true "Horizon in months, lead time and safety days, optional categories"
+// @Success 200 {array} services.ReorderLevels
+// @Failure 400 {object} handlers.errorResponse
+// @Failure 401 {object} handlers.errorResponse
+// @Failure 403 {object} handlers.errorResponse
+// @Router /inventory/reorder-points/from-forecast [post]
+func (h *InventoryHandler) ReorderPointsFromForecast(c *fiber.Ctx) error {
+	var payload services.ReorderForecastInput
+	if err := c.BodyParser(&payload); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "invalid json")
+	}
+	claims, _ := middleware.ClaimsFromCtx(c)
+	levels, err := h.procurement.ReorderPointsFromForecast(payload)
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+	_, _ = h.auditService.Create(models.AuditLog{Action: "Reorder Points Updated", Category: models.AuditCategoryProduct, User: claims.Email, Details: fmt.Sprintf("Reorder points of %d products derived from forecast", len(levels)), Severity: models.AuditSeverityInfo, Result: "ok"})
+	return c.JSON(levels)
+}
@@ -0,0 +1,186 @@
+package handlers
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"backend/internal/config"
+	"backend/internal/models"
+	"backend/internal/repositories"
+	"backend/internal/security"
+	"backend/internal/services"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+const (
+	// oidcStateCookie carries the state of a sign-in back to the callback,
+	// so the callback only completes in the browser that started it.
+	oidcStateCookie = "oidc_state"
+	oidcCookiePath  = "/api/auth/oidc"
+)
+
+type OIDCHandler struct {
+	service      *services.OIDCService
+	auditService *services.AuditService
+}
+
+func NewOIDCHandler(db *gorm.DB, keys *security.KeySet, cfg config.Config) *OIDCHandler {
+	userRepo := repositories.NewUserRepository(db)
+	return &OIDCHandler{
+		service:      services.NewOIDCService(oidcConfig(cfg), repositories.NewOIDCRepository(db), userRepo, newAuthService(db, keys, cfg)),
+		auditService: services.NewAuditService(repositories.NewAuditRepository(db)),
+	}
+}
+
+// oidcConfig picks the single sign-on settings out of the configuration.
+func oidcConfig(cfg config.Config) services.OIDCConfig {
+	roleMap := make([]services.OIDCRoleMapping, 0, len(cfg.OIDCRoleMap))
+	for _, mapping := range cfg.OIDCRoleMap {
+		roleMap = append(roleMap, services.OIDCRoleMapping{Group: mapping.Group, Role: mapping.Role})
+	}
+	return services.OIDCConfig{
+		Issuer:       cfg.OIDCIssuer,
+		ClientID:     cfg.OIDCClientID,
+		ClientSecret: cfg.OIDCClientSecret,
+		RedirectURL:  cfg.OIDCRedirectURL,
+		Scopes:       cfg.OIDCScopes,
+		GroupsClaim:  cfg.OIDCGroupsClaim,
+		RoleMap:      roleMap,
+		DefaultRole:  cfg.OIDCDefaultRole,
+	}
+}
+
+type oidcCallbackRequest struct {
+	Code  string `json:"code"`
+	State string `json:"state"`
+}
+
+// Origin is where the callback is posted from, or empty when single sign-on
+// has no redirect URL.
+func (h *OIDCHandler) Origin() string {
+	return h.service.Origin()
+}
+
+// Status tells the login page whether to offer single sign-on.
+// @Summary Single sign-on status
+// @Tags auth
+// @Produce json
+// @Success 200 {object} models.OIDCStatus
+// @Router /auth/oidc [get]
+func (h *OIDCHandler) Status(c *fiber.Ctx) error {
+	return c.JSON(models.OIDCStatus{Enabled: h.service.Enabled()})
+}
+
+// Login starts single sign-on and redirects to the identity provider, which
+// sends the browser back to OIDC_REDIRECT_URL with a code and state. The
+// state is also set as an HttpOnly cookie the callback checks.
+// @Summary Start single sign-on
+// @Tags auth
+// @Success 302
+// @Failure 404 {object} handlers.errorResponse
+// @Failure 502 {object} handlers.errorResponse
+// @Router /auth/oidc/login [get]
+func (h *OIDCHandler) Login(c *fiber.Ctx) error {
+	target, state, err := h.service.Start()
+	if err != nil {
+		return oidcError(err)
+	}
+	c.Cookie(&fiber.Cookie{
+		Name:     oidcStateCookie,
+		Value:    state,
+		Path:     oidcCookiePath,
+		MaxAge:   int(services.OIDCLoginTTL / time.Second),
+		Secure:   c.Secure(),
+		HTTPOnly: true,
+		SameSite: fiber.CookieSameSiteLaxMode,
+	})
+	return c.Redirect(target, fiber.StatusFound)
+}
+
+// Callback completes single sign-on with the code and state the identity
+// provider returned. Staff are created on their first login and their role
+// follows their groups. Two-factor authentication applies as for password
+// logins, so the response may be a challenge. The request must carry the
+// state cookie set by the login, so it is sent with credentials.
+// @Summary Complete single sign-on
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param payload body oidcCallbackRequest true "Code and state from the identity provider"
+// @Success 200 {object} models.LoginResponse
+// @Failure 400 {object} handlers.errorResponse
+// @Failure 401 {object} handlers.errorResponse
+// @Failure 404 {object} handlers.errorResponse
+// @Failure 409 {object} handlers.errorResponse
+// @Failure 502 {object} handlers.errorResponse
+// @Router /auth/oidc/callback [post]
+func (h *OIDCHandler) Callback(c *fiber.Ctx) error {
+	var payload oidcCallbackRequest
+	if err := c.BodyParser(&payload); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "invalid json")
+	}
+	if strings.TrimSpace(payload.Code) == "" || strings.TrimSpace(payload.State) == "" {
+		return fiber.NewError(fiber.StatusBadRequest, "code and state are required")
+	}
+	browserState := c.Cookies(oidcStateCookie)
+	c.Cookie(&fiber.Cookie{
+		Name:     oidcStateCookie,
+		Path:     oidcCookiePath,
+		Expires:  time.Unix(0, 0),
+		Secure:   c.Secure(),
+		HTTPOnly: true,
+		SameSite: fiber.CookieSameSiteLaxMode,
+	})
+	result, err := h.service.Complete(payload.Code, payload.State, browserState)
+	if err != nil {
+		if !errors.Is(err, services.ErrOIDCDisabled) {
+			h.audit("Failed SSO Login", "sso", "Single sign-on failed: "+err.Error(), models.AuditSeverityWarning, "", "failed")
+		}
+		return oidcError(err)
+	}
+
+	user := result.Login.User
+	switch {
+	case result.Provisioned:
+		h.audit("User Provisioned", user.Email, "Created "+string(user.Role)+" account on first single sign-on", models.AuditSeverityWarning, user.ID, "ok")
+	case result.Linked:
+		h.audit("SSO Identity Linked", user.Email, "Linked identity provider account to existing user", models.AuditSeverityWarning, user.ID, "ok")
+	}
+	if result.PreviousRole != "" {
+		h.audit("Role Synced", user.Email, "Role changed from "+string(result.PreviousRole)+" to "+string(user.Role)+" by identity provider groups", models.AuditSeverityWarning, user.ID, "ok")
+	}
+	if result.Login.Challenge != "" {
+		h.audit("Two-Factor Challenge", user.Email, "Single sign-on accepted, second factor "+result.Login.TwoFactor, models.AuditSeverityInfo, user.ID, "ok")
+		return c.JSON(result.Login)
+	}
+	h.audit("User Login", user.Email, "Successful single sign-on", models.AuditSeverityInfo, user.ID, "ok")
+	return c.JSON(result.Login)
+}
+
+func (h *OIDCHandler) audit(action, user, details string, severity models.AuditSeverity, entityID, result string) {
+	_, _ = h.auditService.Create(models.AuditLog{
+		Action:   action,
+		Category: models.AuditCategoryUser,
+		User:     user,
+		Details:  details,
+		Severity: severity,
+		Entity:   "user",
+		EntityID: entityID,
+		Result:   result,
+	})
+}
+
+func oidcError(err error) error {
+	switch {
+	case errors.Is(err, services.ErrOIDCDisabled):
+		return fiber.NewError(fiber.StatusNotFound, err.Error())
+	case errors.Is(err, services.ErrOIDCProvider):
+		return fiber.NewError(fiber.StatusBadGateway, err.Error())
+	case errors.Is(err, services.ErrLastAccessManager):
+		return fiber.NewError(fiber.StatusConflict, err.Error())
+	}
+	return fiber.NewError(fiber.StatusUnauthorized, err.Error())
+}
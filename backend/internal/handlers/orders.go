@@ -5,6 +5,7 @@ import (
 	"strings"
 	"time"
 
+	"backend/internal/config"
 	"backend/internal/middleware"
 	"backend/internal/models"
 	"backend/internal/repositories"
@@ -16,12 +17,15 @@ import (
 
 type OrderHandler struct {
 	service      *services.OrderService
+	currencies   *services.CurrencyService
 	auditService *services.AuditService
 }
 
-func NewOrderHandler(db *gorm.DB) *OrderHandler {
+func NewOrderHandler(db *gorm.DB, cfg config.Config) *OrderHandler {
+	currencies := newCurrencyService(db, cfg)
 	return &OrderHandler{
-		service:      services.NewOrderService(repositories.NewOrderRepository(db)),
+		service:      services.NewOrderService(repositories.NewOrderRepository(db), currencies, newInventoryService(db)),
+		currencies:   currencies,
 		auditService: services.NewAuditService(repositories.NewAuditRepository(db)),
 	}
 }
@@ -31,6 +35,7 @@ type createOrderRequest struct {
 	Email    string            `json:"email"`
 	Address  string            `json:"address"`
 	Items    []models.CartItem `json:"items"`
+	Currency string            `json:"currency"`
 }
 
 type updateOrderStatusRequest struct {
@@ -54,26 +59,36 @@ type updateOrderRequest struct {
 // @Produce json
 // @Security BearerAuth
 // @Security OAuth2Password
+// @Param currency query string false "Currency of totals and prices, defaults to the base currency"
 // @Success 200 {array} models.OrderResponse
+// @Failure 400 {object} handlers.errorResponse
 // @Failure 401 {object} handlers.errorResponse
 // @Failure 403 {object} handlers.errorResponse
 // @Failure 500 {object} handlers.errorResponse
 // @Router /orders [get]
 func (h *OrderHandler) List(c *fiber.Ctx) error {
+	rate, err := requestCurrency(c, h.currencies)
+	if err != nil {
+		return err
+	}
 	orders, err := h.service.List()
 	if err != nil {
 		return fiber.NewError(fiber.StatusInternalServerError, "failed to fetch orders")
 	}
+	h.currencies.ConvertOrders(orders, rate)
 	return c.JSON(orders)
 }
 
-// ListMine returns orders of the authenticated client.
+// ListMine returns orders placed with the authenticated client's email,
+// once that email is verified.
 // @Summary List current client orders
 // @Tags orders
 // @Produce json
 // @Security BearerAuth
 // @Security OAuth2Password
+// @Param currency query string false "Currency of totals and prices, defaults to the base currency"
 // @Success 200 {array} models.OrderResponse
+// @Failure 400 {object} handlers.errorResponse
 // @Failure 401 {object} handlers.errorResponse
 // @Failure 403 {object} handlers.errorResponse
 // @Failure 500 {object} handlers.errorResponse
@@ -83,19 +98,26 @@ func (h *OrderHandler) ListMine(c *fiber.Ctx) error {
 	if !ok {
 		return fiber.NewError(fiber.StatusUnauthorized, "unauthorized")
 	}
+	rate, err := requestCurrency(c, h.currencies)
+	if err != nil {
+		return err
+	}
 
 	orders, err := h.service.ListByCustomerEmail(claims.Email)
 	if err != nil {
 		return fiber.NewError(fiber.StatusInternalServerError, "failed to fetch client orders")
 	}
+	h.currencies.ConvertOrders(orders, rate)
 	return c.JSON(orders)
 }
 
-// Create places a new order.
+// Create places a new order. The customer is charged in `currency` (or
+// ?currency=) at the current exchange rate, which is stored with the order.
 // @Summary Create order
 // @Tags orders
 // @Accept json
 // @Produce json
+// @Param currency query string false "Checkout currency, defaults to the base currency"
 // @Param payload body createOrderRequest true "Order payload"
 // @Success 201 {object} models.OrderResponse
 // @Failure 400 {object} handlers.errorResponse
@@ -106,15 +128,26 @@ func (h *OrderHandler) Create(c *fiber.Ctx) error {
 		return fiber.NewError(fiber.StatusBadRequest, "invalid json")
 	}
 
+	currency := strings.TrimSpace(payload.Currency)
+	if currency == "" {
+		currency = c.Query("currency")
+	}
 	order, err := h.service.Create(services.CreateOrderInput{
 		Customer: payload.Customer,
 		Email:    payload.Email,
 		Address:  payload.Address,
 		Items:    payload.Items,
+		Currency: currency,
 	})
 	if err != nil {
 		return fiber.NewError(fiber.StatusBadRequest, err.Error())
 	}
+	rate, err := h.currencies.Rate(order.CheckoutCurrency)
+	if err == nil {
+		orders := []models.OrderResponse{order}
+		h.currencies.ConvertOrders(orders, rate)
+		order = orders[0]
+	}
 
 	_ = h.audit("New Order Placed", models.AuditCategoryOrder, payload.Email, fmt.Sprintf("Order %s created", order.ID), models.AuditSeverityInfo, "order", order.ID, "ok")
 	return c.Status(fiber.StatusCreated).JSON(order)
@@ -148,7 +181,8 @@ func (h *OrderHandler) UpdateStatus(c *fiber.Ctx) error {
 
 	claims, _ := middleware.ClaimsFromCtx(c)
 	user := strings.TrimSpace(payload.User)
-	if user == "" {
+	// Changes made with an API key are always attributed to the key.
+	if user == "" || claims.APIKeyID != "" {
 		user = claims.Email
 	}
 	severity := models.AuditSeverityInfo
@@ -199,7 +233,7 @@ func (h *OrderHandler) Update(c *fiber.Ctx) error {
 
 	claims, _ := middleware.ClaimsFromCtx(c)
 	user := strings.TrimSpace(payload.User)
-	if user == "" {
+	if user == "" || claims.APIKeyID != "" {
 		user = claims.Email
 	}
 	_ = h.audit("Order Updated", models.AuditCategoryOrder, user, fmt.Sprintf("Order %s updated from '%s' to '%s'", updated.ID, prev, updated.Status), models.AuditSeverityInfo, "order", updated.ID, "ok")
@@ -0,0 +1,146 @@
+package handlers
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"backend/internal/middleware"
+	"backend/internal/models"
+	"backend/internal/repositories"
+	"backend/internal/services"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+type PriceHandler struct {
+	service      *services.PriceService
+	auditService *services.AuditService
+}
+
+func NewPriceHandler(db *gorm.DB) *PriceHandler {
+	return &PriceHandler{
+		service:      services.NewPriceService(repositories.NewProductRepository(db), repositories.NewPriceRepository(db)),
+		auditService: services.NewAuditService(repositories.NewAuditRepository(db)),
+	}
+}
+
+type schedulePriceRequest struct {
+	Price         int64  `json:"price"`
+	OriginalPrice *int64 `json:"originalPrice"`
+	StartsAt      string `json:"starts_at"`
+	EndsAt        string `json:"ends_at"`
+}
+
+// List returns price history and scheduled price changes of a product.
+// @Summary Product price history
+// @Tags prices
+// @Produce json
+// @Security BearerAuth
+// @Security OAuth2Password
+// @Param id path string true "Product ID"
+// @Success 200 {object} models.ProductPricesResponse
+// @Failure 400 {object} handlers.errorResponse
+// @Failure 401 {object} handlers.errorResponse
+// @Failure 403 {object} handlers.errorResponse
+// @Failure 404 {object} handlers.errorResponse
+// @Router /products/{id}/prices [get]
+func (h *PriceHandler) List(c *fiber.Ctx) error {
+	prices, err := h.service.Prices(c.Params("id"))
+	if err != nil {
+		if services.IsNotFound(err) {
+			return fiber.NewError(fiber.StatusNotFound, "product not found")
+		}
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+	return c.JSON(prices)
+}
+
+// Schedule plans a future price change. Without ends_at the change is permanent;
+// with ends_at the previous price is restored when the period ends.
+// @Summary Schedule price change
+// @Tags prices
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Security OAuth2Password
+// @Param id path string true "Product ID"
+// @Param payload body schedulePriceRequest true "Scheduled price payload"
+// @Success 201 {object} models.ScheduledPrice
+// @Failure 400 {object} handlers.errorResponse
+// @Failure 401 {object} handlers.errorResponse
+// @Failure 403 {object} handlers.errorResponse
+// @Failure 404 {object} handlers.errorResponse
+// @Router /products/{id}/prices/schedule [post]
+func (h *PriceHandler) Schedule(c *fiber.Ctx) error {
+	id := strings.TrimSpace(c.Params("id"))
+	var payload schedulePriceRequest
+	if err := c.BodyParser(&payload); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "invalid json")
+	}
+
+	startsAt, err := time.Parse(time.RFC3339, payload.StartsAt)
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "invalid starts_at")
+	}
+	input := services.SchedulePriceInput{Price: payload.Price, OriginalPrice: payload.OriginalPrice, StartsAt: startsAt}
+	if payload.EndsAt != "" {
+		endsAt, err := time.Parse(time.RFC3339, payload.EndsAt)
+		if err != nil {
+			return fiber.NewError(fiber.StatusBadRequest, "invalid ends_at")
+		}
+		input.EndsAt = &endsAt
+	}
+
+	claims, _ := middleware.ClaimsFromCtx(c)
+	item, err := h.service.Schedule(id, input, claims.Email)
+	if err != nil {
+		if services.IsNotFound(err) {
+			return fiber.NewError(fiber.StatusNotFound, "product not found")
+		}
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+	_ = h.audit("Price Change Scheduled", claims.Email, fmt.Sprintf("Price of %s scheduled to %d from %s", id, item.Price, item.StartsAt.Format(time.RFC3339)), models.AuditSeverityInfo, id)
+	return c.Status(fiber.StatusCreated).JSON(item)
+}
+
+// CancelSchedule cancels a pending scheduled price change, or ends an
+// active one early and restores the price it replaced.
+// @Summary Cancel scheduled price change
+// @Tags prices
+// @Produce json
+// @Security BearerAuth
+// @Security OAuth2Password
+// @Param id path string true "Product ID"
+// @Param scheduleId path int true "Schedule ID"
+// @Success 200 {object} models.ScheduledPrice
+// @Failure 400 {object} handlers.errorResponse
+// @Failure 401 {object} handlers.errorResponse
+// @Failure 403 {object} handlers.errorResponse
+// @Failure 404 {object} handlers.errorResponse
+// @Router /products/{id}/prices/schedule/{scheduleId} [delete]
+func (h *PriceHandler) CancelSchedule(c *fiber.Ctx) error {
+	id := strings.TrimSpace(c.Params("id"))
+	scheduleID, err := strconv.ParseUint(c.Params("scheduleId"), 10, 64)
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "invalid schedule id")
+	}
+
+	claims, _ := middleware.ClaimsFromCtx(c)
+	item, err := h.service.CancelSchedule(id, uint(scheduleID), claims.Email)
+	if err != nil {
+		if services.IsNotFound(err) {
+			return fiber.NewError(fiber.StatusNotFound, "schedule not found")
+		}
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+	_ = h.audit("Scheduled Price Cancelled", claims.Email, fmt.Sprintf("Scheduled price %d of %s cancelled", item.ID, id), models.AuditSeverityInfo, id)
+	return c.JSON(item)
+}
+
+func (h *PriceHandler) audit(action, user, details string, severity models.AuditSeverity, productID string) error {
+	_, err := h.auditService.Create(models.AuditLog{Action: action, Category: models.AuditCategoryProduct, User: user, Details: details, Severity: severity, Entity: "product", EntityID: productID, Result: "ok"})
+	return err
+}
@@ -0,0 +1,351 @@
+package handlers
+
+import (
+	"fmt"
+	"strings"
+
+	"backend/internal/middleware"
+	"backend/internal/models"
+	"backend/internal/repositories"
+	"backend/internal/services"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+type ProcurementHandler struct {
+	service      *services.ProcurementService
+	movements    *repositories.StockMovementRepository
+	auditService *services.AuditService
+}
+
+func NewProcurementHandler(db *gorm.DB, modelPath string) *ProcurementHandler {
+	return &ProcurementHandler{
+		service: services.NewProcurementService(
+			repositories.NewProcurementRepository(db),
+			repositories.NewProductRepository(db),
+			services.NewForecastService(repositories.NewForecastRepository(db), modelPath),
+		),
+		movements:    repositories.NewStockMovementRepository(db),
+		auditService: services.NewAuditService(repositories.NewAuditRepository(db)),
+	}
+}
+
+type receivePurchaseOrderRequest struct {
+	Lines []services.ReceiptLineInput `json:"lines"`
+}
+
+// ListSuppliers returns all suppliers.
+// @Summary List suppliers
+// @Tags procurement
+// @Produce json
+// @Security BearerAuth
+// @Security OAuth2Password
+// @Success 200 {array} models.Supplier
+// @Failure 401 {object} handlers.errorResponse
+// @Failure 403 {object} handlers.errorResponse
+// @Router /suppliers [get]
+func (h *ProcurementHandler) ListSuppliers(c *fiber.Ctx) error {
+	items, err := h.service.ListSuppliers()
+	if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, "failed to fetch suppliers")
+	}
+	return c.JSON(items)
+}
+
+// CreateSupplier adds a supplier.
+// @Summary Create supplier
+// @Tags procurement
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Security OAuth2Password
+// @Param payload body services.SupplierInput true "Supplier"
+// @Success 201 {object} models.Supplier
+// @Failure 400 {object} handlers.errorResponse
+// @Failure 401 {object} handlers.errorResponse
+// @Failure 403 {object} handlers.errorResponse
+// @Router /suppliers [post]
+func (h *ProcurementHandler) CreateSupplier(c *fiber.Ctx) error {
+	var payload services.SupplierInput
+	if err := c.BodyParser(&payload); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "invalid json")
+	}
+	claims, _ := middleware.ClaimsFromCtx(c)
+	supplier, err := h.service.CreateSupplier(payload)
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+	_ = h.audit("Supplier Created", claims.Email, fmt.Sprintf("Supplier %s created", supplier.Name), "supplier", fmt.Sprint(supplier.ID))
+	return c.Status(fiber.StatusCreated).JSON(supplier)
+}
+
+// UpdateSupplier changes a supplier's details.
+// @Summary Update supplier
+// @Tags procurement
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Security OAuth2Password
+// @Param id path int true "Supplier ID"
+// @Param payload body services.SupplierInput true "Supplier"
+// @Success 200 {object} models.Supplier
+// @Failure 400 {object} handlers.errorResponse
+// @Failure 401 {object} handlers.errorResponse
+// @Failure 403 {object} handlers.errorResponse
+// @Failure 404 {object} handlers.errorResponse
+// @Router /suppliers/{id} [put]
+func (h *ProcurementHandler) UpdateSupplier(c *fiber.Ctx) error {
+	id, err := c.ParamsInt("id")
+	if err != nil || id <= 0 {
+		return fiber.NewError(fiber.StatusBadRequest, "invalid supplier id")
+	}
+	var payload services.SupplierInput
+	if err := c.BodyParser(&payload); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "invalid json")
+	}
+	claims, _ := middleware.ClaimsFromCtx(c)
+	supplier, err := h.service.UpdateSupplier(uint(id), payload)
+	if err != nil {
+		if services.IsNotFound(err) {
+			return fiber.NewError(fiber.StatusNotFound, "supplier not found")
+		}
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+	_ = h.audit("Supplier Updated", claims.Email, fmt.Sprintf("Supplier %s updated", supplier.Name), "supplier", fmt.Sprint(supplier.ID))
+	return c.JSON(supplier)
+}
+
+// List returns purchase orders, newest first.
+// @Summary List purchase orders
+// @Tags procurement
+// @Produce json
+// @Security BearerAuth
+// @Security OAuth2Password
+// @Param status query string false "draft, sent, partially_received, received or cancelled"
+// @Success 200 {array} models.PurchaseOrder
+// @Failure 400 {object} handlers.errorResponse
+// @Failure 401 {object} handlers.errorResponse
+// @Failure 403 {object} handlers.errorResponse
+// @Router /purchase-orders [get]
+func (h *ProcurementHandler) List(c *fiber.Ctx) error {
+	items, err := h.service.List(models.PurchaseOrderStatus(strings.TrimSpace(c.Query("status"))))
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+	return c.JSON(items)
+}
+
+// Get returns a purchase order with its lines.
+// @Summary Get purchase order
+// @Tags procurement
+// @Produce json
+// @Security BearerAuth
+// @Security OAuth2Password
+// @Param id path string true "Purchase order ID"
+// @Success 200 {object} models.PurchaseOrder
+// @Failure 401 {object} handlers.errorResponse
+// @Failure 403 {object} handlers.errorResponse
+// @Failure 404 {object} handlers.errorResponse
+// @Router /purchase-orders/{id} [get]
+func (h *ProcurementHandler) Get(c *fiber.Ctx) error {
+	order, err := h.service.Get(c.Params("id"))
+	if err != nil {
+		return purchaseOrderError(err)
+	}
+	return c.JSON(order)
+}
+
+// Create drafts a purchase order.
+// @Summary Create purchase order
+// @Tags procurement
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Security OAuth2Password
+// @Param payload body services.PurchaseOrderInput true "Purchase order"
+// @Success 201 {object} models.PurchaseOrder
+// @Failure 400 {object} handlers.errorResponse
+// @Failure 401 {object} handlers.errorResponse
+// @Failure 403 {object} handlers.errorResponse
+// @Router /purchase-orders [post]
+func (h *ProcurementHandler) Create(c *fiber.Ctx) error {
+	var payload services.PurchaseOrderInput
+	if err := c.BodyParser(&payload); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "invalid json")
+	}
+	claims, _ := middleware.ClaimsFromCtx(c)
+	order, err := h.service.Create(payload, claims.Email)
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+	_ = h.audit("Purchase Order Created", claims.Email, fmt.Sprintf("Purchase order %s drafted for %s", order.ID, order.Supplier.Name), "purchase_order", order.ID)
+	return c.Status(fiber.StatusCreated).JSON(order)
+}
+
+// CreateFromForecast drafts a purchase order from the demand forecast.
+// @Summary Draft purchase order from forecast
+// @Tags procurement
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Security OAuth2Password
+// @Param payload body services.ForecastPurchaseInput true "Supplier, horizon in months and optional categories"
+// @Success 201 {object} models.PurchaseOrder
+// @Failure 400 {object} handlers.errorResponse
+// @Failure 401 {object} handlers.errorResponse
+// @Failure 403 {object} handlers.errorResponse
+// @Router /purchase-orders/from-forecast [post]
+func (h *ProcurementHandler) CreateFromForecast(c *fiber.Ctx) error {
+	var payload services.ForecastPurchaseInput
+	if err := c.BodyParser(&payload); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "invalid json")
+	}
+	claims, _ := middleware.ClaimsFromCtx(c)
+	order, err := h.service.CreateFromForecast(payload, claims.Email)
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+	_ = h.audit("Purchase Order Created", claims.Email, fmt.Sprintf("Purchase order %s drafted from forecast for %s", order.ID, order.Supplier.Name), "purchase_order", order.ID)
+	return c.Status(fiber.StatusCreated).JSON(order)
+}
+
+// Update edits a draft purchase order.
+// @Summary Update purchase order
+// @Tags procurement
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Security OAuth2Password
+// @Param id path string true "Purchase order ID"
+// @Param payload body services.PurchaseOrderInput true "Purchase order"
+// @Success 200 {object} models.PurchaseOrder
+// @Failure 400 {object} handlers.errorResponse
+// @Failure 401 {object} handlers.errorResponse
+// @Failure 403 {object} handlers.errorResponse
+// @Failure 404 {object} handlers.errorResponse
+// @Router /purchase-orders/{id} [put]
+func (h *ProcurementHandler) Update(c *fiber.Ctx) error {
+	var payload services.PurchaseOrderInput
+	if err := c.BodyParser(&payload); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "invalid json")
+	}
+	claims, _ := middleware.ClaimsFromCtx(c)
+	order, err := h.service.Update(c.Params("id"), payload)
+	if err != nil {
+		return purchaseOrderError(err)
+	}
+	_ = h.audit("Purchase Order Updated", claims.Email, fmt.Sprintf("Purchase order %s updated", order.ID), "purchase_order", order.ID)
+	return c.JSON(order)
+}
+
+// Send marks a draft purchase order as sent to the supplier.
+// @Summary Send purchase order
+// @Tags procurement
+// @Produce json
+// @Security BearerAuth
+// @Security OAuth2Password
+// @Param id path string true "Purchase order ID"
+// @Success 200 {object} models.PurchaseOrder
+// @Failure 400 {object} handlers.errorResponse
+// @Failure 401 {object} handlers.errorResponse
+// @Failure 403 {object} handlers.errorResponse
+// @Failure 404 {object} handlers.errorResponse
+// @Router /purchase-orders/{id}/send [post]
+func (h *ProcurementHandler) Send(c *fiber.Ctx) error {
+	claims, _ := middleware.ClaimsFromCtx(c)
+	order, err := h.service.Send(c.Params("id"))
+	if err != nil {
+		return purchaseOrderError(err)
+	}
+	_ = h.audit("Purchase Order Sent", claims.Email, fmt.Sprintf("Purchase order %s sent to %s", order.ID, order.Supplier.Name), "purchase_order", order.ID)
+	return c.JSON(order)
+}
+
+// Cancel cancels a purchase order nothing has been received for.
+// @Summary Cancel purchase order
+// @Tags procurement
+// @Produce json
+// @Security BearerAuth
+// @Security OAuth2Password
+// @Param id path string true "Purchase order ID"
+// @Success 200 {object} models.PurchaseOrder
+// @Failure 400 {object} handlers.errorResponse
+// @Failure 401 {object} handlers.errorResponse
+// @Failure 403 {object} handlers.errorResponse
+// @Failure 404 {object} handlers.errorResponse
+// @Router /purchase-orders/{id}/cancel [post]
+func (h *ProcurementHandler) Cancel(c *fiber.Ctx) error {
+	claims, _ := middleware.ClaimsFromCtx(c)
+	order, err := h.service.Cancel(c.Params("id"))
+	if err != nil {
+		return purchaseOrderError(err)
+	}
+	_ = h.audit("Purchase Order Cancelled", claims.Email, fmt.Sprintf("Purchase order %s cancelled", order.ID), "purchase_order", order.ID)
+	return c.JSON(order)
+}
+
+// Receive books a goods receipt: received quantities go into stock.
+// @Summary Receive goods
+// @Tags procurement
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Security OAuth2Password
+// @Param id path string true "Purchase order ID"
+// @Param payload body receivePurchaseOrderRequest true "Received quantities per product"
+// @Success 200 {object} models.PurchaseOrder
+// @Failure 400 {object} handlers.errorResponse
+// @Failure 401 {object} handlers.errorResponse
+// @Failure 403 {object} handlers.errorResponse
+// @Failure 404 {object} handlers.errorResponse
+// @Router /purchase-orders/{id}/receive [post]
+func (h *ProcurementHandler) Receive(c *fiber.Ctx) error {
+	var payload receivePurchaseOrderRequest
+	if err := c.BodyParser(&payload); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "invalid json")
+	}
+	claims, _ := middleware.ClaimsFromCtx(c)
+	order, err := h.service.Receive(c.Params("id"), payload.Lines, claims.Email)
+	if err != nil {
+		return purchaseOrderError(err)
+	}
+	units := 0
+	for _, line := range payload.Lines {
+		units += line.Qty
+	}
+	_ = h.audit("Goods Received", claims.Email, fmt.Sprintf("%d units received on purchase order %s, now %s", units, order.ID, order.Status), "purchase_order", order.ID)
+	return c.JSON(order)
+}
+
+// ListStockMovements returns stock changes made outside customer orders.
+// @Summary List stock movements
+// @Tags procurement
+// @Produce json
+// @Security BearerAuth
+// @Security OAuth2Password
+// @Param product_id query string false "Product ID"
+// @Param reference query string false "Document that caused the movement, e.g. a purchase order ID"
+// @Success 200 {array} models.StockMovement
+// @Failure 401 {object} handlers.errorResponse
+// @Failure 403 {object} handlers.errorResponse
+// @Router /stock-movements [get]
+func (h *ProcurementHandler) ListStockMovements(c *fiber.Ctx) error {
+	items, err := h.movements.List(strings.TrimSpace(c.Query("product_id")), strings.TrimSpace(c.Query("reference")))
+	if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, "failed to fetch stock movements")
+	}
+	return c.JSON(items)
+}
+
+func purchaseOrderError(err error) error {
+	if services.IsNotFound(err) {
+		return fiber.NewError(fiber.StatusNotFound, "purchase order not found")
+	}
+	return fiber.NewError(fiber.StatusBadRequest, err.Error())
+}
+
+func (h *ProcurementHandler) audit(action, user, details, entity, entityID string) error {
+	_, err := h.auditService.Create(models.AuditLog{Action: action, Category: models.AuditCategoryProduct, User: user, Details: details, Severity: models.AuditSeverityInfo, Entity: entity, EntityID: entityID, Result: "ok"})
+	return err
+}
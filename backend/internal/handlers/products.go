@@ -2,8 +2,11 @@ package handlers
 
 import (
 	"fmt"
+	"io"
+	"log"
 	"strings"
 
+	"backend/internal/config"
 	"backend/internal/middleware"
 	"backend/internal/models"
 	"backend/internal/repositories"
@@ -14,30 +17,69 @@ import (
 )
 
 type ProductHandler struct {
-	service      *services.ProductService
-	auditService *services.AuditService
+	service       *services.ProductService
+	importService *services.ProductImportService
+	translations  *services.TranslationService
+	currencies    *services.CurrencyService
+	auditService  *services.AuditService
+	permissions   *services.PermissionService
 }
 
-func NewProductHandler(db *gorm.DB) *ProductHandler {
+func NewProductHandler(db *gorm.DB, cfg config.Config, permissions *services.PermissionService) *ProductHandler {
+	productService := services.NewProductService(repositories.NewProductRepository(db))
 	return &ProductHandler{
-		service:      services.NewProductService(repositories.NewProductRepository(db)),
-		auditService: services.NewAuditService(repositories.NewAuditRepository(db)),
+		service:       productService,
+		importService: services.NewProductImportService(productService, repositories.NewProductImportRepository(db)),
+		translations:  newTranslationService(db),
+		currencies:    newCurrencyService(db, cfg),
+		auditService:  services.NewAuditService(repositories.NewAuditRepository(db)),
+		permissions:   permissions,
 	}
 }
 
-// List returns all products.
+// List returns catalog products.
 // @Summary List products
+// @Description Anonymous callers and customers get the storefront view: active products only, with an availability bucket instead of exact stock.
+// @Description Callers with the read:stock permission, or API keys with that scope, get the staff view (models.Product) including inactive products and exact stock.
+// @Description The storefront view is translated per ?lang= or Accept-Language, falling back to the base content, and priced in ?currency=; the staff view is never translated or converted.
 // @Tags products
 // @Produce json
-// @Success 200 {array} models.Product
+// @Param lang query string false "Content language, e.g. en"
+// @Param currency query string false "Price currency, defaults to the base currency"
+// @Success 200 {array} models.CatalogProduct "Storefront view; staff tokens receive []models.Product"
+// @Failure 400 {object} handlers.errorResponse
 // @Failure 500 {object} handlers.errorResponse
 // @Router /products [get]
 func (h *ProductHandler) List(c *fiber.Ctx) error {
-	products, err := h.service.List()
+	if h.isCatalogStaff(c) {
+		products, err := h.service.List()
+		if err != nil {
+			return fiber.NewError(fiber.StatusInternalServerError, "failed to fetch products")
+		}
+		return c.JSON(products)
+	}
+
+	rate, err := requestCurrency(c, h.currencies)
+	if err != nil {
+		return err
+	}
+	products, err := h.service.ListPublic()
 	if err != nil {
 		return fiber.NewError(fiber.StatusInternalServerError, "failed to fetch products")
 	}
-	return c.JSON(products)
+	catalog := make([]models.CatalogProduct, 0, len(products))
+	for _, p := range products {
+		catalog = append(catalog, models.NewCatalogProduct(p))
+	}
+	items := make([]*models.CatalogProduct, 0, len(catalog))
+	for i := range catalog {
+		items = append(items, &catalog[i])
+	}
+	if err := h.translations.LocalizeCatalog(items, requestLocales(c)); err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, "failed to fetch products")
+	}
+	h.currencies.ConvertCatalog(items, rate)
+	return c.JSON(catalog)
 }
 
 // Create creates a new product.
@@ -58,34 +100,57 @@ func (h *ProductHandler) Create(c *fiber.Ctx) error {
 	if err := c.BodyParser(&payload); err != nil {
 		return fiber.NewError(fiber.StatusBadRequest, "invalid json")
 	}
-	created, err := h.service.Create(payload)
+	claims, _ := middleware.ClaimsFromCtx(c)
+	created, err := h.service.Create(payload, claims.Email)
 	if err != nil {
 		return fiber.NewError(fiber.StatusBadRequest, err.Error())
 	}
-	claims, _ := middleware.ClaimsFromCtx(c)
 	_ = h.audit("Product Created", models.AuditCategoryProduct, claims.Email, fmt.Sprintf("Created product %s", created.Name), models.AuditSeverityInfo, "product", created.ID, "ok")
 	return c.Status(fiber.StatusCreated).JSON(created)
 }
 
 // Get returns a product by ID.
 // @Summary Get product
+// @Description Returns the storefront view for anonymous callers and customers (inactive products are not found).
+// @Description Callers with the read:stock permission, or API keys with that scope, get the staff view (models.Product).
+// @Description The storefront view is translated per ?lang= or Accept-Language and priced in ?currency=.
 // @Tags products
 // @Produce json
 // @Param id path string true "Product ID"
-// @Success 200 {object} models.Product
+// @Param lang query string false "Content language, e.g. en"
+// @Param currency query string false "Price currency, defaults to the base currency"
+// @Success 200 {object} models.CatalogProduct "Storefront view; staff tokens receive models.Product"
 // @Failure 400 {object} handlers.errorResponse
 // @Failure 404 {object} handlers.errorResponse
 // @Router /products/{id} [get]
 func (h *ProductHandler) Get(c *fiber.Ctx) error {
 	id := strings.TrimSpace(c.Params("id"))
-	product, err := h.service.Get(id)
+	staff := h.isCatalogStaff(c)
+	get := h.service.GetPublic
+	if staff {
+		get = h.service.Get
+	}
+	product, err := get(id)
 	if err != nil {
 		if services.IsNotFound(err) {
 			return fiber.NewError(fiber.StatusNotFound, "product not found")
 		}
 		return fiber.NewError(fiber.StatusBadRequest, err.Error())
 	}
-	return c.JSON(product)
+	if staff {
+		return c.JSON(product)
+	}
+	rate, err := requestCurrency(c, h.currencies)
+	if err != nil {
+		return err
+	}
+	catalog := models.NewCatalogProduct(product)
+	items := []*models.CatalogProduct{&catalog}
+	if err := h.translations.LocalizeCatalog(items, requestLocales(c)); err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, "failed to fetch product")
+	}
+	h.currencies.ConvertCatalog(items, rate)
+	return c.JSON(catalog)
 }
 
 // Update updates a product by ID.
@@ -110,7 +175,8 @@ func (h *ProductHandler) Update(c *fiber.Ctx) error {
 		return fiber.NewError(fiber.StatusBadRequest, "invalid json")
 	}
 
-	prev, updated, err := h.service.Update(id, payload)
+	claims, _ := middleware.ClaimsFromCtx(c)
+	prev, updated, err := h.service.Update(id, payload, claims.Email)
 	if err != nil {
 		if services.IsNotFound(err) {
 			return fiber.NewError(fiber.StatusNotFound, "product not found")
@@ -118,13 +184,13 @@ func (h *ProductHandler) Update(c *fiber.Ctx) error {
 		return fiber.NewError(fiber.StatusBadRequest, err.Error())
 	}
 
-	claims, _ := middleware.ClaimsFromCtx(c)
 	_ = h.audit("Product Updated", models.AuditCategoryProduct, claims.Email, fmt.Sprintf("Updated %s: price %d -> %d, stock %d -> %d", updated.Name, prev.Price, updated.Price, prev.Stock, updated.Stock), models.AuditSeverityInfo, "product", updated.ID, "ok")
 	return c.JSON(updated)
 }
 
-// Delete removes a product by ID.
-// @Summary Delete product
+// Delete archives a product by ID. Archived products are hidden from the
+// catalog but stay resolvable from historical orders.
+// @Summary Archive product
 // @Tags products
 // @Produce json
 // @Security BearerAuth
@@ -145,10 +211,171 @@ func (h *ProductHandler) Delete(c *fiber.Ctx) error {
 		return fiber.NewError(fiber.StatusBadRequest, err.Error())
 	}
 	claims, _ := middleware.ClaimsFromCtx(c)
-	_ = h.audit("Product Deleted", models.AuditCategoryProduct, claims.Email, fmt.Sprintf("Deleted product %s", id), models.AuditSeverityWarning, "product", id, "ok")
+	_ = h.audit("Product Archived", models.AuditCategoryProduct, claims.Email, fmt.Sprintf("Archived product %s", id), models.AuditSeverityWarning, "product", id, "ok")
 	return c.SendStatus(fiber.StatusNoContent)
 }
 
+// ListArchived returns archived products.
+// @Summary List archived products
+// @Tags products
+// @Produce json
+// @Security BearerAuth
+// @Security OAuth2Password
+// @Success 200 {array} models.Product
+// @Failure 401 {object} handlers.errorResponse
+// @Failure 403 {object} handlers.errorResponse
+// @Failure 500 {object} handlers.errorResponse
+// @Router /products/archived [get]
+func (h *ProductHandler) ListArchived(c *fiber.Ctx) error {
+	products, err := h.service.ListArchived()
+	if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, "failed to fetch archived products")
+	}
+	return c.JSON(products)
+}
+
+// Restore brings an archived product back to the catalog.
+// @Summary Restore archived product
+// @Tags products
+// @Produce json
+// @Security BearerAuth
+// @Security OAuth2Password
+// @Param id path string true "Product ID"
+// @Success 200 {object} models.Product
+// @Failure 400 {object} handlers.errorResponse
+// @Failure 401 {object} handlers.errorResponse
+// @Failure 403 {object} handlers.errorResponse
+// @Failure 404 {object} handlers.errorResponse
+// @Router /products/{id}/restore [post]
+func (h *ProductHandler) Restore(c *fiber.Ctx) error {
+	id := strings.TrimSpace(c.Params("id"))
+	product, err := h.service.Restore(id)
+	if err != nil {
+		if services.IsNotFound(err) {
+			return fiber.NewError(fiber.StatusNotFound, "archived product not found")
+		}
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+	claims, _ := middleware.ClaimsFromCtx(c)
+	_ = h.audit("Product Restored", models.AuditCategoryProduct, claims.Email, fmt.Sprintf("Restored product %s", product.Name), models.AuditSeverityInfo, "product", product.ID, "ok")
+	return c.JSON(product)
+}
+
+// Import uploads a CSV or XLSX file and upserts products by SKU.
+// With dry_run=true the file is only validated and a per-row report is returned.
+// @Summary Import products
+// @Tags products
+// @Accept multipart/form-data
+// @Produce json
+// @Security BearerAuth
+// @Security OAuth2Password
+// @Param file formData file true "CSV or XLSX file"
+// @Param dry_run query bool false "Validate without saving"
+// @Success 200 {object} models.ProductImportReport
+// @Success 202 {object} models.ProductImportJob
+// @Failure 400 {object} handlers.errorResponse
+// @Failure 401 {object} handlers.errorResponse
+// @Failure 403 {object} handlers.errorResponse
+// @Router /products/import [post]
+func (h *ProductHandler) Import(c *fiber.Ctx) error {
+	header, err := c.FormFile("file")
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "file is required")
+	}
+	file, err := header.Open()
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "failed to read file")
+	}
+	defer file.Close()
+	data, err := io.ReadAll(file)
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "failed to read file")
+	}
+
+	if c.QueryBool("dry_run") {
+		report, err := h.importService.DryRun(header.Filename, data)
+		if err != nil {
+			return fiber.NewError(fiber.StatusBadRequest, err.Error())
+		}
+		return c.JSON(report)
+	}
+
+	claims, _ := middleware.ClaimsFromCtx(c)
+	job, err := h.importService.Start(header.Filename, claims.Email, data)
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+	_ = h.audit("Product Import Started", models.AuditCategoryProduct, claims.Email, fmt.Sprintf("Import %s started from %s with %d rows", job.ID, job.FileName, job.Total), models.AuditSeverityInfo, "product_import", job.ID, "ok")
+	return c.Status(fiber.StatusAccepted).JSON(job)
+}
+
+// ImportStatus returns progress of a product import job.
+// @Summary Get product import job
+// @Tags products
+// @Produce json
+// @Security BearerAuth
+// @Security OAuth2Password
+// @Param jobId path string true "Import job ID"
+// @Success 200 {object} models.ProductImportJob
+// @Failure 400 {object} handlers.errorResponse
+// @Failure 401 {object} handlers.errorResponse
+// @Failure 403 {object} handlers.errorResponse
+// @Failure 404 {object} handlers.errorResponse
+// @Router /products/import/{jobId} [get]
+func (h *ProductHandler) ImportStatus(c *fiber.Ctx) error {
+	job, err := h.importService.Get(c.Params("jobId"))
+	if err != nil {
+		if services.IsNotFound(err) {
+			return fiber.NewError(fiber.StatusNotFound, "import job not found")
+		}
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+	return c.JSON(job)
+}
+
+// Export downloads the product catalog as CSV or XLSX.
+// @Summary Export products
+// @Tags products
+// @Produce text/csv
+// @Produce application/vnd.openxmlformats-officedocument.spreadsheetml.sheet
+// @Security BearerAuth
+// @Security OAuth2Password
+// @Param format query string false "csv or xlsx" default(csv)
+// @Success 200 {file} file
+// @Failure 400 {object} handlers.errorResponse
+// @Failure 401 {object} handlers.errorResponse
+// @Failure 403 {object} handlers.errorResponse
+// @Router /products/export [get]
+func (h *ProductHandler) Export(c *fiber.Ctx) error {
+	format := strings.ToLower(strings.TrimSpace(c.Query("format", services.ExportFormatCSV)))
+	data, err := h.importService.Export(format)
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+
+	contentType := "text/csv; charset=utf-8"
+	if format == services.ExportFormatXLSX {
+		contentType = "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+	}
+	c.Set(fiber.HeaderContentType, contentType)
+	c.Set(fiber.HeaderContentDisposition, fmt.Sprintf("attachment; filename=\"products.%s\"", format))
+	return c.Send(data)
+}
+
+// isCatalogStaff reports whether the caller gets the staff view of the
+// catalog, which takes the read:stock permission or API key scope.
+func (h *ProductHandler) isCatalogStaff(c *fiber.Ctx) bool {
+	claims, ok := middleware.ClaimsFromCtx(c)
+	if !ok {
+		return false
+	}
+	allowed, err := middleware.Permitted(h.permissions, claims, "read", "stock")
+	if err != nil {
+		log.Printf("products: check catalog access: %v", err)
+	}
+	return allowed
+}
+
 func (h *ProductHandler) audit(action string, category models.AuditCategory, user, details string, severity models.AuditSeverity, entity, entityID, result string) error {
 	_, err := h.auditService.Create(models.AuditLog{Action: action, Category: category, User: user, Details: details, Severity: severity, Entity: entity, EntityID: entityID, Result: result})
 	return err
@@ -0,0 +1,162 @@
+package handlers
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"backend/internal/config"
+	"backend/internal/middleware"
+	"backend/internal/models"
+	"backend/internal/repositories"
+	"backend/internal/services"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+type RecommendationHandler struct {
+	service      *services.RecommendationService
+	translations *services.TranslationService
+	currencies   *services.CurrencyService
+	auditService *services.AuditService
+}
+
+func NewRecommendationHandler(db *gorm.DB, cfg config.Config) *RecommendationHandler {
+	return &RecommendationHandler{
+		service:      services.NewRecommendationService(repositories.NewProductRepository(db), repositories.NewRecommendationRepository(db)),
+		translations: newTranslationService(db),
+		currencies:   newCurrencyService(db, cfg),
+		auditService: services.NewAuditService(repositories.NewAuditRepository(db)),
+	}
+}
+
+type productLinkRequest struct {
+	ProductID string `json:"product_id"`
+}
+
+type recomputeResponse struct {
+	Pairs int `json:"pairs"`
+}
+
+// Related returns products to show next to a product: manual links first,
+// then products frequently bought together with it, then similar products
+// from the same category.
+// @Summary Related products
+// @Tags recommendations
+// @Produce json
+// @Param id path string true "Product ID"
+// @Param limit query int false "Maximum number of products" default(8)
+// @Param lang query string false "Content language, e.g. en"
+// @Param currency query string false "Price currency, defaults to the base currency"
+// @Success 200 {array} models.RelatedProduct
+// @Failure 400 {object} handlers.errorResponse
+// @Failure 404 {object} handlers.errorResponse
+// @Router /products/{id}/related [get]
+func (h *RecommendationHandler) Related(c *fiber.Ctx) error {
+	rate, err := requestCurrency(c, h.currencies)
+	if err != nil {
+		return err
+	}
+	related, err := h.service.Related(c.Params("id"), c.QueryInt("limit", services.DefaultRelatedLimit))
+	if err != nil {
+		if services.IsNotFound(err) {
+			return fiber.NewError(fiber.StatusNotFound, "product not found")
+		}
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+	items := make([]*models.CatalogProduct, 0, len(related))
+	for i := range related {
+		items = append(items, &related[i].CatalogProduct)
+	}
+	if err := h.translations.LocalizeCatalog(items, requestLocales(c)); err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, "failed to fetch related products")
+	}
+	h.currencies.ConvertCatalog(items, rate)
+	return c.JSON(related)
+}
+
+// AddLink links a related product to a product.
+// @Summary Add related product link
+// @Tags recommendations
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Security OAuth2Password
+// @Param id path string true "Product ID"
+// @Param payload body productLinkRequest true "Related product"
+// @Success 201 {object} models.ProductLink
+// @Failure 400 {object} handlers.errorResponse
+// @Failure 401 {object} handlers.errorResponse
+// @Failure 403 {object} handlers.errorResponse
+// @Failure 404 {object} handlers.errorResponse
+// @Router /products/{id}/related [post]
+func (h *RecommendationHandler) AddLink(c *fiber.Ctx) error {
+	var payload productLinkRequest
+	if err := c.BodyParser(&payload); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "invalid json")
+	}
+
+	claims, _ := middleware.ClaimsFromCtx(c)
+	link, err := h.service.AddLink(c.Params("id"), payload.ProductID, claims.Email)
+	if err != nil {
+		if services.IsNotFound(err) {
+			return fiber.NewError(fiber.StatusNotFound, "product not found")
+		}
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+	_ = h.audit("Related Product Linked", claims.Email, fmt.Sprintf("Product %s linked to %s", link.RelatedID, link.ProductID), link.ProductID)
+	return c.Status(fiber.StatusCreated).JSON(link)
+}
+
+// RemoveLink removes a manual related product link.
+// @Summary Remove related product link
+// @Tags recommendations
+// @Security BearerAuth
+// @Security OAuth2Password
+// @Param id path string true "Product ID"
+// @Param relatedId path string true "Related product ID"
+// @Success 204
+// @Failure 400 {object} handlers.errorResponse
+// @Failure 401 {object} handlers.errorResponse
+// @Failure 403 {object} handlers.errorResponse
+// @Failure 404 {object} handlers.errorResponse
+// @Router /products/{id}/related/{relatedId} [delete]
+func (h *RecommendationHandler) RemoveLink(c *fiber.Ctx) error {
+	id := strings.TrimSpace(c.Params("id"))
+	relatedID := strings.TrimSpace(c.Params("relatedId"))
+	if err := h.service.RemoveLink(id, relatedID); err != nil {
+		if services.IsNotFound(err) {
+			return fiber.NewError(fiber.StatusNotFound, "link not found")
+		}
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+	claims, _ := middleware.ClaimsFromCtx(c)
+	_ = h.audit("Related Product Unlinked", claims.Email, fmt.Sprintf("Product %s unlinked from %s", relatedID, id), id)
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+// Recompute rebuilds "frequently bought together" pairs from order history
+// without waiting for the background job.
+// @Summary Recompute recommendations
+// @Tags recommendations
+// @Produce json
+// @Security BearerAuth
+// @Security OAuth2Password
+// @Success 200 {object} recomputeResponse
+// @Failure 401 {object} handlers.errorResponse
+// @Failure 403 {object} handlers.errorResponse
+// @Failure 500 {object} handlers.errorResponse
+// @Router /recommendations/recompute [post]
+func (h *RecommendationHandler) Recompute(c *fiber.Ctx) error {
+	pairs, err := h.service.Recompute(time.Now().UTC())
+	if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, err.Error())
+	}
+	return c.JSON(recomputeResponse{Pairs: pairs})
+}
+
+func (h *RecommendationHandler) audit(action, user, details, productID string) error {
+	_, err := h.auditService.Create(models.AuditLog{Action: action, Category: models.AuditCategoryProduct, User: user, Details: details, Severity: models.AuditSeverityInfo, Entity: "product", EntityID: productID, Result: "ok"})
+	return err
+}
@@ -9,11 +9,15 @@ import (
 )
 
 type ReferenceHandler struct {
-	service *services.ReferenceService
+	service      *services.ReferenceService
+	translations *services.TranslationService
 }
 
 func NewReferenceHandler(db *gorm.DB) *ReferenceHandler {
-	return &ReferenceHandler{service: services.NewReferenceService(repositories.NewCategoryRepository(db), repositories.NewCustomerRepository(db))}
+	return &ReferenceHandler{
+		service:      services.NewReferenceService(repositories.NewCategoryRepository(db), repositories.NewCustomerRepository(db)),
+		translations: newTranslationService(db),
+	}
 }
 
 type createCategoryRequest struct {
@@ -27,10 +31,12 @@ type createCustomerRequest struct {
 	Email    string `json:"email"`
 }
 
-// ListCategories returns reference categories.
+// ListCategories returns reference categories, translated per ?lang= or
+// Accept-Language.
 // @Summary List categories
 // @Tags references
 // @Produce json
+// @Param lang query string false "Content language, e.g. en"
 // @Success 200 {array} models.Category
 // @Failure 500 {object} handlers.errorResponse
 // @Router /categories [get]
@@ -39,6 +45,9 @@ func (h *ReferenceHandler) ListCategories(c *fiber.Ctx) error {
 	if err != nil {
 		return fiber.NewError(fiber.StatusInternalServerError, "failed to fetch categories")
 	}
+	if err := h.translations.LocalizeCategories(items, requestLocales(c)); err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, "failed to fetch categories")
+	}
 	return c.JSON(items)
 }
 
@@ -0,0 +1,152 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"backend/internal/middleware"
+	"backend/internal/models"
+	"backend/internal/repositories"
+	"backend/internal/services"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+type ReviewHandler struct {
+	service      *services.ReviewService
+	auditService *services.AuditService
+}
+
+func NewReviewHandler(db *gorm.DB) *ReviewHandler {
+	return &ReviewHandler{
+		service:      services.NewReviewService(repositories.NewReviewRepository(db), repositories.NewProductRepository(db), repositories.NewUserRepository(db)),
+		auditService: services.NewAuditService(repositories.NewAuditRepository(db)),
+	}
+}
+
+type createReviewRequest struct {
+	Rating  int    `json:"rating"`
+	Comment string `json:"comment"`
+}
+
+type moderateReviewRequest struct {
+	Status models.ReviewStatus `json:"status"`
+}
+
+// ListByProduct returns approved reviews of a product.
+// @Summary List product reviews
+// @Tags reviews
+// @Produce json
+// @Param id path string true "Product ID"
+// @Success 200 {array} models.Review
+// @Failure 400 {object} handlers.errorResponse
+// @Failure 404 {object} handlers.errorResponse
+// @Router /products/{id}/reviews [get]
+func (h *ReviewHandler) ListByProduct(c *fiber.Ctx) error {
+	reviews, err := h.service.ListApproved(c.Params("id"))
+	if err != nil {
+		if services.IsNotFound(err) {
+			return fiber.NewError(fiber.StatusNotFound, "product not found")
+		}
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+	return c.JSON(reviews)
+}
+
+// Create submits a review for moderation. Only clients with a verified email
+// and a delivered order containing the product may review it.
+// @Summary Submit product review
+// @Tags reviews
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Security OAuth2Password
+// @Param id path string true "Product ID"
+// @Param payload body createReviewRequest true "Review payload"
+// @Success 201 {object} models.Review
+// @Failure 400 {object} handlers.errorResponse
+// @Failure 401 {object} handlers.errorResponse
+// @Failure 403 {object} handlers.errorResponse
+// @Failure 404 {object} handlers.errorResponse
+// @Router /products/{id}/reviews [post]
+func (h *ReviewHandler) Create(c *fiber.Ctx) error {
+	var payload createReviewRequest
+	if err := c.BodyParser(&payload); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "invalid json")
+	}
+
+	claims, _ := middleware.ClaimsFromCtx(c)
+	review, err := h.service.Submit(c.Params("id"), claims.Email, payload.Rating, payload.Comment)
+	if err != nil {
+		if errors.Is(err, services.ErrReviewNotEligible) {
+			return fiber.NewError(fiber.StatusForbidden, err.Error())
+		}
+		if services.IsNotFound(err) {
+			return fiber.NewError(fiber.StatusNotFound, "product not found")
+		}
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+	_ = h.audit("Review Submitted", claims.Email, fmt.Sprintf("Review %s submitted for product %s", review.ID, review.ProductID), review.ID)
+	return c.Status(fiber.StatusCreated).JSON(review)
+}
+
+// List returns reviews for moderation.
+// @Summary List reviews for moderation
+// @Tags reviews
+// @Produce json
+// @Security BearerAuth
+// @Security OAuth2Password
+// @Param status query string false "pending, approved or rejected" default(pending)
+// @Success 200 {array} models.Review
+// @Failure 400 {object} handlers.errorResponse
+// @Failure 401 {object} handlers.errorResponse
+// @Failure 403 {object} handlers.errorResponse
+// @Router /reviews [get]
+func (h *ReviewHandler) List(c *fiber.Ctx) error {
+	status := models.ReviewStatus(strings.TrimSpace(c.Query("status", string(models.ReviewStatusPending))))
+	reviews, err := h.service.ListForModeration(status)
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+	return c.JSON(reviews)
+}
+
+// Moderate approves or rejects a review and recomputes the product rating.
+// @Summary Moderate review
+// @Tags reviews
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Security OAuth2Password
+// @Param id path string true "Review ID"
+// @Param payload body moderateReviewRequest true "Moderation payload"
+// @Success 200 {object} models.Review
+// @Failure 400 {object} handlers.errorResponse
+// @Failure 401 {object} handlers.errorResponse
+// @Failure 403 {object} handlers.errorResponse
+// @Failure 404 {object} handlers.errorResponse
+// @Router /reviews/{id}/moderation [patch]
+func (h *ReviewHandler) Moderate(c *fiber.Ctx) error {
+	var payload moderateReviewRequest
+	if err := c.BodyParser(&payload); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "invalid json")
+	}
+
+	claims, _ := middleware.ClaimsFromCtx(c)
+	review, err := h.service.Moderate(c.Params("id"), payload.Status, claims.Email)
+	if err != nil {
+		if services.IsNotFound(err) {
+			return fiber.NewError(fiber.StatusNotFound, "review not found")
+		}
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+	_ = h.audit("Review Moderated", claims.Email, fmt.Sprintf("Review %s for product %s %s", review.ID, review.ProductID, review.Status), review.ID)
+	return c.JSON(review)
+}
+
+func (h *ReviewHandler) audit(action, user, details, reviewID string) error {
+	_, err := h.auditService.Create(models.AuditLog{Action: action, Category: models.AuditCategoryProduct, User: user, Details: details, Severity: models.AuditSeverityInfo, Entity: "review", EntityID: reviewID, Result: "ok"})
+	return err
+}
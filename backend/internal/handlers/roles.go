@@ -0,0 +1,305 @@
+package handlers
+
+import (
+	"errors"
+	"strconv"
+
+	"backend/internal/middleware"
+	"backend/internal/models"
+	"backend/internal/repositories"
+	"backend/internal/services"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+type RoleHandler struct {
+	service      *services.RoleService
+	auditService *services.AuditService
+}
+
+// NewRoleHandler shares permissions with the route middleware so changes
+// made here invalidate the cache it authorizes from.
+func NewRoleHandler(db *gorm.DB, permissions *services.PermissionService) *RoleHandler {
+	return &RoleHandler{
+		service:      services.NewRoleService(repositories.NewPermissionRepository(db), permissions),
+		auditService: services.NewAuditService(repositories.NewAuditRepository(db)),
+	}
+}
+
+type createRoleRequest struct {
+	Name string `json:"name"`
+}
+
+type grantPermissionRequest struct {
+	PermissionID uint `json:"permission_id"`
+}
+
+// List returns every role with its number of users.
+// @Summary List roles
+// @Tags roles
+// @Produce json
+// @Security BearerAuth
+// @Security OAuth2Password
+// @Success 200 {array} models.RoleSummary
+// @Failure 401 {object} handlers.errorResponse
+// @Failure 403 {object} handlers.errorResponse
+// @Router /roles [get]
+func (h *RoleHandler) List(c *fiber.Ctx) error {
+	roles, err := h.service.List()
+	if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, "failed to fetch roles")
+	}
+	return c.JSON(roles)
+}
+
+// Create adds a custom role without permissions.
+// @Summary Create role
+// @Tags roles
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Security OAuth2Password
+// @Param payload body createRoleRequest true "Role payload"
+// @Success 201 {object} models.Role
+// @Failure 400 {object} handlers.errorResponse
+// @Failure 401 {object} handlers.errorResponse
+// @Failure 403 {object} handlers.errorResponse
+// @Failure 409 {object} handlers.errorResponse
+// @Router /roles [post]
+func (h *RoleHandler) Create(c *fiber.Ctx) error {
+	var payload createRoleRequest
+	if err := c.BodyParser(&payload); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "invalid json")
+	}
+	role, err := h.service.Create(payload.Name)
+	if err != nil {
+		return roleError(err, "role not found")
+	}
+	h.audit(c, "Role Created", "Created role "+string(role.Name), models.AuditSeverityInfo, "role", role.ID)
+	return c.Status(fiber.StatusCreated).JSON(role)
+}
+
+// Delete removes a custom role that no user is assigned to.
+// @Summary Delete role
+// @Tags roles
+// @Security BearerAuth
+// @Security OAuth2Password
+// @Param id path int true "Role ID"
+// @Success 204
+// @Failure 400 {object} handlers.errorResponse
+// @Failure 401 {object} handlers.errorResponse
+// @Failure 403 {object} handlers.errorResponse
+// @Failure 404 {object} handlers.errorResponse
+// @Failure 409 {object} handlers.errorResponse
+// @Router /roles/{id} [delete]
+func (h *RoleHandler) Delete(c *fiber.Ctx) error {
+	id, err := uintParam(c, "id", "invalid role id")
+	if err != nil {
+		return err
+	}
+	role, err := h.service.Delete(id)
+	if err != nil {
+		return roleError(err, "role not found")
+	}
+	h.audit(c, "Role Deleted", "Deleted role "+string(role.Name), models.AuditSeverityWarning, "role", role.ID)
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+// Permissions lists what a role is allowed and denied.
+// @Summary List role permissions
+// @Tags roles
+// @Produce json
+// @Security BearerAuth
+// @Security OAuth2Password
+// @Param id path int true "Role ID"
+// @Success 200 {array} models.RolePermissionView
+// @Failure 400 {object} handlers.errorResponse
+// @Failure 401 {object} handlers.errorResponse
+// @Failure 403 {object} handlers.errorResponse
+// @Failure 404 {object} handlers.errorResponse
+// @Router /roles/{id}/permissions [get]
+func (h *RoleHandler) Permissions(c *fiber.Ctx) error {
+	id, err := uintParam(c, "id", "invalid role id")
+	if err != nil {
+		return err
+	}
+	permissions, err := h.service.Permissions(id)
+	if err != nil {
+		return roleError(err, "role not found")
+	}
+	return c.JSON(permissions)
+}
+
+// Grant gives a role a permission; granting a deny permission withdraws the
+// matching access.
+// @Summary Grant permission to role
+// @Tags roles
+// @Accept json
+// @Security BearerAuth
+// @Security OAuth2Password
+// @Param id path int true "Role ID"
+// @Param payload body grantPermissionRequest true "Permission to grant"
+// @Success 204
+// @Failure 400 {object} handlers.errorResponse
+// @Failure 401 {object} handlers.errorResponse
+// @Failure 403 {object} handlers.errorResponse
+// @Failure 404 {object} handlers.errorResponse
+// @Failure 409 {object} handlers.errorResponse
+// @Router /roles/{id}/permissions [post]
+func (h *RoleHandler) Grant(c *fiber.Ctx) error {
+	id, err := uintParam(c, "id", "invalid role id")
+	if err != nil {
+		return err
+	}
+	var payload grantPermissionRequest
+	if err := c.BodyParser(&payload); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "invalid json")
+	}
+	role, permission, err := h.service.Grant(id, payload.PermissionID)
+	if err != nil {
+		return roleError(err, "role or permission not found")
+	}
+	h.audit(c, "Permission Granted", "Granted "+permissionLabel(permission)+" to "+string(role.Name), models.AuditSeverityWarning, "role", role.ID)
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+// Revoke takes a permission away from a role.
+// @Summary Revoke permission from role
+// @Tags roles
+// @Security BearerAuth
+// @Security OAuth2Password
+// @Param id path int true "Role ID"
+// @Param permissionId path int true "Permission ID"
+// @Success 204
+// @Failure 400 {object} handlers.errorResponse
+// @Failure 401 {object} handlers.errorResponse
+// @Failure 403 {object} handlers.errorResponse
+// @Failure 404 {object} handlers.errorResponse
+// @Failure 409 {object} handlers.errorResponse
+// @Router /roles/{id}/permissions/{permissionId} [delete]
+func (h *RoleHandler) Revoke(c *fiber.Ctx) error {
+	id, err := uintParam(c, "id", "invalid role id")
+	if err != nil {
+		return err
+	}
+	permissionID, err := uintParam(c, "permissionId", "invalid permission id")
+	if err != nil {
+		return err
+	}
+	role, permission, err := h.service.Revoke(id, permissionID)
+	if err != nil {
+		return roleError(err, "role permission not found")
+	}
+	h.audit(c, "Permission Revoked", "Revoked "+permissionLabel(permission)+" from "+string(role.Name), models.AuditSeverityWarning, "role", role.ID)
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+// ListPermissions returns every defined permission.
+// @Summary List permissions
+// @Tags roles
+// @Produce json
+// @Security BearerAuth
+// @Security OAuth2Password
+// @Success 200 {array} models.PermissionView
+// @Failure 401 {object} handlers.errorResponse
+// @Failure 403 {object} handlers.errorResponse
+// @Router /permissions [get]
+func (h *RoleHandler) ListPermissions(c *fiber.Ctx) error {
+	permissions, err := h.service.ListPermissions()
+	if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, "failed to fetch permissions")
+	}
+	return c.JSON(permissions)
+}
+
+// CreatePermission defines a new allow or deny permission to grant to roles.
+// @Summary Create permission
+// @Tags roles
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Security OAuth2Password
+// @Param payload body services.PermissionInput true "Permission payload"
+// @Success 201 {object} models.Permission
+// @Failure 400 {object} handlers.errorResponse
+// @Failure 401 {object} handlers.errorResponse
+// @Failure 403 {object} handlers.errorResponse
+// @Failure 409 {object} handlers.errorResponse
+// @Router /permissions [post]
+func (h *RoleHandler) CreatePermission(c *fiber.Ctx) error {
+	var payload services.PermissionInput
+	if err := c.BodyParser(&payload); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "invalid json")
+	}
+	permission, err := h.service.CreatePermission(payload)
+	if err != nil {
+		return roleError(err, "permission not found")
+	}
+	h.audit(c, "Permission Created", "Created permission "+permissionLabel(permission), models.AuditSeverityInfo, "permission", permission.ID)
+	return c.Status(fiber.StatusCreated).JSON(permission)
+}
+
+// DeletePermission removes a permission and revokes it from every role.
+// @Summary Delete permission
+// @Tags roles
+// @Security BearerAuth
+// @Security OAuth2Password
+// @Param id path int true "Permission ID"
+// @Success 204
+// @Failure 400 {object} handlers.errorResponse
+// @Failure 401 {object} handlers.errorResponse
+// @Failure 403 {object} handlers.errorResponse
+// @Failure 404 {object} handlers.errorResponse
+// @Failure 409 {object} handlers.errorResponse
+// @Router /permissions/{id} [delete]
+func (h *RoleHandler) DeletePermission(c *fiber.Ctx) error {
+	id, err := uintParam(c, "id", "invalid permission id")
+	if err != nil {
+		return err
+	}
+	permission, err := h.service.DeletePermission(id)
+	if err != nil {
+		return roleError(err, "permission not found")
+	}
+	h.audit(c, "Permission Deleted", "Deleted permission "+permissionLabel(permission), models.AuditSeverityWarning, "permission", permission.ID)
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+func (h *RoleHandler) audit(c *fiber.Ctx, action, details string, severity models.AuditSeverity, entity string, entityID uint) {
+	claims, _ := middleware.ClaimsFromCtx(c)
+	_, _ = h.auditService.Create(models.AuditLog{
+		Action:   action,
+		Category: models.AuditCategorySystem,
+		User:     claims.Email,
+		Details:  details,
+		Severity: severity,
+		Entity:   entity,
+		EntityID: strconv.FormatUint(uint64(entityID), 10),
+		Result:   "ok",
+	})
+}
+
+func permissionLabel(permission models.Permission) string {
+	return permission.Effect + " " + permission.Action + ":" + permission.Resource
+}
+
+func uintParam(c *fiber.Ctx, name, message string) (uint, error) {
+	id, err := strconv.ParseUint(c.Params(name), 10, 64)
+	if err != nil || id == 0 {
+		return 0, fiber.NewError(fiber.StatusBadRequest, message)
+	}
+	return uint(id), nil
+}
+
+func roleError(err error, notFound string) error {
+	switch {
+	case services.IsNotFound(err):
+		return fiber.NewError(fiber.StatusNotFound, notFound)
+	case errors.Is(err, services.ErrLastAccessManager), errors.Is(err, services.ErrBuiltinRole),
+		errors.Is(err, services.ErrRoleExists), errors.Is(err, services.ErrRoleInUse),
+		errors.Is(err, services.ErrPermissionExists), errors.Is(err, services.ErrPermissionEnforced):
+		return fiber.NewError(fiber.StatusConflict, err.Error())
+	}
+	return fiber.NewError(fiber.StatusBadRequest, err.Error())
+}
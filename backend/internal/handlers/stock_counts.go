@@ -0,0 +1,176 @@
+package handlers
+
+import (
+	"fmt"
+	"strings"
+
+	"backend/internal/middleware"
+	"backend/internal/models"
+	"backend/internal/repositories"
+	"backend/internal/services"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+type StockCountHandler struct {
+	service      *services.StockCountService
+	auditService *services.AuditService
+}
+
+func NewStockCountHandler(db *gorm.DB) *StockCountHandler {
+	return &StockCountHandler{
+		service:      services.NewStockCountService(repositories.NewStockCountRepository(db), repositories.NewProductRepository(db), repositories.NewCategoryRepository(db)),
+		auditService: services.NewAuditService(repositories.NewAuditRepository(db)),
+	}
+}
+
+type recordStockCountRequest struct {
+	Lines []services.CountedQtyInput `json:"lines"`
+}
+
+// List returns stock counts, newest first.
+// @Summary List stock counts
+// @Tags inventory
+// @Produce json
+// @Security BearerAuth
+// @Security OAuth2Password
+// @Param status query string false "open, posted or cancelled"
+// @Success 200 {array} models.StockCount
+// @Failure 400 {object} handlers.errorResponse
+// @Failure 401 {object} handlers.errorResponse
+// @Failure 403 {object} handlers.errorResponse
+// @Router /stock-counts [get]
+func (h *StockCountHandler) List(c *fiber.Ctx) error {
+	items, err := h.service.List(models.StockCountStatus(strings.TrimSpace(c.Query("status"))))
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+	return c.JSON(items)
+}
+
+// Get returns a stock count with expected and counted quantities and variances.
+// @Summary Get stock count
+// @Tags inventory
+// @Produce json
+// @Security BearerAuth
+// @Security OAuth2Password
+// @Param id path string true "Stock count ID"
+// @Success 200 {object} models.StockCount
+// @Failure 401 {object} handlers.errorResponse
+// @Failure 403 {object} handlers.errorResponse
+// @Failure 404 {object} handlers.errorResponse
+// @Router /stock-counts/{id} [get]
+func (h *StockCountHandler) Get(c *fiber.Ctx) error {
+	count, err := h.service.Get(c.Params("id"))
+	if err != nil {
+		return stockCountError(err)
+	}
+	return c.JSON(count)
+}
+
+// Start opens a stock count for a category and/or a warehouse location.
+// @Summary Start stock count
+// @Tags inventory
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Security OAuth2Password
+// @Param payload body services.StartStockCountInput true "Category and/or location to count"
+// @Success 201 {object} models.StockCount
+// @Failure 400 {object} handlers.errorResponse
+// @Failure 401 {object} handlers.errorResponse
+// @Failure 403 {object} handlers.errorResponse
+// @Router /stock-counts [post]
+func (h *StockCountHandler) Start(c *fiber.Ctx) error {
+	var payload services.StartStockCountInput
+	if err := c.BodyParser(&payload); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "invalid json")
+	}
+	claims, _ := middleware.ClaimsFromCtx(c)
+	count, err := h.service.Start(payload, claims.Email)
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+	_, _ = h.auditService.Create(models.AuditLog{Action: "Stock Count Started", Category: models.AuditCategoryProduct, User: claims.Email, Details: fmt.Sprintf("Stock count %s started for %d products", count.ID, len(count.Lines)), Severity: models.AuditSeverityInfo, Entity: "stock_count", EntityID: count.ID, Result: "ok"})
+	return c.Status(fiber.StatusCreated).JSON(count)
+}
+
+// Record stores scanned or entered quantities.
+// @Summary Record counted quantities
+// @Tags inventory
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Security OAuth2Password
+// @Param id path string true "Stock count ID"
+// @Param payload body recordStockCountRequest true "Counted quantities by product_id or sku"
+// @Success 200 {object} models.StockCount
+// @Failure 400 {object} handlers.errorResponse
+// @Failure 401 {object} handlers.errorResponse
+// @Failure 403 {object} handlers.errorResponse
+// @Failure 404 {object} handlers.errorResponse
+// @Router /stock-counts/{id}/lines [put]
+func (h *StockCountHandler) Record(c *fiber.Ctx) error {
+	var payload recordStockCountRequest
+	if err := c.BodyParser(&payload); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "invalid json")
+	}
+	count, err := h.service.Record(c.Params("id"), payload.Lines)
+	if err != nil {
+		return stockCountError(err)
+	}
+	return c.JSON(count)
+}
+
+// Post applies the counted variances to stock.
+// @Summary Post stock count
+// @Tags inventory
+// @Produce json
+// @Security BearerAuth
+// @Security OAuth2Password
+// @Param id path string true "Stock count ID"
+// @Success 200 {object} models.StockCount
+// @Failure 400 {object} handlers.errorResponse
+// @Failure 401 {object} handlers.errorResponse
+// @Failure 403 {object} handlers.errorResponse
+// @Failure 404 {object} handlers.errorResponse
+// @Router /stock-counts/{id}/post [post]
+func (h *StockCountHandler) Post(c *fiber.Ctx) error {
+	claims, _ := middleware.ClaimsFromCtx(c)
+	count, err := h.service.Post(c.Params("id"), claims.Email)
+	if err != nil {
+		return stockCountError(err)
+	}
+	return c.JSON(count)
+}
+
+// Cancel discards an open stock count without touching stock.
+// @Summary Cancel stock count
+// @Tags inventory
+// @Produce json
+// @Security BearerAuth
+// @Security OAuth2Password
+// @Param id path string true "Stock count ID"
+// @Success 200 {object} models.StockCount
+// @Failure 400 {object} handlers.errorResponse
+// @Failure 401 {object} handlers.errorResponse
+// @Failure 403 {object} handlers.errorResponse
+// @Failure 404 {object} handlers.errorResponse
+// @Router /stock-counts/{id}/cancel [post]
+func (h *StockCountHandler) Cancel(c *fiber.Ctx) error {
+	claims, _ := middleware.ClaimsFromCtx(c)
+	count, err := h.service.Cancel(c.Params("id"))
+	if err != nil {
+		return stockCountError(err)
+	}
+	_, _ = h.auditService.Create(models.AuditLog{Action: "Stock Count Cancelled", Category: models.AuditCategoryProduct, User: claims.Email, Details: fmt.Sprintf("Stock count %s cancelled", count.ID), Severity: models.AuditSeverityInfo, Entity: "stock_count", EntityID: count.ID, Result: "ok"})
+	return c.JSON(count)
+}
+
+func stockCountError(err error) error {
+	if services.IsNotFound(err) {
+		return fiber.NewError(fiber.StatusNotFound, "stock count not found")
+	}
+	return fiber.NewError(fiber.StatusBadRequest, err.Error())
+}
@@ -0,0 +1,103 @@
+package handlers
+
+import (
+	"backend/internal/config"
+	"backend/internal/middleware"
+	"backend/internal/repositories"
+	"backend/internal/services"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+type StockSubscriptionHandler struct {
+	service *services.StockSubscriptionService
+}
+
+func NewStockSubscriptionHandler(db *gorm.DB, cfg config.Config) *StockSubscriptionHandler {
+	return &StockSubscriptionHandler{
+		service: services.NewStockSubscriptionService(repositories.NewProductRepository(db), repositories.NewStockSubscriptionRepository(db), services.NewMailer(cfg.MailDir)),
+	}
+}
+
+// Subscribe asks to email the authenticated client once an out-of-stock
+// product is back in stock. Subscribing again returns the pending subscription.
+// @Summary Subscribe to back-in-stock notification
+// @Tags products
+// @Produce json
+// @Security BearerAuth
+// @Security OAuth2Password
+// @Param id path string true "Product ID"
+// @Success 200 {object} models.StockSubscription
+// @Success 201 {object} models.StockSubscription
+// @Failure 400 {object} handlers.errorResponse
+// @Failure 401 {object} handlers.errorResponse
+// @Failure 403 {object} handlers.errorResponse
+// @Failure 404 {object} handlers.errorResponse
+// @Router /products/{id}/subscription [post]
+func (h *StockSubscriptionHandler) Subscribe(c *fiber.Ctx) error {
+	claims, ok := middleware.ClaimsFromCtx(c)
+	if !ok {
+		return fiber.NewError(fiber.StatusUnauthorized, "unauthorized")
+	}
+	item, created, err := h.service.Subscribe(c.Params("id"), claims.Email)
+	if err != nil {
+		if services.IsNotFound(err) {
+			return fiber.NewError(fiber.StatusNotFound, "product not found")
+		}
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+	if created {
+		return c.Status(fiber.StatusCreated).JSON(item)
+	}
+	return c.JSON(item)
+}
+
+// Unsubscribe cancels the authenticated client's pending back-in-stock
+// notification for a product.
+// @Summary Cancel back-in-stock notification
+// @Tags products
+// @Security BearerAuth
+// @Security OAuth2Password
+// @Param id path string true "Product ID"
+// @Success 204
+// @Failure 401 {object} handlers.errorResponse
+// @Failure 403 {object} handlers.errorResponse
+// @Failure 404 {object} handlers.errorResponse
+// @Router /products/{id}/subscription [delete]
+func (h *StockSubscriptionHandler) Unsubscribe(c *fiber.Ctx) error {
+	claims, ok := middleware.ClaimsFromCtx(c)
+	if !ok {
+		return fiber.NewError(fiber.StatusUnauthorized, "unauthorized")
+	}
+	if err := h.service.Unsubscribe(c.Params("id"), claims.Email); err != nil {
+		if services.IsNotFound(err) {
+			return fiber.NewError(fiber.StatusNotFound, "subscription not found")
+		}
+		return fiber.NewError(fiber.StatusInternalServerError, "failed to cancel subscription")
+	}
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+// List returns every back-in-stock subscription to a product, notified or not.
+// @Summary List back-in-stock subscriptions
+// @Tags products
+// @Produce json
+// @Security BearerAuth
+// @Security OAuth2Password
+// @Param id path string true "Product ID"
+// @Success 200 {array} models.StockSubscription
+// @Failure 401 {object} handlers.errorResponse
+// @Failure 403 {object} handlers.errorResponse
+// @Failure 404 {object} handlers.errorResponse
+// @Router /products/{id}/subscriptions [get]
+func (h *StockSubscriptionHandler) List(c *fiber.Ctx) error {
+	items, err := h.service.List(c.Params("id"))
+	if err != nil {
+		if services.IsNotFound(err) {
+			return fiber.NewError(fiber.StatusNotFound, "product not found")
+		}
+		return fiber.NewError(fiber.StatusInternalServerError, "failed to fetch subscriptions")
+	}
+	return c.JSON(items)
+}
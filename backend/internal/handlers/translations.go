@@ -0,0 +1,226 @@
+package handlers
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"backend/internal/middleware"
+	"backend/internal/models"
+	"backend/internal/repositories"
+	"backend/internal/services"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+type TranslationHandler struct {
+	service      *services.TranslationService
+	auditService *services.AuditService
+}
+
+func NewTranslationHandler(db *gorm.DB) *TranslationHandler {
+	return &TranslationHandler{
+		service:      newTranslationService(db),
+		auditService: services.NewAuditService(repositories.NewAuditRepository(db)),
+	}
+}
+
+type productTranslationRequest struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Material    string `json:"material"`
+}
+
+type categoryTranslationRequest struct {
+	Name string `json:"name"`
+}
+
+// ListProduct returns all translations of a product.
+// @Summary List product translations
+// @Tags translations
+// @Produce json
+// @Security BearerAuth
+// @Security OAuth2Password
+// @Param id path string true "Product ID"
+// @Success 200 {array} models.ProductTranslation
+// @Failure 400 {object} handlers.errorResponse
+// @Failure 401 {object} handlers.errorResponse
+// @Failure 403 {object} handlers.errorResponse
+// @Failure 404 {object} handlers.errorResponse
+// @Router /products/{id}/translations [get]
+func (h *TranslationHandler) ListProduct(c *fiber.Ctx) error {
+	items, err := h.service.ProductTranslations(c.Params("id"))
+	if err != nil {
+		if services.IsNotFound(err) {
+			return fiber.NewError(fiber.StatusNotFound, "product not found")
+		}
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+	return c.JSON(items)
+}
+
+// SetProduct creates or replaces the translation of a product for a locale.
+// Empty fields fall back to the base content.
+// @Summary Set product translation
+// @Tags translations
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Security OAuth2Password
+// @Param id path string true "Product ID"
+// @Param locale path string true "Locale, e.g. en"
+// @Param payload body productTranslationRequest true "Translated fields"
+// @Success 200 {object} models.ProductTranslation
+// @Failure 400 {object} handlers.errorResponse
+// @Failure 401 {object} handlers.errorResponse
+// @Failure 403 {object} handlers.errorResponse
+// @Failure 404 {object} handlers.errorResponse
+// @Router /products/{id}/translations/{locale} [put]
+func (h *TranslationHandler) SetProduct(c *fiber.Ctx) error {
+	var payload productTranslationRequest
+	if err := c.BodyParser(&payload); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "invalid json")
+	}
+
+	claims, _ := middleware.ClaimsFromCtx(c)
+	item, err := h.service.SetProductTranslation(c.Params("id"), c.Params("locale"), services.ProductTranslationInput{
+		Name:        payload.Name,
+		Description: payload.Description,
+		Material:    payload.Material,
+	}, claims.Email)
+	if err != nil {
+		if services.IsNotFound(err) {
+			return fiber.NewError(fiber.StatusNotFound, "product not found")
+		}
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+	_ = h.audit("Product Translation Updated", claims.Email, fmt.Sprintf("Translation %s of product %s updated", item.Locale, item.ProductID), "product", item.ProductID)
+	return c.JSON(item)
+}
+
+// DeleteProduct removes the translation of a product for a locale.
+// @Summary Delete product translation
+// @Tags translations
+// @Security BearerAuth
+// @Security OAuth2Password
+// @Param id path string true "Product ID"
+// @Param locale path string true "Locale, e.g. en"
+// @Success 204
+// @Failure 400 {object} handlers.errorResponse
+// @Failure 401 {object} handlers.errorResponse
+// @Failure 403 {object} handlers.errorResponse
+// @Failure 404 {object} handlers.errorResponse
+// @Router /products/{id}/translations/{locale} [delete]
+func (h *TranslationHandler) DeleteProduct(c *fiber.Ctx) error {
+	id := strings.TrimSpace(c.Params("id"))
+	if err := h.service.DeleteProductTranslation(id, c.Params("locale")); err != nil {
+		if services.IsNotFound(err) {
+			return fiber.NewError(fiber.StatusNotFound, "translation not found")
+		}
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+	claims, _ := middleware.ClaimsFromCtx(c)
+	_ = h.audit("Product Translation Deleted", claims.Email, fmt.Sprintf("Translation %s of product %s deleted", c.Params("locale"), id), "product", id)
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+// ListCategory returns all translations of a category.
+// @Summary List category translations
+// @Tags translations
+// @Produce json
+// @Security BearerAuth
+// @Security OAuth2Password
+// @Param id path int true "Category ID"
+// @Success 200 {array} models.CategoryTranslation
+// @Failure 400 {object} handlers.errorResponse
+// @Failure 401 {object} handlers.errorResponse
+// @Failure 403 {object} handlers.errorResponse
+// @Failure 404 {object} handlers.errorResponse
+// @Router /categories/{id}/translations [get]
+func (h *TranslationHandler) ListCategory(c *fiber.Ctx) error {
+	id, err := strconv.ParseUint(c.Params("id"), 10, 64)
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "invalid category id")
+	}
+	items, err := h.service.CategoryTranslations(uint(id))
+	if err != nil {
+		if services.IsNotFound(err) {
+			return fiber.NewError(fiber.StatusNotFound, "category not found")
+		}
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+	return c.JSON(items)
+}
+
+// SetCategory creates or replaces the translation of a category for a locale.
+// @Summary Set category translation
+// @Tags translations
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Security OAuth2Password
+// @Param id path int true "Category ID"
+// @Param locale path string true "Locale, e.g. en"
+// @Param payload body categoryTranslationRequest true "Translated name"
+// @Success 200 {object} models.CategoryTranslation
+// @Failure 400 {object} handlers.errorResponse
+// @Failure 401 {object} handlers.errorResponse
+// @Failure 403 {object} handlers.errorResponse
+// @Failure 404 {object} handlers.errorResponse
+// @Router /categories/{id}/translations/{locale} [put]
+func (h *TranslationHandler) SetCategory(c *fiber.Ctx) error {
+	id, err := strconv.ParseUint(c.Params("id"), 10, 64)
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "invalid category id")
+	}
+	var payload categoryTranslationRequest
+	if err := c.BodyParser(&payload); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "invalid json")
+	}
+
+	claims, _ := middleware.ClaimsFromCtx(c)
+	item, err := h.service.SetCategoryTranslation(uint(id), c.Params("locale"), payload.Name, claims.Email)
+	if err != nil {
+		if services.IsNotFound(err) {
+			return fiber.NewError(fiber.StatusNotFound, "category not found")
+		}
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+	_ = h.audit("Category Translation Updated", claims.Email, fmt.Sprintf("Translation %s of category %d updated", item.Locale, item.CategoryID), "category", strconv.FormatUint(id, 10))
+	return c.JSON(item)
+}
+
+// DeleteCategory removes the translation of a category for a locale.
+// @Summary Delete category translation
+// @Tags translations
+// @Security BearerAuth
+// @Security OAuth2Password
+// @Param id path int true "Category ID"
+// @Param locale path string true "Locale, e.g. en"
+// @Success 204
+// @Failure 400 {object} handlers.errorResponse
+// @Failure 401 {object} handlers.errorResponse
+// @Failure 403 {object} handlers.errorResponse
+// @Failure 404 {object} handlers.errorResponse
+// @Router /categories/{id}/translations/{locale} [delete]
+func (h *TranslationHandler) DeleteCategory(c *fiber.Ctx) error {
+	id, err := strconv.ParseUint(c.Params("id"), 10, 64)
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "invalid category id")
+	}
+	if err := h.service.DeleteCategoryTranslation(uint(id), c.Params("locale")); err != nil {
+		if services.IsNotFound(err) {
+			return fiber.NewError(fiber.StatusNotFound, "translation not found")
+		}
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+	claims, _ := middleware.ClaimsFromCtx(c)
+	_ = h.audit("Category Translation Deleted", claims.Email, fmt.Sprintf("Translation %s of category %d deleted", c.Params("locale"), id), "category", strconv.FormatUint(id, 10))
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+func (h *TranslationHandler) audit(action, user, details, entity, entityID string) error {
+	_, err := h.auditService.Create(models.AuditLog{Action: action, Category: models.AuditCategoryProduct, User: user, Details: details, Severity: models.AuditSeverityInfo, Entity: entity, EntityID: entityID, Result: "ok"})
+	return err
+}
@@ -0,0 +1,282 @@
+package handlers
+
+import (
+	"errors"
+	"strings"
+
+	"backend/internal/config"
+	"backend/internal/middleware"
+	"backend/internal/models"
+	"backend/internal/repositories"
+	"backend/internal/security"
+	"backend/internal/services"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+type TwoFactorHandler struct {
+	service      *services.TwoFactorService
+	auditService *services.AuditService
+}
+
+func NewTwoFactorHandler(db *gorm.DB, keys *security.KeySet, cfg config.Config) *TwoFactorHandler {
+	userRepo := repositories.NewUserRepository(db)
+	twoFactorRepo := repositories.NewTwoFactorRepository(db)
+	authService := newAuthService(db, keys, cfg)
+	return &TwoFactorHandler{
+		service:      services.NewTwoFactorService(userRepo, twoFactorRepo, authService),
+		auditService: services.NewAuditService(repositories.NewAuditRepository(db)),
+	}
+}
+
+type twoFactorSetupRequest struct {
+	// Challenge authenticates enrollment during a login that requires it;
+	// signed-in users send a bearer token instead.
+	Challenge string `json:"challenge"`
+}
+
+type twoFactorCodeRequest struct {
+	Challenge string `json:"challenge"`
+	Code      string `json:"code"`
+}
+
+// Status reports whether the current user has two-factor authentication.
+// @Summary Two-factor status
+// @Tags auth
+// @Produce json
+// @Security BearerAuth
+// @Security OAuth2Password
+// @Success 200 {object} models.TwoFactorStatus
+// @Failure 401 {object} handlers.errorResponse
+// @Router /auth/2fa [get]
+func (h *TwoFactorHandler) Status(c *fiber.Ctx) error {
+	claims, _ := middleware.ClaimsFromCtx(c)
+	status, err := h.service.Status(claims.UserID)
+	if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, "failed to fetch two-factor status")
+	}
+	return c.JSON(status)
+}
+
+// Setup creates a TOTP secret to scan into an authenticator app. Either a
+// bearer token or an enrollment challenge from /auth/login is required.
+// @Summary Start TOTP enrollment
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Security OAuth2Password
+// @Param payload body twoFactorSetupRequest false "Enrollment challenge"
+// @Success 200 {object} models.TOTPSetup
+// @Failure 400 {object} handlers.errorResponse
+// @Failure 401 {object} handlers.errorResponse
+// @Router /auth/2fa/setup [post]
+func (h *TwoFactorHandler) Setup(c *fiber.Ctx) error {
+	var payload twoFactorSetupRequest
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(&payload); err != nil {
+			return fiber.NewError(fiber.StatusBadRequest, "invalid json")
+		}
+	}
+
+	var (
+		setup models.TOTPSetup
+		err   error
+	)
+	if claims, ok := middleware.ClaimsFromCtx(c); ok {
+		setup, err = h.service.Setup(claims.UserID)
+	} else if strings.TrimSpace(payload.Challenge) != "" {
+		setup, err = h.service.SetupForChallenge(payload.Challenge)
+	} else {
+		return fiber.NewError(fiber.StatusUnauthorized, "bearer token or enrollment challenge required")
+	}
+	if err != nil {
+		return twoFactorError(err)
+	}
+	return c.JSON(setup)
+}
+
+// Enable confirms enrollment with a code from the authenticator and returns
+// one-time recovery codes. With an enrollment challenge it also completes
+// the login.
+// @Summary Confirm TOTP enrollment
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Security OAuth2Password
+// @Param payload body twoFactorCodeRequest true "Code and optional enrollment challenge"
+// @Success 200 {object} models.TwoFactorEnabled
+// @Failure 400 {object} handlers.errorResponse
+// @Failure 401 {object} handlers.errorResponse
+// @Router /auth/2fa/enable [post]
+func (h *TwoFactorHandler) Enable(c *fiber.Ctx) error {
+	var payload twoFactorCodeRequest
+	if err := c.BodyParser(&payload); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "invalid json")
+	}
+
+	if claims, ok := middleware.ClaimsFromCtx(c); ok {
+		codes, err := h.service.Enable(claims.UserID, payload.Code)
+		if err != nil {
+			return twoFactorError(err)
+		}
+		_ = h.createAudit("Two-Factor Enabled", claims.Email, claims.UserID, models.AuditSeverityInfo)
+		return c.JSON(models.TwoFactorEnabled{RecoveryCodes: codes})
+	}
+	if strings.TrimSpace(payload.Challenge) == "" {
+		return fiber.NewError(fiber.StatusUnauthorized, "bearer token or enrollment challenge required")
+	}
+	result, err := h.service.EnableForChallenge(payload.Challenge, payload.Code)
+	if err != nil {
+		return twoFactorError(err)
+	}
+	_ = h.createAudit("Two-Factor Enabled", result.Login.User.Email, result.Login.User.ID, models.AuditSeverityInfo)
+	_ = h.createAudit("User Login", result.Login.User.Email, result.Login.User.ID, models.AuditSeverityInfo)
+	return c.JSON(result)
+}
+
+// Disable turns two-factor authentication off for the current user.
+// @Summary Disable two-factor authentication
+// @Tags auth
+// @Accept json
+// @Security BearerAuth
+// @Security OAuth2Password
+// @Param payload body twoFactorCodeRequest true "Current TOTP or recovery code"
+// @Success 204
+// @Failure 400 {object} handlers.errorResponse
+// @Failure 401 {object} handlers.errorResponse
+// @Router /auth/2fa/disable [post]
+func (h *TwoFactorHandler) Disable(c *fiber.Ctx) error {
+	claims, _ := middleware.ClaimsFromCtx(c)
+	var payload twoFactorCodeRequest
+	if err := c.BodyParser(&payload); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "invalid json")
+	}
+	if err := h.service.Disable(claims.UserID, payload.Code); err != nil {
+		return twoFactorError(err)
+	}
+	_ = h.createAudit("Two-Factor Disabled", claims.Email, claims.UserID, models.AuditSeverityWarning)
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+// RecoveryCodes replaces the current user's recovery codes.
+// @Summary Regenerate recovery codes
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Security OAuth2Password
+// @Param payload body twoFactorCodeRequest true "Current TOTP or recovery code"
+// @Success 200 {object} models.TwoFactorEnabled
+// @Failure 400 {object} handlers.errorResponse
+// @Failure 401 {object} handlers.errorResponse
+// @Router /auth/2fa/recovery-codes [post]
+func (h *TwoFactorHandler) RecoveryCodes(c *fiber.Ctx) error {
+	claims, _ := middleware.ClaimsFromCtx(c)
+	var payload twoFactorCodeRequest
+	if err := c.BodyParser(&payload); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "invalid json")
+	}
+	codes, err := h.service.RegenerateRecoveryCodes(claims.UserID, payload.Code)
+	if err != nil {
+		return twoFactorError(err)
+	}
+	_ = h.createAudit("Recovery Codes Regenerated", claims.Email, claims.UserID, models.AuditSeverityInfo)
+	return c.JSON(models.TwoFactorEnabled{RecoveryCodes: codes})
+}
+
+// Reset removes a user's second factor and ends their sessions.
+// @Summary Reset user two-factor authentication
+// @Tags users
+// @Security BearerAuth
+// @Security OAuth2Password
+// @Param id path string true "User ID"
+// @Success 204
+// @Failure 401 {object} handlers.errorResponse
+// @Failure 403 {object} handlers.errorResponse
+// @Failure 404 {object} handlers.errorResponse
+// @Router /users/{id}/2fa [delete]
+func (h *TwoFactorHandler) Reset(c *fiber.Ctx) error {
+	id := strings.TrimSpace(c.Params("id"))
+	if err := h.service.Reset(id); err != nil {
+		if services.IsNotFound(err) {
+			return fiber.NewError(fiber.StatusNotFound, "user not found")
+		}
+		return fiber.NewError(fiber.StatusInternalServerError, "failed to reset two-factor authentication")
+	}
+	claims, _ := middleware.ClaimsFromCtx(c)
+	_ = h.createAudit("Two-Factor Reset", claims.Email, id, models.AuditSeverityWarning)
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+// Policy lists the roles that must use two-factor authentication.
+// @Summary Two-factor policy
+// @Tags auth
+// @Produce json
+// @Security BearerAuth
+// @Security OAuth2Password
+// @Success 200 {object} models.TwoFactorPolicy
+// @Failure 401 {object} handlers.errorResponse
+// @Failure 403 {object} handlers.errorResponse
+// @Router /auth/2fa/policy [get]
+func (h *TwoFactorHandler) Policy(c *fiber.Ctx) error {
+	policy, err := h.service.Policy()
+	if err != nil {
+		return fiber.NewError(fiber.StatusInternalServerError, "failed to fetch two-factor policy")
+	}
+	return c.JSON(policy)
+}
+
+// SetPolicy sets the roles that must use two-factor authentication. Members
+// without it are asked to enroll on their next login.
+// @Summary Update two-factor policy
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Security OAuth2Password
+// @Param payload body models.TwoFactorPolicy true "Roles requiring two-factor authentication"
+// @Success 200 {object} models.TwoFactorPolicy
+// @Failure 400 {object} handlers.errorResponse
+// @Failure 401 {object} handlers.errorResponse
+// @Failure 403 {object} handlers.errorResponse
+// @Router /auth/2fa/policy [put]
+func (h *TwoFactorHandler) SetPolicy(c *fiber.Ctx) error {
+	var payload models.TwoFactorPolicy
+	if err := c.BodyParser(&payload); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, "invalid json")
+	}
+	policy, err := h.service.SetPolicy(payload)
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+	claims, _ := middleware.ClaimsFromCtx(c)
+	roles := make([]string, 0, len(policy.RequiredRoles))
+	for _, role := range policy.RequiredRoles {
+		roles = append(roles, string(role))
+	}
+	_, _ = h.auditService.Create(models.AuditLog{
+		Action:   "Two-Factor Policy Updated",
+		Category: models.AuditCategorySystem,
+		User:     claims.Email,
+		Details:  "Two-factor authentication required for: " + strings.Join(roles, ", "),
+		Severity: models.AuditSeverityWarning,
+		Entity:   "role",
+		Result:   "ok",
+	})
+	return c.JSON(policy)
+}
+
+func (h *TwoFactorHandler) createAudit(action, user, userID string, severity models.AuditSeverity) error {
+	_, err := h.auditService.Create(models.AuditLog{Action: action, Category: models.AuditCategoryUser, User: user, Details: action + " for user " + userID, Severity: severity, Entity: "user", EntityID: userID, Result: "ok"})
+	return err
+}
+
+func twoFactorError(err error) error {
+	if errors.Is(err, services.ErrInvalidChallenge) || errors.Is(err, services.ErrInvalidTwoFactorCode) {
+		return fiber.NewError(fiber.StatusUnauthorized, err.Error())
+	}
+	return fiber.NewError(fiber.StatusBadRequest, err.Error())
+}
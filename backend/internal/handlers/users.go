@@ -3,9 +3,11 @@ package handlers
 import (
 	"strings"
 
+	"backend/internal/config"
 	"backend/internal/middleware"
 	"backend/internal/models"
 	"backend/internal/repositories"
+	"backend/internal/security"
 	"backend/internal/services"
 
 	"github.com/gofiber/fiber/v2"
@@ -17,11 +19,10 @@ type UserHandler struct {
 	auditService *services.AuditService
 }
 
-func NewUserHandler(db *gorm.DB, appSecret string) *UserHandler {
+func NewUserHandler(db *gorm.DB, keys *security.KeySet, cfg config.Config) *UserHandler {
 	userRepo := repositories.NewUserRepository(db)
-	authService := services.NewAuthService(userRepo, appSecret)
 	return &UserHandler{
-		service:      services.NewUserService(userRepo, authService),
+		service:      services.NewUserService(userRepo, newAuthService(db, keys, cfg)),
 		auditService: services.NewAuditService(repositories.NewAuditRepository(db)),
 	}
 }
@@ -92,7 +93,8 @@ func (h *UserHandler) Create(c *fiber.Ctx) error {
 	return c.Status(fiber.StatusCreated).JSON(user)
 }
 
-// SetBlocked blocks or unblocks a user.
+// SetBlocked blocks or unblocks a user. Blocking the last active user who
+// can manage access is refused.
 // @Summary Block or unblock user
 // @Tags users
 // @Accept json
@@ -105,6 +107,8 @@ func (h *UserHandler) Create(c *fiber.Ctx) error {
 // @Failure 400 {object} handlers.errorResponse
 // @Failure 401 {object} handlers.errorResponse
 // @Failure 403 {object} handlers.errorResponse
+// @Failure 404 {object} handlers.errorResponse
+// @Failure 409 {object} handlers.errorResponse
 // @Router /users/{id}/block [patch]
 func (h *UserHandler) SetBlocked(c *fiber.Ctx) error {
 	id := strings.TrimSpace(c.Params("id"))
@@ -113,7 +117,7 @@ func (h *UserHandler) SetBlocked(c *fiber.Ctx) error {
 		return fiber.NewError(fiber.StatusBadRequest, "invalid json")
 	}
 	if err := h.service.SetBlocked(id, payload.IsBlocked); err != nil {
-		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+		return roleError(err, "user not found")
 	}
 	claims, _ := middleware.ClaimsFromCtx(c)
 	action := "User Unblocked"
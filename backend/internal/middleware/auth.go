@@ -1,6 +1,7 @@
 package middleware
 
 import (
+	"slices"
 	"strings"
 
 	"backend/internal/models"
@@ -11,22 +12,122 @@ import (
 
 const LocalsClaimsKey = "claims"
 
-func RequireAuth(secret string) fiber.Handler {
+// SessionChecker confirms that the session behind a verified token has not
+// been revoked and its user is not blocked.
+type SessionChecker interface {
+	CheckSession(claims security.Claims) error
+}
+
+// APIKeyAuthenticator resolves an integration API key to the claims its
+// request runs with.
+type APIKeyAuthenticator interface {
+	AuthenticateAPIKey(key string) (security.Claims, error)
+}
+
+// requestToken returns the API key from X-API-Key, else the bearer token.
+func requestToken(c *fiber.Ctx) string {
+	if token := strings.TrimSpace(c.Get("X-API-Key")); token != "" {
+		return token
+	}
+	authHeader := strings.TrimSpace(c.Get("Authorization"))
+	if !strings.HasPrefix(authHeader, "Bearer ") {
+		return ""
+	}
+	return strings.TrimSpace(strings.TrimPrefix(authHeader, "Bearer "))
+}
+
+// RequireAuth accepts a bearer access token, or an API key sent as the
+// bearer token or in X-API-Key.
+func RequireAuth(keys *security.KeySet, sessions SessionChecker, apiKeys APIKeyAuthenticator) fiber.Handler {
 	return func(c *fiber.Ctx) error {
-		authHeader := strings.TrimSpace(c.Get("Authorization"))
-		if authHeader == "" || !strings.HasPrefix(authHeader, "Bearer ") {
+		token := requestToken(c)
+		if token == "" {
 			return fiber.NewError(fiber.StatusUnauthorized, "missing bearer token")
 		}
-		token := strings.TrimSpace(strings.TrimPrefix(authHeader, "Bearer "))
-		claims, err := security.ParseToken(secret, token)
+		if strings.HasPrefix(token, models.APIKeyPrefix) {
+			claims, err := apiKeys.AuthenticateAPIKey(token)
+			if err != nil {
+				return fiber.NewError(fiber.StatusUnauthorized, "invalid api key")
+			}
+			c.Locals(LocalsClaimsKey, claims)
+			return c.Next()
+		}
+		claims, err := keys.Parse(token)
 		if err != nil {
 			return fiber.NewError(fiber.StatusUnauthorized, "invalid token")
 		}
+		if err := sessions.CheckSession(claims); err != nil {
+			return fiber.NewError(fiber.StatusUnauthorized, err.Error())
+		}
 		c.Locals(LocalsClaimsKey, claims)
 		return c.Next()
 	}
 }
 
+// EmailVerifier confirms that a user has verified their email.
+type EmailVerifier interface {
+	CheckEmailVerified(userID string) error
+}
+
+// RequireVerifiedEmail refuses users who have not verified their email on
+// routes that trust the email claim, such as the orders placed with it.
+func RequireVerifiedEmail(verifier EmailVerifier) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		claims, ok := c.Locals(LocalsClaimsKey).(security.Claims)
+		if !ok {
+			return fiber.NewError(fiber.StatusUnauthorized, "missing auth context")
+		}
+		if claims.APIKeyID != "" {
+			return c.Next()
+		}
+		if err := verifier.CheckEmailVerified(claims.UserID); err != nil {
+			return fiber.NewError(fiber.StatusForbidden, err.Error())
+		}
+		return c.Next()
+	}
+}
+
+// RequireUser refuses API keys on routes that act on the signed-in user's
+// own account.
+func RequireUser() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		claims, ok := c.Locals(LocalsClaimsKey).(security.Claims)
+		if !ok {
+			return fiber.NewError(fiber.StatusUnauthorized, "missing auth context")
+		}
+		if claims.APIKeyID != "" {
+			return fiber.NewError(fiber.StatusForbidden, "api keys cannot use this route")
+		}
+		return c.Next()
+	}
+}
+
+// OptionalAuth attaches claims when a valid bearer token is present and lets
+// anonymous requests through unchanged, for public routes with staff extras.
+// API keys are accepted too unless apiKeys is nil, for routes that act on
+// the signed-in user's own account.
+func OptionalAuth(keys *security.KeySet, sessions SessionChecker, apiKeys APIKeyAuthenticator) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		token := requestToken(c)
+		if token == "" {
+			return c.Next()
+		}
+		if strings.HasPrefix(token, models.APIKeyPrefix) {
+			if apiKeys == nil {
+				return c.Next()
+			}
+			if claims, err := apiKeys.AuthenticateAPIKey(token); err == nil {
+				c.Locals(LocalsClaimsKey, claims)
+			}
+			return c.Next()
+		}
+		if claims, err := keys.Parse(token); err == nil && sessions.CheckSession(claims) == nil {
+			c.Locals(LocalsClaimsKey, claims)
+		}
+		return c.Next()
+	}
+}
+
 func RequireRoles(roles ...models.RoleName) fiber.Handler {
 	allowed := map[models.RoleName]struct{}{}
 	for _, role := range roles {
@@ -44,6 +145,57 @@ func RequireRoles(roles ...models.RoleName) fiber.Handler {
 	}
 }
 
+// PermissionChecker decides whether a role may perform an action on a
+// resource.
+type PermissionChecker interface {
+	Allowed(role models.RoleName, action, resource string) (bool, error)
+}
+
+// Permitted reports whether the caller's role is granted action on resource
+// and not denied it. An API key also needs action:resource among its
+// scopes, and is checked against the role of the user who created it.
+func Permitted(permissions PermissionChecker, claims security.Claims, action, resource string) (bool, error) {
+	if claims.APIKeyID != "" && !slices.Contains(claims.Scopes, strings.ToLower(action+":"+resource)) {
+		return false, nil
+	}
+	return permissions.Allowed(claims.Role, action, resource)
+}
+
+// RequirePermission lets the request through only when the caller is
+// Permitted action on resource.
+func RequirePermission(permissions PermissionChecker, action, resource string) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		claims, ok := c.Locals(LocalsClaimsKey).(security.Claims)
+		if !ok {
+			return fiber.NewError(fiber.StatusUnauthorized, "missing auth context")
+		}
+		allowed, err := Permitted(permissions, claims, action, resource)
+		if err != nil {
+			return fiber.NewError(fiber.StatusInternalServerError, "failed to check permissions")
+		}
+		if !allowed {
+			if claims.APIKeyID != "" {
+				return fiber.NewError(fiber.StatusForbidden, "api key lacks scope "+strings.ToLower(action+":"+resource))
+			}
+			return fiber.NewError(fiber.StatusForbidden, "insufficient permissions")
+		}
+		return c.Next()
+	}
+}
+
+func HasRole(c *fiber.Ctx, roles ...models.RoleName) bool {
+	claims, ok := ClaimsFromCtx(c)
+	if !ok {
+		return false
+	}
+	for _, role := range roles {
+		if claims.Role == role {
+			return true
+		}
+	}
+	return false
+}
+
 func ClaimsFromCtx(c *fiber.Ctx) (security.Claims, bool) {
 	claims, ok := c.Locals(LocalsClaimsKey).(security.Claims)
 	return claims, ok
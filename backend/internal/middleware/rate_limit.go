@@ -0,0 +1,53 @@
+package middleware
+
+import (
+	"log"
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// RateLimitStore counts requests in fixed windows; services.CounterStore
+// implements it in memory or in the database.
+type RateLimitStore interface {
+	Hit(key string, window time.Duration, now time.Time) (int, time.Time, error)
+}
+
+// RateLimitRule allows Limit requests per client IP in each Window. A zero
+// Limit disables the limit.
+type RateLimitRule struct {
+	Limit  int
+	Window time.Duration
+}
+
+// RateLimit limits requests per client IP for a route group. Responses carry
+// X-RateLimit-* headers, and rejected ones a Retry-After header. Requests
+// pass when the store fails, so an outage does not take the API down.
+func RateLimit(store RateLimitStore, group string, rule RateLimitRule) fiber.Handler {
+	if rule.Limit <= 0 {
+		return func(c *fiber.Ctx) error { return c.Next() }
+	}
+	return func(c *fiber.Ctx) error {
+		now := time.Now().UTC()
+		count, resetAt, err := store.Hit("rate:"+group+":"+c.IP(), rule.Window, now)
+		if err != nil {
+			log.Printf("rate limit %s: %v", group, err)
+			return c.Next()
+		}
+		c.Set("X-RateLimit-Limit", strconv.Itoa(rule.Limit))
+		c.Set("X-RateLimit-Remaining", strconv.Itoa(max(rule.Limit-count, 0)))
+		c.Set("X-RateLimit-Reset", strconv.FormatInt(resetAt.Unix(), 10))
+		if count > rule.Limit {
+			SetRetryAfter(c, resetAt.Sub(now))
+			return fiber.NewError(fiber.StatusTooManyRequests, "too many requests")
+		}
+		return c.Next()
+	}
+}
+
+// SetRetryAfter sets the Retry-After header in whole seconds, rounded up.
+func SetRetryAfter(c *fiber.Ctx, wait time.Duration) {
+	c.Set(fiber.HeaderRetryAfter, strconv.Itoa(int(math.Ceil(max(wait, time.Second).Seconds()))))
+}
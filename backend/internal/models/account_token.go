@@ -0,0 +1,22 @@
+package models
+
+import "time"
+
+type AccountTokenPurpose string
+
+const (
+	AccountTokenPasswordReset     AccountTokenPurpose = "password_reset"
+	AccountTokenEmailVerification AccountTokenPurpose = "email_verification"
+)
+
+// AccountToken is a single-use secret mailed to a user to reset the password
+// or verify the email address. Only its SHA-256 hash is stored.
+type AccountToken struct {
+	ID        uint                `gorm:"primaryKey" json:"-"`
+	UserID    string              `gorm:"size:64;index;not null" json:"-"`
+	Purpose   AccountTokenPurpose `gorm:"size:32;not null" json:"-"`
+	TokenHash string              `gorm:"size:64;uniqueIndex;not null" json:"-"`
+	ExpiresAt time.Time           `gorm:"not null" json:"-"`
+	UsedAt    *time.Time          `json:"-"`
+	CreatedAt time.Time           `json:"-"`
+}
@@ -10,10 +10,20 @@ type AdminUser struct {
 type LoginResponse struct {
 	User  AdminUser `json:"user"`
 	Token string    `json:"token"`
+	// RefreshToken can be exchanged once for a new token pair; ExpiresIn is
+	// the lifetime of Token in seconds.
+	RefreshToken string `json:"refresh_token"`
+	ExpiresIn    int64  `json:"expires_in"`
+	// TwoFactor is set instead of tokens when the password was right but a
+	// second step is due: "required" asks for a code for Challenge, and
+	// "enrollment_required" asks to set up TOTP with Challenge first.
+	TwoFactor string `json:"two_factor,omitempty"`
+	Challenge string `json:"challenge,omitempty"`
 }
 
 type TokenResponse struct {
-	AccessToken string `json:"access_token"`
-	TokenType   string `json:"token_type"`
-	ExpiresIn   int64  `json:"expires_in"`
+	AccessToken  string `json:"access_token"`
+	TokenType    string `json:"token_type"`
+	ExpiresIn    int64  `json:"expires_in"`
+	RefreshToken string `json:"refresh_token,omitempty"`
 }
@@ -0,0 +1,23 @@
+package models
+
+type BarcodeType string
+
+const (
+	BarcodeEAN13   BarcodeType = "ean13"
+	BarcodeCode128 BarcodeType = "code128"
+)
+
+type LookupType string
+
+const (
+	LookupProduct LookupType = "product"
+	LookupOrder   LookupType = "order"
+)
+
+// LookupResult is what a scanned code resolves to.
+type LookupResult struct {
+	Type    LookupType     `json:"type"`
+	Code    string         `json:"code"`
+	Product *Product       `json:"product,omitempty"`
+	Order   *OrderResponse `json:"order,omitempty"`
+}
@@ -0,0 +1,86 @@
+package models
+
+import "time"
+
+type Availability string
+
+const (
+	AvailabilityInStock    Availability = "in_stock"
+	AvailabilityLowStock   Availability = "low_stock"
+	AvailabilityOutOfStock Availability = "out_of_stock"
+	AvailabilityPreorder   Availability = "preorder"
+)
+
+// LowStockThreshold is the stock level at or below which the storefront
+// shows a product as running low.
+const LowStockThreshold = 5
+
+// CatalogProduct is the storefront view of a product. It hides internal
+// fields such as exact stock, the active flag and timestamps.
+type CatalogProduct struct {
+	ID            string       `json:"id"`
+	Type          ProductType  `json:"type"`
+	Name          string       `json:"name"`
+	SKU           string       `json:"sku"`
+	CategoryID    uint         `json:"category_id"`
+	Category      string       `json:"category"`
+	Price         int64        `json:"price"`
+	OriginalPrice *int64       `json:"originalPrice,omitempty"`
+	Currency      string       `json:"currency"`
+	Image         string       `json:"image"`
+	Description   string       `json:"description"`
+	Dimensions    string       `json:"dimensions"`
+	Material      string       `json:"material"`
+	Featured      bool         `json:"featured"`
+	Rating        float64      `json:"rating"`
+	Reviews       int          `json:"reviews"`
+	Availability  Availability `json:"availability"`
+	// PreorderAvailableAt is when a product out of stock and open for
+	// pre-order is expected back.
+	PreorderAvailableAt *time.Time `json:"preorder_available_at,omitempty"`
+	// Components lists what a bundle contains; empty for single products.
+	Components []BundleComponent `json:"components,omitempty"`
+	// ComponentsPrice is what a bundle's components cost when bought separately.
+	ComponentsPrice int64 `json:"components_price,omitempty"`
+}
+
+func AvailabilityFor(stock int) Availability {
+	switch {
+	case stock <= 0:
+		return AvailabilityOutOfStock
+	case stock <= LowStockThreshold:
+		return AvailabilityLowStock
+	default:
+		return AvailabilityInStock
+	}
+}
+
+func NewCatalogProduct(p Product) CatalogProduct {
+	availability := AvailabilityFor(p.Stock)
+	var availableAt *time.Time
+	if availability == AvailabilityOutOfStock && p.PreorderEnabled {
+		availability = AvailabilityPreorder
+		availableAt = p.PreorderAvailableAt
+	}
+	return CatalogProduct{
+		ID:                  p.ID,
+		Type:                p.Type,
+		Name:                p.Name,
+		SKU:                 p.SKU,
+		CategoryID:          p.CategoryID,
+		Category:            p.Category,
+		Price:               p.Price,
+		OriginalPrice:       p.OriginalPrice,
+		Image:               p.Image,
+		Description:         p.Description,
+		Dimensions:          p.Dimensions,
+		Material:            p.Material,
+		Featured:            p.Featured,
+		Rating:              p.Rating,
+		Reviews:             p.Reviews,
+		Availability:        availability,
+		PreorderAvailableAt: availableAt,
+		Components:          p.Components,
+		ComponentsPrice:     p.ComponentsPrice,
+	}
+}
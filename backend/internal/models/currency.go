@@ -0,0 +1,25 @@
+package models
+
+import (
+	"math"
+	"time"
+)
+
+// ExchangeRate converts amounts from the base currency: one unit of the base
+// currency is worth Rate units of Currency. Converted amounts are rounded to
+// the nearest multiple of Rounding (1 by default).
+type ExchangeRate struct {
+	Currency  string    `gorm:"primaryKey;size:3" json:"currency"`
+	Rate      float64   `gorm:"not null" json:"rate"`
+	Rounding  int64     `gorm:"not null;default:1" json:"rounding"`
+	UpdatedBy string    `gorm:"size:180" json:"updated_by,omitempty"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+func (r ExchangeRate) Convert(amount int64) int64 {
+	step := r.Rounding
+	if step <= 0 {
+		step = 1
+	}
+	return int64(math.Round(float64(amount)*r.Rate/float64(step))) * step
+}
@@ -0,0 +1,80 @@
+package models
+
+import "time"
+
+// PickListEntry is one product to take from its location, summed over the
+// orders that need it. Bundles are listed as their components.
+type PickListEntry struct {
+	ProductID string          `json:"product_id"`
+	SKU       string          `json:"sku"`
+	Name      string          `json:"name"`
+	Location  string          `json:"location"`
+	Qty       int             `json:"qty"`
+	Orders    []PickListOrder `json:"orders"`
+}
+
+type PickListOrder struct {
+	OrderID string `json:"order_id"`
+	LineID  uint   `json:"line_id"`
+	Qty     int    `json:"qty"`
+}
+
+type PickList struct {
+	GeneratedAt time.Time       `json:"generated_at"`
+	Orders      []string        `json:"orders"`
+	Entries     []PickListEntry `json:"entries"`
+}
+
+// FulfillmentLine is an order line as the warehouse sees it.
+type FulfillmentLine struct {
+	ID          uint       `json:"id"`
+	ProductID   string     `json:"product_id"`
+	SKU         string     `json:"sku"`
+	Name        string     `json:"name"`
+	Location    string     `json:"location"`
+	Qty         int        `json:"qty"`
+	PreorderQty int        `json:"preorder_qty"`
+	PickedAt    *time.Time `json:"picked_at,omitempty"`
+	PickedBy    string     `json:"picked_by,omitempty"`
+	PackedAt    *time.Time `json:"packed_at,omitempty"`
+	PackedBy    string     `json:"packed_by,omitempty"`
+}
+
+type Fulfillment struct {
+	OrderID  string            `json:"order_id"`
+	Status   OrderState        `json:"status"`
+	Customer string            `json:"customer"`
+	Email    string            `json:"email"`
+	Address  string            `json:"address"`
+	Date     time.Time         `json:"date"`
+	Lines    []FulfillmentLine `json:"lines"`
+}
+
+// FulfillmentOf maps an order with preloaded lines and customer.
+func FulfillmentOf(order Order) Fulfillment {
+	lines := make([]FulfillmentLine, 0, len(order.Items))
+	for _, item := range order.Items {
+		lines = append(lines, FulfillmentLine{
+			ID:          item.ID,
+			ProductID:   item.ProductID,
+			SKU:         item.Product.SKU,
+			Name:        item.Product.Name,
+			Location:    item.Product.Location,
+			Qty:         item.Qty,
+			PreorderQty: item.PreorderQty,
+			PickedAt:    item.PickedAt,
+			PickedBy:    item.PickedBy,
+			PackedAt:    item.PackedAt,
+			PackedBy:    item.PackedBy,
+		})
+	}
+	return Fulfillment{
+		OrderID:  order.ID,
+		Status:   OrderState(order.StatusRef.Code),
+		Customer: order.Customer.FullName,
+		Email:    order.Customer.Email,
+		Address:  order.Address,
+		Date:     order.CreatedAt,
+		Lines:    lines,
+	}
+}
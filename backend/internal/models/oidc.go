@@ -0,0 +1,32 @@
+package models
+
+import "time"
+
+// OIDCLogin is a single sign-on attempt between the redirect to the identity
+// provider and its callback. The state is stored hashed; the nonce and PKCE
+// verifier only live until the callback.
+type OIDCLogin struct {
+	ID           uint       `gorm:"primaryKey" json:"-"`
+	StateHash    string     `gorm:"size:64;uniqueIndex;not null" json:"-"`
+	Nonce        string     `gorm:"size:64;not null" json:"-"`
+	CodeVerifier string     `gorm:"size:128;not null" json:"-"`
+	ExpiresAt    time.Time  `gorm:"not null" json:"-"`
+	UsedAt       *time.Time `json:"-"`
+	CreatedAt    time.Time  `json:"-"`
+}
+
+// UserIdentity links a user to an account at an identity provider, which
+// is known by its issuer and the subject it gave the account.
+type UserIdentity struct {
+	ID          uint       `gorm:"primaryKey" json:"-"`
+	UserID      string     `gorm:"size:64;index;not null" json:"-"`
+	Issuer      string     `gorm:"size:255;uniqueIndex:idx_user_identity_subject;not null" json:"-"`
+	Subject     string     `gorm:"size:255;uniqueIndex:idx_user_identity_subject;not null" json:"-"`
+	Email       string     `gorm:"size:180;not null" json:"-"`
+	CreatedAt   time.Time  `json:"-"`
+	LastLoginAt *time.Time `json:"-"`
+}
+
+type OIDCStatus struct {
+	Enabled bool `json:"enabled"`
+}
@@ -15,6 +15,8 @@ const (
 type CartItem struct {
 	Product  Product `json:"product"`
 	Quantity int     `json:"quantity"`
+	// PreorderQty is how many of Quantity are still waiting for stock.
+	PreorderQty int `json:"preorder_qty,omitempty"`
 }
 
 type OrderItem struct {
@@ -27,6 +29,15 @@ type OrderItem struct {
 	Price     int64     `gorm:"not null" json:"price"`
 	CreatedAt time.Time `json:"created_at"`
 	UpdatedAt time.Time `json:"updated_at"`
+	// PreorderQty is the part of Qty ordered beyond stock that has not been
+	// allocated from incoming stock yet.
+	PreorderQty int `gorm:"not null;default:0" json:"preorder_qty"`
+	// PickedAt and PackedAt record the warehouse steps of the line; editing
+	// the order recreates its lines and starts them over.
+	PickedAt *time.Time `json:"picked_at,omitempty"`
+	PickedBy string     `gorm:"size:255" json:"picked_by,omitempty"`
+	PackedAt *time.Time `json:"packed_at,omitempty"`
+	PackedBy string     `gorm:"size:255" json:"packed_by,omitempty"`
 }
 
 type Order struct {
@@ -40,6 +51,11 @@ type Order struct {
 	Items      []OrderItem    `gorm:"foreignKey:OrderID" json:"-"`
 	CreatedAt  time.Time      `json:"created_at"`
 	UpdatedAt  time.Time      `json:"updated_at"`
+	// Currency, ExchangeRate and CurrencyTotal record what the customer was
+	// charged at checkout; TotalSum stays in the base currency.
+	Currency      string  `gorm:"size:3" json:"currency"`
+	ExchangeRate  float64 `gorm:"not null;default:1" json:"exchange_rate"`
+	CurrencyTotal int64   `gorm:"not null;default:0" json:"currency_total"`
 }
 
 type OrderResponse struct {
@@ -51,6 +67,12 @@ type OrderResponse struct {
 	Status   OrderState `json:"status"`
 	Date     time.Time  `json:"date"`
 	Address  string     `json:"address"`
+	// Currency is the currency of Total and item prices in this response;
+	// the checkout fields show what the customer was charged.
+	Currency         string  `json:"currency"`
+	CheckoutCurrency string  `json:"checkout_currency"`
+	CheckoutRate     float64 `json:"checkout_rate"`
+	CheckoutTotal    int64   `json:"checkout_total"`
 }
 
 func IsValidOrderStatus(status OrderState) bool {
@@ -1,9 +1,26 @@
 package models
 
 type RolePermissionView struct {
-	Role        RoleName `json:"role"`
-	Action      string   `json:"action"`
-	Resource    string   `json:"resource"`
-	Effect      string   `json:"effect"`
-	Description string   `json:"description"`
+	PermissionID uint     `json:"permission_id"`
+	Role         RoleName `json:"role"`
+	Action       string   `json:"action"`
+	Resource     string   `json:"resource"`
+	Effect       string   `json:"effect"`
+	Description  string   `json:"description"`
+}
+
+// RoleSummary is a role as listed to administrators.
+type RoleSummary struct {
+	ID               uint     `json:"id"`
+	Name             RoleName `json:"name"`
+	Builtin          bool     `json:"builtin"`
+	RequireTwoFactor bool     `json:"require_two_factor"`
+	UserCount        int64    `json:"user_count"`
+}
+
+// PermissionView is a permission with whether an API route checks it;
+// enforced allow permissions cannot be deleted.
+type PermissionView struct {
+	Permission
+	Enforced bool `json:"enforced"`
 }
@@ -0,0 +1,58 @@
+package models
+
+import "time"
+
+type PriceChangeSource string
+
+const (
+	PriceSourceCreate   PriceChangeSource = "create"
+	PriceSourceManual   PriceChangeSource = "manual"
+	PriceSourceSchedule PriceChangeSource = "schedule"
+)
+
+type ScheduledPriceStatus string
+
+const (
+	ScheduledPricePending   ScheduledPriceStatus = "pending"
+	ScheduledPriceActive    ScheduledPriceStatus = "active"
+	ScheduledPriceCompleted ScheduledPriceStatus = "completed"
+	ScheduledPriceCancelled ScheduledPriceStatus = "cancelled"
+)
+
+type PriceHistory struct {
+	ID                uint              `gorm:"primaryKey" json:"id"`
+	ProductID         string            `gorm:"size:64;index;not null" json:"product_id"`
+	Product           Product           `gorm:"foreignKey:ProductID" json:"-"`
+	Price             int64             `gorm:"not null" json:"price"`
+	OriginalPrice     *int64            `json:"originalPrice,omitempty"`
+	PrevPrice         *int64            `json:"prev_price,omitempty"`
+	PrevOriginalPrice *int64            `json:"prev_originalPrice,omitempty"`
+	Source            PriceChangeSource `gorm:"size:40;not null" json:"source"`
+	ScheduleID        *uint             `json:"schedule_id,omitempty"`
+	ChangedBy         string            `gorm:"size:180" json:"changed_by"`
+	ChangedAt         time.Time         `gorm:"index;not null" json:"changed_at"`
+	CreatedAt         time.Time         `json:"created_at"`
+}
+
+type ScheduledPrice struct {
+	ID                   uint                 `gorm:"primaryKey" json:"id"`
+	ProductID            string               `gorm:"size:64;index;not null" json:"product_id"`
+	Product              Product              `gorm:"foreignKey:ProductID" json:"-"`
+	Price                int64                `gorm:"not null" json:"price"`
+	OriginalPrice        *int64               `json:"originalPrice,omitempty"`
+	StartsAt             time.Time            `gorm:"index;not null" json:"starts_at"`
+	EndsAt               *time.Time           `gorm:"index" json:"ends_at,omitempty"`
+	Status               ScheduledPriceStatus `gorm:"size:40;index;not null" json:"status"`
+	RestorePrice         *int64               `json:"-"`
+	RestoreOriginalPrice *int64               `json:"-"`
+	CreatedBy            string               `gorm:"size:180" json:"created_by"`
+	CreatedAt            time.Time            `json:"created_at"`
+	UpdatedAt            time.Time            `json:"updated_at"`
+}
+
+type ProductPricesResponse struct {
+	ProductID string           `json:"product_id"`
+	Price     int64            `json:"price"`
+	History   []PriceHistory   `json:"history"`
+	Scheduled []ScheduledPrice `json:"scheduled"`
+}
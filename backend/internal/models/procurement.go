@@ -0,0 +1,78 @@
+package models
+
+import "time"
+
+type Supplier struct {
+	ID           uint      `gorm:"primaryKey" json:"id"`
+	Name         string    `gorm:"size:180;uniqueIndex;not null" json:"name"`
+	Email        string    `gorm:"size:160" json:"email"`
+	Phone        string    `gorm:"size:40" json:"phone"`
+	LeadTimeDays int       `gorm:"not null;default:0" json:"lead_time_days"`
+	IsActive     bool      `gorm:"not null;default:true" json:"is_active"`
+	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
+}
+
+type PurchaseOrderStatus string
+
+const (
+	PurchaseOrderDraft             PurchaseOrderStatus = "draft"
+	PurchaseOrderSent              PurchaseOrderStatus = "sent"
+	PurchaseOrderPartiallyReceived PurchaseOrderStatus = "partially_received"
+	PurchaseOrderReceived          PurchaseOrderStatus = "received"
+	PurchaseOrderCancelled         PurchaseOrderStatus = "cancelled"
+)
+
+func IsValidPurchaseOrderStatus(status PurchaseOrderStatus) bool {
+	switch status {
+	case PurchaseOrderDraft, PurchaseOrderSent, PurchaseOrderPartiallyReceived, PurchaseOrderReceived, PurchaseOrderCancelled:
+		return true
+	default:
+		return false
+	}
+}
+
+// PurchaseOrder is an order of stock from a supplier. Only drafts can be
+// edited; sent orders are received in one or more goods receipts.
+type PurchaseOrder struct {
+	ID         string              `gorm:"primaryKey;size:64" json:"id"`
+	SupplierID uint                `gorm:"index;not null" json:"supplier_id"`
+	Supplier   Supplier            `gorm:"foreignKey:SupplierID" json:"supplier"`
+	Status     PurchaseOrderStatus `gorm:"size:30;index;not null;default:'draft'" json:"status"`
+	ExpectedAt *time.Time          `json:"expected_at,omitempty"`
+	Notes      string              `gorm:"type:text" json:"notes"`
+	Lines      []PurchaseOrderLine `gorm:"foreignKey:PurchaseOrderID" json:"lines"`
+	Total      int64               `gorm:"-" json:"total"`
+	CreatedBy  string              `gorm:"size:160" json:"created_by"`
+	CreatedAt  time.Time           `json:"created_at"`
+	UpdatedAt  time.Time           `json:"updated_at"`
+	SentAt     *time.Time          `json:"sent_at,omitempty"`
+	ReceivedAt *time.Time          `json:"received_at,omitempty"`
+}
+
+type PurchaseOrderLine struct {
+	ID              uint    `gorm:"primaryKey" json:"id"`
+	PurchaseOrderID string  `gorm:"size:64;index;not null" json:"-"`
+	ProductID       string  `gorm:"size:64;index;not null" json:"product_id"`
+	Product         Product `gorm:"foreignKey:ProductID" json:"-"`
+	Name            string  `gorm:"-" json:"name,omitempty"`
+	SKU             string  `gorm:"-" json:"sku,omitempty"`
+	Qty             int     `gorm:"not null" json:"qty"`
+	ReceivedQty     int     `gorm:"not null;default:0" json:"received_qty"`
+	// UnitCost is the purchase price per unit in the base currency.
+	UnitCost int64 `gorm:"not null;default:0" json:"unit_cost"`
+}
+
+func (l PurchaseOrderLine) Remaining() int {
+	return l.Qty - l.ReceivedQty
+}
+
+// SyncViewFields fills line names and the order total from preloaded products.
+func (o *PurchaseOrder) SyncViewFields() {
+	o.Total = 0
+	for i := range o.Lines {
+		o.Lines[i].Name = o.Lines[i].Product.Name
+		o.Lines[i].SKU = o.Lines[i].Product.SKU
+		o.Total += int64(o.Lines[i].Qty) * o.Lines[i].UnitCost
+	}
+}
@@ -1,36 +1,115 @@
 package models
 
-import "time"
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type ProductType string
+
+const (
+	ProductTypeSingle ProductType = "single"
+	ProductTypeBundle ProductType = "bundle"
+)
 
 type Product struct {
-	ID            string    `gorm:"primaryKey;size:64" json:"id"`
-	Name          string    `gorm:"size:180;not null" json:"name"`
-	SKU           string    `gorm:"size:90;uniqueIndex;not null" json:"sku"`
-	CategoryID    uint      `gorm:"index;not null" json:"category_id"`
-	CategoryRef   Category  `gorm:"foreignKey:CategoryID" json:"-"`
-	Category      string    `gorm:"-" json:"category"`
-	Price         int64     `gorm:"not null" json:"price"`
-	OriginalPrice *int64    `json:"originalPrice,omitempty"`
-	Image         string    `gorm:"size:255;not null" json:"image"`
-	Description   string    `gorm:"type:text" json:"description"`
-	Dimensions    string    `gorm:"size:120" json:"dimensions"`
-	Material      string    `gorm:"size:180" json:"material"`
-	StockQty      int       `gorm:"not null;default:0" json:"-"`
-	Stock         int       `gorm:"-" json:"stock"`
-	IsActive      bool      `gorm:"not null;default:true" json:"is_active"`
-	Featured      bool      `gorm:"not null;default:false" json:"featured"`
-	Rating        float64   `gorm:"not null;default:0" json:"rating"`
-	Reviews       int       `gorm:"not null;default:0" json:"reviews"`
-	CreatedAt     time.Time `json:"created_at"`
-	UpdatedAt     time.Time `json:"updated_at"`
+	ID            string      `gorm:"primaryKey;size:64" json:"id"`
+	Type          ProductType `gorm:"size:20;not null;default:'single'" json:"type"`
+	Name          string      `gorm:"size:180;not null" json:"name"`
+	SKU           string      `gorm:"size:90;uniqueIndex;not null" json:"sku"`
+	CategoryID    uint        `gorm:"index;not null" json:"category_id"`
+	CategoryRef   Category    `gorm:"foreignKey:CategoryID" json:"-"`
+	Category      string      `gorm:"-" json:"category"`
+	Price         int64       `gorm:"not null" json:"price"`
+	OriginalPrice *int64      `json:"originalPrice,omitempty"`
+	Image         string      `gorm:"size:255;not null" json:"image"`
+	Description   string      `gorm:"type:text" json:"description"`
+	Dimensions    string      `gorm:"size:120" json:"dimensions"`
+	Material      string      `gorm:"size:180" json:"material"`
+	// Location is where the product is stored in the warehouse, e.g. a bin code.
+	Location string `gorm:"size:60;index" json:"location"`
+	// Barcode is the EAN-13 or Code128 value printed on the product; the SKU
+	// is used on labels when it is empty.
+	Barcode     string      `gorm:"size:64;index" json:"barcode"`
+	BarcodeType BarcodeType `gorm:"size:16" json:"barcode_type"`
+	StockQty    int         `gorm:"not null;default:0" json:"-"`
+	// ReorderPoint and SafetyStock raise stock alerts once StockQty is at or
+	// below them; 0 disables the threshold.
+	ReorderPoint int               `gorm:"not null;default:0" json:"reorder_point"`
+	SafetyStock  int               `gorm:"not null;default:0" json:"safety_stock"`
+	Stock        int               `gorm:"-" json:"stock"`
+	Components   []BundleComponent `gorm:"foreignKey:BundleID" json:"components,omitempty"`
+	// ComponentsPrice is what the bundle's components cost when bought separately.
+	ComponentsPrice int64 `gorm:"-" json:"components_price,omitempty"`
+	IsActive        bool  `gorm:"not null;default:true" json:"is_active"`
+	Featured        bool  `gorm:"not null;default:false" json:"featured"`
+	// PreorderEnabled accepts orders beyond stock; the missing units are
+	// expected by PreorderAvailableAt and go to pre-orders first when they arrive.
+	PreorderEnabled     bool           `gorm:"not null;default:false" json:"preorder_enabled"`
+	PreorderAvailableAt *time.Time     `json:"preorder_available_at,omitempty"`
+	Rating              float64        `gorm:"not null;default:0" json:"rating"`
+	Reviews             int            `gorm:"not null;default:0" json:"reviews"`
+	CreatedAt           time.Time      `json:"created_at"`
+	UpdatedAt           time.Time      `json:"updated_at"`
+	DeletedAt           gorm.DeletedAt `gorm:"index" json:"deleted_at,omitempty" swaggertype:"string" format:"date-time"`
+}
+
+// BundleComponent is one line of a bundle: Quantity units of the component
+// product go into each unit of the bundle.
+type BundleComponent struct {
+	ID          uint    `gorm:"primaryKey" json:"-"`
+	BundleID    string  `gorm:"size:64;index;not null" json:"-"`
+	ComponentID string  `gorm:"size:64;index;not null" json:"product_id"`
+	Component   Product `gorm:"foreignKey:ComponentID" json:"-"`
+	Name        string  `gorm:"-" json:"name,omitempty"`
+	Quantity    int     `gorm:"not null" json:"quantity"`
+}
+
+func (p *Product) IsBundle() bool {
+	return p.Type == ProductTypeBundle
 }
 
 func (p *Product) SyncViewFields() {
+	if p.IsBundle() {
+		p.syncBundleFields()
+		return
+	}
 	if p.Stock == 0 || p.Stock != p.StockQty {
 		p.Stock = p.StockQty
 	}
 }
 
 func (p *Product) SyncDBFields() {
+	if p.IsBundle() {
+		// A bundle holds no stock of its own; it is assembled from components.
+		p.StockQty = 0
+		return
+	}
 	p.StockQty = p.Stock
 }
+
+// syncBundleFields derives a bundle's stock from how many complete sets its
+// components allow. Components must be preloaded; archived or hidden
+// components make the bundle unavailable.
+func (p *Product) syncBundleFields() {
+	p.ComponentsPrice = 0
+	if len(p.Components) == 0 {
+		p.Stock = 0
+		return
+	}
+	stock := -1
+	for i := range p.Components {
+		c := &p.Components[i]
+		c.Name = c.Component.Name
+		p.ComponentsPrice += c.Component.Price * int64(c.Quantity)
+		sets := 0
+		if c.Component.ID != "" && c.Component.IsActive && c.Quantity > 0 {
+			sets = c.Component.StockQty / c.Quantity
+		}
+		if stock < 0 || sets < stock {
+			stock = sets
+		}
+	}
+	p.Stock = stock
+}
@@ -0,0 +1,44 @@
+package models
+
+import "time"
+
+type ImportJobStatus string
+
+const (
+	ImportJobQueued    ImportJobStatus = "queued"
+	ImportJobRunning   ImportJobStatus = "running"
+	ImportJobCompleted ImportJobStatus = "completed"
+	ImportJobFailed    ImportJobStatus = "failed"
+)
+
+type ImportRowError struct {
+	Row     int    `json:"row"`
+	SKU     string `json:"sku,omitempty"`
+	Message string `json:"message"`
+}
+
+type ProductImportJob struct {
+	ID         string           `gorm:"primaryKey;size:64" json:"id"`
+	FileName   string           `gorm:"size:255;not null" json:"file_name"`
+	Status     ImportJobStatus  `gorm:"size:40;not null" json:"status"`
+	Total      int              `gorm:"not null;default:0" json:"total"`
+	Processed  int              `gorm:"not null;default:0" json:"processed"`
+	Created    int              `gorm:"not null;default:0" json:"created"`
+	Updated    int              `gorm:"not null;default:0" json:"updated"`
+	Failed     int              `gorm:"not null;default:0" json:"failed"`
+	ErrorsJSON string           `gorm:"type:text" json:"-"`
+	Errors     []ImportRowError `gorm:"-" json:"errors"`
+	CreatedBy  string           `gorm:"size:180" json:"created_by"`
+	FinishedAt *time.Time       `json:"finished_at,omitempty"`
+	CreatedAt  time.Time        `json:"created_at"`
+	UpdatedAt  time.Time        `json:"updated_at"`
+}
+
+type ProductImportReport struct {
+	DryRun bool             `json:"dry_run"`
+	Total  int              `json:"total"`
+	Valid  int              `json:"valid"`
+	Create int              `json:"create"`
+	Update int              `json:"update"`
+	Errors []ImportRowError `json:"errors"`
+}
@@ -0,0 +1,11 @@
+package models
+
+import "time"
+
+// RateCounter is a fixed-window counter shared by the rate limiter and the
+// login throttle when counters are kept in the database.
+type RateCounter struct {
+	Key     string    `gorm:"primaryKey;size:191" json:"key"`
+	Count   int       `gorm:"not null" json:"count"`
+	ResetAt time.Time `gorm:"not null;index" json:"reset_at"`
+}
@@ -12,25 +12,58 @@ const (
 	RoleClient    RoleName = "Client"
 )
 
+// IsBuiltin reports whether the role is one the application itself relies
+// on; custom roles can be created and deleted by administrators.
+func (n RoleName) IsBuiltin() bool {
+	switch n {
+	case RoleAdmin, RoleManager, RoleWarehouse, RoleExecutive, RoleClient:
+		return true
+	}
+	return false
+}
+
 type Role struct {
-	ID        uint      `gorm:"primaryKey" json:"id"`
-	Name      RoleName  `gorm:"size:64;uniqueIndex;not null" json:"name"`
-	CreatedAt time.Time `json:"created_at"`
-	UpdatedAt time.Time `json:"updated_at"`
+	ID   uint     `gorm:"primaryKey" json:"id"`
+	Name RoleName `gorm:"size:64;uniqueIndex;not null" json:"name"`
+	// RequireTwoFactor makes members enroll in TOTP before they can sign in.
+	RequireTwoFactor bool      `gorm:"not null;default:false" json:"require_two_factor"`
+	CreatedAt        time.Time `json:"created_at"`
+	UpdatedAt        time.Time `json:"updated_at"`
 }
 
 type User struct {
-	ID           string    `gorm:"primaryKey;size:64" json:"id"`
-	Email        string    `gorm:"size:180;uniqueIndex;not null" json:"email"`
-	PasswordHash string    `gorm:"size:255;not null" json:"-"`
-	Name         string    `gorm:"size:120;not null" json:"name"`
-	RoleID       uint      `gorm:"not null" json:"role_id"`
-	Role         Role      `gorm:"foreignKey:RoleID" json:"role"`
-	IsBlocked    bool      `gorm:"not null;default:false" json:"is_blocked"`
-	CreatedAt    time.Time `json:"created_at"`
-	UpdatedAt    time.Time `json:"updated_at"`
+	ID           string `gorm:"primaryKey;size:64" json:"id"`
+	Email        string `gorm:"size:180;uniqueIndex;not null" json:"email"`
+	PasswordHash string `gorm:"size:255;not null" json:"-"`
+	Name         string `gorm:"size:120;not null" json:"name"`
+	RoleID       uint   `gorm:"not null" json:"role_id"`
+	Role         Role   `gorm:"foreignKey:RoleID" json:"role"`
+	IsBlocked    bool   `gorm:"not null;default:false" json:"is_blocked"`
+	// EmailVerifiedAt is set once the user follows the verification link;
+	// staff accounts created by an administrator start verified.
+	EmailVerifiedAt *time.Time `json:"email_verified_at,omitempty"`
+	// SSOLinkAllowed lets single sign-on link an Admin or Client account,
+	// which it otherwise refuses; the user opts in while signed in.
+	SSOLinkAllowed bool      `gorm:"not null;default:false" json:"sso_link_allowed"`
+	CreatedAt      time.Time `json:"created_at"`
+	UpdatedAt      time.Time `json:"updated_at"`
 }
 
+// Permission effects. A deny granted to a role overrides any allow for the
+// same action and resource.
+const (
+	PermissionAllow = "allow"
+	PermissionDeny  = "deny"
+)
+
+// ManageAccessAction on ManageAccessResource lets a role grant and revoke
+// permissions. Changes that would leave no active user holding it are
+// refused.
+const (
+	ManageAccessAction   = "grant"
+	ManageAccessResource = "role"
+)
+
 type Permission struct {
 	ID          uint      `gorm:"primaryKey" json:"id"`
 	Action      string    `gorm:"size:80;not null" json:"action"`
@@ -0,0 +1,40 @@
+package models
+
+import "time"
+
+type RelatedSource string
+
+const (
+	RelatedSourceManual         RelatedSource = "manual"
+	RelatedSourceBoughtTogether RelatedSource = "bought_together"
+	// RelatedSourceSimilar fills the remaining slots with products from the
+	// same category at a similar price.
+	RelatedSourceSimilar RelatedSource = "similar"
+)
+
+// ProductLink is a related product picked by a manager.
+type ProductLink struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	ProductID string    `gorm:"size:64;not null;uniqueIndex:idx_product_link" json:"product_id"`
+	RelatedID string    `gorm:"size:64;not null;uniqueIndex:idx_product_link" json:"related_id"`
+	CreatedBy string    `gorm:"size:180" json:"created_by"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// ProductRecommendation is a "frequently bought together" pair mined from
+// order history. The table is rebuilt by a background job.
+type ProductRecommendation struct {
+	ID         uint      `gorm:"primaryKey" json:"-"`
+	ProductID  string    `gorm:"size:64;index;not null" json:"product_id"`
+	RelatedID  string    `gorm:"size:64;not null" json:"related_id"`
+	Score      float64   `gorm:"not null" json:"score"`
+	Support    int       `gorm:"not null" json:"support"`
+	ComputedAt time.Time `gorm:"not null" json:"computed_at"`
+}
+
+// RelatedProduct is a storefront product suggested next to another one.
+type RelatedProduct struct {
+	CatalogProduct
+	Source RelatedSource `json:"source"`
+	Score  float64       `json:"score,omitempty"`
+}
@@ -0,0 +1,36 @@
+package models
+
+import "time"
+
+type ReviewStatus string
+
+const (
+	ReviewStatusPending  ReviewStatus = "pending"
+	ReviewStatusApproved ReviewStatus = "approved"
+	ReviewStatusRejected ReviewStatus = "rejected"
+)
+
+type Review struct {
+	ID          string       `gorm:"primaryKey;size:64" json:"id"`
+	ProductID   string       `gorm:"size:64;index;not null;uniqueIndex:idx_review_product_user" json:"product_id"`
+	Product     Product      `gorm:"foreignKey:ProductID" json:"-"`
+	UserID      string       `gorm:"size:64;index;not null;uniqueIndex:idx_review_product_user" json:"user_id"`
+	User        User         `gorm:"foreignKey:UserID" json:"-"`
+	AuthorName  string       `gorm:"size:120;not null" json:"author_name"`
+	Rating      int          `gorm:"not null" json:"rating"`
+	Comment     string       `gorm:"type:text" json:"comment"`
+	Status      ReviewStatus `gorm:"size:40;index;not null" json:"status"`
+	ModeratedBy string       `gorm:"size:180" json:"moderated_by,omitempty"`
+	ModeratedAt *time.Time   `json:"moderated_at,omitempty"`
+	CreatedAt   time.Time    `json:"created_at"`
+	UpdatedAt   time.Time    `json:"updated_at"`
+}
+
+func IsValidReviewStatus(status ReviewStatus) bool {
+	switch status {
+	case ReviewStatusPending, ReviewStatusApproved, ReviewStatusRejected:
+		return true
+	default:
+		return false
+	}
+}
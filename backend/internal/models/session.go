@@ -0,0 +1,31 @@
+package models
+
+import "time"
+
+// Session is a login that can be refreshed and revoked. Access tokens carry
+// its ID in the sid claim.
+type Session struct {
+	ID           string     `gorm:"primaryKey;size:64" json:"id"`
+	UserID       string     `gorm:"size:64;index;not null" json:"user_id"`
+	User         User       `gorm:"foreignKey:UserID" json:"-"`
+	CreatedAt    time.Time  `json:"created_at"`
+	LastUsedAt   time.Time  `json:"last_used_at"`
+	ExpiresAt    time.Time  `gorm:"not null" json:"expires_at"`
+	RevokedAt    *time.Time `json:"revoked_at,omitempty"`
+	RevokeReason string     `gorm:"size:120" json:"revoke_reason,omitempty"`
+}
+
+func (s Session) IsActive(now time.Time) bool {
+	return s.RevokedAt == nil && now.Before(s.ExpiresAt)
+}
+
+// RefreshToken is one link of a session's rotation chain. Only a hash of the
+// token is stored; a token presented again after it was used means it leaked.
+type RefreshToken struct {
+	ID        uint       `gorm:"primaryKey" json:"-"`
+	SessionID string     `gorm:"size:64;index;not null" json:"-"`
+	TokenHash string     `gorm:"size:64;uniqueIndex;not null" json:"-"`
+	ExpiresAt time.Time  `gorm:"not null" json:"-"`
+	UsedAt    *time.Time `json:"-"`
+	CreatedAt time.Time  `json:"-"`
+}
@@ -0,0 +1,52 @@
+package models
+
+import "time"
+
+type StockAlertLevel string
+
+const (
+	// StockAlertReorder means stock is at or below the reorder point.
+	StockAlertReorder StockAlertLevel = "reorder"
+	// StockAlertCritical means stock is at or below the safety stock.
+	StockAlertCritical StockAlertLevel = "critical"
+)
+
+type StockAlertStatus string
+
+const (
+	StockAlertOpen     StockAlertStatus = "open"
+	StockAlertResolved StockAlertStatus = "resolved"
+)
+
+// StockAlert tells that a product needs reordering. A product has at most one
+// open alert, which a partial unique index enforces across checkers; it
+// escalates to critical and is resolved once stock is back above the reorder
+// point.
+type StockAlert struct {
+	ID           uint             `gorm:"primaryKey" json:"id"`
+	ProductID    string           `gorm:"size:64;index;uniqueIndex:idx_stock_alerts_open_product,where:status = 'open';not null" json:"product_id"`
+	Product      Product          `gorm:"foreignKey:ProductID" json:"-"`
+	Name         string           `gorm:"-" json:"name"`
+	SKU          string           `gorm:"-" json:"sku"`
+	Level        StockAlertLevel  `gorm:"size:20;not null" json:"level"`
+	Status       StockAlertStatus `gorm:"size:20;index;not null;default:'open'" json:"status"`
+	StockQty     int              `gorm:"not null" json:"stock"`
+	ReorderPoint int              `gorm:"not null" json:"reorder_point"`
+	SafetyStock  int              `gorm:"not null" json:"safety_stock"`
+	CreatedAt    time.Time        `json:"created_at"`
+	UpdatedAt    time.Time        `json:"updated_at"`
+	ResolvedAt   *time.Time       `json:"resolved_at,omitempty"`
+}
+
+// StockAlertLevelFor returns the alert level for a product's stock, or ""
+// when it is above its thresholds.
+func StockAlertLevelFor(p Product) StockAlertLevel {
+	switch {
+	case p.SafetyStock > 0 && p.StockQty <= p.SafetyStock:
+		return StockAlertCritical
+	case p.ReorderPoint > 0 && p.StockQty <= p.ReorderPoint:
+		return StockAlertReorder
+	default:
+		return ""
+	}
+}
@@ -0,0 +1,55 @@
+package models
+
+import "time"
+
+type StockCountStatus string
+
+const (
+	StockCountOpen      StockCountStatus = "open"
+	StockCountPosted    StockCountStatus = "posted"
+	StockCountCancelled StockCountStatus = "cancelled"
+)
+
+// StockCount is a stocktaking document for the products of a category and/or
+// a warehouse location. ExpectedQty is the stock when the count was started,
+// so orders placed while counting are not mistaken for variances.
+type StockCount struct {
+	ID         string           `gorm:"primaryKey;size:64" json:"id"`
+	CategoryID *uint            `gorm:"index" json:"category_id,omitempty"`
+	Location   string           `gorm:"size:60" json:"location,omitempty"`
+	Status     StockCountStatus `gorm:"size:20;index;not null;default:'open'" json:"status"`
+	Notes      string           `gorm:"type:text" json:"notes"`
+	Lines      []StockCountLine `gorm:"foreignKey:StockCountID" json:"lines"`
+	CreatedBy  string           `gorm:"size:160" json:"created_by"`
+	CreatedAt  time.Time        `json:"created_at"`
+	UpdatedAt  time.Time        `json:"updated_at"`
+	PostedBy   string           `gorm:"size:160" json:"posted_by,omitempty"`
+	PostedAt   *time.Time       `json:"posted_at,omitempty"`
+}
+
+type StockCountLine struct {
+	ID           uint    `gorm:"primaryKey" json:"id"`
+	StockCountID string  `gorm:"size:64;index;not null" json:"-"`
+	ProductID    string  `gorm:"size:64;index;not null" json:"product_id"`
+	Product      Product `gorm:"foreignKey:ProductID" json:"-"`
+	Name         string  `gorm:"-" json:"name,omitempty"`
+	SKU          string  `gorm:"-" json:"sku,omitempty"`
+	ExpectedQty  int     `gorm:"not null" json:"expected_qty"`
+	// CountedQty is nil until the product has been counted.
+	CountedQty *int `json:"counted_qty"`
+	Variance   *int `gorm:"-" json:"variance"`
+}
+
+// SyncViewFields fills line names and variances from preloaded products.
+func (c *StockCount) SyncViewFields() {
+	for i := range c.Lines {
+		line := &c.Lines[i]
+		line.Name = line.Product.Name
+		line.SKU = line.Product.SKU
+		line.Variance = nil
+		if line.CountedQty != nil {
+			variance := *line.CountedQty - line.ExpectedQty
+			line.Variance = &variance
+		}
+	}
+}
@@ -0,0 +1,23 @@
+package models
+
+import "time"
+
+type StockMovementReason string
+
+const (
+	StockMovementReceipt    StockMovementReason = "receipt"
+	StockMovementAdjustment StockMovementReason = "count_adjustment"
+	StockMovementImport     StockMovementReason = "import"
+)
+
+// StockMovement records a change of a product's stock that did not come from
+// a customer order, with the document that caused it.
+type StockMovement struct {
+	ID        uint                `gorm:"primaryKey" json:"id"`
+	ProductID string              `gorm:"size:64;index;not null" json:"product_id"`
+	Delta     int                 `gorm:"not null" json:"delta"`
+	Reason    StockMovementReason `gorm:"size:30;index;not null" json:"reason"`
+	Reference string              `gorm:"size:64;index" json:"reference"`
+	CreatedBy string              `gorm:"size:160" json:"created_by"`
+	CreatedAt time.Time           `gorm:"index" json:"created_at"`
+}
@@ -0,0 +1,14 @@
+package models
+
+import "time"
+
+// StockSubscription asks to email a client once an out-of-stock product is
+// back in stock. NotifiedAt is set when the notification has been sent.
+type StockSubscription struct {
+	ID         uint       `gorm:"primaryKey" json:"id"`
+	ProductID  string     `gorm:"size:64;index;not null" json:"product_id"`
+	Product    Product    `gorm:"foreignKey:ProductID" json:"-"`
+	Email      string     `gorm:"size:160;index;not null" json:"email"`
+	NotifiedAt *time.Time `gorm:"index" json:"notified_at,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
@@ -0,0 +1,29 @@
+package models
+
+import "time"
+
+// DefaultLocale is the language of the base product and category content.
+// Other locales are stored as translations and fall back to the base content.
+const DefaultLocale = "ru"
+
+type ProductTranslation struct {
+	ID          uint      `gorm:"primaryKey" json:"-"`
+	ProductID   string    `gorm:"size:64;not null;uniqueIndex:idx_product_translation" json:"product_id"`
+	Locale      string    `gorm:"size:20;not null;uniqueIndex:idx_product_translation" json:"locale"`
+	Name        string    `gorm:"size:180" json:"name"`
+	Description string    `gorm:"type:text" json:"description"`
+	Material    string    `gorm:"size:180" json:"material"`
+	UpdatedBy   string    `gorm:"size:180" json:"updated_by"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+type CategoryTranslation struct {
+	ID         uint      `gorm:"primaryKey" json:"-"`
+	CategoryID uint      `gorm:"not null;uniqueIndex:idx_category_translation" json:"category_id"`
+	Locale     string    `gorm:"size:20;not null;uniqueIndex:idx_category_translation" json:"locale"`
+	Name       string    `gorm:"size:120;not null" json:"name"`
+	UpdatedBy  string    `gorm:"size:180" json:"updated_by"`
+	CreatedAt  time.Time `json:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
@@ -0,0 +1,64 @@
+package models
+
+import "time"
+
+const (
+	TwoFactorRequired           = "required"
+	TwoFactorEnrollmentRequired = "enrollment_required"
+)
+
+// UserTOTP is a user's RFC 6238 secret. It only protects logins once
+// ConfirmedAt is set by a first valid code.
+type UserTOTP struct {
+	UserID      string     `gorm:"primaryKey;size:64" json:"-"`
+	Secret      string     `gorm:"size:64;not null" json:"-"`
+	ConfirmedAt *time.Time `json:"-"`
+	// LastUsedStep is the time step of the last accepted code; a code is
+	// accepted once.
+	LastUsedStep int64     `gorm:"not null;default:0" json:"-"`
+	CreatedAt    time.Time `json:"-"`
+	UpdatedAt    time.Time `json:"-"`
+}
+
+// RecoveryCode replaces a TOTP code once when the authenticator is lost.
+type RecoveryCode struct {
+	ID       uint       `gorm:"primaryKey" json:"-"`
+	UserID   string     `gorm:"size:64;index;not null" json:"-"`
+	CodeHash string     `gorm:"size:64;not null" json:"-"`
+	UsedAt   *time.Time `json:"-"`
+}
+
+// LoginChallenge links the password step of a login to its second factor.
+type LoginChallenge struct {
+	ID        uint       `gorm:"primaryKey" json:"-"`
+	UserID    string     `gorm:"size:64;index;not null" json:"-"`
+	Purpose   string     `gorm:"size:32;not null" json:"-"`
+	TokenHash string     `gorm:"size:64;uniqueIndex;not null" json:"-"`
+	Attempts  int        `gorm:"not null;default:0" json:"-"`
+	ExpiresAt time.Time  `gorm:"not null" json:"-"`
+	UsedAt    *time.Time `json:"-"`
+	CreatedAt time.Time  `json:"-"`
+}
+
+type TOTPSetup struct {
+	Secret     string `json:"secret"`
+	OTPAuthURI string `json:"otpauth_uri"`
+	// QRCode is a data: URI of a PNG encoding OTPAuthURI.
+	QRCode string `json:"qr_code"`
+}
+
+type TwoFactorStatus struct {
+	Enabled           bool  `json:"enabled"`
+	Required          bool  `json:"required"`
+	RecoveryCodesLeft int64 `json:"recovery_codes_left"`
+}
+
+type TwoFactorEnabled struct {
+	RecoveryCodes []string `json:"recovery_codes"`
+	// Login holds the tokens when enrollment finished a login challenge.
+	Login *LoginResponse `json:"login,omitempty"`
+}
+
+type TwoFactorPolicy struct {
+	RequiredRoles []RoleName `json:"required_roles"`
+}
@@ -0,0 +1,68 @@
+package repositories
+
+import (
+	"time"
+
+	"backend/internal/models"
+
+	"gorm.io/gorm"
+)
+
+type AccountTokenRepository struct{ db *gorm.DB }
+
+func NewAccountTokenRepository(db *gorm.DB) *AccountTokenRepository {
+	return &AccountTokenRepository{db: db}
+}
+
+// Create stores a token and retires the user's earlier unused tokens of the
+// same purpose, so only the latest mailed link works.
+func (r *AccountTokenRepository) Create(token *models.AccountToken) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&models.AccountToken{}).
+			Where("user_id = ? AND purpose = ? AND used_at IS NULL", token.UserID, token.Purpose).
+			Update("used_at", token.CreatedAt).Error; err != nil {
+			return err
+		}
+		return tx.Create(token).Error
+	})
+}
+
+func (r *AccountTokenRepository) Find(hash string, purpose models.AccountTokenPurpose) (models.AccountToken, error) {
+	var token models.AccountToken
+	err := r.db.First(&token, "token_hash = ? AND purpose = ?", hash, purpose).Error
+	return token, err
+}
+
+// ResetPassword uses a reset token and stores the new password hash in one
+// transaction. It reports false when the token was already used.
+func (r *AccountTokenRepository) ResetPassword(token models.AccountToken, passwordHash string, at time.Time) (bool, error) {
+	return r.redeem(token, at, func(tx *gorm.DB) error {
+		return tx.Model(&models.User{}).Where("id = ?", token.UserID).Update("password_hash", passwordHash).Error
+	})
+}
+
+// VerifyEmail uses a verification token and marks the user's email verified.
+func (r *AccountTokenRepository) VerifyEmail(token models.AccountToken, at time.Time) (bool, error) {
+	return r.redeem(token, at, func(tx *gorm.DB) error {
+		return tx.Model(&models.User{}).Where("id = ? AND email_verified_at IS NULL", token.UserID).Update("email_verified_at", at).Error
+	})
+}
+
+func (r *AccountTokenRepository) redeem(token models.AccountToken, at time.Time, apply func(tx *gorm.DB) error) (bool, error) {
+	redeemed := false
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		res := tx.Model(&models.AccountToken{}).Where("id = ? AND used_at IS NULL", token.ID).Update("used_at", at)
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return nil
+		}
+		if err := apply(tx); err != nil {
+			return err
+		}
+		redeemed = true
+		return nil
+	})
+	return redeemed, err
+}
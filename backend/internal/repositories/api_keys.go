@@ -0,0 +1,53 @@
+package repositories
+
+import (
+	"time"
+
+	"backend/internal/models"
+
+	"gorm.io/gorm"
+)
+
+type APIKeyRepository struct{ db *gorm.DB }
+
+func NewAPIKeyRepository(db *gorm.DB) *APIKeyRepository {
+	return &APIKeyRepository{db: db}
+}
+
+func (r *APIKeyRepository) Create(key *models.APIKey) error {
+	return r.db.Create(key).Error
+}
+
+func (r *APIKeyRepository) List() ([]models.APIKey, error) {
+	var keys []models.APIKey
+	err := r.db.Order("created_at desc").Find(&keys).Error
+	return keys, err
+}
+
+func (r *APIKeyRepository) FindByPrefix(prefix string) (models.APIKey, error) {
+	var key models.APIKey
+	err := r.db.First(&key, "prefix = ?", prefix).Error
+	return key, err
+}
+
+// Revoke stops a key from authenticating; revoking it again is a no-op.
+func (r *APIKeyRepository) Revoke(id string, at time.Time) (models.APIKey, error) {
+	var key models.APIKey
+	if err := r.db.First(&key, "id = ?", id).Error; err != nil {
+		return models.APIKey{}, err
+	}
+	if key.RevokedAt == nil {
+		if err := r.db.Model(&key).Update("revoked_at", at).Error; err != nil {
+			return models.APIKey{}, err
+		}
+	}
+	return key, nil
+}
+
+// Touch records that the key was used unless that was already recorded
+// within the last interval, so busy integrations do not write on every call.
+func (r *APIKeyRepository) Touch(id string, at time.Time, interval time.Duration) error {
+	return r.db.Model(&models.APIKey{}).
+		Where("id = ? AND (last_used_at IS NULL OR last_used_at < ?)", id, at.Add(-interval)).
+		Update("last_used_at", at).Error
+}
@@ -0,0 +1,48 @@
+package repositories
+
+import (
+	"backend/internal/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type ExchangeRateRepository struct{ db *gorm.DB }
+
+func NewExchangeRateRepository(db *gorm.DB) *ExchangeRateRepository {
+	return &ExchangeRateRepository{db: db}
+}
+
+func (r *ExchangeRateRepository) List() ([]models.ExchangeRate, error) {
+	var items []models.ExchangeRate
+	err := r.db.Order("currency asc").Find(&items).Error
+	return items, err
+}
+
+func (r *ExchangeRateRepository) Get(currency string) (models.ExchangeRate, error) {
+	var item models.ExchangeRate
+	err := r.db.First(&item, "currency = ?", currency).Error
+	return item, err
+}
+
+// Save upserts all rates in one transaction.
+func (r *ExchangeRateRepository) Save(items []models.ExchangeRate) error {
+	if len(items) == 0 {
+		return nil
+	}
+	return r.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "currency"}},
+		DoUpdates: clause.AssignmentColumns([]string{"rate", "rounding", "updated_by", "updated_at"}),
+	}).Create(&items).Error
+}
+
+func (r *ExchangeRateRepository) Delete(currency string) error {
+	res := r.db.Where("currency = ?", currency).Delete(&models.ExchangeRate{})
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
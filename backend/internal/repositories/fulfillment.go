@@ -0,0 +1,85 @@
+package repositories
+
+import (
+	"time"
+
+	"backend/internal/models"
+
+	"gorm.io/gorm"
+)
+
+type FulfillmentRepository struct{ db *gorm.DB }
+
+func NewFulfillmentRepository(db *gorm.DB) *FulfillmentRepository {
+	return &FulfillmentRepository{db: db}
+}
+
+// preloadFulfillment loads order lines in a stable order with their products
+// and, for bundles, the components to pick.
+func preloadFulfillment(query *gorm.DB) *gorm.DB {
+	unscoped := func(tx *gorm.DB) *gorm.DB { return tx.Unscoped() }
+	return query.
+		Preload("Customer").
+		Preload("StatusRef").
+		Preload("Items", func(tx *gorm.DB) *gorm.DB { return tx.Order("id asc") }).
+		Preload("Items.Product", unscoped).
+		Preload("Items.Product.Components").
+		Preload("Items.Product.Components.Component", unscoped)
+}
+
+// ListByStatus returns the orders in a status, oldest first.
+func (r *FulfillmentRepository) ListByStatus(status models.OrderState) ([]models.Order, error) {
+	var orders []models.Order
+	err := r.db.Scopes(preloadFulfillment).
+		Joins("JOIN order_status_refs ON order_status_refs.id = orders.status_id").
+		Where("order_status_refs.code = ?", string(status)).
+		Order("orders.created_at asc").
+		Find(&orders).Error
+	return orders, err
+}
+
+func (r *FulfillmentRepository) Get(id string) (models.Order, error) {
+	var order models.Order
+	err := r.db.Scopes(preloadFulfillment).First(&order, "id = ?", id).Error
+	return order, err
+}
+
+// Pick marks the given lines of an order as picked. Lines picked before keep
+// their original time and picker.
+func (r *FulfillmentRepository) Pick(orderID string, lineIDs []uint, actor string, at time.Time) error {
+	return r.db.Model(&models.OrderItem{}).
+		Where("order_id = ? AND id IN ? AND picked_at IS NULL", orderID, lineIDs).
+		Updates(map[string]any{"picked_at": at, "picked_by": actor, "updated_at": at}).Error
+}
+
+// Pack marks the given picked lines as packed and, once every line of the
+// order is packed, moves the order to shippedStatusID in the same
+// transaction. It reports whether the order was shipped.
+func (r *FulfillmentRepository) Pack(orderID string, lineIDs []uint, actor string, at time.Time, shippedStatusID uint) (bool, error) {
+	shipped := false
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Model(&models.OrderItem{}).
+			Where("order_id = ? AND id IN ? AND picked_at IS NOT NULL AND packed_at IS NULL", orderID, lineIDs).
+			Updates(map[string]any{"packed_at": at, "packed_by": actor, "updated_at": at}).Error
+		if err != nil {
+			return err
+		}
+		var unpacked int64
+		if err := tx.Model(&models.OrderItem{}).Where("order_id = ? AND packed_at IS NULL", orderID).Count(&unpacked).Error; err != nil {
+			return err
+		}
+		if unpacked > 0 {
+			return nil
+		}
+		res := tx.Model(&models.Order{}).Where("id = ?", orderID).Updates(map[string]any{"status_id": shippedStatusID, "updated_at": at})
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+		shipped = true
+		return nil
+	})
+	return shipped, err
+}
@@ -0,0 +1,67 @@
+package repositories
+
+import (
+	"time"
+
+	"backend/internal/models"
+
+	"gorm.io/gorm"
+)
+
+type OIDCRepository struct{ db *gorm.DB }
+
+func NewOIDCRepository(db *gorm.DB) *OIDCRepository {
+	return &OIDCRepository{db: db}
+}
+
+func (r *OIDCRepository) CreateLogin(login *models.OIDCLogin) error {
+	return r.db.Create(login).Error
+}
+
+// UseLogin spends the open login attempt with the state hash, so a callback
+// cannot be replayed.
+func (r *OIDCRepository) UseLogin(stateHash string, at time.Time) (models.OIDCLogin, error) {
+	var login models.OIDCLogin
+	if err := r.db.First(&login, "state_hash = ? AND used_at IS NULL AND expires_at > ?", stateHash, at).Error; err != nil {
+		return models.OIDCLogin{}, err
+	}
+	res := r.db.Model(&models.OIDCLogin{}).Where("id = ? AND used_at IS NULL", login.ID).Update("used_at", at)
+	if res.Error != nil {
+		return models.OIDCLogin{}, res.Error
+	}
+	if res.RowsAffected == 0 {
+		return models.OIDCLogin{}, gorm.ErrRecordNotFound
+	}
+	return login, nil
+}
+
+// DeleteExpiredLogins drops attempts that were used or can no longer be.
+func (r *OIDCRepository) DeleteExpiredLogins(before time.Time) error {
+	return r.db.Where("used_at IS NOT NULL OR expires_at < ?", before).Delete(&models.OIDCLogin{}).Error
+}
+
+func (r *OIDCRepository) FindIdentity(issuer, subject string) (models.UserIdentity, error) {
+	var identity models.UserIdentity
+	err := r.db.First(&identity, "issuer = ? AND subject = ?", issuer, subject).Error
+	return identity, err
+}
+
+func (r *OIDCRepository) CreateIdentity(identity *models.UserIdentity) error {
+	return r.db.Create(identity).Error
+}
+
+// Provision creates a user together with the identity it signed in with.
+func (r *OIDCRepository) Provision(user *models.User, identity *models.UserIdentity) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(user).Error; err != nil {
+			return err
+		}
+		identity.UserID = user.ID
+		return tx.Create(identity).Error
+	})
+}
+
+func (r *OIDCRepository) TouchIdentity(id uint, email string, at time.Time) error {
+	return r.db.Model(&models.UserIdentity{}).Where("id = ?", id).
+		Updates(map[string]any{"email": email, "last_login_at": at}).Error
+}
@@ -0,0 +1,185 @@
+package repositories
+
+import (
+	"backend/internal/models"
+
+	"gorm.io/gorm"
+)
+
+type PermissionRepository struct{ db *gorm.DB }
+
+func NewPermissionRepository(db *gorm.DB) *PermissionRepository {
+	return &PermissionRepository{db: db}
+}
+
+// ForRole returns every permission, allow or deny, granted to the role.
+func (r *PermissionRepository) ForRole(role models.RoleName) ([]models.Permission, error) {
+	var permissions []models.Permission
+	err := r.db.Model(&models.Permission{}).
+		Joins("JOIN role_permissions ON role_permissions.permission_id = permissions.id").
+		Joins("JOIN roles ON roles.id = role_permissions.role_id").
+		Where("roles.name = ?", role).
+		Find(&permissions).Error
+	return permissions, err
+}
+
+// ListRoles returns every role with the number of users assigned to it.
+func (r *PermissionRepository) ListRoles() ([]models.RoleSummary, error) {
+	var roles []models.RoleSummary
+	err := r.db.Model(&models.Role{}).
+		Select("roles.id, roles.name, roles.require_two_factor, COUNT(users.id) AS user_count").
+		Joins("LEFT JOIN users ON users.role_id = roles.id").
+		Group("roles.id, roles.name, roles.require_two_factor").
+		Order("roles.id asc").
+		Scan(&roles).Error
+	for i := range roles {
+		roles[i].Builtin = roles[i].Name.IsBuiltin()
+	}
+	return roles, err
+}
+
+func (r *PermissionRepository) FindRole(id uint) (models.Role, error) {
+	var role models.Role
+	err := r.db.First(&role, id).Error
+	return role, err
+}
+
+// RoleNameTaken reports whether a role with the name exists, ignoring case.
+func (r *PermissionRepository) RoleNameTaken(name models.RoleName) (bool, error) {
+	var count int64
+	err := r.db.Model(&models.Role{}).Where("LOWER(name) = LOWER(?)", name).Count(&count).Error
+	return count > 0, err
+}
+
+func (r *PermissionRepository) CreateRole(role *models.Role) error {
+	return r.db.Create(role).Error
+}
+
+func (r *PermissionRepository) CountRoleUsers(roleID uint) (int64, error) {
+	var count int64
+	err := r.db.Model(&models.User{}).Where("role_id = ?", roleID).Count(&count).Error
+	return count, err
+}
+
+// DeleteRole removes a role and its grants, then runs check inside the same
+// transaction so a failing check keeps both.
+func (r *PermissionRepository) DeleteRole(id uint, check func(*PermissionRepository) error) error {
+	return r.change(check, func(tx *gorm.DB) error {
+		if err := tx.Where("role_id = ?", id).Delete(&models.RolePermission{}).Error; err != nil {
+			return err
+		}
+		res := tx.Delete(&models.Role{}, id)
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+		return nil
+	})
+}
+
+// RolePermissions returns the permissions granted to a role.
+func (r *PermissionRepository) RolePermissions(roleID uint) ([]models.RolePermissionView, error) {
+	var views []models.RolePermissionView
+	err := r.db.Model(&models.RolePermission{}).
+		Select("permissions.id AS permission_id, roles.name AS role, permissions.action, permissions.resource, permissions.effect, permissions.description").
+		Joins("JOIN permissions ON permissions.id = role_permissions.permission_id").
+		Joins("JOIN roles ON roles.id = role_permissions.role_id").
+		Where("role_permissions.role_id = ?", roleID).
+		Order("permissions.resource asc, permissions.action asc, permissions.effect asc").
+		Scan(&views).Error
+	return views, err
+}
+
+// Grant maps a permission to a role unless it already is, then runs check.
+func (r *PermissionRepository) Grant(roleID, permissionID uint, check func(*PermissionRepository) error) error {
+	return r.change(check, func(tx *gorm.DB) error {
+		grant := models.RolePermission{RoleID: roleID, PermissionID: permissionID}
+		return tx.Where("role_id = ? AND permission_id = ?", roleID, permissionID).FirstOrCreate(&grant).Error
+	})
+}
+
+// Revoke removes a permission from a role, then runs check.
+func (r *PermissionRepository) Revoke(roleID, permissionID uint, check func(*PermissionRepository) error) error {
+	return r.change(check, func(tx *gorm.DB) error {
+		res := tx.Where("role_id = ? AND permission_id = ?", roleID, permissionID).Delete(&models.RolePermission{})
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+		return nil
+	})
+}
+
+func (r *PermissionRepository) List() ([]models.Permission, error) {
+	var permissions []models.Permission
+	err := r.db.Order("resource asc, action asc, effect asc").Find(&permissions).Error
+	return permissions, err
+}
+
+func (r *PermissionRepository) FindPermission(id uint) (models.Permission, error) {
+	var permission models.Permission
+	err := r.db.First(&permission, id).Error
+	return permission, err
+}
+
+// PermissionExists reports whether the action, resource and effect are
+// already defined.
+func (r *PermissionRepository) PermissionExists(action, resource, effect string) (bool, error) {
+	var count int64
+	err := r.db.Model(&models.Permission{}).Where("action = ? AND resource = ? AND effect = ?", action, resource, effect).Count(&count).Error
+	return count > 0, err
+}
+
+func (r *PermissionRepository) CreatePermission(permission *models.Permission) error {
+	return r.db.Create(permission).Error
+}
+
+// DeletePermission removes a permission and its grants, then runs check.
+func (r *PermissionRepository) DeletePermission(id uint, check func(*PermissionRepository) error) error {
+	return r.change(check, func(tx *gorm.DB) error {
+		if err := tx.Where("permission_id = ?", id).Delete(&models.RolePermission{}).Error; err != nil {
+			return err
+		}
+		res := tx.Delete(&models.Permission{}, id)
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+		return nil
+	})
+}
+
+// CountHolders counts the active users whose role is allowed action on
+// resource and not denied it.
+func (r *PermissionRepository) CountHolders(action, resource string) (int64, error) {
+	granted := func(effect string) *gorm.DB {
+		return r.db.Model(&models.RolePermission{}).Select("role_permissions.role_id").
+			Joins("JOIN permissions ON permissions.id = role_permissions.permission_id").
+			Where("permissions.action = ? AND permissions.resource = ? AND permissions.effect = ?", action, resource, effect)
+	}
+	var count int64
+	err := r.db.Model(&models.User{}).
+		Where("is_blocked = ?", false).
+		Where("role_id IN (?)", granted(models.PermissionAllow)).
+		Where("role_id NOT IN (?)", granted(models.PermissionDeny)).
+		Count(&count).Error
+	return count, err
+}
+
+func (r *PermissionRepository) change(check func(*PermissionRepository) error, apply func(tx *gorm.DB) error) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := apply(tx); err != nil {
+			return err
+		}
+		if check == nil {
+			return nil
+		}
+		return check(&PermissionRepository{db: tx})
+	})
+}
@@ -0,0 +1,99 @@
+package repositories
+
+import (
+	"time"
+
+	"backend/internal/models"
+
+	"gorm.io/gorm"
+)
+
+type PriceRepository struct{ db *gorm.DB }
+
+func NewPriceRepository(db *gorm.DB) *PriceRepository { return &PriceRepository{db: db} }
+
+func (r *PriceRepository) ListHistory(productID string) ([]models.PriceHistory, error) {
+	var items []models.PriceHistory
+	err := r.db.Where("product_id = ?", productID).Order("changed_at desc, id desc").Find(&items).Error
+	return items, err
+}
+
+func (r *PriceRepository) ListSchedules(productID string) ([]models.ScheduledPrice, error) {
+	var items []models.ScheduledPrice
+	err := r.db.Where("product_id = ?", productID).Order("starts_at asc").Find(&items).Error
+	return items, err
+}
+
+func (r *PriceRepository) ListOpenSchedules(productID string) ([]models.ScheduledPrice, error) {
+	var items []models.ScheduledPrice
+	err := r.db.Where("product_id = ? AND status IN ?", productID, []models.ScheduledPriceStatus{models.ScheduledPricePending, models.ScheduledPriceActive}).
+		Order("starts_at asc").Find(&items).Error
+	return items, err
+}
+
+func (r *PriceRepository) GetSchedule(id uint) (models.ScheduledPrice, error) {
+	var item models.ScheduledPrice
+	err := r.db.First(&item, "id = ?", id).Error
+	return item, err
+}
+
+func (r *PriceRepository) CreateSchedule(item *models.ScheduledPrice) error {
+	return r.db.Create(item).Error
+}
+
+func (r *PriceRepository) SaveSchedule(tx *gorm.DB, item *models.ScheduledPrice) error {
+	return tx.Save(item).Error
+}
+
+// MoveSchedule writes the schedule's status and restore prices only if it is
+// still in status from, and reports whether it was. Concurrent changes of a
+// schedule, such as a cancellation and the scheduler, so cannot both apply.
+func (r *PriceRepository) MoveSchedule(tx *gorm.DB, item *models.ScheduledPrice, from models.ScheduledPriceStatus) (bool, error) {
+	res := tx.Model(&models.ScheduledPrice{}).Where("id = ? AND status = ?", item.ID, from).Updates(map[string]any{
+		"status":                 item.Status,
+		"restore_price":          item.RestorePrice,
+		"restore_original_price": item.RestoreOriginalPrice,
+		"updated_at":             time.Now().UTC(),
+	})
+	if res.Error != nil {
+		return false, res.Error
+	}
+	return res.RowsAffected > 0, nil
+}
+
+func (r *PriceRepository) DueToStart(now time.Time) ([]models.ScheduledPrice, error) {
+	var items []models.ScheduledPrice
+	err := r.db.Where("status = ? AND starts_at <= ?", models.ScheduledPricePending, now).Order("starts_at asc").Find(&items).Error
+	return items, err
+}
+
+func (r *PriceRepository) DueToEnd(now time.Time) ([]models.ScheduledPrice, error) {
+	var items []models.ScheduledPrice
+	err := r.db.Where("status = ? AND ends_at IS NOT NULL AND ends_at <= ?", models.ScheduledPriceActive, now).Order("ends_at asc").Find(&items).Error
+	return items, err
+}
+
+func (r *PriceRepository) FindProduct(tx *gorm.DB, id string) (models.Product, error) {
+	var product models.Product
+	err := tx.First(&product, "id = ?", id).Error
+	return product, err
+}
+
+func (r *PriceRepository) SetProductPrice(tx *gorm.DB, productID string, price int64, originalPrice *int64) error {
+	res := tx.Model(&models.Product{}).Where("id = ?", productID).Updates(map[string]any{"price": price, "original_price": originalPrice})
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+func (r *PriceRepository) CreateHistory(tx *gorm.DB, entry *models.PriceHistory) error {
+	return tx.Create(entry).Error
+}
+
+func (r *PriceRepository) Begin() *gorm.DB {
+	return r.db.Begin()
+}
@@ -0,0 +1,213 @@
+package repositories
+
+import (
+	"errors"
+	"time"
+
+	"backend/internal/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type ProcurementRepository struct{ db *gorm.DB }
+
+func NewProcurementRepository(db *gorm.DB) *ProcurementRepository {
+	return &ProcurementRepository{db: db}
+}
+
+func (r *ProcurementRepository) ListSuppliers() ([]models.Supplier, error) {
+	var items []models.Supplier
+	err := r.db.Order("name asc").Find(&items).Error
+	return items, err
+}
+
+func (r *ProcurementRepository) GetSupplier(id uint) (models.Supplier, error) {
+	var item models.Supplier
+	err := r.db.First(&item, id).Error
+	return item, err
+}
+
+func (r *ProcurementRepository) SupplierNameTaken(name string, exceptID uint) (bool, error) {
+	var count int64
+	err := r.db.Model(&models.Supplier{}).Where("LOWER(name) = LOWER(?) AND id <> ?", name, exceptID).Count(&count).Error
+	return count > 0, err
+}
+
+func (r *ProcurementRepository) SaveSupplier(item *models.Supplier) error {
+	return r.db.Save(item).Error
+}
+
+func preloadPurchaseOrder(query *gorm.DB) *gorm.DB {
+	return query.Preload("Supplier").
+		Preload("Lines", func(db *gorm.DB) *gorm.DB { return db.Order("id asc") }).
+		Preload("Lines.Product", func(db *gorm.DB) *gorm.DB { return db.Unscoped() })
+}
+
+func (r *ProcurementRepository) ListPurchaseOrders(status models.PurchaseOrderStatus) ([]models.PurchaseOrder, error) {
+	query := r.db.Scopes(preloadPurchaseOrder)
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+	var items []models.PurchaseOrder
+	if err := query.Order("created_at desc").Find(&items).Error; err != nil {
+		return nil, err
+	}
+	for i := range items {
+		items[i].SyncViewFields()
+	}
+	return items, nil
+}
+
+func (r *ProcurementRepository) GetPurchaseOrder(id string) (models.PurchaseOrder, error) {
+	var item models.PurchaseOrder
+	if err := r.db.Scopes(preloadPurchaseOrder).First(&item, "id = ?", id).Error; err != nil {
+		return models.PurchaseOrder{}, err
+	}
+	item.SyncViewFields()
+	return item, nil
+}
+
+// SavePurchaseOrder writes the order and replaces its lines.
+func (r *ProcurementRepository) SavePurchaseOrder(item *models.PurchaseOrder, create bool) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		query := tx.Omit(clause.Associations)
+		var err error
+		if create {
+			err = query.Create(item).Error
+		} else {
+			err = query.Save(item).Error
+		}
+		if err != nil {
+			return err
+		}
+		if err := tx.Where("purchase_order_id = ?", item.ID).Delete(&models.PurchaseOrderLine{}).Error; err != nil {
+			return err
+		}
+		lines := make([]models.PurchaseOrderLine, 0, len(item.Lines))
+		for _, line := range item.Lines {
+			lines = append(lines, models.PurchaseOrderLine{
+				PurchaseOrderID: item.ID,
+				ProductID:       line.ProductID,
+				Qty:             line.Qty,
+				ReceivedQty:     line.ReceivedQty,
+				UnitCost:        line.UnitCost,
+			})
+		}
+		if len(lines) == 0 {
+			return nil
+		}
+		return tx.Create(&lines).Error
+	})
+}
+
+func (r *ProcurementRepository) UpdateStatus(id string, fields map[string]any) error {
+	return r.db.Model(&models.PurchaseOrder{}).Where("id = ?", id).Updates(fields).Error
+}
+
+// Receive books received quantities per line id, moves them into stock and
+// updates the order status, all in one transaction. It returns false and
+// books nothing when the order is no longer open for receipt or a line would
+// receive more than was ordered, e.g. because another receipt got there
+// first.
+func (r *ProcurementRepository) Receive(orderID string, lines []models.PurchaseOrderLine, received map[uint]int, actor string, at time.Time) (bool, error) {
+	booked := false
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		open := []models.PurchaseOrderStatus{models.PurchaseOrderSent, models.PurchaseOrderPartiallyReceived}
+		res := tx.Model(&models.PurchaseOrder{}).Where("id = ? AND status IN ?", orderID, open).Update("updated_at", at)
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return nil
+		}
+		for _, line := range lines {
+			qty := received[line.ID]
+			if qty == 0 {
+				continue
+			}
+			res := tx.Model(&models.PurchaseOrderLine{}).
+				Where("id = ? AND received_qty + ? <= qty", line.ID, qty).
+				Update("received_qty", gorm.Expr("received_qty + ?", qty))
+			if res.Error != nil {
+				return res.Error
+			}
+			if res.RowsAffected == 0 {
+				return errReceiptConflict
+			}
+			if err := moveStock(tx, line.ProductID, qty, models.StockMovementReceipt, orderID, actor, at); err != nil {
+				return err
+			}
+		}
+
+		var outstanding int64
+		if err := tx.Model(&models.PurchaseOrderLine{}).Where("purchase_order_id = ? AND received_qty < qty", orderID).Count(&outstanding).Error; err != nil {
+			return err
+		}
+		fields := map[string]any{"status": models.PurchaseOrderPartiallyReceived}
+		if outstanding == 0 {
+			fields["status"] = models.PurchaseOrderReceived
+			fields["received_at"] = at
+		}
+		if err := tx.Model(&models.PurchaseOrder{}).Where("id = ?", orderID).Updates(fields).Error; err != nil {
+			return err
+		}
+		booked = true
+		return nil
+	})
+	if errors.Is(err, errReceiptConflict) {
+		return false, nil
+	}
+	return booked, err
+}
+
+// errReceiptConflict rolls back a receipt that would over-receive a line.
+var errReceiptConflict = errors.New("receipt exceeds the quantity left to receive")
+
+// OnOrder returns, per product, the units still to come on draft, sent and
+// partially received purchase orders.
+func (r *ProcurementRepository) OnOrder() (map[string]int, error) {
+	var rows []struct {
+		ProductID string
+		Qty       int
+	}
+	open := []models.PurchaseOrderStatus{models.PurchaseOrderDraft, models.PurchaseOrderSent, models.PurchaseOrderPartiallyReceived}
+	err := r.db.Model(&models.PurchaseOrderLine{}).
+		Select("purchase_order_lines.product_id, SUM(purchase_order_lines.qty - purchase_order_lines.received_qty) AS qty").
+		Joins("JOIN purchase_orders ON purchase_orders.id = purchase_order_lines.purchase_order_id").
+		Where("purchase_orders.status IN ?", open).
+		Group("purchase_order_lines.product_id").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+	result := make(map[string]int, len(rows))
+	for _, row := range rows {
+		result[row.ProductID] = row.Qty
+	}
+	return result, nil
+}
+
+// UnitsSold returns units of each product sold in non-cancelled orders
+// placed since the given time.
+func (r *ProcurementRepository) UnitsSold(since time.Time) (map[string]int, error) {
+	var rows []struct {
+		ProductID string
+		Qty       int
+	}
+	err := r.db.Model(&models.OrderItem{}).
+		Select("order_items.product_id, SUM(order_items.qty) AS qty").
+		Joins("JOIN orders ON orders.id = order_items.order_id").
+		Joins("JOIN order_status_refs ON order_status_refs.id = orders.status_id").
+		Where("order_status_refs.code <> ? AND orders.created_at >= ?", models.OrderStatusCancelled, since).
+		Group("order_items.product_id").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+	result := make(map[string]int, len(rows))
+	for _, row := range rows {
+		result[row.ProductID] = row.Qty
+	}
+	return result, nil
+}
@@ -0,0 +1,56 @@
+package repositories
+
+import (
+	"encoding/json"
+	"time"
+
+	"backend/internal/models"
+
+	"gorm.io/gorm"
+)
+
+type ProductImportRepository struct{ db *gorm.DB }
+
+func NewProductImportRepository(db *gorm.DB) *ProductImportRepository {
+	return &ProductImportRepository{db: db}
+}
+
+func (r *ProductImportRepository) Create(job *models.ProductImportJob) error {
+	return r.db.Create(job).Error
+}
+
+func (r *ProductImportRepository) Save(job *models.ProductImportJob) error {
+	raw, err := json.Marshal(job.Errors)
+	if err != nil {
+		return err
+	}
+	job.ErrorsJSON = string(raw)
+	return r.db.Save(job).Error
+}
+
+func (r *ProductImportRepository) GetByID(id string) (models.ProductImportJob, error) {
+	var job models.ProductImportJob
+	if err := r.db.First(&job, "id = ?", id).Error; err != nil {
+		return models.ProductImportJob{}, err
+	}
+	job.Errors = []models.ImportRowError{}
+	if job.ErrorsJSON != "" {
+		if err := json.Unmarshal([]byte(job.ErrorsJSON), &job.Errors); err != nil {
+			return models.ProductImportJob{}, err
+		}
+	}
+	return job, nil
+}
+
+// FailUnfinished marks every queued or running job as failed with message
+// and returns how many there were.
+func (r *ProductImportRepository) FailUnfinished(message string, at time.Time) (int64, error) {
+	raw, err := json.Marshal([]models.ImportRowError{{Message: message}})
+	if err != nil {
+		return 0, err
+	}
+	res := r.db.Model(&models.ProductImportJob{}).
+		Where("status IN ?", []models.ImportJobStatus{models.ImportJobQueued, models.ImportJobRunning}).
+		Updates(map[string]any{"status": models.ImportJobFailed, "finished_at": at, "errors_json": string(raw)})
+	return res.RowsAffected, res.Error
+}
@@ -0,0 +1,58 @@
+package repositories
+
+import (
+	"time"
+
+	"backend/internal/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type RateCounterRepository struct{ db *gorm.DB }
+
+func NewRateCounterRepository(db *gorm.DB) *RateCounterRepository {
+	return &RateCounterRepository{db: db}
+}
+
+// Hit increments the counter of key in its current window, or starts a new
+// window of the given length when there is none or it has ended.
+func (r *RateCounterRepository) Hit(key string, window time.Duration, now time.Time) (models.RateCounter, error) {
+	var counter models.RateCounter
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		res := tx.Model(&models.RateCounter{}).Where("key = ? AND reset_at > ?", key, now).Update("count", gorm.Expr("count + 1"))
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			fresh := models.RateCounter{Key: key, Count: 1, ResetAt: now.Add(window)}
+			if err := tx.Clauses(clause.OnConflict{
+				Columns:   []clause.Column{{Name: "key"}},
+				DoUpdates: clause.AssignmentColumns([]string{"count", "reset_at"}),
+			}).Create(&fresh).Error; err != nil {
+				return err
+			}
+		}
+		return tx.First(&counter, "key = ?", key).Error
+	})
+	return counter, err
+}
+
+// Get returns the counter of key, or a zero counter when its window ended.
+func (r *RateCounterRepository) Get(key string, now time.Time) (models.RateCounter, error) {
+	var counter models.RateCounter
+	err := r.db.Where("key = ? AND reset_at > ?", key, now).Limit(1).Find(&counter).Error
+	return counter, err
+}
+
+func (r *RateCounterRepository) Delete(keys ...string) error {
+	if len(keys) == 0 {
+		return nil
+	}
+	return r.db.Where("key IN ?", keys).Delete(&models.RateCounter{}).Error
+}
+
+func (r *RateCounterRepository) DeleteExpired(now time.Time) (int64, error) {
+	res := r.db.Where("reset_at <= ?", now).Delete(&models.RateCounter{})
+	return res.RowsAffected, res.Error
+}
@@ -0,0 +1,89 @@
+package repositories
+
+import (
+	"backend/internal/models"
+
+	"gorm.io/gorm"
+)
+
+type RecommendationRepository struct{ db *gorm.DB }
+
+func NewRecommendationRepository(db *gorm.DB) *RecommendationRepository {
+	return &RecommendationRepository{db: db}
+}
+
+func (r *RecommendationRepository) ListLinks(productID string) ([]models.ProductLink, error) {
+	var links []models.ProductLink
+	err := r.db.Where("product_id = ?", productID).Order("created_at asc, id asc").Find(&links).Error
+	return links, err
+}
+
+func (r *RecommendationRepository) CreateLink(link *models.ProductLink) error {
+	return r.db.Create(link).Error
+}
+
+func (r *RecommendationRepository) LinkExists(productID, relatedID string) (bool, error) {
+	var count int64
+	err := r.db.Model(&models.ProductLink{}).Where("product_id = ? AND related_id = ?", productID, relatedID).Count(&count).Error
+	return count > 0, err
+}
+
+func (r *RecommendationRepository) DeleteLink(productID, relatedID string) error {
+	res := r.db.Where("product_id = ? AND related_id = ?", productID, relatedID).Delete(&models.ProductLink{})
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+func (r *RecommendationRepository) ListRecommendations(productID string) ([]models.ProductRecommendation, error) {
+	var items []models.ProductRecommendation
+	err := r.db.Where("product_id = ?", productID).Order("score desc, support desc, related_id asc").Find(&items).Error
+	return items, err
+}
+
+// OrderBaskets returns the product ids of every non-cancelled order.
+func (r *RecommendationRepository) OrderBaskets() ([][]string, error) {
+	var rows []struct {
+		OrderID   string
+		ProductID string
+	}
+	err := r.db.Model(&models.OrderItem{}).
+		Select("order_items.order_id, order_items.product_id").
+		Joins("JOIN orders ON orders.id = order_items.order_id").
+		Joins("JOIN order_status_refs ON order_status_refs.id = orders.status_id").
+		Where("order_status_refs.code <> ?", models.OrderStatusCancelled).
+		Order("order_items.order_id asc").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	baskets := [][]string{}
+	current := ""
+	for _, row := range rows {
+		if row.OrderID != current || len(baskets) == 0 {
+			baskets = append(baskets, []string{})
+			current = row.OrderID
+		}
+		baskets[len(baskets)-1] = append(baskets[len(baskets)-1], row.ProductID)
+	}
+	return baskets, nil
+}
+
+// ReplaceRecommendations swaps the whole recommendations table in one
+// transaction so readers never see a half-built set.
+func (r *RecommendationRepository) ReplaceRecommendations(items []models.ProductRecommendation) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("1 = 1").Delete(&models.ProductRecommendation{}).Error; err != nil {
+			return err
+		}
+		if len(items) == 0 {
+			return nil
+		}
+		return tx.CreateInBatches(&items, 200).Error
+	})
+}
@@ -3,10 +3,12 @@ package repositories
 import (
 	"errors"
 	"strings"
+	"time"
 
 	"backend/internal/models"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type ProductRepository struct{ db *gorm.DB }
@@ -28,8 +30,39 @@ func NewCategoryRepository(db *gorm.DB) *CategoryRepository { return &CategoryRe
 func NewCustomerRepository(db *gorm.DB) *CustomerRepository { return &CustomerRepository{db: db} }
 
 func (r *ProductRepository) List() ([]models.Product, error) {
+	return r.list(r.db)
+}
+
+func (r *ProductRepository) ListActive() ([]models.Product, error) {
+	return r.list(r.db.Where("is_active = ?", true))
+}
+
+// ListActiveByIDs returns the active products among ids, in no particular order.
+func (r *ProductRepository) ListActiveByIDs(ids []string) ([]models.Product, error) {
+	if len(ids) == 0 {
+		return []models.Product{}, nil
+	}
+	return r.list(r.db.Where("id IN ? AND is_active = ?", ids, true))
+}
+
+// ListSimilar returns up to limit active products from the same category
+// priced within minPrice..maxPrice, closest to price first.
+func (r *ProductRepository) ListSimilar(categoryID uint, price, minPrice, maxPrice int64, exclude []string, limit int) ([]models.Product, error) {
+	query := r.db.Where("category_id = ? AND is_active = ? AND price BETWEEN ? AND ?", categoryID, true, minPrice, maxPrice)
+	if len(exclude) > 0 {
+		query = query.Where("id NOT IN ?", exclude)
+	}
+	query = query.Order(clause.OrderBy{Expression: clause.Expr{SQL: "ABS(price - ?) ASC", Vars: []any{price}}}).Limit(limit)
+	return r.list(query)
+}
+
+func (r *ProductRepository) ListArchived() ([]models.Product, error) {
+	return r.list(r.db.Unscoped().Where("deleted_at IS NOT NULL"))
+}
+
+func (r *ProductRepository) list(query *gorm.DB) ([]models.Product, error) {
 	var products []models.Product
-	err := r.db.Preload("CategoryRef").Order("created_at asc").Find(&products).Error
+	err := query.Preload("CategoryRef").Scopes(preloadComponents).Order("created_at asc").Find(&products).Error
 	if err != nil {
 		return nil, err
 	}
@@ -42,7 +75,25 @@ func (r *ProductRepository) List() ([]models.Product, error) {
 
 func (r *ProductRepository) GetByID(id string) (models.Product, error) {
 	var product models.Product
-	err := r.db.Preload("CategoryRef").First(&product, "id = ?", id).Error
+	err := r.db.Preload("CategoryRef").Scopes(preloadComponents).First(&product, "id = ?", id).Error
+	if err != nil {
+		return models.Product{}, err
+	}
+	product.Category = product.CategoryRef.Name
+	product.SyncViewFields()
+	return product, nil
+}
+
+// FindComponents returns the active, non-bundle products with the given ids.
+func (r *ProductRepository) FindComponents(ids []string) ([]models.Product, error) {
+	var products []models.Product
+	err := r.db.Where("id IN ? AND type <> ? AND is_active = ?", ids, models.ProductTypeBundle, true).Find(&products).Error
+	return products, err
+}
+
+func (r *ProductRepository) FindBySKU(sku string) (models.Product, error) {
+	var product models.Product
+	err := r.db.Preload("CategoryRef").Scopes(preloadComponents).First(&product, "sku = ?", strings.TrimSpace(sku)).Error
 	if err != nil {
 		return models.Product{}, err
 	}
@@ -51,6 +102,12 @@ func (r *ProductRepository) GetByID(id string) (models.Product, error) {
 	return product, nil
 }
 
+func (r *ProductRepository) IsArchivedSKU(sku string) (bool, error) {
+	var count int64
+	err := r.db.Unscoped().Model(&models.Product{}).Where("sku = ? AND deleted_at IS NOT NULL", strings.TrimSpace(sku)).Count(&count).Error
+	return count > 0, err
+}
+
 func (r *ProductRepository) FindCategoryIDByName(name string) (uint, error) {
 	normalizedName := strings.TrimSpace(name)
 
@@ -69,13 +126,80 @@ func (r *ProductRepository) FindCategoryIDByName(name string) (uint, error) {
 }
 
 func (r *ProductRepository) Create(product *models.Product) error {
-	product.SyncDBFields()
-	return r.db.Create(product).Error
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		return saveProduct(tx, product, true)
+	})
+}
+
+// FindByBarcode looks up a product by barcode, including archived products so
+// that their barcodes are not reused.
+func (r *ProductRepository) FindByBarcode(code string) (models.Product, error) {
+	var product models.Product
+	err := r.db.Unscoped().Preload("CategoryRef").Scopes(preloadComponents).First(&product, "barcode = ?", strings.TrimSpace(code)).Error
+	if err != nil {
+		return models.Product{}, err
+	}
+	product.Category = product.CategoryRef.Name
+	product.SyncViewFields()
+	return product, nil
 }
 
 func (r *ProductRepository) Update(product *models.Product) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		return saveProduct(tx, product, false)
+	})
+}
+
+func (r *ProductRepository) CreateWithPriceHistory(product *models.Product, entry models.PriceHistory) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := saveProduct(tx, product, true); err != nil {
+			return err
+		}
+		entry.ProductID = product.ID
+		return tx.Create(&entry).Error
+	})
+}
+
+func (r *ProductRepository) UpdateWithPriceHistory(product *models.Product, entry models.PriceHistory) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := saveProduct(tx, product, false); err != nil {
+			return err
+		}
+		entry.ProductID = product.ID
+		return tx.Create(&entry).Error
+	})
+}
+
+// saveProduct writes the product row and replaces its bundle components.
+// Associations are written explicitly so preloaded relations are never upserted.
+func saveProduct(tx *gorm.DB, product *models.Product, create bool) error {
 	product.SyncDBFields()
-	return r.db.Save(product).Error
+	query := tx.Omit(clause.Associations)
+	var err error
+	if create {
+		err = query.Create(product).Error
+	} else {
+		err = query.Save(product).Error
+	}
+	if err != nil {
+		return err
+	}
+
+	if err := tx.Where("bundle_id = ?", product.ID).Delete(&models.BundleComponent{}).Error; err != nil {
+		return err
+	}
+	if !product.IsBundle() || len(product.Components) == 0 {
+		return nil
+	}
+	components := make([]models.BundleComponent, 0, len(product.Components))
+	for _, c := range product.Components {
+		components = append(components, models.BundleComponent{BundleID: product.ID, ComponentID: c.ComponentID, Quantity: c.Quantity})
+	}
+	return tx.Omit(clause.Associations).Create(&components).Error
+}
+
+func (r *ProductRepository) SetReorderLevels(id string, reorderPoint, safetyStock int) error {
+	return r.db.Model(&models.Product{}).Where("id = ?", id).Updates(map[string]any{"reorder_point": reorderPoint, "safety_stock": safetyStock}).Error
 }
 
 func (r *ProductRepository) Delete(id string) error {
@@ -89,9 +213,34 @@ func (r *ProductRepository) Delete(id string) error {
 	return nil
 }
 
+func (r *ProductRepository) Restore(id string) error {
+	result := r.db.Unscoped().Model(&models.Product{}).Where("id = ? AND deleted_at IS NOT NULL", id).Update("deleted_at", nil)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// preloadComponents loads bundle components together with their products so
+// that bundle stock can be derived from them.
+func preloadComponents(query *gorm.DB) *gorm.DB {
+	return query.Preload("Components", func(tx *gorm.DB) *gorm.DB { return tx.Order("id asc") }).Preload("Components.Component")
+}
+
+// preloadItems loads order lines together with their products, including
+// archived ones, so that order history stays complete.
+func preloadItems(query *gorm.DB) *gorm.DB {
+	return query.
+		Preload("Items.Product", func(tx *gorm.DB) *gorm.DB { return tx.Unscoped() }).
+		Preload("Items.Product.CategoryRef")
+}
+
 func (r *OrderRepository) List() ([]models.Order, error) {
 	var orders []models.Order
-	err := r.db.Preload("Customer").Preload("StatusRef").Preload("Items.Product.CategoryRef").Order("created_at desc").Find(&orders).Error
+	err := preloadItems(r.db.Preload("Customer").Preload("StatusRef")).Order("created_at desc").Find(&orders).Error
 	return orders, err
 }
 
@@ -102,7 +251,7 @@ func (r *OrderRepository) ListByCustomerEmail(email string) ([]models.Order, err
 		Where("LOWER(customers.email) = ?", strings.ToLower(strings.TrimSpace(email))).
 		Preload("Customer").
 		Preload("StatusRef").
-		Preload("Items.Product.CategoryRef").
+		Scopes(preloadItems).
 		Order("orders.created_at desc").
 		Find(&orders).Error
 	return orders, err
@@ -110,7 +259,7 @@ func (r *OrderRepository) ListByCustomerEmail(email string) ([]models.Order, err
 
 func (r *OrderRepository) GetByID(id string) (models.Order, error) {
 	var order models.Order
-	err := r.db.Preload("Customer").Preload("StatusRef").Preload("Items.Product.CategoryRef").First(&order, "id = ?", id).Error
+	err := preloadItems(r.db.Preload("Customer").Preload("StatusRef")).First(&order, "id = ?", id).Error
 	return order, err
 }
 
@@ -186,7 +335,7 @@ func (r *OrderRepository) FindOrCreateCustomer(tx *gorm.DB, fullName, email stri
 
 func (r *OrderRepository) FindProductForUpdate(tx *gorm.DB, id string) (models.Product, error) {
 	var product models.Product
-	err := tx.Preload("CategoryRef").First(&product, "id = ?", id).Error
+	err := tx.Preload("CategoryRef").Scopes(preloadComponents).First(&product, "id = ?", id).Error
 	if err != nil {
 		return models.Product{}, err
 	}
@@ -195,6 +344,92 @@ func (r *OrderRepository) FindProductForUpdate(tx *gorm.DB, id string) (models.P
 	return product, nil
 }
 
+// FindComponentsForRestock returns the components of a bundle, including
+// when the bundle itself has been archived.
+func (r *OrderRepository) FindComponentsForRestock(tx *gorm.DB, bundleID string) ([]models.BundleComponent, error) {
+	var components []models.BundleComponent
+	err := tx.Where("bundle_id = ?", bundleID).Order("id asc").Find(&components).Error
+	return components, err
+}
+
+// IsBundle reports whether the product is a bundle, including archived ones.
+func (r *OrderRepository) IsBundle(tx *gorm.DB, id string) (bool, error) {
+	var count int64
+	err := tx.Unscoped().Model(&models.Product{}).Where("id = ? AND type = ?", id, models.ProductTypeBundle).Count(&count).Error
+	return count > 0, err
+}
+
+// AdjustStock changes stock by delta, including for archived products.
+func (r *OrderRepository) AdjustStock(tx *gorm.DB, id string, delta int) error {
+	res := tx.Unscoped().Model(&models.Product{}).Where("id = ?", id).Update("stock_qty", gorm.Expr("stock_qty + ?", delta))
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// AllocatePreorders gives the product's stock to its open pre-order lines,
+// oldest order first, and returns the number of units allocated.
+func (r *OrderRepository) AllocatePreorders(tx *gorm.DB, productID string) (int, error) {
+	return allocatePreorders(tx, productID)
+}
+
+// AllocatePreorders is the standalone form of OrderRepository.AllocatePreorders
+// for stock changes made outside an order transaction.
+func (r *ProductRepository) AllocatePreorders(productID string) (int, error) {
+	allocated := 0
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		var err error
+		allocated, err = allocatePreorders(tx, productID)
+		return err
+	})
+	return allocated, err
+}
+
+func allocatePreorders(tx *gorm.DB, productID string) (int, error) {
+	var product models.Product
+	if err := tx.Unscoped().Select("id", "stock_qty").First(&product, "id = ?", productID).Error; err != nil {
+		return 0, err
+	}
+	if product.StockQty <= 0 {
+		return 0, nil
+	}
+
+	var lines []models.OrderItem
+	err := tx.Model(&models.OrderItem{}).
+		Select("order_items.*").
+		Joins("JOIN orders ON orders.id = order_items.order_id").
+		Joins("JOIN order_status_refs ON order_status_refs.id = orders.status_id").
+		Where("order_items.product_id = ? AND order_items.preorder_qty > 0", productID).
+		Where("order_status_refs.code <> ?", models.OrderStatusCancelled).
+		Order("orders.created_at asc, order_items.id asc").
+		Find(&lines).Error
+	if err != nil {
+		return 0, err
+	}
+
+	available := product.StockQty
+	allocated := 0
+	for _, line := range lines {
+		if available == 0 {
+			break
+		}
+		take := min(line.PreorderQty, available)
+		if err := tx.Model(&models.OrderItem{}).Where("id = ?", line.ID).Update("preorder_qty", line.PreorderQty-take).Error; err != nil {
+			return allocated, err
+		}
+		available -= take
+		allocated += take
+	}
+	if allocated == 0 {
+		return 0, nil
+	}
+	return allocated, tx.Unscoped().Model(&models.Product{}).Where("id = ?", productID).Update("stock_qty", available).Error
+}
+
 func (r *UserRepository) FindByEmail(email string) (models.User, error) {
 	var user models.User
 	err := r.db.Preload("Role").Where("LOWER(email) = ?", strings.ToLower(strings.TrimSpace(email))).First(&user).Error
@@ -211,8 +446,46 @@ func (r *UserRepository) Create(user *models.User) error {
 	return r.db.Create(user).Error
 }
 
-func (r *UserRepository) SetBlocked(id string, blocked bool) error {
-	res := r.db.Model(&models.User{}).Where("id = ?", id).Update("is_blocked", blocked)
+func (r *UserRepository) UpdatePasswordHash(id, hash string) error {
+	return r.db.Model(&models.User{}).Where("id = ?", id).Update("password_hash", hash).Error
+}
+
+func (r *UserRepository) FindByID(id string) (models.User, error) {
+	var user models.User
+	err := r.db.Preload("Role").First(&user, "id = ?", id).Error
+	return user, err
+}
+
+// SetBlocked blocks or unblocks a user. The change is rolled back when check
+// refuses the resulting access.
+func (r *UserRepository) SetBlocked(id string, blocked bool, check func(*PermissionRepository) error) error {
+	return NewPermissionRepository(r.db).change(check, func(tx *gorm.DB) error {
+		res := tx.Model(&models.User{}).Where("id = ?", id).Update("is_blocked", blocked)
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+		return nil
+	})
+}
+
+func (r *UserRepository) SetRole(id string, roleID uint, check func(*PermissionRepository) error) error {
+	return NewPermissionRepository(r.db).change(check, func(tx *gorm.DB) error {
+		res := tx.Model(&models.User{}).Where("id = ?", id).Update("role_id", roleID)
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+		return nil
+	})
+}
+
+func (r *UserRepository) SetSSOLinkAllowed(id string, allowed bool) error {
+	res := r.db.Model(&models.User{}).Where("id = ?", id).Update("sso_link_allowed", allowed)
 	if res.Error != nil {
 		return res.Error
 	}
@@ -250,12 +523,47 @@ func (r *ForecastRepository) Categories() ([]models.Category, error) {
 	return categories, err
 }
 
+// CategoryPriceTrends returns the average relative price change per category
+// between each product's last recorded price at since and its current price.
+func (r *ForecastRepository) CategoryPriceTrends(since time.Time) (map[uint]float64, error) {
+	var rows []struct {
+		CategoryID uint
+		Trend      float64
+	}
+	baseline := r.db.Model(&models.PriceHistory{}).
+		Select("price_histories.id").
+		Where("price_histories.product_id = products.id AND price_histories.changed_at <= ?", since).
+		Order("price_histories.changed_at desc, price_histories.id desc").
+		Limit(1)
+	err := r.db.Model(&models.Product{}).
+		Select("products.category_id, AVG(CAST(products.price - baseline.price AS DOUBLE PRECISION) / baseline.price) AS trend").
+		Joins("JOIN price_histories baseline ON baseline.product_id = products.id").
+		Where("baseline.id = (?) AND baseline.price > 0", baseline).
+		Group("products.category_id").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	trends := make(map[uint]float64, len(rows))
+	for _, row := range rows {
+		trends[row.CategoryID] = row.Trend
+	}
+	return trends, nil
+}
+
 func (r *CategoryRepository) List() ([]models.Category, error) {
 	var items []models.Category
 	err := r.db.Order("name asc").Find(&items).Error
 	return items, err
 }
 
+func (r *CategoryRepository) GetByID(id uint) (models.Category, error) {
+	var item models.Category
+	err := r.db.First(&item, "id = ?", id).Error
+	return item, err
+}
+
 func (r *CategoryRepository) Create(item *models.Category) error {
 	return r.db.Create(item).Error
 }
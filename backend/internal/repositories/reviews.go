@@ -0,0 +1,82 @@
+package repositories
+
+import (
+	"math"
+	"strings"
+
+	"backend/internal/models"
+
+	"gorm.io/gorm"
+)
+
+type ReviewRepository struct{ db *gorm.DB }
+
+func NewReviewRepository(db *gorm.DB) *ReviewRepository { return &ReviewRepository{db: db} }
+
+func (r *ReviewRepository) Create(review *models.Review) error {
+	return r.db.Create(review).Error
+}
+
+func (r *ReviewRepository) GetByID(id string) (models.Review, error) {
+	var review models.Review
+	err := r.db.First(&review, "id = ?", id).Error
+	return review, err
+}
+
+func (r *ReviewRepository) ListByProduct(productID string, status models.ReviewStatus) ([]models.Review, error) {
+	var reviews []models.Review
+	err := r.db.Where("product_id = ? AND status = ?", productID, status).Order("created_at desc").Find(&reviews).Error
+	return reviews, err
+}
+
+func (r *ReviewRepository) ListByStatus(status models.ReviewStatus) ([]models.Review, error) {
+	var reviews []models.Review
+	query := r.db.Order("created_at asc")
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+	err := query.Find(&reviews).Error
+	return reviews, err
+}
+
+func (r *ReviewRepository) ExistsForUser(productID, userID string) (bool, error) {
+	var count int64
+	err := r.db.Model(&models.Review{}).Where("product_id = ? AND user_id = ?", productID, userID).Count(&count).Error
+	return count > 0, err
+}
+
+func (r *ReviewRepository) HasDeliveredOrder(email, productID string) (bool, error) {
+	var count int64
+	err := r.db.Model(&models.OrderItem{}).
+		Joins("JOIN orders ON orders.id = order_items.order_id").
+		Joins("JOIN customers ON customers.id = orders.customer_id").
+		Joins("JOIN order_status_refs ON order_status_refs.id = orders.status_id").
+		Where("order_items.product_id = ? AND LOWER(customers.email) = ? AND order_status_refs.code = ?", productID, strings.ToLower(strings.TrimSpace(email)), models.OrderStatusDelivered).
+		Count(&count).Error
+	return count > 0, err
+}
+
+// SaveModeration stores the moderation decision and refreshes the product's
+// rating and review counter from approved reviews in one transaction.
+func (r *ReviewRepository) SaveModeration(review *models.Review) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Save(review).Error; err != nil {
+			return err
+		}
+
+		var stats struct {
+			Count int64
+			Avg   float64
+		}
+		if err := tx.Model(&models.Review{}).
+			Select("COUNT(*) AS count, COALESCE(AVG(rating), 0) AS avg").
+			Where("product_id = ? AND status = ?", review.ProductID, models.ReviewStatusApproved).
+			Scan(&stats).Error; err != nil {
+			return err
+		}
+
+		rating := math.Round(stats.Avg*10) / 10
+		return tx.Model(&models.Product{}).Where("id = ?", review.ProductID).
+			Updates(map[string]any{"rating": rating, "reviews": stats.Count}).Error
+	})
+}
@@ -0,0 +1,98 @@
+package repositories
+
+import (
+	"time"
+
+	"backend/internal/models"
+
+	"gorm.io/gorm"
+)
+
+type SessionRepository struct{ db *gorm.DB }
+
+func NewSessionRepository(db *gorm.DB) *SessionRepository {
+	return &SessionRepository{db: db}
+}
+
+func (r *SessionRepository) Create(session *models.Session, token *models.RefreshToken) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Omit("User").Create(session).Error; err != nil {
+			return err
+		}
+		token.SessionID = session.ID
+		return tx.Create(token).Error
+	})
+}
+
+// Get returns a session with its user and role.
+func (r *SessionRepository) Get(id string) (models.Session, error) {
+	var session models.Session
+	err := r.db.Preload("User.Role").First(&session, "id = ?", id).Error
+	return session, err
+}
+
+func (r *SessionRepository) FindRefreshToken(hash string) (models.RefreshToken, error) {
+	var token models.RefreshToken
+	err := r.db.First(&token, "token_hash = ?", hash).Error
+	return token, err
+}
+
+// Rotate marks a refresh token as used and stores its successor. It reports
+// false when the token had already been used, e.g. by a concurrent request.
+func (r *SessionRepository) Rotate(tokenID uint, next *models.RefreshToken, at time.Time) (bool, error) {
+	rotated := false
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		res := tx.Model(&models.RefreshToken{}).Where("id = ? AND used_at IS NULL", tokenID).Update("used_at", at)
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return nil
+		}
+		if err := tx.Create(next).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&models.Session{}).Where("id = ?", next.SessionID).Update("last_used_at", at).Error; err != nil {
+			return err
+		}
+		rotated = true
+		return nil
+	})
+	return rotated, err
+}
+
+// AddRefreshToken stores another valid token for a session without using
+// up any other.
+func (r *SessionRepository) AddRefreshToken(token *models.RefreshToken, at time.Time) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(token).Error; err != nil {
+			return err
+		}
+		return tx.Model(&models.Session{}).Where("id = ?", token.SessionID).Update("last_used_at", at).Error
+	})
+}
+
+// HasUsedSuccessor reports whether a token issued in the same session after
+// the given one has been used.
+func (r *SessionRepository) HasUsedSuccessor(token models.RefreshToken) (bool, error) {
+	var count int64
+	err := r.db.Model(&models.RefreshToken{}).
+		Where("session_id = ? AND id > ? AND used_at IS NOT NULL", token.SessionID, token.ID).
+		Count(&count).Error
+	return count > 0, err
+}
+
+func (r *SessionRepository) Revoke(id, reason string, at time.Time) error {
+	return r.db.Model(&models.Session{}).
+		Where("id = ? AND revoked_at IS NULL", id).
+		Updates(map[string]any{"revoked_at": at, "revoke_reason": reason}).Error
+}
+
+// RevokeForUser ends every active session of a user and returns how many
+// were ended.
+func (r *SessionRepository) RevokeForUser(userID, reason string, at time.Time) (int64, error) {
+	res := r.db.Model(&models.Session{}).
+		Where("user_id = ? AND revoked_at IS NULL", userID).
+		Updates(map[string]any{"revoked_at": at, "revoke_reason": reason})
+	return res.RowsAffected, res.Error
+}
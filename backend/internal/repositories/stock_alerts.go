@@ -0,0 +1,57 @@
+package repositories
+
+import (
+	"backend/internal/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type StockAlertRepository struct{ db *gorm.DB }
+
+func NewStockAlertRepository(db *gorm.DB) *StockAlertRepository {
+	return &StockAlertRepository{db: db}
+}
+
+func (r *StockAlertRepository) List(status models.StockAlertStatus) ([]models.StockAlert, error) {
+	query := r.db.Preload("Product", func(db *gorm.DB) *gorm.DB { return db.Unscoped() })
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+	var items []models.StockAlert
+	if err := query.Order("created_at desc, id desc").Find(&items).Error; err != nil {
+		return nil, err
+	}
+	for i := range items {
+		items[i].Name = items[i].Product.Name
+		items[i].SKU = items[i].Product.SKU
+	}
+	return items, nil
+}
+
+// ListOpen returns the open alerts keyed by product id.
+func (r *StockAlertRepository) ListOpen() (map[string]models.StockAlert, error) {
+	var items []models.StockAlert
+	if err := r.db.Where("status = ?", models.StockAlertOpen).Find(&items).Error; err != nil {
+		return nil, err
+	}
+	result := make(map[string]models.StockAlert, len(items))
+	for _, item := range items {
+		result[item.ProductID] = item
+	}
+	return result, nil
+}
+
+// Create opens an alert. It returns false when the product already has an
+// open alert, e.g. because another checker raised it first.
+func (r *StockAlertRepository) Create(item *models.StockAlert) (bool, error) {
+	res := r.db.Clauses(clause.OnConflict{DoNothing: true}).Create(item)
+	return res.RowsAffected > 0, res.Error
+}
+
+// Update changes an open alert that is still at the given level. It returns
+// false when another checker changed or resolved it in the meantime.
+func (r *StockAlertRepository) Update(id uint, level models.StockAlertLevel, fields map[string]any) (bool, error) {
+	res := r.db.Model(&models.StockAlert{}).Where("id = ? AND status = ? AND level = ?", id, models.StockAlertOpen, level).Updates(fields)
+	return res.RowsAffected > 0, res.Error
+}
@@ -0,0 +1,131 @@
+package repositories
+
+import (
+	"fmt"
+	"time"
+
+	"backend/internal/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type StockCountRepository struct{ db *gorm.DB }
+
+func NewStockCountRepository(db *gorm.DB) *StockCountRepository {
+	return &StockCountRepository{db: db}
+}
+
+func preloadStockCount(query *gorm.DB) *gorm.DB {
+	return query.Preload("Lines", func(db *gorm.DB) *gorm.DB { return db.Order("id asc") }).
+		Preload("Lines.Product", func(db *gorm.DB) *gorm.DB { return db.Unscoped() })
+}
+
+func (r *StockCountRepository) List(status models.StockCountStatus) ([]models.StockCount, error) {
+	query := r.db.Scopes(preloadStockCount)
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+	var items []models.StockCount
+	if err := query.Order("created_at desc").Find(&items).Error; err != nil {
+		return nil, err
+	}
+	for i := range items {
+		items[i].SyncViewFields()
+	}
+	return items, nil
+}
+
+func (r *StockCountRepository) Get(id string) (models.StockCount, error) {
+	var item models.StockCount
+	if err := r.db.Scopes(preloadStockCount).First(&item, "id = ?", id).Error; err != nil {
+		return models.StockCount{}, err
+	}
+	item.SyncViewFields()
+	return item, nil
+}
+
+func (r *StockCountRepository) Create(item *models.StockCount) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Omit(clause.Associations).Create(item).Error; err != nil {
+			return err
+		}
+		for i := range item.Lines {
+			item.Lines[i].StockCountID = item.ID
+		}
+		if len(item.Lines) == 0 {
+			return nil
+		}
+		return tx.Omit(clause.Associations).Create(&item.Lines).Error
+	})
+}
+
+// SaveLines stores counted quantities and adds lines for products found
+// during the count.
+func (r *StockCountRepository) SaveLines(countID string, lines []models.StockCountLine, at time.Time) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		for _, line := range lines {
+			var err error
+			if line.ID == 0 {
+				line.StockCountID = countID
+				err = tx.Omit(clause.Associations).Create(&line).Error
+			} else {
+				err = tx.Model(&models.StockCountLine{}).Where("id = ?", line.ID).Update("counted_qty", line.CountedQty).Error
+			}
+			if err != nil {
+				return err
+			}
+		}
+		return tx.Model(&models.StockCount{}).Where("id = ?", countID).Update("updated_at", at).Error
+	})
+}
+
+func (r *StockCountRepository) UpdateStatus(id string, fields map[string]any) error {
+	return r.db.Model(&models.StockCount{}).Where("id = ?", id).Updates(fields).Error
+}
+
+// Post applies every counted variance to stock as a count adjustment and
+// writes the audit entries in the same transaction, so either the whole count
+// is booked or nothing is. The count is marked posted first; it returns false
+// and books nothing when the count is no longer open, e.g. because another
+// post got there first.
+func (r *StockCountRepository) Post(count models.StockCount, audits []models.AuditLog, actor string, at time.Time) (bool, error) {
+	posted := false
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		res := tx.Model(&models.StockCount{}).Where("id = ? AND status = ?", count.ID, models.StockCountOpen).Updates(map[string]any{
+			"status":     models.StockCountPosted,
+			"posted_by":  actor,
+			"posted_at":  at,
+			"updated_at": at,
+		})
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return nil
+		}
+		for _, line := range count.Lines {
+			if line.Variance == nil || *line.Variance == 0 {
+				continue
+			}
+			var product models.Product
+			if err := tx.Unscoped().Select("id", "stock_qty").First(&product, "id = ?", line.ProductID).Error; err != nil {
+				return err
+			}
+			if product.StockQty+*line.Variance < 0 {
+				return fmt.Errorf("adjusting %s by %d would make its stock negative", line.SKU, *line.Variance)
+			}
+			if err := moveStock(tx, line.ProductID, *line.Variance, models.StockMovementAdjustment, count.ID, actor, at); err != nil {
+				return err
+			}
+		}
+		if len(audits) > 0 {
+			if err := tx.Create(&audits).Error; err != nil {
+				return err
+			}
+		}
+		posted = true
+		return nil
+	})
+	return posted, err
+}
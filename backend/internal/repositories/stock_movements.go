@@ -0,0 +1,71 @@
+package repositories
+
+import (
+	"time"
+
+	"backend/internal/models"
+
+	"gorm.io/gorm"
+)
+
+type StockMovementRepository struct{ db *gorm.DB }
+
+func NewStockMovementRepository(db *gorm.DB) *StockMovementRepository {
+	return &StockMovementRepository{db: db}
+}
+
+// List returns movements newest first, optionally of one product or caused
+// by one document.
+func (r *StockMovementRepository) List(productID, reference string) ([]models.StockMovement, error) {
+	query := r.db.Model(&models.StockMovement{})
+	if productID != "" {
+		query = query.Where("product_id = ?", productID)
+	}
+	if reference != "" {
+		query = query.Where("reference = ?", reference)
+	}
+	var items []models.StockMovement
+	err := query.Order("created_at desc, id desc").Find(&items).Error
+	return items, err
+}
+
+// SetStock brings a product's stock to qty and books the difference as a
+// movement, so the ledger keeps explaining the stock. It returns the
+// difference, which is zero when nothing changed.
+func (r *ProductRepository) SetStock(productID string, qty int, reason models.StockMovementReason, reference, actor string, at time.Time) (int, error) {
+	var delta int
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		var product models.Product
+		if err := tx.Select("stock_qty").First(&product, "id = ?", productID).Error; err != nil {
+			return err
+		}
+		delta = qty - product.StockQty
+		if delta == 0 {
+			return nil
+		}
+		return moveStock(tx, productID, delta, reason, reference, actor, at)
+	})
+	return delta, err
+}
+
+// moveStock changes a product's stock by delta and records why. Incoming
+// stock is allocated to open pre-orders first.
+func moveStock(tx *gorm.DB, productID string, delta int, reason models.StockMovementReason, reference, actor string, at time.Time) error {
+	res := tx.Unscoped().Model(&models.Product{}).Where("id = ?", productID).Update("stock_qty", gorm.Expr("stock_qty + ?", delta))
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	movement := models.StockMovement{ProductID: productID, Delta: delta, Reason: reason, Reference: reference, CreatedBy: actor, CreatedAt: at}
+	if err := tx.Create(&movement).Error; err != nil {
+		return err
+	}
+	if delta > 0 {
+		if _, err := allocatePreorders(tx, productID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,55 @@
+package repositories
+
+import (
+	"time"
+
+	"backend/internal/models"
+
+	"gorm.io/gorm"
+)
+
+type StockSubscriptionRepository struct{ db *gorm.DB }
+
+func NewStockSubscriptionRepository(db *gorm.DB) *StockSubscriptionRepository {
+	return &StockSubscriptionRepository{db: db}
+}
+
+func (r *StockSubscriptionRepository) ListByProduct(productID string) ([]models.StockSubscription, error) {
+	var items []models.StockSubscription
+	err := r.db.Where("product_id = ?", productID).Order("created_at asc, id asc").Find(&items).Error
+	return items, err
+}
+
+// FindPending returns the email's subscription to the product that has not
+// been notified yet.
+func (r *StockSubscriptionRepository) FindPending(productID, email string) (models.StockSubscription, error) {
+	var item models.StockSubscription
+	err := r.db.Where("product_id = ? AND email = ? AND notified_at IS NULL", productID, email).First(&item).Error
+	return item, err
+}
+
+// ListPending returns every subscription still waiting for a notification.
+func (r *StockSubscriptionRepository) ListPending() ([]models.StockSubscription, error) {
+	var items []models.StockSubscription
+	err := r.db.Where("notified_at IS NULL").Order("product_id asc, created_at asc, id asc").Find(&items).Error
+	return items, err
+}
+
+func (r *StockSubscriptionRepository) Create(item *models.StockSubscription) error {
+	return r.db.Create(item).Error
+}
+
+func (r *StockSubscriptionRepository) DeletePending(productID, email string) error {
+	res := r.db.Where("product_id = ? AND email = ? AND notified_at IS NULL", productID, email).Delete(&models.StockSubscription{})
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+func (r *StockSubscriptionRepository) MarkNotified(id uint, at time.Time) error {
+	return r.db.Model(&models.StockSubscription{}).Where("id = ?", id).Update("notified_at", at).Error
+}
@@ -0,0 +1,80 @@
+package repositories
+
+import (
+	"backend/internal/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type TranslationRepository struct{ db *gorm.DB }
+
+func NewTranslationRepository(db *gorm.DB) *TranslationRepository {
+	return &TranslationRepository{db: db}
+}
+
+func (r *TranslationRepository) ListProduct(productID string) ([]models.ProductTranslation, error) {
+	var items []models.ProductTranslation
+	err := r.db.Where("product_id = ?", productID).Order("locale asc").Find(&items).Error
+	return items, err
+}
+
+func (r *TranslationRepository) FindProducts(productIDs, locales []string) ([]models.ProductTranslation, error) {
+	var items []models.ProductTranslation
+	if len(productIDs) == 0 || len(locales) == 0 {
+		return items, nil
+	}
+	err := r.db.Where("product_id IN ? AND locale IN ?", productIDs, locales).Find(&items).Error
+	return items, err
+}
+
+func (r *TranslationRepository) SaveProduct(item *models.ProductTranslation) error {
+	return r.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "product_id"}, {Name: "locale"}},
+		DoUpdates: clause.AssignmentColumns([]string{"name", "description", "material", "updated_by", "updated_at"}),
+	}).Create(item).Error
+}
+
+func (r *TranslationRepository) DeleteProduct(productID, locale string) error {
+	res := r.db.Where("product_id = ? AND locale = ?", productID, locale).Delete(&models.ProductTranslation{})
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+func (r *TranslationRepository) ListCategory(categoryID uint) ([]models.CategoryTranslation, error) {
+	var items []models.CategoryTranslation
+	err := r.db.Where("category_id = ?", categoryID).Order("locale asc").Find(&items).Error
+	return items, err
+}
+
+func (r *TranslationRepository) FindCategories(categoryIDs []uint, locales []string) ([]models.CategoryTranslation, error) {
+	var items []models.CategoryTranslation
+	if len(categoryIDs) == 0 || len(locales) == 0 {
+		return items, nil
+	}
+	err := r.db.Where("category_id IN ? AND locale IN ?", categoryIDs, locales).Find(&items).Error
+	return items, err
+}
+
+func (r *TranslationRepository) SaveCategory(item *models.CategoryTranslation) error {
+	return r.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "category_id"}, {Name: "locale"}},
+		DoUpdates: clause.AssignmentColumns([]string{"name", "updated_by", "updated_at"}),
+	}).Create(item).Error
+}
+
+func (r *TranslationRepository) DeleteCategory(categoryID uint, locale string) error {
+	res := r.db.Where("category_id = ? AND locale = ?", categoryID, locale).Delete(&models.CategoryTranslation{})
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
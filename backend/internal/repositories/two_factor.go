@@ -0,0 +1,138 @@
+package repositories
+
+import (
+	"time"
+
+	"backend/internal/models"
+
+	"gorm.io/gorm"
+)
+
+type TwoFactorRepository struct{ db *gorm.DB }
+
+func NewTwoFactorRepository(db *gorm.DB) *TwoFactorRepository {
+	return &TwoFactorRepository{db: db}
+}
+
+func (r *TwoFactorRepository) GetTOTP(userID string) (models.UserTOTP, error) {
+	var totp models.UserTOTP
+	err := r.db.First(&totp, "user_id = ?", userID).Error
+	return totp, err
+}
+
+// IsEnabled reports whether the user has a confirmed TOTP secret.
+func (r *TwoFactorRepository) IsEnabled(userID string) (bool, error) {
+	var count int64
+	err := r.db.Model(&models.UserTOTP{}).Where("user_id = ? AND confirmed_at IS NOT NULL", userID).Count(&count).Error
+	return count > 0, err
+}
+
+// SavePending stores a new unconfirmed secret, replacing an earlier one.
+func (r *TwoFactorRepository) SavePending(totp *models.UserTOTP) error {
+	return r.db.Save(totp).Error
+}
+
+// Confirm enables a pending secret and stores the first set of recovery
+// codes.
+func (r *TwoFactorRepository) Confirm(userID string, step int64, at time.Time, codes []models.RecoveryCode) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		res := tx.Model(&models.UserTOTP{}).Where("user_id = ? AND confirmed_at IS NULL", userID).
+			Updates(map[string]any{"confirmed_at": at, "last_used_step": step})
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+		return replaceRecoveryCodes(tx, userID, codes)
+	})
+}
+
+// UseStep records an accepted code and reports false when a code of the
+// same or a later step was already accepted.
+func (r *TwoFactorRepository) UseStep(userID string, step int64) (bool, error) {
+	res := r.db.Model(&models.UserTOTP{}).Where("user_id = ? AND last_used_step < ?", userID, step).Update("last_used_step", step)
+	return res.RowsAffected == 1, res.Error
+}
+
+func (r *TwoFactorRepository) Delete(userID string) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("user_id = ?", userID).Delete(&models.RecoveryCode{}).Error; err != nil {
+			return err
+		}
+		return tx.Where("user_id = ?", userID).Delete(&models.UserTOTP{}).Error
+	})
+}
+
+func (r *TwoFactorRepository) ReplaceRecoveryCodes(userID string, codes []models.RecoveryCode) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		return replaceRecoveryCodes(tx, userID, codes)
+	})
+}
+
+func replaceRecoveryCodes(tx *gorm.DB, userID string, codes []models.RecoveryCode) error {
+	if err := tx.Where("user_id = ?", userID).Delete(&models.RecoveryCode{}).Error; err != nil {
+		return err
+	}
+	if len(codes) == 0 {
+		return nil
+	}
+	return tx.Create(&codes).Error
+}
+
+// UseRecoveryCode spends an unused recovery code and reports whether one
+// matched.
+func (r *TwoFactorRepository) UseRecoveryCode(userID, hash string, at time.Time) (bool, error) {
+	res := r.db.Model(&models.RecoveryCode{}).Where("user_id = ? AND code_hash = ? AND used_at IS NULL", userID, hash).Update("used_at", at)
+	return res.RowsAffected > 0, res.Error
+}
+
+func (r *TwoFactorRepository) CountRecoveryCodes(userID string) (int64, error) {
+	var count int64
+	err := r.db.Model(&models.RecoveryCode{}).Where("user_id = ? AND used_at IS NULL", userID).Count(&count).Error
+	return count, err
+}
+
+func (r *TwoFactorRepository) CreateChallenge(challenge *models.LoginChallenge) error {
+	return r.db.Create(challenge).Error
+}
+
+func (r *TwoFactorRepository) FindChallenge(hash string) (models.LoginChallenge, error) {
+	var challenge models.LoginChallenge
+	err := r.db.First(&challenge, "token_hash = ?", hash).Error
+	return challenge, err
+}
+
+// CountChallengeAttempt adds a code attempt to an open challenge and reports
+// false once the challenge is used or out of attempts.
+func (r *TwoFactorRepository) CountChallengeAttempt(id uint, maxAttempts int) (bool, error) {
+	res := r.db.Model(&models.LoginChallenge{}).
+		Where("id = ? AND used_at IS NULL AND attempts < ?", id, maxAttempts).
+		Update("attempts", gorm.Expr("attempts + 1"))
+	return res.RowsAffected == 1, res.Error
+}
+
+func (r *TwoFactorRepository) UseChallenge(id uint, at time.Time) (bool, error) {
+	res := r.db.Model(&models.LoginChallenge{}).Where("id = ? AND used_at IS NULL", id).Update("used_at", at)
+	return res.RowsAffected == 1, res.Error
+}
+
+func (r *TwoFactorRepository) RequiredRoles() ([]models.RoleName, error) {
+	var names []models.RoleName
+	err := r.db.Model(&models.Role{}).Where("require_two_factor = ?", true).Order("name asc").Pluck("name", &names).Error
+	return names, err
+}
+
+// SetRequiredRoles requires two-factor authentication for exactly the given
+// roles.
+func (r *TwoFactorRepository) SetRequiredRoles(names []models.RoleName) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&models.Role{}).Where("1 = 1").Update("require_two_factor", false).Error; err != nil {
+			return err
+		}
+		if len(names) == 0 {
+			return nil
+		}
+		return tx.Model(&models.Role{}).Where("name IN ?", names).Update("require_two_factor", true).Error
+	})
+}
@@ -0,0 +1,429 @@
+package routes
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"testing"
+	"time"
+
+	"backend/internal/models"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+const (
+	fakeOIDCClientID     = "furniture-admin"
+	fakeOIDCClientSecret = "client-secret"
+	fakeOIDCRedirectURL  = "http://localhost:3000/sso/callback"
+)
+
+// fakeOIDCAccount is who signs in at the fake provider next.
+type fakeOIDCAccount struct {
+	Subject       string
+	Email         string
+	EmailVerified bool
+	Name          string
+	Groups        []string
+	// Nonce replaces the nonce of the authorization request when set.
+	Nonce string
+}
+
+type fakeOIDCGrant struct {
+	account     fakeOIDCAccount
+	nonce       string
+	challenge   string
+	redirectURI string
+}
+
+// fakeOIDCProvider is an in-process OpenID Connect provider that signs ID
+// tokens with its own RSA key and enforces PKCE at the token endpoint.
+type fakeOIDCProvider struct {
+	t      *testing.T
+	server *httptest.Server
+	key    *rsa.PrivateKey
+
+	mu      sync.Mutex
+	account fakeOIDCAccount
+	grants  map[string]fakeOIDCGrant
+}
+
+func newFakeOIDCProvider(t *testing.T) *fakeOIDCProvider {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("generate provider key: %v", err)
+	}
+	p := &fakeOIDCProvider{t: t, key: key, grants: map[string]fakeOIDCGrant{}}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", p.discovery)
+	mux.HandleFunc("/authorize", p.authorize)
+	mux.HandleFunc("/token", p.token)
+	mux.HandleFunc("/jwks", p.jwks)
+	p.server = httptest.NewServer(mux)
+	t.Cleanup(p.server.Close)
+	return p
+}
+
+func (p *fakeOIDCProvider) signInAs(account fakeOIDCAccount) {
+	p.mu.Lock()
+	p.account = account
+	p.mu.Unlock()
+}
+
+func (p *fakeOIDCProvider) discovery(w http.ResponseWriter, _ *http.Request) {
+	writeFakeJSON(w, http.StatusOK, map[string]any{
+		"issuer":                           p.server.URL,
+		"authorization_endpoint":           p.server.URL + "/authorize",
+		"token_endpoint":                   p.server.URL + "/token",
+		"jwks_uri":                         p.server.URL + "/jwks",
+		"code_challenge_methods_supported": []string{"S256"},
+	})
+}
+
+func (p *fakeOIDCProvider) authorize(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	if query.Get("response_type") != "code" || query.Get("client_id") != fakeOIDCClientID ||
+		query.Get("code_challenge_method") != "S256" || query.Get("code_challenge") == "" || query.Get("nonce") == "" {
+		http.Error(w, "invalid authorization request", http.StatusBadRequest)
+		return
+	}
+	code := randomFakeValue()
+	p.mu.Lock()
+	p.grants[code] = fakeOIDCGrant{account: p.account, nonce: query.Get("nonce"), challenge: query.Get("code_challenge"), redirectURI: query.Get("redirect_uri")}
+	p.mu.Unlock()
+	http.Redirect(w, r, query.Get("redirect_uri")+"?"+url.Values{"code": {code}, "state": {query.Get("state")}}.Encode(), http.StatusFound)
+}
+
+func (p *fakeOIDCProvider) token(w http.ResponseWriter, r *http.Request) {
+	clientID, secret, ok := r.BasicAuth()
+	if !ok || clientID != fakeOIDCClientID || secret != fakeOIDCClientSecret {
+		writeFakeJSON(w, http.StatusUnauthorized, map[string]string{"error": "invalid_client"})
+		return
+	}
+	_ = r.ParseForm()
+	p.mu.Lock()
+	grant, found := p.grants[r.PostForm.Get("code")]
+	delete(p.grants, r.PostForm.Get("code"))
+	p.mu.Unlock()
+	verifier := sha256.Sum256([]byte(r.PostForm.Get("code_verifier")))
+	if !found || r.PostForm.Get("grant_type") != "authorization_code" || r.PostForm.Get("redirect_uri") != grant.redirectURI ||
+		base64.RawURLEncoding.EncodeToString(verifier[:]) != grant.challenge {
+		writeFakeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid_grant"})
+		return
+	}
+
+	nonce := grant.nonce
+	if grant.account.Nonce != "" {
+		nonce = grant.account.Nonce
+	}
+	now := time.Now().Unix()
+	idToken := p.sign(map[string]any{
+		"iss":            p.server.URL,
+		"sub":            grant.account.Subject,
+		"aud":            fakeOIDCClientID,
+		"exp":            now + 300,
+		"iat":            now,
+		"nonce":          nonce,
+		"email":          grant.account.Email,
+		"email_verified": grant.account.EmailVerified,
+		"name":           grant.account.Name,
+		"groups":         grant.account.Groups,
+	})
+	writeFakeJSON(w, http.StatusOK, map[string]any{"access_token": randomFakeValue(), "token_type": "Bearer", "expires_in": 300, "id_token": idToken})
+}
+
+func (p *fakeOIDCProvider) jwks(w http.ResponseWriter, _ *http.Request) {
+	writeFakeJSON(w, http.StatusOK, map[string]any{"keys": []map[string]string{{
+		"kty": "RSA",
+		"kid": "fake-1",
+		"use": "sig",
+		"alg": "RS256",
+		"n":   base64.RawURLEncoding.EncodeToString(p.key.N.Bytes()),
+		"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(p.key.E)).Bytes()),
+	}}})
+}
+
+func (p *fakeOIDCProvider) sign(claims map[string]any) string {
+	header, _ := json.Marshal(map[string]string{"alg": "RS256", "typ": "JWT", "kid": "fake-1"})
+	payload, _ := json.Marshal(claims)
+	input := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(payload)
+	digest := sha256.Sum256([]byte(input))
+	signature, err := rsa.SignPKCS1v15(rand.Reader, p.key, crypto.SHA256, digest[:])
+	if err != nil {
+		p.t.Errorf("sign id token: %v", err)
+	}
+	return input + "." + base64.RawURLEncoding.EncodeToString(signature)
+}
+
+func writeFakeJSON(w http.ResponseWriter, status int, body any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(body)
+}
+
+func randomFakeValue() string {
+	buf := make([]byte, 16)
+	_, _ = rand.Read(buf)
+	return base64.RawURLEncoding.EncodeToString(buf)
+}
+
+func setupOIDCTestApp(t *testing.T) (*fiber.App, *gorm.DB, *fakeOIDCProvider) {
+	t.Helper()
+	provider := newFakeOIDCProvider(t)
+	t.Setenv("OIDC_ISSUER", provider.server.URL)
+	t.Setenv("OIDC_CLIENT_ID", fakeOIDCClientID)
+	t.Setenv("OIDC_CLIENT_SECRET", fakeOIDCClientSecret)
+	t.Setenv("OIDC_REDIRECT_URL", fakeOIDCRedirectURL)
+	t.Setenv("OIDC_ROLE_MAP", "store-managers=Manager, warehouse=Warehouse")
+	app, db := setupTestApp(t)
+	return app, db, provider
+}
+
+// startSSO follows the redirect to the fake provider and returns the code
+// and state it sends the browser back with. The state is also what the
+// login set as the browser's state cookie.
+func startSSO(t *testing.T, app *fiber.App) (string, string) {
+	t.Helper()
+	resp, err := app.Test(httptest.NewRequest(http.MethodGet, "/api/auth/oidc/login", nil))
+	if err != nil {
+		t.Fatalf("start sso: %v", err)
+	}
+	if resp.StatusCode != http.StatusFound {
+		t.Fatalf("expected redirect to the identity provider, got %d", resp.StatusCode)
+	}
+	var cookie *http.Cookie
+	for _, set := range resp.Cookies() {
+		if set.Name == "oidc_state" {
+			cookie = set
+		}
+	}
+	if cookie == nil || cookie.Value == "" || !cookie.HttpOnly || cookie.SameSite != http.SameSiteLaxMode {
+		t.Fatalf("expected an HttpOnly SameSite=Lax state cookie, got %+v", cookie)
+	}
+	client := &http.Client{CheckRedirect: func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse }}
+	authorized, err := client.Get(resp.Header.Get("Location"))
+	if err != nil {
+		t.Fatalf("authorize: %v", err)
+	}
+	defer authorized.Body.Close()
+	if authorized.StatusCode != http.StatusFound {
+		t.Fatalf("expected the provider to redirect back, got %d", authorized.StatusCode)
+	}
+	callback, err := url.Parse(authorized.Header.Get("Location"))
+	if err != nil || callback.Scheme+"://"+callback.Host+callback.Path != fakeOIDCRedirectURL {
+		t.Fatalf("expected redirect to %s, got %s", fakeOIDCRedirectURL, authorized.Header.Get("Location"))
+	}
+	if callback.Query().Get("state") != cookie.Value {
+		t.Fatalf("expected the state cookie to match the state sent to the provider")
+	}
+	return callback.Query().Get("code"), callback.Query().Get("state")
+}
+
+// completeSSO posts the callback from the browser that started the sign-in.
+func completeSSO(t *testing.T, app *fiber.App, code, state string) (*http.Response, models.LoginResponse) {
+	t.Helper()
+	return completeSSOWithCookie(t, app, code, state, "oidc_state="+state)
+}
+
+func completeSSOWithCookie(t *testing.T, app *fiber.App, code, state, cookie string) (*http.Response, models.LoginResponse) {
+	t.Helper()
+	headers := map[string]string{}
+	if cookie != "" {
+		headers["Cookie"] = cookie
+	}
+	resp := performJSONRequest(t, app, http.MethodPost, "/api/auth/oidc/callback", map[string]string{"code": code, "state": state}, headers)
+	var login models.LoginResponse
+	if resp.StatusCode == http.StatusOK {
+		if err := json.NewDecoder(resp.Body).Decode(&login); err != nil {
+			t.Fatalf("decode sso login: %v", err)
+		}
+	}
+	return resp, login
+}
+
+func TestOIDCSingleSignOnProvisionsAndSyncsStaff(t *testing.T) {
+	app, db, provider := setupOIDCTestApp(t)
+
+	status := performJSONRequest(t, app, http.MethodGet, "/api/auth/oidc", nil, nil)
+	var enabled models.OIDCStatus
+	_ = json.NewDecoder(status.Body).Decode(&enabled)
+	if !enabled.Enabled {
+		t.Fatalf("expected single sign-on to be enabled")
+	}
+
+	// A first login creates the user with the role of their group.
+	provider.signInAs(fakeOIDCAccount{Subject: "idp-1", Email: "Nina@Maison.co", EmailVerified: true, Name: "Nina Petrova", Groups: []string{"staff", "store-managers"}})
+	code, state := startSSO(t, app)
+	resp, login := completeSSO(t, app, code, state)
+	if resp.StatusCode != http.StatusOK || login.Token == "" || login.User.Role != models.RoleManager || login.User.Email != "nina@maison.co" {
+		t.Fatalf("expected a provisioned manager session, got %d %+v", resp.StatusCode, login)
+	}
+	me := performJSONRequest(t, app, http.MethodGet, "/api/orders", nil, map[string]string{"Authorization": "Bearer " + login.Token})
+	if me.StatusCode != http.StatusOK {
+		t.Fatalf("expected the sso session to authorize manager routes, got %d", me.StatusCode)
+	}
+	var identity models.UserIdentity
+	if err := db.First(&identity, "issuer = ? AND subject = ?", provider.server.URL, "idp-1").Error; err != nil || identity.UserID != login.User.ID {
+		t.Fatalf("expected the identity to be linked to the new user: %+v %v", identity, err)
+	}
+	var provisioned int64
+	db.Model(&models.AuditLog{}).Where("action = ? AND user = ?", "User Provisioned", "nina@maison.co").Count(&provisioned)
+	if provisioned != 1 {
+		t.Fatalf("expected provisioning to be audited once, got %d", provisioned)
+	}
+
+	// The state is single-use.
+	if replay, _ := completeSSO(t, app, code, state); replay.StatusCode != http.StatusUnauthorized {
+		t.Fatalf("expected a replayed callback to be refused, got %d", replay.StatusCode)
+	}
+	if forged, _ := completeSSO(t, app, code, "not-a-state"); forged.StatusCode != http.StatusUnauthorized {
+		t.Fatalf("expected an unknown state to be refused, got %d", forged.StatusCode)
+	}
+
+	// The callback completes only in the browser that started the sign-in,
+	// and a refused one leaves the attempt open for it.
+	code, state = startSSO(t, app)
+	if resp, _ := completeSSOWithCookie(t, app, code, state, ""); resp.StatusCode != http.StatusUnauthorized {
+		t.Fatalf("expected a callback without the state cookie to be refused, got %d", resp.StatusCode)
+	}
+	_, otherState := startSSO(t, app)
+	if resp, _ := completeSSOWithCookie(t, app, code, state, "oidc_state="+otherState); resp.StatusCode != http.StatusUnauthorized {
+		t.Fatalf("expected a callback with another browser's state cookie to be refused, got %d", resp.StatusCode)
+	}
+	if resp, _ := completeSSO(t, app, code, state); resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected the starting browser to complete the sign-in, got %d", resp.StatusCode)
+	}
+	preflight := performJSONRequest(t, app, http.MethodOptions, "/api/auth/oidc/callback", nil, map[string]string{
+		"Origin": "http://localhost:3000", "Access-Control-Request-Method": http.MethodPost,
+	})
+	if preflight.Header.Get("Access-Control-Allow-Origin") != "http://localhost:3000" || preflight.Header.Get("Access-Control-Allow-Credentials") != "true" {
+		t.Fatalf("expected the callback to admit credentials from the redirect origin, got %v", preflight.Header)
+	}
+
+	// The role follows the groups on every login, and a change ends the
+	// sessions opened with the old role.
+	managerToken := login.Token
+	provider.signInAs(fakeOIDCAccount{Subject: "idp-1", Email: "nina@maison.co", EmailVerified: true, Groups: []string{"warehouse"}})
+	code, state = startSSO(t, app)
+	if resp, login = completeSSO(t, app, code, state); resp.StatusCode != http.StatusOK || login.User.Role != models.RoleWarehouse || login.User.ID != identity.UserID {
+		t.Fatalf("expected the same user synced to Warehouse, got %d %+v", resp.StatusCode, login)
+	}
+	if resp := performJSONRequest(t, app, http.MethodGet, "/api/orders", nil, map[string]string{"Authorization": "Bearer " + managerToken}); resp.StatusCode != http.StatusUnauthorized {
+		t.Fatalf("expected the manager session to end with the role change, got %d", resp.StatusCode)
+	}
+
+	// Without a mapped group there is no access, and no Client role either.
+	provider.signInAs(fakeOIDCAccount{Subject: "idp-2", Email: "guest@maison.co", EmailVerified: true, Groups: []string{"customers"}})
+	code, state = startSSO(t, app)
+	if resp, _ = completeSSO(t, app, code, state); resp.StatusCode != http.StatusUnauthorized {
+		t.Fatalf("expected an unmapped user to be refused, got %d", resp.StatusCode)
+	}
+	var guests int64
+	db.Model(&models.User{}).Where("email = ?", "guest@maison.co").Count(&guests)
+	if guests != 0 {
+		t.Fatalf("expected no account for an unmapped user")
+	}
+
+	// Existing accounts are linked only by a verified address.
+	provider.signInAs(fakeOIDCAccount{Subject: "idp-3", Email: "warehouse@maison.co", EmailVerified: false, Groups: []string{"warehouse"}})
+	code, state = startSSO(t, app)
+	if resp, _ = completeSSO(t, app, code, state); resp.StatusCode != http.StatusUnauthorized {
+		t.Fatalf("expected an unverified address to be refused, got %d", resp.StatusCode)
+	}
+	provider.signInAs(fakeOIDCAccount{Subject: "idp-3", Email: "warehouse@maison.co", EmailVerified: true, Groups: []string{"warehouse"}})
+	code, state = startSSO(t, app)
+	resp, login = completeSSO(t, app, code, state)
+	var local models.User
+	db.First(&local, "email = ?", "warehouse@maison.co")
+	if resp.StatusCode != http.StatusOK || login.User.ID != local.ID {
+		t.Fatalf("expected the existing warehouse account to be linked, got %d %+v", resp.StatusCode, login)
+	}
+	// Nor is an account whose owner never verified the address.
+	db.Model(&models.User{}).Where("email = ?", "executive@maison.co").Update("email_verified_at", nil)
+	provider.signInAs(fakeOIDCAccount{Subject: "idp-4", Email: "executive@maison.co", EmailVerified: true, Groups: []string{"warehouse"}})
+	code, state = startSSO(t, app)
+	if resp, _ = completeSSO(t, app, code, state); resp.StatusCode != http.StatusUnauthorized {
+		t.Fatalf("expected an account with an unverified email not to be linked, got %d", resp.StatusCode)
+	}
+
+	// An ID token minted for another sign-in is refused.
+	provider.signInAs(fakeOIDCAccount{Subject: "idp-1", Email: "nina@maison.co", EmailVerified: true, Groups: []string{"warehouse"}, Nonce: "replayed-nonce"})
+	code, state = startSSO(t, app)
+	if resp, _ = completeSSO(t, app, code, state); resp.StatusCode != http.StatusUnauthorized {
+		t.Fatalf("expected a mismatched nonce to be refused, got %d", resp.StatusCode)
+	}
+}
+
+func TestOIDCLinksAdministratorsAndClientsOnlyWhenTheyOptIn(t *testing.T) {
+	app, db, provider := setupOIDCTestApp(t)
+
+	// An administrator is not linked until they allow it.
+	provider.signInAs(fakeOIDCAccount{Subject: "idp-admin", Email: "admin@maison.co", EmailVerified: true, Groups: []string{"store-managers"}})
+	code, state := startSSO(t, app)
+	if resp, _ := completeSSO(t, app, code, state); resp.StatusCode != http.StatusUnauthorized {
+		t.Fatalf("expected the administrator account not to be linked, got %d", resp.StatusCode)
+	}
+	adminHeaders := map[string]string{"Authorization": "Bearer " + loginAndGetToken(t, app, "admin@maison.co", "admin123")}
+	resp := performJSONRequest(t, app, http.MethodPut, "/api/auth/oidc/link", map[string]bool{"allowed": true}, adminHeaders)
+	var admin models.User
+	if err := json.NewDecoder(resp.Body).Decode(&admin); err != nil || resp.StatusCode != http.StatusOK || !admin.SSOLinkAllowed {
+		t.Fatalf("expected the administrator to allow linking, got %d %+v %v", resp.StatusCode, admin, err)
+	}
+	// Even then the groups may not demote the last administrator.
+	code, state = startSSO(t, app)
+	if resp, _ := completeSSO(t, app, code, state); resp.StatusCode != http.StatusConflict {
+		t.Fatalf("expected demoting the last administrator to conflict, got %d", resp.StatusCode)
+	}
+	if err := db.Preload("Role").First(&admin, "email = ?", "admin@maison.co").Error; err != nil || admin.Role.Name != models.RoleAdmin {
+		t.Fatalf("expected the administrator to keep the role, got %+v %v", admin, err)
+	}
+	if resp := performJSONRequest(t, app, http.MethodGet, "/api/roles", nil, adminHeaders); resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected the administrator session to survive the refused change, got %d", resp.StatusCode)
+	}
+
+	// Nor is a client, who would otherwise become staff.
+	performJSONRequest(t, app, http.MethodPost, "/api/auth/signup", map[string]string{
+		"email": "sso-client@example.com", "password": "client123", "name": "SSO Client",
+	}, nil)
+	markEmailVerified(t, db, "sso-client@example.com")
+	provider.signInAs(fakeOIDCAccount{Subject: "idp-client", Email: "sso-client@example.com", EmailVerified: true, Groups: []string{"store-managers"}})
+	code, state = startSSO(t, app)
+	if resp, _ := completeSSO(t, app, code, state); resp.StatusCode != http.StatusUnauthorized {
+		t.Fatalf("expected the client account not to be linked, got %d", resp.StatusCode)
+	}
+	var identities int64
+	db.Model(&models.UserIdentity{}).Where("subject = ?", "idp-client").Count(&identities)
+	if identities != 0 {
+		t.Fatalf("expected no identity for the refused client")
+	}
+	clientHeaders := map[string]string{"Authorization": "Bearer " + loginAndGetToken(t, app, "sso-client@example.com", "client123")}
+	if resp := performJSONRequest(t, app, http.MethodPut, "/api/auth/oidc/link", map[string]bool{"allowed": true}, clientHeaders); resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected the client to allow linking, got %d", resp.StatusCode)
+	}
+	code, state = startSSO(t, app)
+	resp, login := completeSSO(t, app, code, state)
+	if resp.StatusCode != http.StatusOK || login.User.Role != models.RoleManager {
+		t.Fatalf("expected the opted-in client to be linked and synced, got %d %+v", resp.StatusCode, login)
+	}
+	if resp := performJSONRequest(t, app, http.MethodGet, "/api/auth/me", nil, clientHeaders); resp.StatusCode != http.StatusUnauthorized {
+		t.Fatalf("expected the client session to end with the role change, got %d", resp.StatusCode)
+	}
+}
+
+func TestOIDCSingleSignOnIsOffByDefault(t *testing.T) {
+	app, _ := setupTestApp(t)
+	resp := performJSONRequest(t, app, http.MethodGet, "/api/auth/oidc/login", nil, nil)
+	if resp.StatusCode != http.StatusNotFound {
+		t.Fatalf("expected sso login to be unavailable without an issuer, got %d", resp.StatusCode)
+	}
+}
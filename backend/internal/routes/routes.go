@@ -1,9 +1,12 @@
 package routes
 
 import (
+	"backend/internal/config"
 	"backend/internal/handlers"
 	"backend/internal/middleware"
 	"backend/internal/models"
+	"backend/internal/repositories"
+	"backend/internal/security"
 	"backend/internal/services"
 
 	"github.com/gofiber/fiber/v2"
@@ -12,11 +15,19 @@ import (
 	"gorm.io/gorm"
 )
 
-func Register(app *fiber.App, db *gorm.DB, appSecret string) {
+func Register(app *fiber.App, db *gorm.DB, keys *security.KeySet, cfg config.Config) {
+	oidcHandler := handlers.NewOIDCHandler(db, keys, cfg)
+	oidcOrigin := oidcHandler.Origin()
 	app.Use(cors.New(cors.Config{
-		AllowOrigins: "*",
-		AllowHeaders: "Origin, Content-Type, Accept, Authorization",
-		AllowMethods: "GET, POST, PUT, PATCH, DELETE, OPTIONS",
+		// The single sign-on callback carries its state cookie, so it gets a
+		// policy of its own that admits credentials from one origin.
+		Next: func(c *fiber.Ctx) bool {
+			return oidcOrigin != "" && c.Path() == "/api/auth/oidc/callback"
+		},
+		AllowOrigins:  "*",
+		AllowHeaders:  "Origin, Content-Type, Accept, Authorization",
+		AllowMethods:  "GET, POST, PUT, PATCH, DELETE, OPTIONS",
+		ExposeHeaders: "Retry-After, X-RateLimit-Limit, X-RateLimit-Remaining, X-RateLimit-Reset",
 	}))
 
 	app.Get("/swagger/*", swagger.HandlerDefault)
@@ -24,48 +35,203 @@ func Register(app *fiber.App, db *gorm.DB, appSecret string) {
 	api := app.Group("/api")
 	api.Get("/health", handlers.Health)
 
-	authHandler := handlers.NewAuthHandler(db, appSecret)
+	// Per-IP request limits for the unauthenticated surface. Login lockouts
+	// count in the same store.
+	limits := services.NewCounterStore(repositories.NewRateCounterRepository(db), cfg.RateLimitStore)
+	authLimit := middleware.RateLimit(limits, "auth", middleware.RateLimitRule(cfg.RateLimitAuth))
+	orderLimit := middleware.RateLimit(limits, "orders", middleware.RateLimitRule(cfg.RateLimitOrders))
+	catalogLimit := middleware.RateLimit(limits, "catalog", middleware.RateLimitRule(cfg.RateLimitCatalog))
+	api.Use("/auth", authLimit)
+
+	authHandler := handlers.NewAuthHandler(db, keys, cfg, limits)
+	app.Get("/.well-known/jwks.json", authHandler.JWKS)
+	api.Get("/auth/jwks.json", authHandler.JWKS)
 	api.Post("/auth/login", authHandler.Login)
 	api.Post("/auth/token", authHandler.Token)
 	api.Post("/auth/signup", authHandler.Signup)
-
-	productHandler := handlers.NewProductHandler(db)
-	api.Get("/products", productHandler.List)
-	api.Get("/products/:id", productHandler.Get)
-
-	orderHandler := handlers.NewOrderHandler(db)
-	api.Post("/orders", orderHandler.Create)
+	api.Post("/auth/refresh", authHandler.Refresh)
+	api.Post("/auth/password/forgot", authHandler.ForgotPassword)
+	api.Post("/auth/password/reset", authHandler.ResetPassword)
+	api.Post("/auth/verify-email", authHandler.VerifyEmail)
+
+	api.Get("/auth/oidc", oidcHandler.Status)
+	api.Get("/auth/oidc/login", oidcHandler.Login)
+	if oidcOrigin != "" {
+		api.Use("/auth/oidc/callback", cors.New(cors.Config{
+			AllowOrigins:     oidcOrigin,
+			AllowHeaders:     "Origin, Content-Type, Accept",
+			AllowMethods:     "POST, OPTIONS",
+			AllowCredentials: true,
+		}))
+	}
+	api.Post("/auth/oidc/callback", oidcHandler.Callback)
+
+	// Staff routes are authorized against the role and permission tables, so
+	// access can be changed without a redeploy.
+	permissions := services.NewPermissionService(repositories.NewPermissionRepository(db), cfg.PermissionCacheTTL)
+	can := func(action, resource string) fiber.Handler {
+		permissions.Enforce(action, resource)
+		return middleware.RequirePermission(permissions, action, resource)
+	}
+	apiKeyHandler := handlers.NewAPIKeyHandler(db, cfg, permissions)
+	requireAuth := middleware.RequireAuth(keys, authHandler.Checker(), apiKeyHandler.Authenticator())
+	requireUser := middleware.RequireUser()
+	requireVerified := middleware.RequireVerifiedEmail(authHandler.Verifier())
+
+	optionalAuth := middleware.OptionalAuth(keys, authHandler.Checker(), nil)
+	optionalAuthOrKey := middleware.OptionalAuth(keys, authHandler.Checker(), apiKeyHandler.Authenticator())
+	api.Post("/auth/logout", optionalAuth, authHandler.Logout)
+	api.Post("/auth/login/2fa", authHandler.LoginTwoFactor)
+
+	twoFactorHandler := handlers.NewTwoFactorHandler(db, keys, cfg)
+	api.Post("/auth/2fa/setup", optionalAuth, twoFactorHandler.Setup)
+	api.Post("/auth/2fa/enable", optionalAuth, twoFactorHandler.Enable)
+
+	// The staff view of the catalog takes read:stock, which no route
+	// enforces on its own.
+	permissions.Enforce("read", "stock")
+	productHandler := handlers.NewProductHandler(db, cfg, permissions)
+	api.Get("/products", catalogLimit, optionalAuthOrKey, productHandler.List)
+	api.Get("/products/export", requireAuth, can("export", "product"), productHandler.Export)
+	api.Get("/products/archived", requireAuth, can("delete", "product"), productHandler.ListArchived)
+	api.Get("/products/:id", catalogLimit, optionalAuthOrKey, productHandler.Get)
+
+	reviewHandler := handlers.NewReviewHandler(db)
+	api.Get("/products/:id/reviews", catalogLimit, reviewHandler.ListByProduct)
+
+	recommendationHandler := handlers.NewRecommendationHandler(db, cfg)
+	api.Get("/products/:id/related", catalogLimit, recommendationHandler.Related)
+
+	orderHandler := handlers.NewOrderHandler(db, cfg)
+	api.Post("/orders", orderLimit, orderHandler.Create)
 
 	refHandler := handlers.NewReferenceHandler(db)
-	api.Get("/categories", refHandler.ListCategories)
-
-	authenticated := api.Group("", middleware.RequireAuth(appSecret))
-	authenticated.Get("/auth/me", authHandler.Me)
-	authenticated.Post("/auth/register", middleware.RequireRoles(models.RoleAdmin), authHandler.Register)
-
-	authenticated.Post("/products", middleware.RequireRoles(models.RoleAdmin, models.RoleManager), productHandler.Create)
-	authenticated.Put("/products/:id", middleware.RequireRoles(models.RoleAdmin, models.RoleManager), productHandler.Update)
-	authenticated.Delete("/products/:id", middleware.RequireRoles(models.RoleAdmin), productHandler.Delete)
-
-	authenticated.Get("/orders", middleware.RequireRoles(models.RoleAdmin, models.RoleManager, models.RoleWarehouse, models.RoleExecutive), orderHandler.List)
-	authenticated.Get("/orders/my", middleware.RequireRoles(models.RoleClient), orderHandler.ListMine)
-	authenticated.Put("/orders/:id", middleware.RequireRoles(models.RoleAdmin, models.RoleManager, models.RoleWarehouse), orderHandler.Update)
-	authenticated.Patch("/orders/:id/status", middleware.RequireRoles(models.RoleAdmin, models.RoleManager, models.RoleWarehouse), orderHandler.UpdateStatus)
+	api.Get("/categories", catalogLimit, refHandler.ListCategories)
+
+	currencyHandler := handlers.NewCurrencyHandler(db, cfg)
+	api.Get("/exchange-rates", catalogLimit, currencyHandler.List)
+
+	authenticated := api.Group("", requireAuth)
+	authenticated.Get("/auth/me", requireUser, authHandler.Me)
+	authenticated.Post("/auth/verify-email/resend", requireUser, authHandler.ResendVerification)
+	authenticated.Put("/auth/oidc/link", requireUser, authHandler.AllowSSOLink)
+	authenticated.Get("/auth/2fa", requireUser, twoFactorHandler.Status)
+	authenticated.Post("/auth/2fa/disable", requireUser, twoFactorHandler.Disable)
+	authenticated.Post("/auth/2fa/recovery-codes", requireUser, twoFactorHandler.RecoveryCodes)
+	authenticated.Get("/auth/2fa/policy", can("read", "two_factor_policy"), twoFactorHandler.Policy)
+	authenticated.Put("/auth/2fa/policy", can("update", "two_factor_policy"), twoFactorHandler.SetPolicy)
+	authenticated.Post("/auth/register", can("create", "user"), authHandler.Register)
+
+	authenticated.Post("/products", can("create", "product"), productHandler.Create)
+	authenticated.Post("/products/import", can("create", "product"), productHandler.Import)
+	authenticated.Get("/products/import/:jobId", can("create", "product"), productHandler.ImportStatus)
+	authenticated.Put("/products/:id", can("update", "product"), productHandler.Update)
+	authenticated.Delete("/products/:id", can("delete", "product"), productHandler.Delete)
+	authenticated.Post("/products/:id/restore", can("delete", "product"), productHandler.Restore)
+
+	priceHandler := handlers.NewPriceHandler(db)
+	authenticated.Get("/products/:id/prices", can("read", "price"), priceHandler.List)
+	authenticated.Post("/products/:id/prices/schedule", can("update", "price"), priceHandler.Schedule)
+	authenticated.Delete("/products/:id/prices/schedule/:scheduleId", can("update", "price"), priceHandler.CancelSchedule)
+
+	authenticated.Post("/products/:id/related", can("update", "product"), recommendationHandler.AddLink)
+	authenticated.Delete("/products/:id/related/:relatedId", can("update", "product"), recommendationHandler.RemoveLink)
+	authenticated.Post("/recommendations/recompute", can("update", "product"), recommendationHandler.Recompute)
+
+	translationHandler := handlers.NewTranslationHandler(db)
+	authenticated.Get("/products/:id/translations", can("read", "translation"), translationHandler.ListProduct)
+	authenticated.Put("/products/:id/translations/:locale", can("update", "translation"), translationHandler.SetProduct)
+	authenticated.Delete("/products/:id/translations/:locale", can("update", "translation"), translationHandler.DeleteProduct)
+
+	stockSubscriptionHandler := handlers.NewStockSubscriptionHandler(db, cfg)
+	authenticated.Get("/products/:id/subscriptions", can("read", "stock_subscription"), stockSubscriptionHandler.List)
+	authenticated.Post("/products/:id/subscription", can("subscribe", "product"), stockSubscriptionHandler.Subscribe)
+	authenticated.Delete("/products/:id/subscription", can("subscribe", "product"), stockSubscriptionHandler.Unsubscribe)
+
+	authenticated.Post("/products/:id/reviews", can("create", "review"), requireVerified, reviewHandler.Create)
+	authenticated.Get("/reviews", can("moderate", "review"), reviewHandler.List)
+	authenticated.Patch("/reviews/:id/moderation", can("moderate", "review"), reviewHandler.Moderate)
+
+	authenticated.Get("/orders", can("read", "order"), orderHandler.List)
+	authenticated.Get("/orders/my", can("read", "own_order"), requireVerified, orderHandler.ListMine)
+	authenticated.Put("/orders/:id", can("update", "order"), orderHandler.Update)
+	authenticated.Patch("/orders/:id/status", can("status", "order"), orderHandler.UpdateStatus)
+
+	barcodeHandler := handlers.NewBarcodeHandler(db, cfg)
+	authenticated.Get("/products/:id/barcode", can("read", "barcode"), barcodeHandler.ProductBarcode)
+	authenticated.Get("/orders/:id/qr", can("read", "barcode"), barcodeHandler.OrderQR)
+	authenticated.Get("/lookup/:code", can("read", "barcode"), barcodeHandler.Lookup)
+
+	fulfillmentHandler := handlers.NewFulfillmentHandler(db, cfg)
+	authenticated.Get("/warehouse/pick-list", can("fulfil", "order"), fulfillmentHandler.PickList)
+	authenticated.Get("/orders/:id/fulfillment", can("fulfil", "order"), fulfillmentHandler.Get)
+	authenticated.Post("/orders/:id/pick", can("fulfil", "order"), fulfillmentHandler.Pick)
+	authenticated.Post("/orders/:id/pack", can("fulfil", "order"), fulfillmentHandler.Pack)
+	authenticated.Get("/orders/:id/packing-slip", can("fulfil", "order"), fulfillmentHandler.PackingSlip)
 
 	auditLogHandler := handlers.NewAuditLogHandler(db)
-	authenticated.Get("/audit-logs", middleware.RequireRoles(models.RoleAdmin, models.RoleManager, models.RoleWarehouse, models.RoleExecutive), auditLogHandler.List)
-	authenticated.Post("/audit-logs", middleware.RequireRoles(models.RoleAdmin, models.RoleManager), auditLogHandler.Create)
-
-	userHandler := handlers.NewUserHandler(db, appSecret)
-	authenticated.Get("/users", middleware.RequireRoles(models.RoleAdmin), userHandler.List)
-	authenticated.Post("/users", middleware.RequireRoles(models.RoleAdmin), userHandler.Create)
-	authenticated.Patch("/users/:id/block", middleware.RequireRoles(models.RoleAdmin), userHandler.SetBlocked)
-
-	authenticated.Post("/categories", middleware.RequireRoles(models.RoleAdmin, models.RoleManager), refHandler.CreateCategory)
-	authenticated.Get("/customers", middleware.RequireRoles(models.RoleAdmin, models.RoleManager), refHandler.ListCustomers)
-	authenticated.Post("/customers", middleware.RequireRoles(models.RoleAdmin, models.RoleManager), refHandler.CreateCustomer)
+	authenticated.Get("/audit-logs", can("read", "audit_log"), auditLogHandler.List)
+	authenticated.Post("/audit-logs", can("create", "audit_log"), auditLogHandler.Create)
+
+	userHandler := handlers.NewUserHandler(db, keys, cfg)
+	authenticated.Get("/users", can("read", "user"), userHandler.List)
+	authenticated.Post("/users", can("create", "user"), userHandler.Create)
+	authenticated.Patch("/users/:id/block", can("block", "user"), userHandler.SetBlocked)
+	authenticated.Delete("/users/:id/2fa", can("reset_two_factor", "user"), twoFactorHandler.Reset)
+
+	roleHandler := handlers.NewRoleHandler(db, permissions)
+	authenticated.Get("/roles", can("read", "role"), roleHandler.List)
+	authenticated.Post("/roles", can("create", "role"), roleHandler.Create)
+	authenticated.Delete("/roles/:id", can("delete", "role"), roleHandler.Delete)
+	authenticated.Get("/roles/:id/permissions", can("read", "role"), roleHandler.Permissions)
+	authenticated.Post("/roles/:id/permissions", can(models.ManageAccessAction, models.ManageAccessResource), roleHandler.Grant)
+	authenticated.Delete("/roles/:id/permissions/:permissionId", can(models.ManageAccessAction, models.ManageAccessResource), roleHandler.Revoke)
+	authenticated.Get("/permissions", can("read", "permission"), roleHandler.ListPermissions)
+	authenticated.Post("/permissions", can("create", "permission"), roleHandler.CreatePermission)
+	authenticated.Delete("/permissions/:id", can("delete", "permission"), roleHandler.DeletePermission)
+
+	authenticated.Get("/api-keys", can("read", "api_key"), apiKeyHandler.List)
+	authenticated.Post("/api-keys", requireUser, can("create", "api_key"), apiKeyHandler.Create)
+	authenticated.Delete("/api-keys/:id", can("revoke", "api_key"), apiKeyHandler.Revoke)
+
+	authenticated.Post("/categories", can("create", "category"), refHandler.CreateCategory)
+	authenticated.Get("/categories/:id/translations", can("read", "translation"), translationHandler.ListCategory)
+	authenticated.Put("/categories/:id/translations/:locale", can("update", "translation"), translationHandler.SetCategory)
+	authenticated.Delete("/categories/:id/translations/:locale", can("update", "translation"), translationHandler.DeleteCategory)
+	authenticated.Get("/customers", can("read", "customer"), refHandler.ListCustomers)
+	authenticated.Post("/customers", can("create", "customer"), refHandler.CreateCustomer)
+
+	authenticated.Put("/exchange-rates", can("update", "exchange_rate"), currencyHandler.Set)
+	authenticated.Post("/exchange-rates/import", can("update", "exchange_rate"), currencyHandler.Import)
+	authenticated.Delete("/exchange-rates/:currency", can("update", "exchange_rate"), currencyHandler.Delete)
+
+	procurementHandler := handlers.NewProcurementHandler(db, services.DefaultModelPath())
+	authenticated.Get("/suppliers", can("read", "supplier"), procurementHandler.ListSuppliers)
+	authenticated.Post("/suppliers", can("create", "supplier"), procurementHandler.CreateSupplier)
+	authenticated.Put("/suppliers/:id", can("update", "supplier"), procurementHandler.UpdateSupplier)
+	authenticated.Get("/purchase-orders", can("read", "purchase_order"), procurementHandler.List)
+	authenticated.Post("/purchase-orders", can("create", "purchase_order"), procurementHandler.Create)
+	authenticated.Post("/purchase-orders/from-forecast", can("create", "purchase_order"), procurementHandler.CreateFromForecast)
+	authenticated.Get("/purchase-orders/:id", can("read", "purchase_order"), procurementHandler.Get)
+	authenticated.Put("/purchase-orders/:id", can("update", "purchase_order"), procurementHandler.Update)
+	authenticated.Post("/purchase-orders/:id/send", can("update", "purchase_order"), procurementHandler.Send)
+	authenticated.Post("/purchase-orders/:id/cancel", can("update", "purchase_order"), procurementHandler.Cancel)
+	authenticated.Post("/purchase-orders/:id/receive", can("receive", "purchase_order"), procurementHandler.Receive)
+	authenticated.Get("/stock-movements", can("read", "stock_movement"), procurementHandler.ListStockMovements)
+
+	stockCountHandler := handlers.NewStockCountHandler(db)
+	authenticated.Get("/stock-counts", can("read", "stock_count"), stockCountHandler.List)
+	authenticated.Post("/stock-counts", can("update", "stock_count"), stockCountHandler.Start)
+	authenticated.Get("/stock-counts/:id", can("read", "stock_count"), stockCountHandler.Get)
+	authenticated.Put("/stock-counts/:id/lines", can("update", "stock_count"), stockCountHandler.Record)
+	authenticated.Post("/stock-counts/:id/post", can("update", "stock_count"), stockCountHandler.Post)
+	authenticated.Post("/stock-counts/:id/cancel", can("update", "stock_count"), stockCountHandler.Cancel)
+
+	inventoryHandler := handlers.NewInventoryHandler(db, services.DefaultModelPath())
+	authenticated.Get("/inventory/alerts", can("read", "stock_alert"), inventoryHandler.Alerts)
+	authenticated.Post("/inventory/reorder-points/from-forecast", can("update", "reorder_point"), inventoryHandler.ReorderPointsFromForecast)
 
 	forecastHandler := handlers.NewForecastHandler(db, services.DefaultModelPath())
-	authenticated.Post("/forecast/train", middleware.RequireRoles(models.RoleAdmin, models.RoleExecutive), forecastHandler.Train)
-	authenticated.Get("/forecast", middleware.RequireRoles(models.RoleAdmin, models.RoleManager, models.RoleExecutive), forecastHandler.Forecast)
+	authenticated.Post("/forecast/train", can("train", "forecast"), forecastHandler.Train)
+	authenticated.Get("/forecast", can("read", "forecast"), forecastHandler.Forecast)
 }
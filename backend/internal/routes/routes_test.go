@@ -2,23 +2,38 @@ package routes
 
 import (
 	"bytes"
+	"encoding/base64"
 	"encoding/json"
 	"fmt"
 	"io"
+	"mime/multipart"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
 	"testing"
+	"time"
 
 	"backend/internal/config"
 	"backend/internal/database"
 	"backend/internal/models"
+	"backend/internal/repositories"
+	"backend/internal/security"
+	"backend/internal/services"
 
 	"github.com/gofiber/fiber/v2"
 	"gorm.io/driver/sqlite"
 	"gorm.io/gorm"
 )
 
-const testSecret = "test-secret"
+const (
+	testSecret   = "test-secret"
+	testIssuer   = "furniture-store"
+	testAudience = "furniture-store-api"
+)
 
 func setupTestApp(t *testing.T) (*fiber.App, *gorm.DB) {
 	t.Helper()
@@ -37,8 +52,16 @@ func setupTestApp(t *testing.T) (*fiber.App, *gorm.DB) {
 	t.Setenv("AI_MODEL_PATH", modelPath)
 	t.Setenv("APP_SECRET", testSecret)
 
+	keys, err := security.NewKeySet(security.KeySetOptions{Issuer: testIssuer, Audience: testAudience, Secret: testSecret})
+	if err != nil {
+		t.Fatalf("jwt keys: %v", err)
+	}
+	cfg, err := config.Load()
+	if err != nil {
+		t.Fatalf("load config: %v", err)
+	}
 	app := fiber.New()
-	Register(app, db, testSecret)
+	Register(app, db, keys, cfg)
 	return app, db
 }
 
@@ -143,252 +166,2514 @@ func TestTokenEndpointReturnsOAuthPayload(t *testing.T) {
 	}
 }
 
-func TestProductCreateRequiresAuthentication(t *testing.T) {
+func TestLoginIssuesStandardJWT(t *testing.T) {
 	app, _ := setupTestApp(t)
-	managerToken := loginAndGetToken(t, app, "manager@maison.co", "manager123")
+	token := loginAndGetToken(t, app, "manager@maison.co", "manager123")
 
-	payload := map[string]any{
-		"id":          "p-admin",
-		"name":        "Admin Product",
-		"category":    "Гостиная",
-		"price":       120,
-		"image":       "/images/admin.jpg",
-		"description": "Created by admin",
-		"dimensions":  "10x10x10",
-		"material":    "Oak",
-		"stock":       3,
-		"sku":         "SKU-ADMIN",
-		"featured":    false,
-		"rating":      4.0,
-		"reviews":     0,
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		t.Fatalf("expected header.payload.signature, got %s", token)
+	}
+	var header map[string]string
+	data, _ := base64.RawURLEncoding.DecodeString(parts[0])
+	if err := json.Unmarshal(data, &header); err != nil {
+		t.Fatalf("decode header: %v", err)
+	}
+	if header["alg"] != "HS256" || header["typ"] != "JWT" || header["kid"] == "" {
+		t.Fatalf("unexpected JWT header %+v", header)
+	}
+	var claims map[string]any
+	data, _ = base64.RawURLEncoding.DecodeString(parts[1])
+	if err := json.Unmarshal(data, &claims); err != nil {
+		t.Fatalf("decode claims: %v", err)
+	}
+	for _, name := range []string{"iss", "aud", "sub", "iat", "nbf", "exp", "jti"} {
+		if _, ok := claims[name]; !ok {
+			t.Fatalf("expected registered claim %s, got %+v", name, claims)
+		}
+	}
+	if claims["iss"] != testIssuer || claims["aud"] != testAudience {
+		t.Fatalf("unexpected issuer or audience %+v", claims)
 	}
 
-	resp := performJSONRequest(t, app, http.MethodPost, "/api/products", payload, nil)
+	tampered := parts[0] + "." + parts[1] + "." + strings.Repeat("A", len(parts[2]))
+	resp := performJSONRequest(t, app, http.MethodGet, "/api/orders", nil, map[string]string{"Authorization": "Bearer " + tampered})
 	if resp.StatusCode != http.StatusUnauthorized {
-		t.Fatalf("expected 401 without token, got %d", resp.StatusCode)
+		t.Fatalf("expected a tampered token to be rejected, got %d", resp.StatusCode)
 	}
 
-	resp = performJSONRequest(t, app, http.MethodPost, "/api/products", payload, map[string]string{
-		"Authorization": "Bearer " + managerToken,
-	})
-	if resp.StatusCode != http.StatusCreated {
-		t.Fatalf("expected 201 for authenticated manager, got %d", resp.StatusCode)
+	resp = performJSONRequest(t, app, http.MethodGet, "/.well-known/jwks.json", nil, nil)
+	if resp.StatusCode != http.StatusNotFound {
+		t.Fatalf("expected no JWKS without asymmetric keys, got %d", resp.StatusCode)
 	}
 }
 
-func TestCreateOrderRollsBackOnInsufficientStock(t *testing.T) {
+func TestRefreshTokensRotateAndSessionsRevoke(t *testing.T) {
 	app, db := setupTestApp(t)
-	productID := mustFindProductIDBySKU(t, db, "SOF-HVNS-BEI")
 
-	resp := performJSONRequest(t, app, http.MethodPost, "/api/orders", map[string]any{
-		"customer": "John Doe",
-		"email":    "john@example.com",
-		"address":  "Main Street",
-		"items": []map[string]any{
-			{
-				"product":  map[string]any{"id": productID},
-				"quantity": 100,
-			},
-		},
-	}, nil)
+	login := func(email, password string) models.LoginResponse {
+		t.Helper()
+		resp := performJSONRequest(t, app, http.MethodPost, "/api/auth/login", map[string]string{"email": email, "password": password}, nil)
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("expected successful login, got %d", resp.StatusCode)
+		}
+		var payload models.LoginResponse
+		if err := json.NewDecoder(resp.Body).Decode(&payload); err != nil {
+			t.Fatalf("decode login payload: %v", err)
+		}
+		if payload.RefreshToken == "" || payload.ExpiresIn != 15*60 {
+			t.Fatalf("expected refresh token and 15 minute access token, got %+v", payload)
+		}
+		return payload
+	}
+	refresh := func(token string) *http.Response {
+		return performJSONRequest(t, app, http.MethodPost, "/api/auth/refresh", map[string]string{"refresh_token": token}, nil)
+	}
+	bearer := func(token string) map[string]string {
+		return map[string]string{"Authorization": "Bearer " + token}
+	}
 
-	if resp.StatusCode != http.StatusBadRequest {
-		t.Fatalf("expected 400 for insufficient stock, got %d", resp.StatusCode)
+	first := login("manager@maison.co", "manager123")
+	resp := refresh(first.RefreshToken)
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected refresh to succeed, got %d", resp.StatusCode)
+	}
+	var second models.LoginResponse
+	if err := json.NewDecoder(resp.Body).Decode(&second); err != nil {
+		t.Fatalf("decode refresh payload: %v", err)
+	}
+	if second.RefreshToken == "" || second.RefreshToken == first.RefreshToken || second.User.Email != "manager@maison.co" {
+		t.Fatalf("expected a rotated refresh token, got %+v", second)
+	}
+	if resp := performJSONRequest(t, app, http.MethodGet, "/api/orders", nil, bearer(second.Token)); resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected refreshed access token to work, got %d", resp.StatusCode)
 	}
 
-	var product models.Product
-	if err := db.First(&product, "id = ?", productID).Error; err != nil {
-		t.Fatalf("fetch product: %v", err)
+	// A second tab refreshing with the token that was just rotated gets its
+	// own pair instead of signing the user out.
+	resp = refresh(first.RefreshToken)
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected a refresh within the reuse grace to succeed, got %d", resp.StatusCode)
 	}
-	if product.StockQty != 12 {
-		t.Fatalf("expected stock rollback to preserve 12 items, got %d", product.StockQty)
+	var sibling models.LoginResponse
+	if err := json.NewDecoder(resp.Body).Decode(&sibling); err != nil {
+		t.Fatalf("decode refresh payload: %v", err)
+	}
+	if sibling.RefreshToken == "" || sibling.RefreshToken == second.RefreshToken {
+		t.Fatalf("expected a separate refresh token for the second tab, got %+v", sibling)
+	}
+	resp = refresh(second.RefreshToken)
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected the first tab's token to keep working, got %d", resp.StatusCode)
+	}
+	var rotated models.LoginResponse
+	if err := json.NewDecoder(resp.Body).Decode(&rotated); err != nil {
+		t.Fatalf("decode refresh payload: %v", err)
+	}
+
+	// Once a later token was used, replaying the first one revokes the whole
+	// session.
+	if resp := refresh(first.RefreshToken); resp.StatusCode != http.StatusUnauthorized {
+		t.Fatalf("expected reused refresh token to be rejected, got %d", resp.StatusCode)
+	}
+	if resp := refresh(rotated.RefreshToken); resp.StatusCode != http.StatusUnauthorized {
+		t.Fatalf("expected session to be revoked after reuse, got %d", resp.StatusCode)
+	}
+	if resp := performJSONRequest(t, app, http.MethodGet, "/api/orders", nil, bearer(rotated.Token)); resp.StatusCode != http.StatusUnauthorized {
+		t.Fatalf("expected access token of revoked session to be rejected, got %d", resp.StatusCode)
+	}
+	var reuseAudits int64
+	db.Model(&models.AuditLog{}).Where("action = ? AND user = ?", "Refresh Token Reuse", "manager@maison.co").Count(&reuseAudits)
+	if reuseAudits != 1 {
+		t.Fatalf("expected one reuse audit entry, got %d", reuseAudits)
+	}
+
+	// Logout with the bearer token ends the session.
+	third := login("manager@maison.co", "manager123")
+	if resp := performJSONRequest(t, app, http.MethodPost, "/api/auth/logout", nil, bearer(third.Token)); resp.StatusCode != http.StatusNoContent {
+		t.Fatalf("expected logout to succeed, got %d", resp.StatusCode)
+	}
+	if resp := performJSONRequest(t, app, http.MethodGet, "/api/auth/me", nil, bearer(third.Token)); resp.StatusCode != http.StatusUnauthorized {
+		t.Fatalf("expected access token to stop working after logout, got %d", resp.StatusCode)
+	}
+	if resp := refresh(third.RefreshToken); resp.StatusCode != http.StatusUnauthorized {
+		t.Fatalf("expected refresh after logout to fail, got %d", resp.StatusCode)
+	}
+
+	// Blocking a user ends their sessions immediately.
+	warehouse := login("warehouse@maison.co", "warehouse123")
+	adminToken := loginAndGetToken(t, app, "admin@maison.co", "admin123")
+	userID := mustFindUserIDByEmail(t, db, "warehouse@maison.co")
+	resp = performJSONRequest(t, app, http.MethodPatch, "/api/users/"+userID+"/block", map[string]any{"is_blocked": true}, bearer(adminToken))
+	if resp.StatusCode != http.StatusNoContent {
+		t.Fatalf("expected block to succeed, got %d", resp.StatusCode)
+	}
+	if resp := performJSONRequest(t, app, http.MethodGet, "/api/orders", nil, bearer(warehouse.Token)); resp.StatusCode != http.StatusUnauthorized {
+		t.Fatalf("expected blocked user's token to be rejected, got %d", resp.StatusCode)
+	}
+	if resp := refresh(warehouse.RefreshToken); resp.StatusCode != http.StatusUnauthorized {
+		t.Fatalf("expected blocked user's refresh token to be rejected, got %d", resp.StatusCode)
 	}
 }
 
-func TestManagerCanUpdateOrderStatus(t *testing.T) {
+func TestTwoFactorLoginEnrollmentAndPolicy(t *testing.T) {
 	app, db := setupTestApp(t)
 	managerToken := loginAndGetToken(t, app, "manager@maison.co", "manager123")
-	orderID := mustFindOrderIDByAddress(t, db, "г. Екатеринбург, ул. Малышева, д. 18, кв. 24")
-
-	resp := performJSONRequest(t, app, http.MethodPatch, "/api/orders/"+orderID+"/status", map[string]any{
-		"status": "shipped",
-	}, map[string]string{
-		"Authorization": "Bearer " + managerToken,
-	})
+	adminToken := loginAndGetToken(t, app, "admin@maison.co", "admin123")
+	bearer := func(token string) map[string]string {
+		return map[string]string{"Authorization": "Bearer " + token}
+	}
+	decode := func(resp *http.Response, target any) {
+		t.Helper()
+		if err := json.NewDecoder(resp.Body).Decode(target); err != nil {
+			t.Fatalf("decode response: %v", err)
+		}
+	}
+	code := func(secret string, offset int64) string {
+		value, err := security.TOTPCode(secret, security.TOTPStep(time.Now())+offset)
+		if err != nil {
+			t.Fatalf("totp code: %v", err)
+		}
+		return value
+	}
+	login := func(email, password string) models.LoginResponse {
+		t.Helper()
+		resp := performJSONRequest(t, app, http.MethodPost, "/api/auth/login", map[string]string{"email": email, "password": password}, nil)
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("expected password step to succeed, got %d", resp.StatusCode)
+		}
+		var payload models.LoginResponse
+		decode(resp, &payload)
+		return payload
+	}
+	secondStep := func(challenge, value string) *http.Response {
+		return performJSONRequest(t, app, http.MethodPost, "/api/auth/login/2fa", map[string]string{"challenge": challenge, "code": value}, nil)
+	}
 
+	// Enrollment: the secret only protects logins once a code confirms it.
+	resp := performJSONRequest(t, app, http.MethodPost, "/api/auth/2fa/setup", nil, bearer(managerToken))
 	if resp.StatusCode != http.StatusOK {
-		t.Fatalf("expected 200 for manager order update, got %d", resp.StatusCode)
+		t.Fatalf("expected setup to succeed, got %d", resp.StatusCode)
 	}
-
-	var order models.Order
-	if err := db.First(&order, "id = ?", orderID).Error; err != nil {
-		t.Fatalf("fetch order: %v", err)
+	var setup models.TOTPSetup
+	decode(resp, &setup)
+	if setup.Secret == "" || !strings.HasPrefix(setup.OTPAuthURI, "otpauth://totp/") || !strings.HasPrefix(setup.QRCode, "data:image/png;base64,") {
+		t.Fatalf("unexpected setup %+v", setup)
+	}
+	if payload := login("manager@maison.co", "manager123"); payload.Token == "" {
+		t.Fatalf("expected an unconfirmed secret not to change login, got %+v", payload)
+	}
+	if resp := performJSONRequest(t, app, http.MethodPost, "/api/auth/2fa/enable", map[string]string{"code": "000000"}, bearer(managerToken)); resp.StatusCode != http.StatusUnauthorized {
+		t.Fatalf("expected a wrong code to be rejected, got %d", resp.StatusCode)
+	}
+	enrollCode := code(setup.Secret, 0)
+	resp = performJSONRequest(t, app, http.MethodPost, "/api/auth/2fa/enable", map[string]string{"code": enrollCode}, bearer(managerToken))
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected enable to succeed, got %d", resp.StatusCode)
+	}
+	var enabled models.TwoFactorEnabled
+	decode(resp, &enabled)
+	if len(enabled.RecoveryCodes) != 10 {
+		t.Fatalf("expected 10 recovery codes, got %v", enabled.RecoveryCodes)
 	}
 
-	var status models.OrderStatusRef
-	if err := db.First(&status, "id = ?", order.StatusID).Error; err != nil {
-		t.Fatalf("fetch order status: %v", err)
+	// The password alone now only earns a challenge.
+	challenge := login("manager@maison.co", "manager123")
+	if challenge.Token != "" || challenge.RefreshToken != "" || challenge.TwoFactor != models.TwoFactorRequired || challenge.Challenge == "" {
+		t.Fatalf("expected a two-factor challenge without tokens, got %+v", challenge)
 	}
-	if status.Code != string(models.OrderStatusShipped) {
-		t.Fatalf("expected order status shipped, got %s", status.Code)
+	if resp := secondStep(challenge.Challenge, enrollCode); resp.StatusCode != http.StatusUnauthorized {
+		t.Fatalf("expected the enrollment code not to be accepted twice, got %d", resp.StatusCode)
+	}
+	resp = secondStep(challenge.Challenge, code(setup.Secret, 1))
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected a fresh code to complete login, got %d", resp.StatusCode)
+	}
+	var completed models.LoginResponse
+	decode(resp, &completed)
+	if completed.Token == "" || completed.RefreshToken == "" {
+		t.Fatalf("expected tokens after the second step, got %+v", completed)
+	}
+	if resp := secondStep(challenge.Challenge, enabled.RecoveryCodes[0]); resp.StatusCode != http.StatusUnauthorized {
+		t.Fatalf("expected a completed challenge not to be reusable, got %d", resp.StatusCode)
 	}
-}
 
-func TestForecastRequiresAuthentication(t *testing.T) {
-	app, _ := setupTestApp(t)
-	managerToken := loginAndGetToken(t, app, "manager@maison.co", "manager123")
+	// Recovery codes work once; challenges allow a few attempts only.
+	challenge = login("manager@maison.co", "manager123")
+	if resp := secondStep(challenge.Challenge, strings.ToUpper(enabled.RecoveryCodes[0])); resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected a recovery code to complete login, got %d", resp.StatusCode)
+	}
+	challenge = login("manager@maison.co", "manager123")
+	if resp := secondStep(challenge.Challenge, enabled.RecoveryCodes[0]); resp.StatusCode != http.StatusUnauthorized {
+		t.Fatalf("expected a used recovery code to be rejected, got %d", resp.StatusCode)
+	}
+	for range 4 {
+		secondStep(challenge.Challenge, "111111")
+	}
+	if resp := secondStep(challenge.Challenge, enabled.RecoveryCodes[1]); resp.StatusCode != http.StatusUnauthorized {
+		t.Fatalf("expected the challenge to close after too many attempts, got %d", resp.StatusCode)
+	}
 
-	resp := performJSONRequest(t, app, http.MethodGet, "/api/forecast?months=3", nil, nil)
-	if resp.StatusCode != http.StatusUnauthorized {
-		t.Fatalf("expected 401 without token, got %d", resp.StatusCode)
+	// The OAuth2 token endpoint takes the code in the otp field.
+	form := url.Values{"username": {"manager@maison.co"}, "password": {"manager123"}}
+	tokenRequest := func(values url.Values) *http.Response {
+		req := httptest.NewRequest(http.MethodPost, "/api/auth/token", strings.NewReader(values.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		resp, err := app.Test(req)
+		if err != nil {
+			t.Fatalf("token request: %v", err)
+		}
+		return resp
+	}
+	if resp := tokenRequest(form); resp.StatusCode != http.StatusUnauthorized {
+		t.Fatalf("expected token request without otp to be rejected, got %d", resp.StatusCode)
+	}
+	form.Set("otp", enabled.RecoveryCodes[2])
+	if resp := tokenRequest(form); resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected token request with otp to succeed, got %d", resp.StatusCode)
 	}
 
-	resp = performJSONRequest(t, app, http.MethodGet, "/api/forecast?months=3", nil, map[string]string{
-		"Authorization": "Bearer " + managerToken,
-	})
+	// Roles in the policy must enroll before they get tokens.
+	resp = performJSONRequest(t, app, http.MethodPut, "/api/auth/2fa/policy", map[string]any{"required_roles": []string{"Warehouse"}}, bearer(managerToken))
+	if resp.StatusCode != http.StatusForbidden {
+		t.Fatalf("expected only administrators to set the policy, got %d", resp.StatusCode)
+	}
+	resp = performJSONRequest(t, app, http.MethodPut, "/api/auth/2fa/policy", map[string]any{"required_roles": []string{"Warehouse"}}, bearer(adminToken))
 	if resp.StatusCode != http.StatusOK {
-		t.Fatalf("expected 200 for manager role, got %d", resp.StatusCode)
+		t.Fatalf("expected policy update to succeed, got %d", resp.StatusCode)
+	}
+	var policy models.TwoFactorPolicy
+	decode(resp, &policy)
+	if len(policy.RequiredRoles) != 1 || policy.RequiredRoles[0] != models.RoleWarehouse {
+		t.Fatalf("unexpected policy %+v", policy)
+	}
+	enrollment := login("warehouse@maison.co", "warehouse123")
+	if enrollment.TwoFactor != models.TwoFactorEnrollmentRequired || enrollment.Token != "" {
+		t.Fatalf("expected enrollment to be required, got %+v", enrollment)
+	}
+	if resp := secondStep(enrollment.Challenge, "123456"); resp.StatusCode != http.StatusUnauthorized {
+		t.Fatalf("expected an enrollment challenge not to complete a login directly, got %d", resp.StatusCode)
+	}
+	// Restarting enrollment counts against the challenge like a code does.
+	spent := login("warehouse@maison.co", "warehouse123")
+	for i := 0; i < 5; i++ {
+		if resp := performJSONRequest(t, app, http.MethodPost, "/api/auth/2fa/setup", map[string]string{"challenge": spent.Challenge}, nil); resp.StatusCode != http.StatusOK {
+			t.Fatalf("expected setup %d with the enrollment challenge to succeed, got %d", i+1, resp.StatusCode)
+		}
+	}
+	if resp := performJSONRequest(t, app, http.MethodPost, "/api/auth/2fa/setup", map[string]string{"challenge": spent.Challenge}, nil); resp.StatusCode != http.StatusUnauthorized {
+		t.Fatalf("expected setups beyond the attempt limit to be refused, got %d", resp.StatusCode)
+	}
+	resp = performJSONRequest(t, app, http.MethodPost, "/api/auth/2fa/setup", map[string]string{"challenge": enrollment.Challenge}, nil)
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected setup with the enrollment challenge to succeed, got %d", resp.StatusCode)
+	}
+	decode(resp, &setup)
+	resp = performJSONRequest(t, app, http.MethodPost, "/api/auth/2fa/enable", map[string]string{"challenge": enrollment.Challenge, "code": code(setup.Secret, 0)}, nil)
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected enrollment to complete the login, got %d", resp.StatusCode)
+	}
+	var enrolled models.TwoFactorEnabled
+	decode(resp, &enrolled)
+	if enrolled.Login == nil || enrolled.Login.Token == "" || len(enrolled.RecoveryCodes) != 10 {
+		t.Fatalf("expected tokens and recovery codes, got %+v", enrolled)
+	}
+	resp = performJSONRequest(t, app, http.MethodPost, "/api/auth/2fa/disable", map[string]string{"code": enrolled.RecoveryCodes[0]}, bearer(enrolled.Login.Token))
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Fatalf("expected a required role not to disable two-factor authentication, got %d", resp.StatusCode)
+	}
+
+	// An administrator can reset a lost second factor.
+	managerID := mustFindUserIDByEmail(t, db, "manager@maison.co")
+	if resp := performJSONRequest(t, app, http.MethodDelete, "/api/users/"+managerID+"/2fa", nil, bearer(adminToken)); resp.StatusCode != http.StatusNoContent {
+		t.Fatalf("expected reset to succeed, got %d", resp.StatusCode)
+	}
+	if payload := login("manager@maison.co", "manager123"); payload.Token == "" {
+		t.Fatalf("expected password login after reset, got %+v", payload)
+	}
+	if resp := performJSONRequest(t, app, http.MethodGet, "/api/auth/me", nil, bearer(completed.Token)); resp.StatusCode != http.StatusUnauthorized {
+		t.Fatalf("expected the reset to revoke existing sessions, got %d", resp.StatusCode)
 	}
 }
 
-func TestLoginRejectsInvalidCredentials(t *testing.T) {
-	app, _ := setupTestApp(t)
+func TestLoginLockoutAndRateLimits(t *testing.T) {
+	t.Setenv("RATE_LIMIT_STORE", "database")
+	t.Setenv("RATE_LIMIT_CATALOG", "3/1m")
+	app, db := setupTestApp(t)
 
-	resp := performJSONRequest(t, app, http.MethodPost, "/api/auth/login", map[string]string{
-		"email":    "admin@maison.co",
-		"password": "wrong-password",
-	}, nil)
+	attempt := func(password string) *http.Response {
+		return performJSONRequest(t, app, http.MethodPost, "/api/auth/login", map[string]string{"email": "manager@maison.co", "password": password}, nil)
+	}
+	for i := 0; i < 10; i++ {
+		if resp := attempt("wrong"); resp.StatusCode != http.StatusUnauthorized {
+			t.Fatalf("expected failure %d to be answered normally, got %d", i+1, resp.StatusCode)
+		}
+	}
+	if resp := attempt("wrong"); resp.StatusCode != http.StatusUnauthorized {
+		t.Fatalf("expected the failure that starts the lockout to be 401, got %d", resp.StatusCode)
+	}
+	resp := attempt("manager123")
+	if resp.StatusCode != http.StatusTooManyRequests {
+		t.Fatalf("expected the locked account to be refused even with the right password, got %d", resp.StatusCode)
+	}
+	if retry := resp.Header.Get("Retry-After"); retry != "30" && retry != "29" {
+		t.Fatalf("expected Retry-After of about 30s, got %q", retry)
+	}
+	var lockouts int64
+	db.Model(&models.AuditLog{}).Where("action = ? AND user = ?", "Login Lockout", "manager@maison.co").Count(&lockouts)
+	if lockouts != 1 {
+		t.Fatalf("expected one lockout audit entry, got %d", lockouts)
+	}
+	loginAndGetToken(t, app, "admin@maison.co", "admin123")
+
+	// Once the lockout ends a correct password clears the failures.
+	db.Model(&models.RateCounter{}).Where("key LIKE ?", "login-lock:%").Update("reset_at", time.Now().UTC().Add(-time.Second))
+	loginAndGetToken(t, app, "manager@maison.co", "manager123")
+	var failures int64
+	db.Model(&models.RateCounter{}).Where("key = ?", "login-fail:account:manager@maison.co").Count(&failures)
+	if failures != 0 {
+		t.Fatalf("expected a successful login to clear the account's failures")
+	}
 
-	if resp.StatusCode != http.StatusUnauthorized {
-		t.Fatalf("expected 401, got %d", resp.StatusCode)
+	for i := 0; i < 3; i++ {
+		resp := performJSONRequest(t, app, http.MethodGet, "/api/categories", nil, nil)
+		if resp.StatusCode != http.StatusOK || resp.Header.Get("X-RateLimit-Remaining") != fmt.Sprint(2-i) {
+			t.Fatalf("expected request %d within the limit, got %d remaining %q", i+1, resp.StatusCode, resp.Header.Get("X-RateLimit-Remaining"))
+		}
+	}
+	resp = performJSONRequest(t, app, http.MethodGet, "/api/products", nil, nil)
+	if resp.StatusCode != http.StatusTooManyRequests || resp.Header.Get("Retry-After") == "" {
+		t.Fatalf("expected the catalog group limit to apply across routes, got %d", resp.StatusCode)
+	}
+	if resp := performJSONRequest(t, app, http.MethodGet, "/api/orders", nil, map[string]string{"Authorization": "Bearer " + loginAndGetToken(t, app, "admin@maison.co", "admin123")}); resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected staff routes outside the group not to be limited, got %d", resp.StatusCode)
 	}
 }
 
-func TestManagerCannotDeleteProduct(t *testing.T) {
-	app, db := setupTestApp(t)
+func TestTwoFactorStepHonorsAccountLockout(t *testing.T) {
+	app, _ := setupTestApp(t)
 	managerToken := loginAndGetToken(t, app, "manager@maison.co", "manager123")
-	productID := mustFindProductIDBySKU(t, db, "SOF-HVNS-BEI")
+	bearer := map[string]string{"Authorization": "Bearer " + managerToken}
 
-	resp := performJSONRequest(t, app, http.MethodDelete, "/api/products/"+productID, nil, map[string]string{
-		"Authorization": "Bearer " + managerToken,
-	})
+	resp := performJSONRequest(t, app, http.MethodPost, "/api/auth/2fa/setup", nil, bearer)
+	var setup models.TOTPSetup
+	if err := json.NewDecoder(resp.Body).Decode(&setup); err != nil {
+		t.Fatalf("decode setup: %v", err)
+	}
+	code := func(offset int64) string {
+		value, err := security.TOTPCode(setup.Secret, security.TOTPStep(time.Now())+offset)
+		if err != nil {
+			t.Fatalf("totp code: %v", err)
+		}
+		return value
+	}
+	if resp := performJSONRequest(t, app, http.MethodPost, "/api/auth/2fa/enable", map[string]string{"code": code(0)}, bearer); resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected enable to succeed, got %d", resp.StatusCode)
+	}
+	resp = performJSONRequest(t, app, http.MethodPost, "/api/auth/login", map[string]string{"email": "manager@maison.co", "password": "manager123"}, nil)
+	var challenge models.LoginResponse
+	if err := json.NewDecoder(resp.Body).Decode(&challenge); err != nil || challenge.Challenge == "" {
+		t.Fatalf("expected a challenge, got %+v %v", challenge, err)
+	}
 
-	if resp.StatusCode != http.StatusForbidden {
-		t.Fatalf("expected 403, got %d", resp.StatusCode)
+	// A challenge issued before the account was locked does not get around
+	// the lockout.
+	for i := 0; i < 11; i++ {
+		performJSONRequest(t, app, http.MethodPost, "/api/auth/login", map[string]string{"email": "manager@maison.co", "password": "wrong"}, nil)
+	}
+	resp = performJSONRequest(t, app, http.MethodPost, "/api/auth/login/2fa", map[string]string{"challenge": challenge.Challenge, "code": code(1)}, nil)
+	if resp.StatusCode != http.StatusTooManyRequests {
+		t.Fatalf("expected the locked account to be refused at the second step, got %d", resp.StatusCode)
 	}
 }
 
-func TestCreateOrderSuccess(t *testing.T) {
+func TestPermissionsAuthorizeRoutesAndHonorDeny(t *testing.T) {
+	t.Setenv("PERMISSION_CACHE_TTL", "1ms")
 	app, db := setupTestApp(t)
-	productID := mustFindProductIDBySKU(t, db, "SOF-HVNS-BEI")
 
-	resp := performJSONRequest(t, app, http.MethodPost, "/api/orders", map[string]any{
-		"customer": "Jane Doe",
-		"email":    "jane@example.com",
-		"address":  "Ocean Avenue",
-		"items": []map[string]any{
-			{
-				"product":  map[string]any{"id": productID},
-				"quantity": 1,
-			},
-		},
-	}, nil)
+	get := func(path, token string) int {
+		resp := performJSONRequest(t, app, http.MethodGet, path, nil, map[string]string{"Authorization": "Bearer " + token})
+		return resp.StatusCode
+	}
+	adminToken := loginAndGetToken(t, app, "admin@maison.co", "admin123")
+	managerToken := loginAndGetToken(t, app, "manager@maison.co", "manager123")
+	warehouseToken := loginAndGetToken(t, app, "warehouse@maison.co", "warehouse123")
 
-	if resp.StatusCode != http.StatusCreated {
-		body, _ := io.ReadAll(resp.Body)
-		t.Fatalf("expected 201, got %d: %s", resp.StatusCode, string(body))
+	if status := get("/api/customers", managerToken); status != http.StatusOK {
+		t.Fatalf("expected manager to read customers, got %d", status)
+	}
+	if status := get("/api/customers", warehouseToken); status != http.StatusForbidden {
+		t.Fatalf("expected warehouse without read:customer to be refused, got %d", status)
 	}
 
-	var product models.Product
-	if err := db.First(&product, "id = ?", productID).Error; err != nil {
-		t.Fatalf("fetch product: %v", err)
+	performJSONRequest(t, app, http.MethodPost, "/api/auth/signup", map[string]string{
+		"email": "perm-client@example.com", "password": "client123", "name": "Permission Client",
+	}, nil)
+	markEmailVerified(t, db, "perm-client@example.com")
+	clientToken := loginAndGetToken(t, app, "perm-client@example.com", "client123")
+	if status := get("/api/orders", clientToken); status != http.StatusForbidden {
+		t.Fatalf("expected client to be refused the order list, got %d", status)
 	}
-	if product.StockQty != 11 {
-		t.Fatalf("expected stock to decrease to 11, got %d", product.StockQty)
+	if status := get("/api/orders/my", clientToken); status != http.StatusOK {
+		t.Fatalf("expected client to read own orders, got %d", status)
 	}
-}
 
-func TestForecastAccessibleByExecutive(t *testing.T) {
-	app, _ := setupTestApp(t)
-	executiveToken := loginAndGetToken(t, app, "executive@maison.co", "executive123")
+	// A deny granted to the role overrides its allow once the cache expires.
+	var manager models.Role
+	if err := db.First(&manager, "name = ?", models.RoleManager).Error; err != nil {
+		t.Fatalf("find manager role: %v", err)
+	}
+	deny := models.Permission{Action: "read", Resource: "customer", Effect: models.PermissionDeny, Description: "test deny"}
+	if err := db.Create(&deny).Error; err != nil {
+		t.Fatalf("create deny: %v", err)
+	}
+	mapping := models.RolePermission{RoleID: manager.ID, PermissionID: deny.ID}
+	if err := db.Create(&mapping).Error; err != nil {
+		t.Fatalf("grant deny: %v", err)
+	}
+	time.Sleep(5 * time.Millisecond)
+	if status := get("/api/customers", managerToken); status != http.StatusForbidden {
+		t.Fatalf("expected deny to refuse manager, got %d", status)
+	}
+	if status := get("/api/customers", adminToken); status != http.StatusOK {
+		t.Fatalf("expected deny on manager to leave admin alone, got %d", status)
+	}
 
-	resp := performJSONRequest(t, app, http.MethodGet, "/api/forecast?months=3", nil, map[string]string{
-		"Authorization": "Bearer " + executiveToken,
-	})
+	db.Delete(&mapping)
+	time.Sleep(5 * time.Millisecond)
+	if status := get("/api/customers", managerToken); status != http.StatusOK {
+		t.Fatalf("expected manager access back after removing the deny, got %d", status)
+	}
 
-	if resp.StatusCode != http.StatusOK {
-		t.Fatalf("expected 200, got %d", resp.StatusCode)
+	// Restarting keeps access an administrator changed.
+	var readUser models.Permission
+	if err := db.First(&readUser, "action = ? AND resource = ? AND effect = ?", "read", "user", models.PermissionAllow).Error; err != nil {
+		t.Fatalf("find read:user: %v", err)
+	}
+	if err := db.Create(&models.RolePermission{RoleID: manager.ID, PermissionID: readUser.ID}).Error; err != nil {
+		t.Fatalf("grant read:user: %v", err)
+	}
+	if err := database.ConnectSeedOnlyForTests(db, config.Config{}); err != nil {
+		t.Fatalf("reseed: %v", err)
+	}
+	time.Sleep(5 * time.Millisecond)
+	if status := get("/api/users", managerToken); status != http.StatusOK {
+		t.Fatalf("expected granted read:user to survive reseeding, got %d", status)
 	}
 }
 
-func TestNonAdminCannotBlockUser(t *testing.T) {
+func TestRoleAdministrationGrantsAndProtectsLastAdministrator(t *testing.T) {
 	app, db := setupTestApp(t)
+	adminToken := loginAndGetToken(t, app, "admin@maison.co", "admin123")
 	managerToken := loginAndGetToken(t, app, "manager@maison.co", "manager123")
-	userID := mustFindUserIDByEmail(t, db, "warehouse@maison.co")
-
-	resp := performJSONRequest(t, app, http.MethodPatch, "/api/users/"+userID+"/block", map[string]any{
-		"is_blocked": true,
-	}, map[string]string{
-		"Authorization": "Bearer " + managerToken,
-	})
+	adminHeaders := map[string]string{"Authorization": "Bearer " + adminToken}
 
-	if resp.StatusCode != http.StatusForbidden {
-		t.Fatalf("expected 403, got %d", resp.StatusCode)
+	if resp := performJSONRequest(t, app, http.MethodGet, "/api/roles", nil, map[string]string{"Authorization": "Bearer " + managerToken}); resp.StatusCode != http.StatusForbidden {
+		t.Fatalf("expected manager to be refused the roles API, got %d", resp.StatusCode)
+	}
+	resp := performJSONRequest(t, app, http.MethodGet, "/api/roles", nil, adminHeaders)
+	var roles []models.RoleSummary
+	if err := json.NewDecoder(resp.Body).Decode(&roles); err != nil {
+		t.Fatalf("decode roles: %v", err)
+	}
+	roleIDs := map[models.RoleName]uint{}
+	for _, role := range roles {
+		roleIDs[role.Name] = role.ID
+		if role.Name == models.RoleAdmin && (!role.Builtin || role.UserCount != 1) {
+			t.Fatalf("expected built-in administrator role with one user, got %+v", role)
+		}
 	}
-}
 
-func TestClientCanSignupAndViewOwnOrders(t *testing.T) {
-	app, db := setupTestApp(t)
-	productID := mustFindProductIDBySKU(t, db, "CHR-ARIA-TER")
+	resp = performJSONRequest(t, app, http.MethodPost, "/api/roles", map[string]string{"name": "Merchandiser"}, adminHeaders)
+	if resp.StatusCode != http.StatusCreated {
+		body, _ := io.ReadAll(resp.Body)
+		t.Fatalf("expected 201 for custom role, got %d: %s", resp.StatusCode, string(body))
+	}
+	var merchandiser models.Role
+	if err := json.NewDecoder(resp.Body).Decode(&merchandiser); err != nil {
+		t.Fatalf("decode role: %v", err)
+	}
+	if resp := performJSONRequest(t, app, http.MethodPost, "/api/roles", map[string]string{"name": "merchandiser"}, adminHeaders); resp.StatusCode != http.StatusConflict {
+		t.Fatalf("expected duplicate role name to conflict, got %d", resp.StatusCode)
+	}
 
-	signupResp := performJSONRequest(t, app, http.MethodPost, "/api/auth/signup", map[string]string{
-		"email":    "client@example.com",
-		"password": "client123",
-		"name":     "Client User",
-	}, nil)
-	if signupResp.StatusCode != http.StatusCreated {
-		body, _ := io.ReadAll(signupResp.Body)
-		t.Fatalf("expected 201 signup, got %d: %s", signupResp.StatusCode, string(body))
+	resp = performJSONRequest(t, app, http.MethodGet, "/api/permissions", nil, adminHeaders)
+	var permissions []models.PermissionView
+	if err := json.NewDecoder(resp.Body).Decode(&permissions); err != nil {
+		t.Fatalf("decode permissions: %v", err)
 	}
+	permissionIDs := map[string]uint{}
+	for _, permission := range permissions {
+		permissionIDs[permission.Effect+" "+permission.Action+":"+permission.Resource] = permission.ID
+		if permission.Action == "read" && permission.Resource == "customer" && !permission.Enforced {
+			t.Fatalf("expected read:customer to be reported as enforced")
+		}
+	}
+	readCustomer := permissionIDs["allow read:customer"]
+	manageAccess := permissionIDs["allow grant:role"]
 
-	token := loginAndGetToken(t, app, "client@example.com", "client123")
+	grantPath := fmt.Sprintf("/api/roles/%d/permissions", merchandiser.ID)
+	if resp := performJSONRequest(t, app, http.MethodPost, grantPath, map[string]uint{"permission_id": readCustomer}, adminHeaders); resp.StatusCode != http.StatusNoContent {
+		t.Fatalf("expected grant to succeed, got %d", resp.StatusCode)
+	}
+	if resp := performJSONRequest(t, app, http.MethodPost, "/api/users", map[string]string{
+		"email": "merch@maison.co", "password": "merch123", "name": "Merchandiser", "role": "Merchandiser",
+	}, adminHeaders); resp.StatusCode != http.StatusCreated {
+		t.Fatalf("expected user with custom role to be created, got %d", resp.StatusCode)
+	}
+	merchHeaders := map[string]string{"Authorization": "Bearer " + loginAndGetToken(t, app, "merch@maison.co", "merch123")}
+	if resp := performJSONRequest(t, app, http.MethodGet, "/api/customers", nil, merchHeaders); resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected granted permission to apply at once, got %d", resp.StatusCode)
+	}
+	if resp := performJSONRequest(t, app, http.MethodGet, "/api/suppliers", nil, merchHeaders); resp.StatusCode != http.StatusForbidden {
+		t.Fatalf("expected ungranted permission to be refused, got %d", resp.StatusCode)
+	}
+	if resp := performJSONRequest(t, app, http.MethodDelete, fmt.Sprintf("/api/roles/%d", merchandiser.ID), nil, adminHeaders); resp.StatusCode != http.StatusConflict {
+		t.Fatalf("expected role in use to stay, got %d", resp.StatusCode)
+	}
 
-	orderResp := performJSONRequest(t, app, http.MethodPost, "/api/orders", map[string]any{
-		"customer": "Client User",
-		"email":    "client@example.com",
-		"address":  "Client Street",
+	if resp := performJSONRequest(t, app, http.MethodDelete, fmt.Sprintf("%s/%d", grantPath, readCustomer), nil, adminHeaders); resp.StatusCode != http.StatusNoContent {
+		t.Fatalf("expected revoke to succeed, got %d", resp.StatusCode)
+	}
+	if resp := performJSONRequest(t, app, http.MethodGet, "/api/customers", nil, merchHeaders); resp.StatusCode != http.StatusForbidden {
+		t.Fatalf("expected revoked permission to be refused, got %d", resp.StatusCode)
+	}
+	var grantAudits int64
+	db.Model(&models.AuditLog{}).Where("action IN ? AND entity_id = ?", []string{"Permission Granted", "Permission Revoked"}, fmt.Sprint(merchandiser.ID)).Count(&grantAudits)
+	if grantAudits != 2 {
+		t.Fatalf("expected grant and revoke audit entries, got %d", grantAudits)
+	}
+
+	// The administrators are the only holders of grant:role.
+	adminGrants := fmt.Sprintf("/api/roles/%d/permissions", roleIDs[models.RoleAdmin])
+	if resp := performJSONRequest(t, app, http.MethodDelete, fmt.Sprintf("%s/%d", adminGrants, manageAccess), nil, adminHeaders); resp.StatusCode != http.StatusConflict {
+		t.Fatalf("expected revoking the last administrator's rights to conflict, got %d", resp.StatusCode)
+	}
+	resp = performJSONRequest(t, app, http.MethodPost, "/api/permissions", map[string]string{"action": "grant", "resource": "role", "effect": "deny"}, adminHeaders)
+	if resp.StatusCode != http.StatusCreated {
+		t.Fatalf("expected deny permission to be created, got %d", resp.StatusCode)
+	}
+	var denyManageAccess models.Permission
+	if err := json.NewDecoder(resp.Body).Decode(&denyManageAccess); err != nil {
+		t.Fatalf("decode permission: %v", err)
+	}
+	if resp := performJSONRequest(t, app, http.MethodPost, adminGrants, map[string]uint{"permission_id": denyManageAccess.ID}, adminHeaders); resp.StatusCode != http.StatusConflict {
+		t.Fatalf("expected denying the last administrator's rights to conflict, got %d", resp.StatusCode)
+	}
+	adminID := mustFindUserIDByEmail(t, db, "admin@maison.co")
+	if resp := performJSONRequest(t, app, http.MethodPatch, "/api/users/"+adminID+"/block", map[string]any{"is_blocked": true}, adminHeaders); resp.StatusCode != http.StatusConflict {
+		t.Fatalf("expected blocking the last administrator to conflict, got %d", resp.StatusCode)
+	}
+	if resp := performJSONRequest(t, app, http.MethodGet, "/api/roles", nil, adminHeaders); resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected administrator to keep access after refused changes, got %d", resp.StatusCode)
+	}
+
+	if resp := performJSONRequest(t, app, http.MethodDelete, fmt.Sprintf("/api/permissions/%d", readCustomer), nil, adminHeaders); resp.StatusCode != http.StatusConflict {
+		t.Fatalf("expected enforced permission to stay, got %d", resp.StatusCode)
+	}
+	if resp := performJSONRequest(t, app, http.MethodDelete, fmt.Sprintf("/api/permissions/%d", denyManageAccess.ID), nil, adminHeaders); resp.StatusCode != http.StatusNoContent {
+		t.Fatalf("expected custom permission to be deleted, got %d", resp.StatusCode)
+	}
+	if resp := performJSONRequest(t, app, http.MethodDelete, fmt.Sprintf("/api/roles/%d", roleIDs[models.RoleAdmin]), nil, adminHeaders); resp.StatusCode != http.StatusConflict {
+		t.Fatalf("expected built-in role to stay, got %d", resp.StatusCode)
+	}
+}
+
+func TestAPIKeysAuthenticateWithScopesAndAreAudited(t *testing.T) {
+	app, db := setupTestApp(t)
+	adminHeaders := map[string]string{"Authorization": "Bearer " + loginAndGetToken(t, app, "admin@maison.co", "admin123")}
+
+	if resp := performJSONRequest(t, app, http.MethodPost, "/api/api-keys", map[string]any{"name": "erp", "scopes": []string{"fly:order"}}, adminHeaders); resp.StatusCode != http.StatusBadRequest {
+		t.Fatalf("expected unknown scope to be rejected, got %d", resp.StatusCode)
+	}
+	if resp := performJSONRequest(t, app, http.MethodPost, "/api/api-keys", map[string]any{"name": "erp", "scopes": []string{"read:order"}, "expires_at": time.Now().Add(-time.Hour)}, adminHeaders); resp.StatusCode != http.StatusBadRequest {
+		t.Fatalf("expected past expiry to be rejected, got %d", resp.StatusCode)
+	}
+	if resp := performJSONRequest(t, app, http.MethodPost, "/api/api-keys", map[string]any{"name": "erp", "scopes": []string{"create:review"}}, adminHeaders); resp.StatusCode != http.StatusBadRequest {
+		t.Fatalf("expected a scope outside the creator's role to be rejected, got %d", resp.StatusCode)
+	}
+	resp := performJSONRequest(t, app, http.MethodPost, "/api/api-keys", map[string]any{"name": "erp-sync", "scopes": []string{"status:order", "READ:order"}}, adminHeaders)
+	if resp.StatusCode != http.StatusCreated {
+		body, _ := io.ReadAll(resp.Body)
+		t.Fatalf("expected 201 for api key, got %d: %s", resp.StatusCode, string(body))
+	}
+	var created models.APIKeyCreated
+	if err := json.NewDecoder(resp.Body).Decode(&created); err != nil {
+		t.Fatalf("decode api key: %v", err)
+	}
+	if !strings.HasPrefix(created.Key, created.APIKey.Prefix+"_") || !strings.HasPrefix(created.Key, models.APIKeyPrefix) {
+		t.Fatalf("expected key %q to start with its prefix %q", created.Key, created.APIKey.Prefix)
+	}
+	if strings.Join(created.APIKey.Scopes, " ") != "read:order status:order" {
+		t.Fatalf("expected normalized scopes, got %v", created.APIKey.Scopes)
+	}
+	var stored models.APIKey
+	if err := db.First(&stored, "id = ?", created.APIKey.ID).Error; err != nil {
+		t.Fatalf("find api key: %v", err)
+	}
+	if stored.KeyHash == "" || strings.Contains(stored.KeyHash, created.Key) || stored.LastUsedAt != nil {
+		t.Fatalf("expected only an unused hash to be stored, got %+v", stored)
+	}
+
+	keyHeaders := map[string]string{"X-API-Key": created.Key}
+	if resp := performJSONRequest(t, app, http.MethodGet, "/api/orders", nil, keyHeaders); resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected scoped key to list orders, got %d", resp.StatusCode)
+	}
+	if resp := performJSONRequest(t, app, http.MethodGet, "/api/orders", nil, map[string]string{"Authorization": "Bearer " + created.Key}); resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected key as bearer token to be accepted, got %d", resp.StatusCode)
+	}
+	if resp := performJSONRequest(t, app, http.MethodGet, "/api/customers", nil, keyHeaders); resp.StatusCode != http.StatusForbidden {
+		t.Fatalf("expected key without scope to be refused, got %d", resp.StatusCode)
+	}
+	if resp := performJSONRequest(t, app, http.MethodGet, "/api/auth/me", nil, keyHeaders); resp.StatusCode != http.StatusForbidden {
+		t.Fatalf("expected key to be refused account routes, got %d", resp.StatusCode)
+	}
+	if resp := performJSONRequest(t, app, http.MethodGet, "/api/orders", nil, map[string]string{"X-API-Key": created.Key + "x"}); resp.StatusCode != http.StatusUnauthorized {
+		t.Fatalf("expected tampered key to be refused, got %d", resp.StatusCode)
+	}
+
+	orderID := mustFindOrderIDByAddress(t, db, "г. Екатеринбург, ул. Малышева, д. 18, кв. 24")
+	if resp := performJSONRequest(t, app, http.MethodPatch, "/api/orders/"+orderID+"/status", map[string]any{"status": "shipped", "user": "someone@else"}, keyHeaders); resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected key to update order status, got %d", resp.StatusCode)
+	}
+	var audited int64
+	db.Model(&models.AuditLog{}).Where("action = ? AND entity_id = ? AND user = ?", "Order Status Changed", orderID, "api-key:erp-sync").Count(&audited)
+	if audited != 1 {
+		t.Fatalf("expected the status change to be attributed to the key, got %d entries", audited)
+	}
+	if err := db.First(&stored, "id = ?", created.APIKey.ID).Error; err != nil || stored.LastUsedAt == nil {
+		t.Fatalf("expected last use to be recorded, got %+v, %v", stored, err)
+	}
+	if resp := performJSONRequest(t, app, http.MethodPost, "/api/api-keys", map[string]any{"name": "minted", "scopes": []string{"read:order"}}, keyHeaders); resp.StatusCode != http.StatusForbidden {
+		t.Fatalf("expected a key to be refused minting keys, got %d", resp.StatusCode)
+	}
+
+	// A deny on the creator's role applies to the key as well.
+	resp = performJSONRequest(t, app, http.MethodPost, "/api/permissions", map[string]string{"action": "read", "resource": "order", "effect": "deny"}, adminHeaders)
+	if resp.StatusCode != http.StatusCreated {
+		t.Fatalf("expected deny permission to be created, got %d", resp.StatusCode)
+	}
+	var denyReadOrder models.Permission
+	if err := json.NewDecoder(resp.Body).Decode(&denyReadOrder); err != nil {
+		t.Fatalf("decode permission: %v", err)
+	}
+	var admin models.Role
+	if err := db.First(&admin, "name = ?", models.RoleAdmin).Error; err != nil {
+		t.Fatalf("find admin role: %v", err)
+	}
+	adminGrants := fmt.Sprintf("/api/roles/%d/permissions", admin.ID)
+	if resp := performJSONRequest(t, app, http.MethodPost, adminGrants, map[string]uint{"permission_id": denyReadOrder.ID}, adminHeaders); resp.StatusCode != http.StatusNoContent {
+		t.Fatalf("expected deny to be granted, got %d", resp.StatusCode)
+	}
+	if resp := performJSONRequest(t, app, http.MethodGet, "/api/orders", nil, keyHeaders); resp.StatusCode != http.StatusForbidden {
+		t.Fatalf("expected the creator's deny to refuse the key, got %d", resp.StatusCode)
+	}
+	if resp := performJSONRequest(t, app, http.MethodDelete, fmt.Sprintf("%s/%d", adminGrants, denyReadOrder.ID), nil, adminHeaders); resp.StatusCode != http.StatusNoContent {
+		t.Fatalf("expected deny to be revoked, got %d", resp.StatusCode)
+	}
+	if resp := performJSONRequest(t, app, http.MethodGet, "/api/orders", nil, keyHeaders); resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected the key to work again once the deny is revoked, got %d", resp.StatusCode)
+	}
+
+	if resp := performJSONRequest(t, app, http.MethodDelete, "/api/api-keys/"+created.APIKey.ID, nil, adminHeaders); resp.StatusCode != http.StatusNoContent {
+		t.Fatalf("expected revoke to succeed, got %d", resp.StatusCode)
+	}
+	if resp := performJSONRequest(t, app, http.MethodGet, "/api/orders", nil, keyHeaders); resp.StatusCode != http.StatusUnauthorized {
+		t.Fatalf("expected revoked key to be refused, got %d", resp.StatusCode)
+	}
+	resp = performJSONRequest(t, app, http.MethodGet, "/api/api-keys", nil, adminHeaders)
+	body, _ := io.ReadAll(resp.Body)
+	if !strings.Contains(string(body), created.APIKey.Prefix) || strings.Contains(string(body), created.Key) || !strings.Contains(string(body), "revoked_at") {
+		t.Fatalf("expected the listing to show the revoked key without its secret, got %s", string(body))
+	}
+}
+
+func TestProductCreateRequiresAuthentication(t *testing.T) {
+	app, _ := setupTestApp(t)
+	managerToken := loginAndGetToken(t, app, "manager@maison.co", "manager123")
+
+	payload := map[string]any{
+		"id":          "p-admin",
+		"name":        "Admin Product",
+		"category":    "Гостиная",
+		"price":       120,
+		"image":       "/images/admin.jpg",
+		"description": "Created by admin",
+		"dimensions":  "10x10x10",
+		"material":    "Oak",
+		"stock":       3,
+		"sku":         "SKU-ADMIN",
+		"featured":    false,
+		"rating":      4.0,
+		"reviews":     0,
+	}
+
+	resp := performJSONRequest(t, app, http.MethodPost, "/api/products", payload, nil)
+	if resp.StatusCode != http.StatusUnauthorized {
+		t.Fatalf("expected 401 without token, got %d", resp.StatusCode)
+	}
+
+	resp = performJSONRequest(t, app, http.MethodPost, "/api/products", payload, map[string]string{
+		"Authorization": "Bearer " + managerToken,
+	})
+	if resp.StatusCode != http.StatusCreated {
+		t.Fatalf("expected 201 for authenticated manager, got %d", resp.StatusCode)
+	}
+}
+
+func TestCreateOrderRollsBackOnInsufficientStock(t *testing.T) {
+	app, db := setupTestApp(t)
+	productID := mustFindProductIDBySKU(t, db, "SOF-HVNS-BEI")
+
+	resp := performJSONRequest(t, app, http.MethodPost, "/api/orders", map[string]any{
+		"customer": "John Doe",
+		"email":    "john@example.com",
+		"address":  "Main Street",
 		"items": []map[string]any{
 			{
 				"product":  map[string]any{"id": productID},
-				"quantity": 1,
+				"quantity": 100,
 			},
 		},
 	}, nil)
-	if orderResp.StatusCode != http.StatusCreated {
-		body, _ := io.ReadAll(orderResp.Body)
-		t.Fatalf("expected 201 order, got %d: %s", orderResp.StatusCode, string(body))
+
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Fatalf("expected 400 for insufficient stock, got %d", resp.StatusCode)
 	}
 
-	mineResp := performJSONRequest(t, app, http.MethodGet, "/api/orders/my", nil, map[string]string{
-		"Authorization": "Bearer " + token,
+	var product models.Product
+	if err := db.First(&product, "id = ?", productID).Error; err != nil {
+		t.Fatalf("fetch product: %v", err)
+	}
+	if product.StockQty != 12 {
+		t.Fatalf("expected stock rollback to preserve 12 items, got %d", product.StockQty)
+	}
+}
+
+func TestManagerCanUpdateOrderStatus(t *testing.T) {
+	app, db := setupTestApp(t)
+	managerToken := loginAndGetToken(t, app, "manager@maison.co", "manager123")
+	orderID := mustFindOrderIDByAddress(t, db, "г. Екатеринбург, ул. Малышева, д. 18, кв. 24")
+
+	resp := performJSONRequest(t, app, http.MethodPatch, "/api/orders/"+orderID+"/status", map[string]any{
+		"status": "shipped",
+	}, map[string]string{
+		"Authorization": "Bearer " + managerToken,
 	})
-	if mineResp.StatusCode != http.StatusOK {
-		body, _ := io.ReadAll(mineResp.Body)
-		t.Fatalf("expected 200 for own orders, got %d: %s", mineResp.StatusCode, string(body))
+
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected 200 for manager order update, got %d", resp.StatusCode)
 	}
 
-	var orders []models.OrderResponse
-	if err := json.NewDecoder(mineResp.Body).Decode(&orders); err != nil {
-		t.Fatalf("decode orders: %v", err)
+	var order models.Order
+	if err := db.First(&order, "id = ?", orderID).Error; err != nil {
+		t.Fatalf("fetch order: %v", err)
 	}
-	if len(orders) != 1 {
-		t.Fatalf("expected 1 own order, got %d", len(orders))
+
+	var status models.OrderStatusRef
+	if err := db.First(&status, "id = ?", order.StatusID).Error; err != nil {
+		t.Fatalf("fetch order status: %v", err)
 	}
-	if orders[0].Email != "client@example.com" {
-		t.Fatalf("expected client order email, got %s", orders[0].Email)
+	if status.Code != string(models.OrderStatusShipped) {
+		t.Fatalf("expected order status shipped, got %s", status.Code)
+	}
+}
+
+func TestForecastRequiresAuthentication(t *testing.T) {
+	app, _ := setupTestApp(t)
+	managerToken := loginAndGetToken(t, app, "manager@maison.co", "manager123")
+
+	resp := performJSONRequest(t, app, http.MethodGet, "/api/forecast?months=3", nil, nil)
+	if resp.StatusCode != http.StatusUnauthorized {
+		t.Fatalf("expected 401 without token, got %d", resp.StatusCode)
+	}
+
+	resp = performJSONRequest(t, app, http.MethodGet, "/api/forecast?months=3", nil, map[string]string{
+		"Authorization": "Bearer " + managerToken,
+	})
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected 200 for manager role, got %d", resp.StatusCode)
+	}
+}
+
+func TestLoginRejectsInvalidCredentials(t *testing.T) {
+	app, _ := setupTestApp(t)
+
+	resp := performJSONRequest(t, app, http.MethodPost, "/api/auth/login", map[string]string{
+		"email":    "admin@maison.co",
+		"password": "wrong-password",
+	}, nil)
+
+	if resp.StatusCode != http.StatusUnauthorized {
+		t.Fatalf("expected 401, got %d", resp.StatusCode)
+	}
+}
+
+func TestLoginRehashesLegacyPasswordHash(t *testing.T) {
+	app, db := setupTestApp(t)
+	managerID := mustFindUserIDByEmail(t, db, "manager@maison.co")
+	legacy := security.LegacyHashPassword("manager123", security.LegacyPasswordSalt)
+	if err := db.Model(&models.User{}).Where("id = ?", managerID).Update("password_hash", legacy).Error; err != nil {
+		t.Fatalf("set legacy hash: %v", err)
+	}
+	hashOf := func() string {
+		t.Helper()
+		var user models.User
+		if err := db.First(&user, "id = ?", managerID).Error; err != nil {
+			t.Fatalf("fetch user: %v", err)
+		}
+		return user.PasswordHash
+	}
+
+	resp := performJSONRequest(t, app, http.MethodPost, "/api/auth/login", map[string]string{"email": "manager@maison.co", "password": "wrong-password"}, nil)
+	if resp.StatusCode != http.StatusUnauthorized || hashOf() != legacy {
+		t.Fatalf("expected a failed login to keep the legacy hash, got %d", resp.StatusCode)
+	}
+
+	loginAndGetToken(t, app, "manager@maison.co", "manager123")
+	migrated := hashOf()
+	if !strings.HasPrefix(migrated, "$argon2id$") {
+		t.Fatalf("expected the hash to be migrated to argon2id, got %s", migrated)
+	}
+	loginAndGetToken(t, app, "manager@maison.co", "manager123")
+	if hashOf() != migrated {
+		t.Fatalf("expected an up-to-date hash to be kept")
+	}
+
+	var admin models.User
+	if err := db.First(&admin, "email = ?", "admin@maison.co").Error; err != nil {
+		t.Fatalf("fetch admin: %v", err)
+	}
+	if !strings.HasPrefix(admin.PasswordHash, "$argon2id$") {
+		t.Fatalf("expected seeded users to get argon2id hashes, got %s", admin.PasswordHash)
+	}
+}
+
+func TestManagerCannotDeleteProduct(t *testing.T) {
+	app, db := setupTestApp(t)
+	managerToken := loginAndGetToken(t, app, "manager@maison.co", "manager123")
+	productID := mustFindProductIDBySKU(t, db, "SOF-HVNS-BEI")
+
+	resp := performJSONRequest(t, app, http.MethodDelete, "/api/products/"+productID, nil, map[string]string{
+		"Authorization": "Bearer " + managerToken,
+	})
+
+	if resp.StatusCode != http.StatusForbidden {
+		t.Fatalf("expected 403, got %d", resp.StatusCode)
+	}
+}
+
+func TestCreateOrderSuccess(t *testing.T) {
+	app, db := setupTestApp(t)
+	productID := mustFindProductIDBySKU(t, db, "SOF-HVNS-BEI")
+
+	resp := performJSONRequest(t, app, http.MethodPost, "/api/orders", map[string]any{
+		"customer": "Jane Doe",
+		"email":    "jane@example.com",
+		"address":  "Ocean Avenue",
+		"items": []map[string]any{
+			{
+				"product":  map[string]any{"id": productID},
+				"quantity": 1,
+			},
+		},
+	}, nil)
+
+	if resp.StatusCode != http.StatusCreated {
+		body, _ := io.ReadAll(resp.Body)
+		t.Fatalf("expected 201, got %d: %s", resp.StatusCode, string(body))
+	}
+
+	var product models.Product
+	if err := db.First(&product, "id = ?", productID).Error; err != nil {
+		t.Fatalf("fetch product: %v", err)
+	}
+	if product.StockQty != 11 {
+		t.Fatalf("expected stock to decrease to 11, got %d", product.StockQty)
 	}
 }
 
+func TestForecastAccessibleByExecutive(t *testing.T) {
+	app, _ := setupTestApp(t)
+	executiveToken := loginAndGetToken(t, app, "executive@maison.co", "executive123")
+
+	resp := performJSONRequest(t, app, http.MethodGet, "/api/forecast?months=3", nil, map[string]string{
+		"Authorization": "Bearer " + executiveToken,
+	})
+
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected 200, got %d", resp.StatusCode)
+	}
+}
+
+func TestNonAdminCannotBlockUser(t *testing.T) {
+	app, db := setupTestApp(t)
+	managerToken := loginAndGetToken(t, app, "manager@maison.co", "manager123")
+	userID := mustFindUserIDByEmail(t, db, "warehouse@maison.co")
+
+	resp := performJSONRequest(t, app, http.MethodPatch, "/api/users/"+userID+"/block", map[string]any{
+		"is_blocked": true,
+	}, map[string]string{
+		"Authorization": "Bearer " + managerToken,
+	})
+
+	if resp.StatusCode != http.StatusForbidden {
+		t.Fatalf("expected 403, got %d", resp.StatusCode)
+	}
+}
+
+var mailedTokenPattern = regexp.MustCompile(`\?token=([A-Za-z0-9_-]+)`)
+
+// lastMailedToken returns the token of the newest link mailed to an address
+// through the FileMailer in dir.
+func lastMailedToken(t *testing.T, dir, to string) string {
+	t.Helper()
+	files, _ := filepath.Glob(filepath.Join(dir, "*-"+to+".eml"))
+	if len(files) == 0 {
+		t.Fatalf("expected mail to %s", to)
+	}
+	data, err := os.ReadFile(files[len(files)-1])
+	if err != nil {
+		t.Fatalf("read mail: %v", err)
+	}
+	match := mailedTokenPattern.FindStringSubmatch(string(data))
+	if match == nil {
+		t.Fatalf("expected a token link in mail %s", string(data))
+	}
+	return match[1]
+}
+
+// markEmailVerified stands in for following the verification link.
+func markEmailVerified(t *testing.T, db *gorm.DB, email string) {
+	t.Helper()
+	if err := db.Model(&models.User{}).Where("email = ?", email).Update("email_verified_at", time.Now().UTC()).Error; err != nil {
+		t.Fatalf("verify email: %v", err)
+	}
+}
+
+func TestPasswordResetAndEmailVerification(t *testing.T) {
+	mailDir := t.TempDir()
+	t.Setenv("MAIL_DIR", mailDir)
+	app, db := setupTestApp(t)
+
+	resp := performJSONRequest(t, app, http.MethodPost, "/api/auth/signup", map[string]string{
+		"email": "reset@example.com", "password": "client123", "name": "Reset Client",
+	}, nil)
+	if resp.StatusCode != http.StatusCreated {
+		t.Fatalf("expected 201 signup, got %d", resp.StatusCode)
+	}
+	verified := func() bool {
+		var user models.User
+		db.First(&user, "email = ?", "reset@example.com")
+		return user.EmailVerifiedAt != nil
+	}
+	if verified() {
+		t.Fatalf("expected a new client to start unverified")
+	}
+
+	verifyToken := lastMailedToken(t, mailDir, "reset@example.com")
+	if resp := performJSONRequest(t, app, http.MethodPost, "/api/auth/verify-email", map[string]string{"token": verifyToken}, nil); resp.StatusCode != http.StatusNoContent {
+		t.Fatalf("expected email verification to succeed, got %d", resp.StatusCode)
+	}
+	if !verified() {
+		t.Fatalf("expected email to be verified")
+	}
+	if resp := performJSONRequest(t, app, http.MethodPost, "/api/auth/verify-email", map[string]string{"token": verifyToken}, nil); resp.StatusCode != http.StatusBadRequest {
+		t.Fatalf("expected verification token to be single-use, got %d", resp.StatusCode)
+	}
+
+	// Unknown addresses get the same answer and no mail.
+	if resp := performJSONRequest(t, app, http.MethodPost, "/api/auth/password/forgot", map[string]string{"email": "nobody@example.com"}, nil); resp.StatusCode != http.StatusAccepted {
+		t.Fatalf("expected 202 for unknown email, got %d", resp.StatusCode)
+	}
+	if files, _ := filepath.Glob(filepath.Join(mailDir, "*nobody@example.com.eml")); len(files) != 0 {
+		t.Fatalf("expected no mail for unknown email")
+	}
+
+	session := loginAndGetToken(t, app, "reset@example.com", "client123")
+	if resp := performJSONRequest(t, app, http.MethodPost, "/api/auth/password/forgot", map[string]string{"email": "Reset@Example.com"}, nil); resp.StatusCode != http.StatusAccepted {
+		t.Fatalf("expected 202, got %d", resp.StatusCode)
+	}
+	staleToken := lastMailedToken(t, mailDir, "reset@example.com")
+	performJSONRequest(t, app, http.MethodPost, "/api/auth/password/forgot", map[string]string{"email": "reset@example.com"}, nil)
+	resetToken := lastMailedToken(t, mailDir, "reset@example.com")
+	if resetToken == staleToken {
+		t.Fatalf("expected a new reset token per request")
+	}
+	if resp := performJSONRequest(t, app, http.MethodPost, "/api/auth/password/reset", map[string]string{"token": staleToken, "password": "stale1234"}, nil); resp.StatusCode != http.StatusBadRequest {
+		t.Fatalf("expected an earlier reset link to stop working, got %d", resp.StatusCode)
+	}
+	if resp := performJSONRequest(t, app, http.MethodPost, "/api/auth/password/reset", map[string]string{"token": verifyToken, "password": "stale1234"}, nil); resp.StatusCode != http.StatusBadRequest {
+		t.Fatalf("expected a verification token not to reset passwords, got %d", resp.StatusCode)
+	}
+	if resp := performJSONRequest(t, app, http.MethodPost, "/api/auth/password/reset", map[string]string{"token": resetToken, "password": "newpass123"}, nil); resp.StatusCode != http.StatusNoContent {
+		t.Fatalf("expected password reset to succeed, got %d", resp.StatusCode)
+	}
+	if resp := performJSONRequest(t, app, http.MethodPost, "/api/auth/password/reset", map[string]string{"token": resetToken, "password": "again1234"}, nil); resp.StatusCode != http.StatusBadRequest {
+		t.Fatalf("expected reset token to be single-use, got %d", resp.StatusCode)
+	}
+
+	if resp := performJSONRequest(t, app, http.MethodGet, "/api/auth/me", nil, map[string]string{"Authorization": "Bearer " + session}); resp.StatusCode != http.StatusUnauthorized {
+		t.Fatalf("expected sessions to be revoked by the reset, got %d", resp.StatusCode)
+	}
+	if resp := performJSONRequest(t, app, http.MethodPost, "/api/auth/login", map[string]string{"email": "reset@example.com", "password": "client123"}, nil); resp.StatusCode != http.StatusUnauthorized {
+		t.Fatalf("expected old password to be rejected, got %d", resp.StatusCode)
+	}
+	loginAndGetToken(t, app, "reset@example.com", "newpass123")
+
+	// Expired links are rejected.
+	performJSONRequest(t, app, http.MethodPost, "/api/auth/password/forgot", map[string]string{"email": "reset@example.com"}, nil)
+	expired := lastMailedToken(t, mailDir, "reset@example.com")
+	db.Model(&models.AccountToken{}).Where("used_at IS NULL").Update("expires_at", time.Now().UTC().Add(-time.Minute))
+	if resp := performJSONRequest(t, app, http.MethodPost, "/api/auth/password/reset", map[string]string{"token": expired, "password": "late12345"}, nil); resp.StatusCode != http.StatusBadRequest {
+		t.Fatalf("expected an expired reset token to be rejected, got %d", resp.StatusCode)
+	}
+}
+
+func TestClientCanSignupAndViewOwnOrders(t *testing.T) {
+	mailDir := t.TempDir()
+	t.Setenv("MAIL_DIR", mailDir)
+	app, db := setupTestApp(t)
+	productID := mustFindProductIDBySKU(t, db, "CHR-ARIA-TER")
+
+	signupResp := performJSONRequest(t, app, http.MethodPost, "/api/auth/signup", map[string]string{
+		"email":    "client@example.com",
+		"password": "client123",
+		"name":     "Client User",
+	}, nil)
+	if signupResp.StatusCode != http.StatusCreated {
+		body, _ := io.ReadAll(signupResp.Body)
+		t.Fatalf("expected 201 signup, got %d: %s", signupResp.StatusCode, string(body))
+	}
+
+	token := loginAndGetToken(t, app, "client@example.com", "client123")
+
+	orderResp := performJSONRequest(t, app, http.MethodPost, "/api/orders", map[string]any{
+		"customer": "Client User",
+		"email":    "client@example.com",
+		"address":  "Client Street",
+		"items": []map[string]any{
+			{
+				"product":  map[string]any{"id": productID},
+				"quantity": 1,
+			},
+		},
+	}, nil)
+	if orderResp.StatusCode != http.StatusCreated {
+		body, _ := io.ReadAll(orderResp.Body)
+		t.Fatalf("expected 201 order, got %d: %s", orderResp.StatusCode, string(body))
+	}
+
+	// Orders are matched by email, so they stay hidden until the client
+	// proves the address is theirs.
+	mineResp := performJSONRequest(t, app, http.MethodGet, "/api/orders/my", nil, map[string]string{
+		"Authorization": "Bearer " + token,
+	})
+	if mineResp.StatusCode != http.StatusForbidden {
+		t.Fatalf("expected 403 for own orders before verification, got %d", mineResp.StatusCode)
+	}
+	verifyToken := lastMailedToken(t, mailDir, "client@example.com")
+	if resp := performJSONRequest(t, app, http.MethodPost, "/api/auth/verify-email", map[string]string{"token": verifyToken}, nil); resp.StatusCode != http.StatusNoContent {
+		t.Fatalf("expected email verification to succeed, got %d", resp.StatusCode)
+	}
+
+	mineResp = performJSONRequest(t, app, http.MethodGet, "/api/orders/my", nil, map[string]string{
+		"Authorization": "Bearer " + token,
+	})
+	if mineResp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(mineResp.Body)
+		t.Fatalf("expected 200 for own orders, got %d: %s", mineResp.StatusCode, string(body))
+	}
+
+	var orders []models.OrderResponse
+	if err := json.NewDecoder(mineResp.Body).Decode(&orders); err != nil {
+		t.Fatalf("decode orders: %v", err)
+	}
+	if len(orders) != 1 {
+		t.Fatalf("expected 1 own order, got %d", len(orders))
+	}
+	if orders[0].Email != "client@example.com" {
+		t.Fatalf("expected client order email, got %s", orders[0].Email)
+	}
+}
+
+func TestProductImportDryRunAndJob(t *testing.T) {
+	app, db := setupTestApp(t)
+	managerToken := loginAndGetToken(t, app, "manager@maison.co", "manager123")
+
+	csvData := "sku,name,category,price,image,stock\n" +
+		"SOF-HVNS-BEI,Модульный диван «Гавань»,Гостиная,59990,/images/prod-sofa-1.jpg,20\n" +
+		"IMP-NEW-001,Imported Stool,Столовая,2500,/images/stool.jpg,5\n" +
+		"IMP-BAD-002,Broken,Unknown,100,/images/x.jpg,1\n" +
+		"imp-new-001,Imported Stool Copy,Столовая,9999,/images/stool.jpg,1\n"
+
+	resp := performMultipartRequest(t, app, "/api/products/import?dry_run=true", "products.csv", []byte(csvData), managerToken)
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		t.Fatalf("expected 200 dry run, got %d: %s", resp.StatusCode, string(body))
+	}
+	var report models.ProductImportReport
+	if err := json.NewDecoder(resp.Body).Decode(&report); err != nil {
+		t.Fatalf("decode report: %v", err)
+	}
+	if report.Total != 4 || report.Create != 1 || report.Update != 1 || len(report.Errors) != 2 || report.Errors[0].Row != 4 || report.Errors[1].Row != 5 {
+		t.Fatalf("unexpected dry run report: %+v", report)
+	}
+
+	job := runProductImport(t, app, managerToken, csvData)
+	if job.Created != 1 || job.Updated != 1 || job.Failed != 2 || job.Processed != 4 {
+		t.Fatalf("unexpected job result: %+v", job)
+	}
+	var stool models.Product
+	if err := db.First(&stool, "sku = ?", "IMP-NEW-001").Error; err != nil || stool.Price != 2500 {
+		t.Fatalf("expected the duplicate row not to overwrite the first, got %d: %v", stool.Price, err)
+	}
+
+	var product models.Product
+	if err := db.First(&product, "sku = ?", "SOF-HVNS-BEI").Error; err != nil {
+		t.Fatalf("fetch product: %v", err)
+	}
+	if product.Price != 59990 || product.StockQty != 20 {
+		t.Fatalf("expected upserted price and stock, got %d / %d", product.Price, product.StockQty)
+	}
+	var movement models.StockMovement
+	if err := db.First(&movement, "product_id = ? AND reference = ?", product.ID, job.ID).Error; err != nil || movement.Delta != 8 || movement.Reason != models.StockMovementImport {
+		t.Fatalf("expected the stock change to be booked as an import movement, got %+v: %v", movement, err)
+	}
+
+	resp = performJSONRequest(t, app, http.MethodGet, "/api/products/export?format=csv", nil, map[string]string{
+		"Authorization": "Bearer " + managerToken,
+	})
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected 200 export, got %d", resp.StatusCode)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	if !bytes.Contains(body, []byte("IMP-NEW-001")) {
+		t.Fatalf("expected imported product in export")
+	}
+}
+
+// runProductImport starts an import of a CSV file and waits for the job to
+// finish.
+func runProductImport(t *testing.T, app *fiber.App, token, csvData string) models.ProductImportJob {
+	t.Helper()
+	resp := performMultipartRequest(t, app, "/api/products/import", "products.csv", []byte(csvData), token)
+	if resp.StatusCode != http.StatusAccepted {
+		body, _ := io.ReadAll(resp.Body)
+		t.Fatalf("expected 202, got %d: %s", resp.StatusCode, string(body))
+	}
+	var job models.ProductImportJob
+	if err := json.NewDecoder(resp.Body).Decode(&job); err != nil {
+		t.Fatalf("decode job: %v", err)
+	}
+
+	deadline := time.Now().Add(5 * time.Second)
+	for job.Status != models.ImportJobCompleted && job.Status != models.ImportJobFailed {
+		if time.Now().After(deadline) {
+			t.Fatalf("import job did not finish, last status %s", job.Status)
+		}
+		time.Sleep(20 * time.Millisecond)
+		resp = performJSONRequest(t, app, http.MethodGet, "/api/products/import/"+job.ID, nil, map[string]string{
+			"Authorization": "Bearer " + token,
+		})
+		if err := json.NewDecoder(resp.Body).Decode(&job); err != nil {
+			t.Fatalf("decode job: %v", err)
+		}
+	}
+	return job
+}
+
+func TestProductImportKeepsFieldsTheFileLacks(t *testing.T) {
+	app, db := setupTestApp(t)
+	managerToken := loginAndGetToken(t, app, "manager@maison.co", "manager123")
+	if err := db.Model(&models.Product{}).Where("sku = ?", "LMP-SOLB-BRS").Updates(map[string]any{
+		"barcode": "4006381333931", "barcode_type": models.BarcodeEAN13, "is_active": false, "reorder_point": 5,
+	}).Error; err != nil {
+		t.Fatalf("prepare product: %v", err)
+	}
+
+	job := runProductImport(t, app, managerToken, "sku,name,category,price,image\n"+
+		"LMP-SOLB-BRS,Торшер «Солей» II,Освещение,4200,/images/prod-lamp-1.jpg\n")
+	if job.Updated != 1 || job.Failed != 0 {
+		t.Fatalf("unexpected job result: %+v", job)
+	}
+	var lamp models.Product
+	if err := db.First(&lamp, "sku = ?", "LMP-SOLB-BRS").Error; err != nil {
+		t.Fatalf("fetch product: %v", err)
+	}
+	if lamp.Name != "Торшер «Солей» II" || lamp.Price != 4200 {
+		t.Fatalf("expected the file's name and price, got %q / %d", lamp.Name, lamp.Price)
+	}
+	if lamp.StockQty != 42 || lamp.Barcode != "4006381333931" || lamp.Location != "C-01" || lamp.Material != "Латунь" || lamp.ReorderPoint != 5 || lamp.IsActive {
+		t.Fatalf("expected the fields the file lacks to be kept, got %+v", lamp)
+	}
+	var movements int64
+	db.Model(&models.StockMovement{}).Where("product_id = ?", lamp.ID).Count(&movements)
+	if movements != 0 {
+		t.Fatalf("expected no stock movement without a stock column, got %d", movements)
+	}
+}
+
+func TestPriceHistoryAndScheduledSale(t *testing.T) {
+	app, db := setupTestApp(t)
+	managerToken := loginAndGetToken(t, app, "manager@maison.co", "manager123")
+	productID := mustFindProductIDBySKU(t, db, "CHR-ARIA-TER")
+	auth := map[string]string{"Authorization": "Bearer " + managerToken}
+
+	resp := performJSONRequest(t, app, http.MethodPost, "/api/products/"+productID+"/prices/schedule", map[string]any{
+		"price":         9990,
+		"originalPrice": 14990,
+		"starts_at":     time.Now().Add(-time.Minute).UTC().Format(time.RFC3339),
+		"ends_at":       time.Now().Add(time.Hour).UTC().Format(time.RFC3339),
+	}, auth)
+	if resp.StatusCode != http.StatusCreated {
+		body, _ := io.ReadAll(resp.Body)
+		t.Fatalf("expected 201 schedule, got %d: %s", resp.StatusCode, string(body))
+	}
+	var scheduled models.ScheduledPrice
+	if err := json.NewDecoder(resp.Body).Decode(&scheduled); err != nil {
+		t.Fatalf("decode schedule: %v", err)
+	}
+	if scheduled.StartsAt.Before(time.Now().Add(-10 * time.Second)) {
+		t.Fatalf("expected past start clamped to now, got %s", scheduled.StartsAt)
+	}
+
+	prices := services.NewPriceService(repositories.NewProductRepository(db), repositories.NewPriceRepository(db))
+	if applied, err := prices.ApplyDue(time.Now().UTC()); err != nil || applied != 1 {
+		t.Fatalf("expected one schedule applied, got %d: %v", applied, err)
+	}
+
+	var product models.Product
+	if err := db.First(&product, "id = ?", productID).Error; err != nil {
+		t.Fatalf("fetch product: %v", err)
+	}
+	if product.Price != 9990 {
+		t.Fatalf("expected sale price 9990, got %d", product.Price)
+	}
+
+	if _, err := prices.ApplyDue(time.Now().Add(2 * time.Hour).UTC()); err != nil {
+		t.Fatalf("apply due: %v", err)
+	}
+	if err := db.First(&product, "id = ?", productID).Error; err != nil {
+		t.Fatalf("fetch product: %v", err)
+	}
+	if product.Price != 14990 || product.OriginalPrice != nil {
+		t.Fatalf("expected price restored to 14990 without original price, got %d", product.Price)
+	}
+
+	resp = performJSONRequest(t, app, http.MethodGet, "/api/products/"+productID+"/prices", nil, auth)
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected 200 prices, got %d", resp.StatusCode)
+	}
+	var history models.ProductPricesResponse
+	if err := json.NewDecoder(resp.Body).Decode(&history); err != nil {
+		t.Fatalf("decode prices: %v", err)
+	}
+	if len(history.History) != 3 {
+		t.Fatalf("expected seed, sale and restore history entries, got %d", len(history.History))
+	}
+	if len(history.Scheduled) != 1 || history.Scheduled[0].Status != models.ScheduledPriceCompleted {
+		t.Fatalf("expected completed schedule, got %+v", history.Scheduled)
+	}
+
+	forecasts := repositories.NewForecastRepository(db)
+	trends, err := forecasts.CategoryPriceTrends(time.Now().Add(time.Hour).UTC())
+	if err != nil {
+		t.Fatalf("price trends: %v", err)
+	}
+	if trends[product.CategoryID] <= 0 {
+		t.Fatalf("expected rising trend measured from the sale price, got %v", trends[product.CategoryID])
+	}
+	trends, err = forecasts.CategoryPriceTrends(time.Now().Add(3 * time.Hour).UTC())
+	if err != nil {
+		t.Fatalf("price trends: %v", err)
+	}
+	if trends[product.CategoryID] != 0 {
+		t.Fatalf("expected flat trend after the restore, got %v", trends[product.CategoryID])
+	}
+}
+
+func TestReviewsRequireDeliveredOrderAndRecomputeRating(t *testing.T) {
+	app, db := setupTestApp(t)
+	managerToken := loginAndGetToken(t, app, "manager@maison.co", "manager123")
+	productID := mustFindProductIDBySKU(t, db, "DSK-STUD-WHT")
+
+	signupResp := performJSONRequest(t, app, http.MethodPost, "/api/auth/signup", map[string]string{
+		"email":    "reviewer@example.com",
+		"password": "client123",
+		"name":     "Reviewer",
+	}, nil)
+	if signupResp.StatusCode != http.StatusCreated {
+		t.Fatalf("expected 201 signup, got %d", signupResp.StatusCode)
+	}
+	markEmailVerified(t, db, "reviewer@example.com")
+	clientToken := loginAndGetToken(t, app, "reviewer@example.com", "client123")
+	clientAuth := map[string]string{"Authorization": "Bearer " + clientToken}
+	review := map[string]any{"rating": 4, "comment": "Solid desk"}
+
+	resp := performJSONRequest(t, app, http.MethodPost, "/api/products/"+productID+"/reviews", review, clientAuth)
+	if resp.StatusCode != http.StatusForbidden {
+		t.Fatalf("expected 403 without delivered order, got %d", resp.StatusCode)
+	}
+
+	orderResp := performJSONRequest(t, app, http.MethodPost, "/api/orders", map[string]any{
+		"customer": "Reviewer",
+		"email":    "reviewer@example.com",
+		"address":  "Review Street",
+		"items":    []map[string]any{{"product": map[string]any{"id": productID}, "quantity": 1}},
+	}, nil)
+	var order models.OrderResponse
+	if err := json.NewDecoder(orderResp.Body).Decode(&order); err != nil {
+		t.Fatalf("decode order: %v", err)
+	}
+	statusResp := performJSONRequest(t, app, http.MethodPatch, "/api/orders/"+order.ID+"/status", map[string]any{"status": "delivered"}, map[string]string{
+		"Authorization": "Bearer " + managerToken,
+	})
+	if statusResp.StatusCode != http.StatusOK {
+		t.Fatalf("expected 200 status update, got %d", statusResp.StatusCode)
+	}
+
+	resp = performJSONRequest(t, app, http.MethodPost, "/api/products/"+productID+"/reviews", review, clientAuth)
+	if resp.StatusCode != http.StatusCreated {
+		body, _ := io.ReadAll(resp.Body)
+		t.Fatalf("expected 201 review, got %d: %s", resp.StatusCode, string(body))
+	}
+	var created models.Review
+	if err := json.NewDecoder(resp.Body).Decode(&created); err != nil {
+		t.Fatalf("decode review: %v", err)
+	}
+
+	resp = performJSONRequest(t, app, http.MethodPatch, "/api/reviews/"+created.ID+"/moderation", map[string]any{"status": "approved"}, map[string]string{
+		"Authorization": "Bearer " + managerToken,
+	})
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected 200 moderation, got %d", resp.StatusCode)
+	}
+
+	var product models.Product
+	if err := db.First(&product, "id = ?", productID).Error; err != nil {
+		t.Fatalf("fetch product: %v", err)
+	}
+	if product.Rating != 4 || product.Reviews != 1 {
+		t.Fatalf("expected rating 4 from 1 review, got %.1f from %d", product.Rating, product.Reviews)
+	}
+}
+
+func TestArchivedProductStaysInOrderHistoryAndCanBeRestored(t *testing.T) {
+	app, db := setupTestApp(t)
+	adminToken := loginAndGetToken(t, app, "admin@maison.co", "admin123")
+	adminAuth := map[string]string{"Authorization": "Bearer " + adminToken}
+	productID := mustFindProductIDBySKU(t, db, "SOF-HVNS-BEI")
+
+	resp := performJSONRequest(t, app, http.MethodDelete, "/api/products/"+productID, nil, adminAuth)
+	if resp.StatusCode != http.StatusNoContent {
+		t.Fatalf("expected 204 archive, got %d", resp.StatusCode)
+	}
+
+	resp = performJSONRequest(t, app, http.MethodGet, "/api/products/"+productID, nil, nil)
+	if resp.StatusCode != http.StatusNotFound {
+		t.Fatalf("expected archived product to be hidden, got %d", resp.StatusCode)
+	}
+
+	resp = performJSONRequest(t, app, http.MethodGet, "/api/orders", nil, adminAuth)
+	var orders []models.OrderResponse
+	if err := json.NewDecoder(resp.Body).Decode(&orders); err != nil {
+		t.Fatalf("decode orders: %v", err)
+	}
+	found := false
+	for _, order := range orders {
+		for _, item := range order.Items {
+			if item.Product.ID == productID && item.Product.Name != "" {
+				found = true
+			}
+		}
+	}
+	if !found {
+		t.Fatalf("expected archived product to stay resolvable from orders")
+	}
+
+	resp = performJSONRequest(t, app, http.MethodPost, "/api/products/"+productID+"/restore", nil, adminAuth)
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected 200 restore, got %d", resp.StatusCode)
+	}
+	resp = performJSONRequest(t, app, http.MethodGet, "/api/products/"+productID, nil, nil)
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected restored product to be visible, got %d", resp.StatusCode)
+	}
+}
+
+func TestPublicProductListHidesInactiveProducts(t *testing.T) {
+	app, db := setupTestApp(t)
+	managerToken := loginAndGetToken(t, app, "manager@maison.co", "manager123")
+	productID := mustFindProductIDBySKU(t, db, "LMP-SOLB-BRS")
+	if err := db.Model(&models.Product{}).Where("id = ?", productID).Update("is_active", false).Error; err != nil {
+		t.Fatalf("deactivate product: %v", err)
+	}
+
+	containsProduct := func(headers map[string]string) bool {
+		resp := performJSONRequest(t, app, http.MethodGet, "/api/products", nil, headers)
+		var products []models.Product
+		if err := json.NewDecoder(resp.Body).Decode(&products); err != nil {
+			t.Fatalf("decode products: %v", err)
+		}
+		for _, p := range products {
+			if p.ID == productID {
+				return true
+			}
+		}
+		return false
+	}
+
+	if containsProduct(nil) {
+		t.Fatalf("expected inactive product to be hidden from public listing")
+	}
+	if !containsProduct(map[string]string{"Authorization": "Bearer " + managerToken}) {
+		t.Fatalf("expected inactive product to be listed for managers")
+	}
+}
+
+func TestPublicCatalogHidesStockWhileStaffSeesIt(t *testing.T) {
+	app, db := setupTestApp(t)
+	managerToken := loginAndGetToken(t, app, "manager@maison.co", "manager123")
+	productID := mustFindProductIDBySKU(t, db, "TBL-STRW-WAL")
+
+	resp := performJSONRequest(t, app, http.MethodGet, "/api/products/"+productID, nil, nil)
+	var public map[string]any
+	if err := json.NewDecoder(resp.Body).Decode(&public); err != nil {
+		t.Fatalf("decode public product: %v", err)
+	}
+	if _, ok := public["stock"]; ok {
+		t.Fatalf("expected public view without exact stock")
+	}
+	if public["availability"] != string(models.AvailabilityInStock) {
+		t.Fatalf("expected in_stock availability, got %v", public["availability"])
+	}
+
+	resp = performJSONRequest(t, app, http.MethodGet, "/api/products/"+productID, nil, map[string]string{
+		"Authorization": "Bearer " + managerToken,
+	})
+	var staff map[string]any
+	if err := json.NewDecoder(resp.Body).Decode(&staff); err != nil {
+		t.Fatalf("decode staff product: %v", err)
+	}
+	if staff["stock"] != float64(8) {
+		t.Fatalf("expected staff view with stock 8, got %v", staff["stock"])
+	}
+
+	// The staff view follows read:stock, not a fixed list of roles, and API
+	// keys get it with that scope.
+	stockOf := func(headers map[string]string) any {
+		t.Helper()
+		resp := performJSONRequest(t, app, http.MethodGet, "/api/products/"+productID, nil, headers)
+		var product map[string]any
+		if err := json.NewDecoder(resp.Body).Decode(&product); err != nil {
+			t.Fatalf("decode product: %v", err)
+		}
+		return product["stock"]
+	}
+	if stock := stockOf(map[string]string{"Authorization": "Bearer " + loginAndGetToken(t, app, "warehouse@maison.co", "warehouse123")}); stock != float64(8) {
+		t.Fatalf("expected warehouse staff to see stock 8, got %v", stock)
+	}
+	adminHeaders := map[string]string{"Authorization": "Bearer " + loginAndGetToken(t, app, "admin@maison.co", "admin123")}
+	newKey := func(scope string) string {
+		t.Helper()
+		resp := performJSONRequest(t, app, http.MethodPost, "/api/api-keys", map[string]any{"name": "catalog-" + scope, "scopes": []string{scope}}, adminHeaders)
+		var created models.APIKeyCreated
+		if err := json.NewDecoder(resp.Body).Decode(&created); err != nil || created.Key == "" {
+			t.Fatalf("expected an api key with %s, got %d %v", scope, resp.StatusCode, err)
+		}
+		return created.Key
+	}
+	if stock := stockOf(map[string]string{"X-API-Key": newKey("read:stock")}); stock != float64(8) {
+		t.Fatalf("expected a read:stock key to see stock 8, got %v", stock)
+	}
+	if stock := stockOf(map[string]string{"X-API-Key": newKey("read:order")}); stock != nil {
+		t.Fatalf("expected a key without read:stock to get the storefront view, got stock %v", stock)
+	}
+}
+
+func TestBundleStockIsDerivedFromComponents(t *testing.T) {
+	app, db := setupTestApp(t)
+	managerToken := loginAndGetToken(t, app, "manager@maison.co", "manager123")
+	managerAuth := map[string]string{"Authorization": "Bearer " + managerToken}
+	sofaID := mustFindProductIDBySKU(t, db, "SOF-HVNS-BEI")
+	rugID := mustFindProductIDBySKU(t, db, "RUG-MRKW-CRM")
+
+	resp := performJSONRequest(t, app, http.MethodPost, "/api/products", map[string]any{
+		"type":     "bundle",
+		"name":     "Комплект для гостиной",
+		"sku":      "SET-LIVING-01",
+		"category": "Гостиная",
+		"price":    65000,
+		"image":    "/images/prod-sofa-1.jpg",
+		"components": []map[string]any{
+			{"product_id": sofaID, "quantity": 1},
+			{"product_id": rugID, "quantity": 2},
+		},
+	}, managerAuth)
+	if resp.StatusCode != http.StatusCreated {
+		body, _ := io.ReadAll(resp.Body)
+		t.Fatalf("expected 201, got %d: %s", resp.StatusCode, string(body))
+	}
+	var bundle models.Product
+	if err := json.NewDecoder(resp.Body).Decode(&bundle); err != nil {
+		t.Fatalf("decode bundle: %v", err)
+	}
+	if bundle.Stock != 9 {
+		t.Fatalf("expected 9 complete sets from components, got %d", bundle.Stock)
+	}
+	if bundle.ComponentsPrice != 56990+2*6500 {
+		t.Fatalf("expected components price %d, got %d", 56990+2*6500, bundle.ComponentsPrice)
+	}
+
+	order := func(qty int) *http.Response {
+		return performJSONRequest(t, app, http.MethodPost, "/api/orders", map[string]any{
+			"customer": "Jane Doe",
+			"email":    "jane@example.com",
+			"address":  "Bundle Street",
+			"items":    []map[string]any{{"product": map[string]any{"id": bundle.ID}, "quantity": qty}},
+		}, nil)
+	}
+	stockOf := func(id string) int {
+		var product models.Product
+		if err := db.First(&product, "id = ?", id).Error; err != nil {
+			t.Fatalf("fetch product: %v", err)
+		}
+		return product.StockQty
+	}
+
+	if resp := order(2); resp.StatusCode != http.StatusCreated {
+		body, _ := io.ReadAll(resp.Body)
+		t.Fatalf("expected 201, got %d: %s", resp.StatusCode, string(body))
+	}
+	if stockOf(sofaID) != 10 || stockOf(rugID) != 14 {
+		t.Fatalf("expected component stock 10/14, got %d/%d", stockOf(sofaID), stockOf(rugID))
+	}
+
+	if resp := order(8); resp.StatusCode != http.StatusBadRequest {
+		t.Fatalf("expected 400 for insufficient component stock, got %d", resp.StatusCode)
+	}
+	if stockOf(sofaID) != 10 || stockOf(rugID) != 14 {
+		t.Fatalf("expected failed order to leave stock untouched, got %d/%d", stockOf(sofaID), stockOf(rugID))
+	}
+
+	resp = performJSONRequest(t, app, http.MethodGet, "/api/products/"+bundle.ID, nil, managerAuth)
+	if err := json.NewDecoder(resp.Body).Decode(&bundle); err != nil {
+		t.Fatalf("decode bundle: %v", err)
+	}
+	if bundle.Stock != 7 {
+		t.Fatalf("expected 7 sets after order, got %d", bundle.Stock)
+	}
+
+	if err := db.Model(&models.Product{}).Where("id = ?", rugID).Update("is_active", false).Error; err != nil {
+		t.Fatalf("deactivate rug: %v", err)
+	}
+	resp = performJSONRequest(t, app, http.MethodPost, "/api/products", map[string]any{
+		"type":       "bundle",
+		"name":       "Комплект с ковром",
+		"sku":        "SET-LIVING-02",
+		"category":   "Гостиная",
+		"price":      60000,
+		"image":      "/images/prod-sofa-1.jpg",
+		"components": []map[string]any{{"product_id": sofaID, "quantity": 1}, {"product_id": rugID, "quantity": 1}},
+	}, managerAuth)
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Fatalf("expected 400 for inactive component, got %d", resp.StatusCode)
+	}
+}
+
+func TestRelatedProductsCombineManualLinksAndOrderHistory(t *testing.T) {
+	app, db := setupTestApp(t)
+	managerToken := loginAndGetToken(t, app, "manager@maison.co", "manager123")
+	managerAuth := map[string]string{"Authorization": "Bearer " + managerToken}
+	sofaID := mustFindProductIDBySKU(t, db, "SOF-HVNS-BEI")
+	rugID := mustFindProductIDBySKU(t, db, "RUG-MRKW-CRM")
+	lampID := mustFindProductIDBySKU(t, db, "LMP-SOLB-BRS")
+
+	resp := performJSONRequest(t, app, http.MethodPost, "/api/products/"+sofaID+"/related", map[string]any{"product_id": lampID}, managerAuth)
+	if resp.StatusCode != http.StatusCreated {
+		body, _ := io.ReadAll(resp.Body)
+		t.Fatalf("expected 201 link, got %d: %s", resp.StatusCode, string(body))
+	}
+
+	for i := 0; i < 2; i++ {
+		resp = performJSONRequest(t, app, http.MethodPost, "/api/orders", map[string]any{
+			"customer": "Jane Doe",
+			"email":    "jane@example.com",
+			"address":  fmt.Sprintf("Set Street %d", i),
+			"items": []map[string]any{
+				{"product": map[string]any{"id": sofaID}, "quantity": 1},
+				{"product": map[string]any{"id": rugID}, "quantity": 1},
+			},
+		}, nil)
+		if resp.StatusCode != http.StatusCreated {
+			t.Fatalf("expected 201 order, got %d", resp.StatusCode)
+		}
+	}
+
+	resp = performJSONRequest(t, app, http.MethodPost, "/api/recommendations/recompute", nil, managerAuth)
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected 200 recompute, got %d", resp.StatusCode)
+	}
+
+	resp = performJSONRequest(t, app, http.MethodGet, "/api/products/"+sofaID+"/related", nil, nil)
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected 200 related, got %d", resp.StatusCode)
+	}
+	var related []models.RelatedProduct
+	if err := json.NewDecoder(resp.Body).Decode(&related); err != nil {
+		t.Fatalf("decode related: %v", err)
+	}
+	if len(related) < 2 {
+		t.Fatalf("expected manual and mined related products, got %+v", related)
+	}
+	if related[0].ID != lampID || related[0].Source != models.RelatedSourceManual {
+		t.Fatalf("expected manual link first, got %+v", related[0])
+	}
+	if related[1].ID != rugID || related[1].Source != models.RelatedSourceBoughtTogether || related[1].Score <= 0 {
+		t.Fatalf("expected rug bought together with sofa, got %+v", related[1])
+	}
+
+	resp = performJSONRequest(t, app, http.MethodPost, "/api/products", map[string]any{
+		"name":     "Диван «Бриз»",
+		"sku":      "SOF-BRIZ-GRY",
+		"category": "Гостиная",
+		"price":    49990,
+		"stock":    5,
+		"image":    "/images/prod-sofa-1.jpg",
+	}, managerAuth)
+	if resp.StatusCode != http.StatusCreated {
+		body, _ := io.ReadAll(resp.Body)
+		t.Fatalf("expected 201 product, got %d: %s", resp.StatusCode, string(body))
+	}
+	resp = performJSONRequest(t, app, http.MethodGet, "/api/products/"+sofaID+"/related", nil, nil)
+	related = nil
+	if err := json.NewDecoder(resp.Body).Decode(&related); err != nil {
+		t.Fatalf("decode related: %v", err)
+	}
+	similar := 0
+	for _, item := range related[2:] {
+		if item.Source != models.RelatedSourceSimilar || item.SKU != "SOF-BRIZ-GRY" {
+			t.Fatalf("expected only the similarly priced living room sofa to fill in, got %+v", item)
+		}
+		similar++
+	}
+	if similar != 1 {
+		t.Fatalf("expected one similar product, got %d", similar)
+	}
+
+	resp = performJSONRequest(t, app, http.MethodDelete, "/api/products/"+sofaID+"/related/"+lampID, nil, managerAuth)
+	if resp.StatusCode != http.StatusNoContent {
+		t.Fatalf("expected 204 unlink, got %d", resp.StatusCode)
+	}
+}
+
+func TestProductContentIsTranslatedWithFallback(t *testing.T) {
+	app, db := setupTestApp(t)
+	managerToken := loginAndGetToken(t, app, "manager@maison.co", "manager123")
+	managerAuth := map[string]string{"Authorization": "Bearer " + managerToken}
+	sofaID := mustFindProductIDBySKU(t, db, "SOF-HVNS-BEI")
+
+	getProduct := func(path string, headers map[string]string) models.CatalogProduct {
+		resp := performJSONRequest(t, app, http.MethodGet, path, nil, headers)
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("expected 200, got %d", resp.StatusCode)
+		}
+		var product models.CatalogProduct
+		if err := json.NewDecoder(resp.Body).Decode(&product); err != nil {
+			t.Fatalf("decode product: %v", err)
+		}
+		return product
+	}
+
+	en := getProduct("/api/products/"+sofaID+"?lang=en", nil)
+	if en.Name != "Haven Modular Sofa" || en.Category != "Living Room" {
+		t.Fatalf("expected english content, got %q / %q", en.Name, en.Category)
+	}
+	if p := getProduct("/api/products/"+sofaID, map[string]string{"Accept-Language": "en-GB,en;q=0.9"}); p.Name != en.Name {
+		t.Fatalf("expected Accept-Language to select english, got %q", p.Name)
+	}
+	ru := getProduct("/api/products/"+sofaID, map[string]string{"Accept-Language": "ru"})
+	if ru.Name != "Модульный диван «Гавань»" {
+		t.Fatalf("expected base content, got %q", ru.Name)
+	}
+
+	resp := performJSONRequest(t, app, http.MethodPut, "/api/products/"+sofaID+"/translations/de", map[string]any{"name": "Modulsofa Haven"}, managerAuth)
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		t.Fatalf("expected 200 translation upsert, got %d: %s", resp.StatusCode, string(body))
+	}
+	de := getProduct("/api/products/"+sofaID+"?lang=de", nil)
+	if de.Name != "Modulsofa Haven" || de.Description != ru.Description {
+		t.Fatalf("expected translated name with base description, got %q / %q", de.Name, de.Description)
+	}
+
+	resp = performJSONRequest(t, app, http.MethodPut, "/api/products/"+sofaID+"/translations/ru", map[string]any{"name": "x"}, managerAuth)
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Fatalf("expected base locale translation to be rejected, got %d", resp.StatusCode)
+	}
+
+	resp = performJSONRequest(t, app, http.MethodGet, "/api/categories?lang=en", nil, nil)
+	var categories []models.Category
+	if err := json.NewDecoder(resp.Body).Decode(&categories); err != nil {
+		t.Fatalf("decode categories: %v", err)
+	}
+	found := false
+	for _, c := range categories {
+		if c.Name == "Living Room" {
+			found = true
+		}
+	}
+	if !found {
+		t.Fatalf("expected translated category names, got %+v", categories)
+	}
+}
+
+func TestPricesAreConvertedAndOrdersKeepCheckoutRate(t *testing.T) {
+	app, db := setupTestApp(t)
+	adminToken := loginAndGetToken(t, app, "admin@maison.co", "admin123")
+	adminAuth := map[string]string{"Authorization": "Bearer " + adminToken}
+	sofaID := mustFindProductIDBySKU(t, db, "SOF-HVNS-BEI")
+
+	resp := performJSONRequest(t, app, http.MethodPut, "/api/exchange-rates", map[string]any{
+		"rates": []map[string]any{{"currency": "USD", "rate": 0.011}},
+	}, adminAuth)
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		t.Fatalf("expected 200 rates, got %d: %s", resp.StatusCode, string(body))
+	}
+
+	resp = performJSONRequest(t, app, http.MethodGet, "/api/products/"+sofaID+"?currency=usd", nil, nil)
+	var product models.CatalogProduct
+	if err := json.NewDecoder(resp.Body).Decode(&product); err != nil {
+		t.Fatalf("decode product: %v", err)
+	}
+	if product.Currency != "USD" || product.Price != 627 || product.OriginalPrice == nil || *product.OriginalPrice != 770 {
+		t.Fatalf("expected USD prices 627/770, got %s %d %v", product.Currency, product.Price, product.OriginalPrice)
+	}
+
+	resp = performJSONRequest(t, app, http.MethodGet, "/api/products?currency=XXX", nil, nil)
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Fatalf("expected 400 for unknown currency, got %d", resp.StatusCode)
+	}
+
+	resp = performJSONRequest(t, app, http.MethodPost, "/api/orders", map[string]any{
+		"customer": "Jane Doe",
+		"email":    "jane@example.com",
+		"address":  "Dollar Street",
+		"currency": "USD",
+		"items":    []map[string]any{{"product": map[string]any{"id": sofaID}, "quantity": 2}},
+	}, nil)
+	if resp.StatusCode != http.StatusCreated {
+		body, _ := io.ReadAll(resp.Body)
+		t.Fatalf("expected 201 order, got %d: %s", resp.StatusCode, string(body))
+	}
+	var order models.OrderResponse
+	if err := json.NewDecoder(resp.Body).Decode(&order); err != nil {
+		t.Fatalf("decode order: %v", err)
+	}
+	if order.CheckoutCurrency != "USD" || order.CheckoutTotal != 1254 || order.Total != 1254 {
+		t.Fatalf("expected USD checkout total 1254, got %+v", order)
+	}
+
+	resp = performJSONRequest(t, app, http.MethodPut, "/api/exchange-rates", map[string]any{
+		"rates": []map[string]any{{"currency": "USD", "rate": 0.02}},
+	}, adminAuth)
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected 200 rates, got %d", resp.StatusCode)
+	}
+
+	resp = performJSONRequest(t, app, http.MethodGet, "/api/orders?currency=USD", nil, adminAuth)
+	var orders []models.OrderResponse
+	if err := json.NewDecoder(resp.Body).Decode(&orders); err != nil {
+		t.Fatalf("decode orders: %v", err)
+	}
+	for _, o := range orders {
+		if o.ID == order.ID && o.Total != 1254 {
+			t.Fatalf("expected order to keep its checkout total, got %d", o.Total)
+		}
+		if o.ID != order.ID && o.CheckoutCurrency == "RUB" && o.Total != (models.ExchangeRate{Rate: 0.02}).Convert(o.CheckoutTotal) {
+			t.Fatalf("expected base orders converted at the current rate, got %+v", o)
+		}
+	}
+}
+
+func TestPurchaseOrderReceiptMovesStock(t *testing.T) {
+	app, db := setupTestApp(t)
+	managerAuth := map[string]string{"Authorization": "Bearer " + loginAndGetToken(t, app, "manager@maison.co", "manager123")}
+	warehouseAuth := map[string]string{"Authorization": "Bearer " + loginAndGetToken(t, app, "warehouse@maison.co", "warehouse123")}
+	lampID := mustFindProductIDBySKU(t, db, "LMP-SOLB-BRS")
+
+	var supplier models.Supplier
+	if err := db.First(&supplier).Error; err != nil {
+		t.Fatalf("fetch supplier: %v", err)
+	}
+	stock := func() int {
+		t.Helper()
+		var product models.Product
+		if err := db.First(&product, "id = ?", lampID).Error; err != nil {
+			t.Fatalf("fetch product: %v", err)
+		}
+		return product.StockQty
+	}
+	decode := func(resp *http.Response, want int) models.PurchaseOrder {
+		t.Helper()
+		if resp.StatusCode != want {
+			body, _ := io.ReadAll(resp.Body)
+			t.Fatalf("expected %d, got %d: %s", want, resp.StatusCode, string(body))
+		}
+		var order models.PurchaseOrder
+		if err := json.NewDecoder(resp.Body).Decode(&order); err != nil {
+			t.Fatalf("decode purchase order: %v", err)
+		}
+		return order
+	}
+
+	order := decode(performJSONRequest(t, app, http.MethodPost, "/api/purchase-orders", map[string]any{
+		"supplier_id": supplier.ID,
+		"lines":       []map[string]any{{"product_id": lampID, "qty": 5, "unit_cost": 4000}},
+	}, managerAuth), http.StatusCreated)
+	if order.Status != models.PurchaseOrderDraft || order.Total != 20000 || order.ExpectedAt == nil {
+		t.Fatalf("expected a draft with total and expected date from lead time, got %+v", order)
+	}
+
+	receipt := func(qty int) *http.Response {
+		return performJSONRequest(t, app, http.MethodPost, "/api/purchase-orders/"+order.ID+"/receive", map[string]any{
+			"lines": []map[string]any{{"product_id": lampID, "qty": qty}},
+		}, warehouseAuth)
+	}
+	if resp := receipt(1); resp.StatusCode != http.StatusBadRequest {
+		t.Fatalf("expected draft receipt to be rejected, got %d", resp.StatusCode)
+	}
+	decode(performJSONRequest(t, app, http.MethodPost, "/api/purchase-orders/"+order.ID+"/send", nil, managerAuth), http.StatusOK)
+
+	before := stock()
+	if order = decode(receipt(2), http.StatusOK); order.Status != models.PurchaseOrderPartiallyReceived {
+		t.Fatalf("expected partially received, got %s", order.Status)
+	}
+	stale := order
+	if resp := receipt(4); resp.StatusCode != http.StatusBadRequest {
+		t.Fatalf("expected receipt beyond the ordered quantity to be rejected, got %d", resp.StatusCode)
+	}
+	if order = decode(receipt(3), http.StatusOK); order.Status != models.PurchaseOrderReceived || order.ReceivedAt == nil {
+		t.Fatalf("expected received with a date, got %+v", order)
+	}
+	if stock() != before+5 {
+		t.Fatalf("expected stock %d, got %d", before+5, stock())
+	}
+
+	// A receipt validated against a read taken before the last one must not
+	// book the same units twice.
+	booked, err := repositories.NewProcurementRepository(db).Receive(stale.ID, stale.Lines, map[uint]int{stale.Lines[0].ID: 3}, "warehouse@maison.co", time.Now().UTC())
+	if err != nil || booked {
+		t.Fatalf("expected stale receipt to be refused, got booked=%v err=%v", booked, err)
+	}
+	if stock() != before+5 {
+		t.Fatalf("expected stale receipt to leave stock at %d, got %d", before+5, stock())
+	}
+
+	var movements []models.StockMovement
+	resp := performJSONRequest(t, app, http.MethodGet, "/api/stock-movements?reference="+order.ID, nil, warehouseAuth)
+	if err := json.NewDecoder(resp.Body).Decode(&movements); err != nil {
+		t.Fatalf("decode movements: %v", err)
+	}
+	if len(movements) != 2 || movements[0].Delta+movements[1].Delta != 5 || movements[0].Reason != models.StockMovementReceipt {
+		t.Fatalf("expected two receipt movements adding 5, got %+v", movements)
+	}
+
+	if resp := performJSONRequest(t, app, http.MethodPost, "/api/purchase-orders", map[string]any{
+		"supplier_id": supplier.ID,
+		"lines":       []map[string]any{{"product_id": lampID, "qty": 1}},
+	}, warehouseAuth); resp.StatusCode != http.StatusForbidden {
+		t.Fatalf("expected warehouse to be unable to draft purchase orders, got %d", resp.StatusCode)
+	}
+}
+
+func TestPurchaseOrderDraftedFromForecast(t *testing.T) {
+	app, db := setupTestApp(t)
+	managerAuth := map[string]string{"Authorization": "Bearer " + loginAndGetToken(t, app, "manager@maison.co", "manager123")}
+
+	var supplier models.Supplier
+	if err := db.First(&supplier).Error; err != nil {
+		t.Fatalf("fetch supplier: %v", err)
+	}
+	resp := performJSONRequest(t, app, http.MethodPost, "/api/purchase-orders/from-forecast", map[string]any{
+		"supplier_id": supplier.ID,
+		"months":      6,
+	}, managerAuth)
+	if resp.StatusCode != http.StatusCreated {
+		body, _ := io.ReadAll(resp.Body)
+		t.Fatalf("expected 201, got %d: %s", resp.StatusCode, string(body))
+	}
+	var order models.PurchaseOrder
+	if err := json.NewDecoder(resp.Body).Decode(&order); err != nil {
+		t.Fatalf("decode purchase order: %v", err)
+	}
+	if order.Status != models.PurchaseOrderDraft || len(order.Lines) == 0 {
+		t.Fatalf("expected a draft with lines, got %+v", order)
+	}
+	for _, line := range order.Lines {
+		if line.Qty <= 0 || line.SKU == "" {
+			t.Fatalf("expected positive quantities of known products, got %+v", line)
+		}
+	}
+
+	resp = performJSONRequest(t, app, http.MethodPost, "/api/purchase-orders/from-forecast", map[string]any{
+		"supplier_id": supplier.ID,
+		"months":      6,
+	}, managerAuth)
+	if resp.StatusCode != http.StatusBadRequest {
+		body, _ := io.ReadAll(resp.Body)
+		t.Fatalf("expected the open draft to cover the forecast, got %d: %s", resp.StatusCode, string(body))
+	}
+}
+
+func TestStockCountPostsVariancesForWarehouse(t *testing.T) {
+	app, db := setupTestApp(t)
+	warehouseAuth := map[string]string{"Authorization": "Bearer " + loginAndGetToken(t, app, "warehouse@maison.co", "warehouse123")}
+	lampID := mustFindProductIDBySKU(t, db, "LMP-SOLB-BRS")
+	rugID := mustFindProductIDBySKU(t, db, "RUG-MRKW-CRM")
+
+	stockOf := func(id string) int {
+		t.Helper()
+		var product models.Product
+		if err := db.First(&product, "id = ?", id).Error; err != nil {
+			t.Fatalf("fetch product: %v", err)
+		}
+		return product.StockQty
+	}
+	decode := func(resp *http.Response, want int) models.StockCount {
+		t.Helper()
+		if resp.StatusCode != want {
+			body, _ := io.ReadAll(resp.Body)
+			t.Fatalf("expected %d, got %d: %s", want, resp.StatusCode, string(body))
+		}
+		var count models.StockCount
+		if err := json.NewDecoder(resp.Body).Decode(&count); err != nil {
+			t.Fatalf("decode stock count: %v", err)
+		}
+		return count
+	}
+
+	count := decode(performJSONRequest(t, app, http.MethodPost, "/api/stock-counts", map[string]any{"location": "C-01"}, warehouseAuth), http.StatusCreated)
+	if len(count.Lines) != 1 || count.Lines[0].ProductID != lampID || count.Lines[0].ExpectedQty != 42 {
+		t.Fatalf("expected the lamp at C-01 with 42 expected, got %+v", count.Lines)
+	}
+
+	// A sale while counting must not show up as a variance.
+	resp := performJSONRequest(t, app, http.MethodPost, "/api/orders", map[string]any{
+		"customer": "Jane Doe",
+		"email":    "jane@example.com",
+		"address":  "Count Street",
+		"items":    []map[string]any{{"product": map[string]any{"id": lampID}, "quantity": 2}},
+	}, nil)
+	if resp.StatusCode != http.StatusCreated {
+		t.Fatalf("expected 201 order, got %d", resp.StatusCode)
+	}
+
+	count = decode(performJSONRequest(t, app, http.MethodPut, "/api/stock-counts/"+count.ID+"/lines", map[string]any{
+		"lines": []map[string]any{
+			{"sku": "LMP-SOLB-BRS", "counted_qty": 39},
+			{"sku": "RUG-MRKW-CRM", "counted_qty": 18},
+		},
+	}, warehouseAuth), http.StatusOK)
+	if len(count.Lines) != 2 || count.Lines[0].Variance == nil || *count.Lines[0].Variance != -3 || *count.Lines[1].Variance != 0 {
+		t.Fatalf("expected lamp variance -3 and the scanned rug added without variance, got %+v", count.Lines)
+	}
+
+	count = decode(performJSONRequest(t, app, http.MethodPost, "/api/stock-counts/"+count.ID+"/post", nil, warehouseAuth), http.StatusOK)
+	if count.Status != models.StockCountPosted || count.PostedAt == nil {
+		t.Fatalf("expected posted count, got %+v", count)
+	}
+	if stockOf(lampID) != 37 || stockOf(rugID) != 18 {
+		t.Fatalf("expected stock 37/18 after posting, got %d/%d", stockOf(lampID), stockOf(rugID))
+	}
+
+	var movements []models.StockMovement
+	if err := db.Where("reference = ?", count.ID).Find(&movements).Error; err != nil {
+		t.Fatalf("fetch movements: %v", err)
+	}
+	if len(movements) != 1 || movements[0].Delta != -3 || movements[0].Reason != models.StockMovementAdjustment {
+		t.Fatalf("expected one -3 adjustment movement, got %+v", movements)
+	}
+	var warnings int64
+	db.Model(&models.AuditLog{}).Where("action = ? AND entity_id = ? AND severity = ?", "Stock Adjusted", lampID, models.AuditSeverityWarning).Count(&warnings)
+	if warnings != 1 {
+		t.Fatalf("expected one warning audit entry for the adjustment, got %d", warnings)
+	}
+
+	if resp := performJSONRequest(t, app, http.MethodPost, "/api/stock-counts/"+count.ID+"/post", nil, warehouseAuth); resp.StatusCode != http.StatusBadRequest {
+		t.Fatalf("expected a posted count to be final, got %d", resp.StatusCode)
+	}
+}
+
+type recordingMailer struct{ sent []string }
+
+func (m *recordingMailer) Send(to, subject, body string) error {
+	m.sent = append(m.sent, to)
+	return nil
+}
+
+func TestLowStockRaisesAlertAfterOrder(t *testing.T) {
+	app, db := setupTestApp(t)
+	managerAuth := map[string]string{"Authorization": "Bearer " + loginAndGetToken(t, app, "manager@maison.co", "manager123")}
+	warehouseAuth := map[string]string{"Authorization": "Bearer " + loginAndGetToken(t, app, "warehouse@maison.co", "warehouse123")}
+	lampID := mustFindProductIDBySKU(t, db, "LMP-SOLB-BRS")
+
+	var lamp models.Product
+	resp := performJSONRequest(t, app, http.MethodGet, "/api/products/"+lampID, nil, managerAuth)
+	if err := json.NewDecoder(resp.Body).Decode(&lamp); err != nil {
+		t.Fatalf("decode product: %v", err)
+	}
+	lamp.ReorderPoint = 5
+	lamp.SafetyStock = 8
+	resp = performJSONRequest(t, app, http.MethodPut, "/api/products/"+lampID, lamp, managerAuth)
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Fatalf("expected safety stock above the reorder point to be rejected, got %d", resp.StatusCode)
+	}
+	lamp.ReorderPoint = 40
+	lamp.SafetyStock = 10
+	resp = performJSONRequest(t, app, http.MethodPut, "/api/products/"+lampID, lamp, managerAuth)
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		t.Fatalf("expected 200 product update, got %d: %s", resp.StatusCode, string(body))
+	}
+
+	alerts := func(status string) []models.StockAlert {
+		t.Helper()
+		resp := performJSONRequest(t, app, http.MethodGet, "/api/inventory/alerts?status="+status, nil, warehouseAuth)
+		if resp.StatusCode != http.StatusOK {
+			body, _ := io.ReadAll(resp.Body)
+			t.Fatalf("expected 200 alerts, got %d: %s", resp.StatusCode, string(body))
+		}
+		var items []models.StockAlert
+		if err := json.NewDecoder(resp.Body).Decode(&items); err != nil {
+			t.Fatalf("decode alerts: %v", err)
+		}
+		return items
+	}
+	if items := alerts("open"); len(items) != 0 {
+		t.Fatalf("expected no alerts before the order, got %+v", items)
+	}
+
+	resp = performJSONRequest(t, app, http.MethodPost, "/api/orders", map[string]any{
+		"customer": "Jane Doe",
+		"email":    "jane@example.com",
+		"address":  "Low Stock Street",
+		"items":    []map[string]any{{"product": map[string]any{"id": lampID}, "quantity": 3}},
+	}, nil)
+	if resp.StatusCode != http.StatusCreated {
+		t.Fatalf("expected 201 order, got %d", resp.StatusCode)
+	}
+
+	// The order queues the check, which runs in the background and writes
+	// the warning last.
+	var warnings int64
+	for deadline := time.Now().Add(5 * time.Second); warnings == 0 && time.Now().Before(deadline); {
+		time.Sleep(20 * time.Millisecond)
+		if err := db.Model(&models.AuditLog{}).Where("action = ? AND entity_id = ? AND severity = ?", "Low Stock", lampID, models.AuditSeverityWarning).Count(&warnings).Error; err != nil {
+			t.Fatalf("count audit logs: %v", err)
+		}
+	}
+	if warnings != 1 {
+		t.Fatalf("expected one low stock warning, got %d", warnings)
+	}
+	items := alerts("open")
+	if len(items) != 1 || items[0].ProductID != lampID || items[0].Level != models.StockAlertReorder || items[0].StockQty != 39 || items[0].SKU != "LMP-SOLB-BRS" {
+		t.Fatalf("expected a reorder alert for the lamp at 39, got %+v", items)
+	}
+
+	resp = performJSONRequest(t, app, http.MethodGet, "/api/inventory/alerts", nil, map[string]string{"Authorization": "Bearer " + loginAndGetToken(t, app, "executive@maison.co", "executive123")})
+	if resp.StatusCode != http.StatusForbidden {
+		t.Fatalf("expected executive to be forbidden, got %d", resp.StatusCode)
+	}
+
+	lamp.Stock = 60
+	resp = performJSONRequest(t, app, http.MethodPut, "/api/products/"+lampID, lamp, managerAuth)
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected 200 restock, got %d", resp.StatusCode)
+	}
+	inventory := services.NewInventoryService(repositories.NewStockAlertRepository(db), repositories.NewProductRepository(db), services.NewAuditService(repositories.NewAuditRepository(db)))
+	alertRepo := repositories.NewStockAlertRepository(db)
+	if created, err := alertRepo.Create(&models.StockAlert{ProductID: lampID, Level: models.StockAlertReorder, Status: models.StockAlertOpen}); err != nil || created {
+		t.Fatalf("expected a second open alert for the lamp to be refused, got created=%v err=%v", created, err)
+	}
+	if _, err := inventory.CheckAll(time.Now().UTC()); err != nil {
+		t.Fatalf("check stock: %v", err)
+	}
+	if items := alerts("open"); len(items) != 0 {
+		t.Fatalf("expected the alert to be resolved after restocking, got %+v", items)
+	}
+	if items := alerts("resolved"); len(items) != 1 || items[0].ResolvedAt == nil {
+		t.Fatalf("expected one resolved alert, got %+v", items)
+	}
+}
+
+func TestWarehousePicksAndPacksOrdersIntoShipment(t *testing.T) {
+	app, db := setupTestApp(t)
+	warehouseAuth := map[string]string{"Authorization": "Bearer " + loginAndGetToken(t, app, "warehouse@maison.co", "warehouse123")}
+	lampID := mustFindProductIDBySKU(t, db, "LMP-SOLB-BRS")
+	rugID := mustFindProductIDBySKU(t, db, "RUG-MRKW-CRM")
+
+	place := func(address string, items []map[string]any) string {
+		t.Helper()
+		resp := performJSONRequest(t, app, http.MethodPost, "/api/orders", map[string]any{
+			"customer": "Иван Петров",
+			"email":    "ivan@example.com",
+			"address":  address,
+			"items":    items,
+		}, nil)
+		if resp.StatusCode != http.StatusCreated {
+			t.Fatalf("expected 201 order, got %d", resp.StatusCode)
+		}
+		var order models.OrderResponse
+		if err := json.NewDecoder(resp.Body).Decode(&order); err != nil {
+			t.Fatalf("decode order: %v", err)
+		}
+		return order.ID
+	}
+	first := place("г. Тверь, ул. Советская, д. 1", []map[string]any{
+		{"product": map[string]any{"id": lampID}, "quantity": 2},
+		{"product": map[string]any{"id": rugID}, "quantity": 1},
+	})
+	second := place("Pick Street 2", []map[string]any{{"product": map[string]any{"id": lampID}, "quantity": 1}})
+
+	resp := performJSONRequest(t, app, http.MethodPost, "/api/orders/"+first+"/pick", nil, warehouseAuth)
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Fatalf("expected pending order picking to be rejected, got %d", resp.StatusCode)
+	}
+	for _, id := range []string{first, second} {
+		resp := performJSONRequest(t, app, http.MethodPatch, "/api/orders/"+id+"/status", map[string]any{"status": "processing"}, warehouseAuth)
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("expected 200 status update, got %d", resp.StatusCode)
+		}
+	}
+
+	resp = performJSONRequest(t, app, http.MethodGet, "/api/warehouse/pick-list?location=C-01", nil, warehouseAuth)
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected 200 pick list, got %d", resp.StatusCode)
+	}
+	var list models.PickList
+	if err := json.NewDecoder(resp.Body).Decode(&list); err != nil {
+		t.Fatalf("decode pick list: %v", err)
+	}
+	var lamp *models.PickListEntry
+	for i := range list.Entries {
+		if list.Entries[i].Location != "C-01" {
+			t.Fatalf("expected only C-01 entries, got %+v", list.Entries[i])
+		}
+		if list.Entries[i].ProductID == lampID {
+			lamp = &list.Entries[i]
+		}
+	}
+	if lamp == nil {
+		t.Fatalf("expected the lamp on the pick list, got %+v", list.Entries)
+	}
+	fromOrders := map[string]int{}
+	for _, o := range lamp.Orders {
+		fromOrders[o.OrderID] += o.Qty
+	}
+	if fromOrders[first] != 2 || fromOrders[second] != 1 {
+		t.Fatalf("expected the lamp batched as 2 + 1 from both orders, got %+v", lamp.Orders)
+	}
+
+	decode := func(resp *http.Response, want int) models.Fulfillment {
+		t.Helper()
+		if resp.StatusCode != want {
+			body, _ := io.ReadAll(resp.Body)
+			t.Fatalf("expected %d, got %d: %s", want, resp.StatusCode, string(body))
+		}
+		var fulfillment models.Fulfillment
+		if err := json.NewDecoder(resp.Body).Decode(&fulfillment); err != nil {
+			t.Fatalf("decode fulfillment: %v", err)
+		}
+		return fulfillment
+	}
+	fulfillment := decode(performJSONRequest(t, app, http.MethodGet, "/api/orders/"+first+"/fulfillment", nil, warehouseAuth), http.StatusOK)
+	if len(fulfillment.Lines) != 2 {
+		t.Fatalf("expected two lines, got %+v", fulfillment.Lines)
+	}
+	lampLine, rugLine := fulfillment.Lines[0].ID, fulfillment.Lines[1].ID
+
+	resp = performJSONRequest(t, app, http.MethodPost, "/api/orders/"+first+"/pack", map[string]any{"line_ids": []uint{lampLine}}, warehouseAuth)
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Fatalf("expected packing an unpicked line to be rejected, got %d", resp.StatusCode)
+	}
+	fulfillment = decode(performJSONRequest(t, app, http.MethodPost, "/api/orders/"+first+"/pick", map[string]any{"line_ids": []uint{lampLine, rugLine}}, warehouseAuth), http.StatusOK)
+	if fulfillment.Lines[0].PickedAt == nil || fulfillment.Lines[0].PickedBy != "warehouse@maison.co" {
+		t.Fatalf("expected lines picked by the warehouse user, got %+v", fulfillment.Lines)
+	}
+	fulfillment = decode(performJSONRequest(t, app, http.MethodPost, "/api/orders/"+first+"/pack", map[string]any{"line_ids": []uint{lampLine}}, warehouseAuth), http.StatusOK)
+	if fulfillment.Status != models.OrderStatusProcessing || fulfillment.Lines[0].PackedAt == nil || fulfillment.Lines[1].PackedAt != nil {
+		t.Fatalf("expected one packed line on a processing order, got %+v", fulfillment)
+	}
+
+	resp = performJSONRequest(t, app, http.MethodGet, "/api/orders/"+first+"/packing-slip", nil, warehouseAuth)
+	if resp.StatusCode != http.StatusOK || resp.Header.Get("Content-Type") != "application/pdf" {
+		t.Fatalf("expected a PDF packing slip, got %d %s", resp.StatusCode, resp.Header.Get("Content-Type"))
+	}
+	slip, _ := io.ReadAll(resp.Body)
+	if !bytes.HasPrefix(slip, []byte("%PDF-")) {
+		t.Fatalf("expected PDF content, got %q", slip[:min(len(slip), 16)])
+	}
+
+	fulfillment = decode(performJSONRequest(t, app, http.MethodPost, "/api/orders/"+first+"/pack", nil, warehouseAuth), http.StatusOK)
+	if fulfillment.Status != models.OrderStatusShipped {
+		t.Fatalf("expected the order to ship once packed, got %s", fulfillment.Status)
+	}
+	var logs int64
+	if err := db.Model(&models.AuditLog{}).Where("action = ? AND entity_id = ? AND details LIKE ?", "Order Status Changed", first, "%to 'shipped'%").Count(&logs).Error; err != nil {
+		t.Fatalf("count audit logs: %v", err)
+	}
+	if logs != 1 {
+		t.Fatalf("expected the shipment to be audited, got %d entries", logs)
+	}
+
+	resp = performJSONRequest(t, app, http.MethodGet, "/api/warehouse/pick-list", nil, warehouseAuth)
+	list = models.PickList{}
+	if err := json.NewDecoder(resp.Body).Decode(&list); err != nil {
+		t.Fatalf("decode pick list: %v", err)
+	}
+	for _, id := range list.Orders {
+		if id == first {
+			t.Fatalf("expected the shipped order to leave the pick list, got %+v", list.Orders)
+		}
+	}
+
+	resp = performJSONRequest(t, app, http.MethodGet, "/api/warehouse/pick-list", nil, map[string]string{"Authorization": "Bearer " + loginAndGetToken(t, app, "executive@maison.co", "executive123")})
+	if resp.StatusCode != http.StatusForbidden {
+		t.Fatalf("expected executive to be forbidden, got %d", resp.StatusCode)
+	}
+}
+
+func TestBarcodesAndScannedCodeLookup(t *testing.T) {
+	app, db := setupTestApp(t)
+	managerAuth := map[string]string{"Authorization": "Bearer " + loginAndGetToken(t, app, "manager@maison.co", "manager123")}
+	warehouseAuth := map[string]string{"Authorization": "Bearer " + loginAndGetToken(t, app, "warehouse@maison.co", "warehouse123")}
+	lampID := mustFindProductIDBySKU(t, db, "LMP-SOLB-BRS")
+	rugID := mustFindProductIDBySKU(t, db, "RUG-MRKW-CRM")
+
+	getProduct := func(id string) models.Product {
+		t.Helper()
+		var product models.Product
+		resp := performJSONRequest(t, app, http.MethodGet, "/api/products/"+id, nil, managerAuth)
+		if err := json.NewDecoder(resp.Body).Decode(&product); err != nil {
+			t.Fatalf("decode product: %v", err)
+		}
+		return product
+	}
+	lamp := getProduct(lampID)
+	lamp.Barcode = "4006381333932"
+	resp := performJSONRequest(t, app, http.MethodPut, "/api/products/"+lampID, lamp, managerAuth)
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Fatalf("expected a wrong EAN-13 check digit to be rejected, got %d", resp.StatusCode)
+	}
+	lamp.Barcode = "4006381333931"
+	resp = performJSONRequest(t, app, http.MethodPut, "/api/products/"+lampID, lamp, managerAuth)
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		t.Fatalf("expected 200 product update, got %d: %s", resp.StatusCode, string(body))
+	}
+	if got := getProduct(lampID); got.BarcodeType != models.BarcodeEAN13 {
+		t.Fatalf("expected the barcode to be stored as ean13, got %q", got.BarcodeType)
+	}
+	rug := getProduct(rugID)
+	rug.Barcode = "4006381333931"
+	resp = performJSONRequest(t, app, http.MethodPut, "/api/products/"+rugID, rug, managerAuth)
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Fatalf("expected a duplicate barcode to be rejected, got %d", resp.StatusCode)
+	}
+
+	for _, path := range []string{"/api/products/" + lampID + "/barcode", "/api/products/" + rugID + "/barcode?width=400&height=120"} {
+		resp := performJSONRequest(t, app, http.MethodGet, path, nil, warehouseAuth)
+		if resp.StatusCode != http.StatusOK || resp.Header.Get("Content-Type") != "image/png" {
+			t.Fatalf("expected a PNG barcode from %s, got %d %s", path, resp.StatusCode, resp.Header.Get("Content-Type"))
+		}
+		data, _ := io.ReadAll(resp.Body)
+		if !bytes.HasPrefix(data, []byte("\x89PNG")) {
+			t.Fatalf("expected PNG content from %s", path)
+		}
+	}
+
+	lookup := func(code string, want int) models.LookupResult {
+		t.Helper()
+		resp := performJSONRequest(t, app, http.MethodGet, "/api/lookup/"+url.PathEscape(code), nil, warehouseAuth)
+		if resp.StatusCode != want {
+			body, _ := io.ReadAll(resp.Body)
+			t.Fatalf("expected %d looking up %q, got %d: %s", want, code, resp.StatusCode, string(body))
+		}
+		var result models.LookupResult
+		if want == http.StatusOK {
+			if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+				t.Fatalf("decode lookup: %v", err)
+			}
+		}
+		return result
+	}
+	if result := lookup("4006381333931", http.StatusOK); result.Type != models.LookupProduct || result.Product.ID != lampID {
+		t.Fatalf("expected the barcode to resolve to the lamp, got %+v", result)
+	}
+	if result := lookup("RUG-MRKW-CRM", http.StatusOK); result.Type != models.LookupProduct || result.Product.ID != rugID {
+		t.Fatalf("expected the SKU to resolve to the rug, got %+v", result)
+	}
+
+	orderID := mustFindOrderIDByAddress(t, db, "г. Екатеринбург, ул. Малышева, д. 18, кв. 24")
+	for _, code := range []string{orderID, services.OrderURL("http://localhost:3000", orderID)} {
+		if result := lookup(code, http.StatusOK); result.Type != models.LookupOrder || result.Order.ID != orderID {
+			t.Fatalf("expected %q to resolve to the order, got %+v", code, result)
+		}
+	}
+	lookup("0000000000000", http.StatusNotFound)
+
+	resp = performJSONRequest(t, app, http.MethodGet, "/api/orders/"+orderID+"/qr", nil, warehouseAuth)
+	if resp.StatusCode != http.StatusOK || resp.Header.Get("Content-Type") != "image/png" {
+		t.Fatalf("expected a PNG QR code, got %d %s", resp.StatusCode, resp.Header.Get("Content-Type"))
+	}
+}
+
+func TestPreordersGetIncomingStockAndSubscribersAreNotified(t *testing.T) {
+	app, db := setupTestApp(t)
+	managerToken := loginAndGetToken(t, app, "manager@maison.co", "manager123")
+	managerAuth := map[string]string{"Authorization": "Bearer " + managerToken}
+	lampID := mustFindProductIDBySKU(t, db, "LMP-SOLB-BRS")
+
+	var lamp models.Product
+	resp := performJSONRequest(t, app, http.MethodGet, "/api/products/"+lampID, nil, managerAuth)
+	if err := json.NewDecoder(resp.Body).Decode(&lamp); err != nil {
+		t.Fatalf("decode product: %v", err)
+	}
+	restock := func(stock int) {
+		t.Helper()
+		availableAt := time.Date(2030, 1, 15, 0, 0, 0, 0, time.UTC)
+		lamp.Stock = stock
+		lamp.PreorderEnabled = true
+		lamp.PreorderAvailableAt = &availableAt
+		resp := performJSONRequest(t, app, http.MethodPut, "/api/products/"+lampID, lamp, managerAuth)
+		if resp.StatusCode != http.StatusOK {
+			body, _ := io.ReadAll(resp.Body)
+			t.Fatalf("expected 200 product update, got %d: %s", resp.StatusCode, string(body))
+		}
+	}
+	order := func(address string, qty int) models.OrderResponse {
+		t.Helper()
+		resp := performJSONRequest(t, app, http.MethodPost, "/api/orders", map[string]any{
+			"customer": "Jane Doe",
+			"email":    "jane@example.com",
+			"address":  address,
+			"items":    []map[string]any{{"product": map[string]any{"id": lampID}, "quantity": qty}},
+		}, nil)
+		if resp.StatusCode != http.StatusCreated {
+			body, _ := io.ReadAll(resp.Body)
+			t.Fatalf("expected 201 order, got %d: %s", resp.StatusCode, string(body))
+		}
+		var created models.OrderResponse
+		if err := json.NewDecoder(resp.Body).Decode(&created); err != nil {
+			t.Fatalf("decode order: %v", err)
+		}
+		return created
+	}
+	preorderQty := func(address string) int {
+		t.Helper()
+		var item models.OrderItem
+		if err := db.Where("order_id = ?", mustFindOrderIDByAddress(t, db, address)).First(&item).Error; err != nil {
+			t.Fatalf("fetch order item: %v", err)
+		}
+		return item.PreorderQty
+	}
+	stock := func() int {
+		t.Helper()
+		var product models.Product
+		if err := db.First(&product, "id = ?", lampID).Error; err != nil {
+			t.Fatalf("fetch product: %v", err)
+		}
+		return product.StockQty
+	}
+
+	restock(2)
+	if first := order("Preorder Street 1", 5); first.Items[0].PreorderQty != 3 {
+		t.Fatalf("expected 3 units pre-ordered beyond stock, got %d", first.Items[0].PreorderQty)
+	}
+	order("Preorder Street 2", 2)
+	if stock() != 0 {
+		t.Fatalf("expected stock to be used up, got %d", stock())
+	}
+
+	var catalog models.CatalogProduct
+	resp = performJSONRequest(t, app, http.MethodGet, "/api/products/"+lampID, nil, nil)
+	if err := json.NewDecoder(resp.Body).Decode(&catalog); err != nil {
+		t.Fatalf("decode catalog product: %v", err)
+	}
+	if catalog.Availability != models.AvailabilityPreorder || catalog.PreorderAvailableAt == nil {
+		t.Fatalf("expected storefront to offer a pre-order with a date, got %+v", catalog)
+	}
+
+	performJSONRequest(t, app, http.MethodPost, "/api/auth/signup", map[string]string{
+		"email":    "waiting@example.com",
+		"password": "client123",
+		"name":     "Waiting Client",
+	}, nil)
+	clientAuth := map[string]string{"Authorization": "Bearer " + loginAndGetToken(t, app, "waiting@example.com", "client123")}
+	if resp := performJSONRequest(t, app, http.MethodPost, "/api/products/"+lampID+"/subscription", nil, clientAuth); resp.StatusCode != http.StatusCreated {
+		t.Fatalf("expected 201 subscription, got %d", resp.StatusCode)
+	}
+	if resp := performJSONRequest(t, app, http.MethodPost, "/api/products/"+lampID+"/subscription", nil, clientAuth); resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected repeated subscription to return the pending one, got %d", resp.StatusCode)
+	}
+
+	mailer := &recordingMailer{}
+	notifier := services.NewStockSubscriptionService(repositories.NewProductRepository(db), repositories.NewStockSubscriptionRepository(db), mailer)
+	if sent, err := notifier.NotifyAvailable(time.Now().UTC()); err != nil || sent != 0 {
+		t.Fatalf("expected no notifications while out of stock, got %d (%v)", sent, err)
+	}
+
+	// Incoming stock fills the oldest pre-order first.
+	restock(4)
+	if preorderQty("Preorder Street 1") != 0 || preorderQty("Preorder Street 2") != 1 || stock() != 0 {
+		t.Fatalf("expected pre-orders 0/1 and no stock left, got %d/%d and %d", preorderQty("Preorder Street 1"), preorderQty("Preorder Street 2"), stock())
+	}
+	if sent, _ := notifier.NotifyAvailable(time.Now().UTC()); sent != 0 {
+		t.Fatalf("expected no notifications while stock is held by pre-orders, got %d", sent)
+	}
+
+	restock(10)
+	if preorderQty("Preorder Street 2") != 0 || stock() != 9 {
+		t.Fatalf("expected remaining pre-order filled and 9 in stock, got %d and %d", preorderQty("Preorder Street 2"), stock())
+	}
+	if sent, err := notifier.NotifyAvailable(time.Now().UTC()); err != nil || sent != 1 || mailer.sent[0] != "waiting@example.com" {
+		t.Fatalf("expected one notification to the subscriber, got %d %v (%v)", sent, mailer.sent, err)
+	}
+	if sent, _ := notifier.NotifyAvailable(time.Now().UTC()); sent != 0 {
+		t.Fatalf("expected subscribers to be notified once, got %d", sent)
+	}
+}
+
+func performMultipartRequest(t *testing.T, app *fiber.App, path, fileName string, data []byte, token string) *http.Response {
+	t.Helper()
+
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+	part, err := writer.CreateFormFile("file", fileName)
+	if err != nil {
+		t.Fatalf("create form file: %v", err)
+	}
+	if _, err := part.Write(data); err != nil {
+		t.Fatalf("write form file: %v", err)
+	}
+	if err := writer.Close(); err != nil {
+		t.Fatalf("close multipart writer: %v", err)
+	}
+
+	req := httptest.NewRequest(http.MethodPost, path, &body)
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+	req.Header.Set("Authorization", "Bearer "+token)
+
+	resp, err := app.Test(req, -1)
+	if err != nil {
+		t.Fatalf("perform request: %v", err)
+	}
+	return resp
+}
+
 func mustFindProductIDBySKU(t *testing.T, db *gorm.DB, sku string) string {
 	t.Helper()
 
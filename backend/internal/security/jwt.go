@@ -0,0 +1,391 @@
+package security
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+type Algorithm string
+
+const (
+	AlgHS256 Algorithm = "HS256"
+	AlgRS256 Algorithm = "RS256"
+	AlgEdDSA Algorithm = "EdDSA"
+)
+
+// clockSkew is how far exp and nbf may be off between servers.
+const clockSkew = 30 * time.Second
+
+// Key is a JWT signing or verification key identified by its kid.
+type Key struct {
+	ID        string
+	Algorithm Algorithm
+	secret    []byte
+	private   crypto.Signer
+	public    crypto.PublicKey
+}
+
+// NewHMACKey makes an HS256 key whose kid is derived from the secret, so that
+// rotated secrets keep distinct kids without extra configuration.
+func NewHMACKey(secret string) Key {
+	sum := sha256.Sum256([]byte(secret))
+	return Key{ID: "hs-" + hex.EncodeToString(sum[:6]), Algorithm: AlgHS256, secret: []byte(secret)}
+}
+
+// ParsePEMKey reads an RSA (RS256) or Ed25519 (EdDSA) key. Private keys can
+// sign and verify, public keys only verify.
+func ParsePEMKey(kid string, data []byte) (Key, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return Key{}, fmt.Errorf("key %s: no PEM block", kid)
+	}
+	var parsed any
+	var err error
+	switch block.Type {
+	case "PRIVATE KEY":
+		parsed, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	case "RSA PRIVATE KEY":
+		parsed, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	case "PUBLIC KEY":
+		parsed, err = x509.ParsePKIXPublicKey(block.Bytes)
+	case "RSA PUBLIC KEY":
+		parsed, err = x509.ParsePKCS1PublicKey(block.Bytes)
+	default:
+		return Key{}, fmt.Errorf("key %s: unsupported PEM block %q", kid, block.Type)
+	}
+	if err != nil {
+		return Key{}, fmt.Errorf("key %s: %w", kid, err)
+	}
+
+	key := Key{ID: kid}
+	switch k := parsed.(type) {
+	case *rsa.PrivateKey:
+		key.Algorithm, key.private, key.public = AlgRS256, k, &k.PublicKey
+	case *rsa.PublicKey:
+		key.Algorithm, key.public = AlgRS256, k
+	case ed25519.PrivateKey:
+		key.Algorithm, key.private, key.public = AlgEdDSA, k, k.Public()
+	case ed25519.PublicKey:
+		key.Algorithm, key.public = AlgEdDSA, k
+	default:
+		return Key{}, fmt.Errorf("key %s: only RSA and Ed25519 keys are supported", kid)
+	}
+	if rsaKey, ok := key.public.(*rsa.PublicKey); ok && rsaKey.N.BitLen() < 2048 {
+		return Key{}, fmt.Errorf("key %s: RSA keys must be at least 2048 bits", kid)
+	}
+	return key, nil
+}
+
+func (k Key) CanSign() bool {
+	return k.secret != nil || k.private != nil
+}
+
+func (k Key) sign(input []byte) ([]byte, error) {
+	switch k.Algorithm {
+	case AlgHS256:
+		mac := hmac.New(sha256.New, k.secret)
+		_, _ = mac.Write(input)
+		return mac.Sum(nil), nil
+	case AlgRS256:
+		digest := sha256.Sum256(input)
+		return k.private.Sign(rand.Reader, digest[:], crypto.SHA256)
+	case AlgEdDSA:
+		return k.private.Sign(rand.Reader, input, crypto.Hash(0))
+	}
+	return nil, errors.New("unsupported algorithm")
+}
+
+func (k Key) verify(input, signature []byte) bool {
+	switch k.Algorithm {
+	case AlgHS256:
+		mac := hmac.New(sha256.New, k.secret)
+		_, _ = mac.Write(input)
+		return hmac.Equal(mac.Sum(nil), signature)
+	case AlgRS256:
+		digest := sha256.Sum256(input)
+		return rsa.VerifyPKCS1v15(k.public.(*rsa.PublicKey), crypto.SHA256, digest[:], signature) == nil
+	case AlgEdDSA:
+		return ed25519.Verify(k.public.(ed25519.PublicKey), input, signature)
+	}
+	return false
+}
+
+type KeySetOptions struct {
+	Issuer   string
+	Audience string
+	// Secret is the current HS256 secret; PreviousSecrets still verify tokens
+	// issued before a rotation.
+	Secret          string
+	PreviousSecrets []string
+	// KeysDir holds RSA and Ed25519 PEM keys named <kid>.pem.
+	KeysDir string
+	// SigningKeyID picks the key from KeysDir that signs new tokens; empty
+	// signs with Secret.
+	SigningKeyID string
+}
+
+// KeySet signs tokens with one key and verifies them with any key it holds,
+// so keys can be rotated without logging everyone out.
+type KeySet struct {
+	issuer   string
+	audience string
+	signing  Key
+	keys     map[string]Key
+}
+
+func NewKeySet(opts KeySetOptions) (*KeySet, error) {
+	ks := &KeySet{issuer: strings.TrimSpace(opts.Issuer), audience: strings.TrimSpace(opts.Audience), keys: map[string]Key{}}
+	if ks.issuer == "" || ks.audience == "" {
+		return nil, errors.New("jwt issuer and audience are required")
+	}
+	for _, secret := range opts.PreviousSecrets {
+		if secret = strings.TrimSpace(secret); secret != "" {
+			key := NewHMACKey(secret)
+			ks.keys[key.ID] = key
+		}
+	}
+	if opts.Secret != "" {
+		ks.signing = NewHMACKey(opts.Secret)
+		ks.keys[ks.signing.ID] = ks.signing
+	}
+
+	if opts.KeysDir != "" {
+		paths, err := filepath.Glob(filepath.Join(opts.KeysDir, "*.pem"))
+		if err != nil {
+			return nil, err
+		}
+		for _, path := range paths {
+			data, err := os.ReadFile(path)
+			if err != nil {
+				return nil, err
+			}
+			key, err := ParsePEMKey(strings.TrimSuffix(filepath.Base(path), ".pem"), data)
+			if err != nil {
+				return nil, err
+			}
+			ks.keys[key.ID] = key
+		}
+	}
+
+	if kid := strings.TrimSpace(opts.SigningKeyID); kid != "" {
+		key, ok := ks.keys[kid]
+		if !ok || key.Algorithm == AlgHS256 {
+			return nil, fmt.Errorf("signing key %s not found in %s", kid, opts.KeysDir)
+		}
+		if !key.CanSign() {
+			return nil, fmt.Errorf("signing key %s is a public key", kid)
+		}
+		ks.signing = key
+	}
+	if !ks.signing.CanSign() {
+		return nil, errors.New("a secret or a signing key is required")
+	}
+	return ks, nil
+}
+
+type jwtHeader struct {
+	Alg Algorithm `json:"alg"`
+	Typ string    `json:"typ,omitempty"`
+	Kid string    `json:"kid,omitempty"`
+}
+
+// Sign issues a JWT for the claims. Issuer, audience, issue time, not-before
+// and a unique ID are filled in; the caller sets Exp.
+func (ks *KeySet) Sign(claims Claims) (string, error) {
+	now := time.Now().UTC().Unix()
+	claims.Issuer = ks.issuer
+	claims.Audience = Audience{ks.audience}
+	claims.IssuedAt = now
+	claims.NotBefore = now
+	if claims.Subject == "" {
+		claims.Subject = claims.UserID
+	}
+	if claims.ID == "" {
+		id := make([]byte, 16)
+		_, _ = rand.Read(id)
+		claims.ID = hex.EncodeToString(id)
+	}
+
+	header, err := json.Marshal(jwtHeader{Alg: ks.signing.Algorithm, Typ: "JWT", Kid: ks.signing.ID})
+	if err != nil {
+		return "", err
+	}
+	payload, err := json.Marshal(claims)
+	if err != nil {
+		return "", err
+	}
+	input := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(payload)
+	signature, err := ks.signing.sign([]byte(input))
+	if err != nil {
+		return "", err
+	}
+	return input + "." + base64.RawURLEncoding.EncodeToString(signature), nil
+}
+
+// Parse verifies a JWT and its registered claims. The key is chosen by kid
+// and must match the alg in the header.
+func (ks *KeySet) Parse(token string) (Claims, error) {
+	payload, err := VerifyJWT(token, func(kid string) (Key, bool) {
+		key, ok := ks.keys[kid]
+		return key, ok
+	})
+	if err != nil {
+		return Claims{}, err
+	}
+
+	var claims Claims
+	if err := json.Unmarshal(payload, &claims); err != nil {
+		return Claims{}, fmt.Errorf("invalid token claims: %w", err)
+	}
+	now := time.Now().UTC()
+	if err := CheckLifetime(claims.Exp, claims.NotBefore, now); err != nil {
+		return Claims{}, err
+	}
+	if claims.Issuer != ks.issuer {
+		return Claims{}, errors.New("invalid token issuer")
+	}
+	if !claims.Audience.Contains(ks.audience) {
+		return Claims{}, errors.New("invalid token audience")
+	}
+	return claims, nil
+}
+
+// VerifyJWT checks the signature of a JWT with the key lookup returns for
+// its kid and returns the payload. The key must match the alg in the
+// header, so a token cannot pick a weaker algorithm than its key.
+func VerifyJWT(token string, lookup func(kid string) (Key, bool)) ([]byte, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, errors.New("invalid token format")
+	}
+	var header jwtHeader
+	if err := decodeSegment(parts[0], &header); err != nil {
+		return nil, fmt.Errorf("invalid token header: %w", err)
+	}
+	key, ok := lookup(header.Kid)
+	if !ok {
+		return nil, errors.New("unknown token key")
+	}
+	if header.Alg != key.Algorithm {
+		return nil, errors.New("token algorithm does not match its key")
+	}
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil || !key.verify([]byte(parts[0]+"."+parts[1]), signature) {
+		return nil, errors.New("invalid token signature")
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return nil, fmt.Errorf("invalid token claims: %w", err)
+	}
+	return payload, nil
+}
+
+// CheckLifetime rejects a token that expired or is not valid yet at now,
+// allowing for clock skew. A token without exp is rejected.
+func CheckLifetime(exp, notBefore int64, now time.Time) error {
+	if exp == 0 || now.After(time.Unix(exp, 0).Add(clockSkew)) {
+		return errors.New("token expired")
+	}
+	if notBefore > 0 && now.Add(clockSkew).Before(time.Unix(notBefore, 0)) {
+		return errors.New("token not valid yet")
+	}
+	return nil
+}
+
+func decodeSegment(segment string, v any) error {
+	data, err := base64.RawURLEncoding.DecodeString(segment)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
+
+// JWK is a public key in RFC 7517 form.
+type JWK struct {
+	Kty string    `json:"kty"`
+	Kid string    `json:"kid"`
+	Use string    `json:"use"`
+	Alg Algorithm `json:"alg"`
+	N   string    `json:"n,omitempty"`
+	E   string    `json:"e,omitempty"`
+	Crv string    `json:"crv,omitempty"`
+	X   string    `json:"x,omitempty"`
+}
+
+// Key turns a published RSA or Ed25519 key into a verification key, such as
+// one from an identity provider's JWKS.
+func (j JWK) Key() (Key, error) {
+	if j.Use != "" && j.Use != "sig" {
+		return Key{}, fmt.Errorf("key %s: not a signing key", j.Kid)
+	}
+	key := Key{ID: j.Kid}
+	switch j.Kty {
+	case "RSA":
+		n, err := base64.RawURLEncoding.DecodeString(j.N)
+		if err != nil {
+			return Key{}, fmt.Errorf("key %s: invalid modulus: %w", j.Kid, err)
+		}
+		e, err := base64.RawURLEncoding.DecodeString(j.E)
+		if err != nil || len(e) == 0 || len(e) > 4 {
+			return Key{}, fmt.Errorf("key %s: invalid exponent", j.Kid)
+		}
+		public := &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(new(big.Int).SetBytes(e).Int64())}
+		if public.N.BitLen() < 2048 {
+			return Key{}, fmt.Errorf("key %s: RSA keys must be at least 2048 bits", j.Kid)
+		}
+		key.Algorithm, key.public = AlgRS256, public
+	case "OKP":
+		x, err := base64.RawURLEncoding.DecodeString(j.X)
+		if j.Crv != "Ed25519" || err != nil || len(x) != ed25519.PublicKeySize {
+			return Key{}, fmt.Errorf("key %s: only Ed25519 OKP keys are supported", j.Kid)
+		}
+		key.Algorithm, key.public = AlgEdDSA, ed25519.PublicKey(x)
+	default:
+		return Key{}, fmt.Errorf("key %s: unsupported key type %q", j.Kid, j.Kty)
+	}
+	if j.Alg != "" && j.Alg != key.Algorithm {
+		return Key{}, fmt.Errorf("key %s: algorithm %s does not match its key type", j.Kid, j.Alg)
+	}
+	return key, nil
+}
+
+type JWKSet struct {
+	Keys []JWK `json:"keys"`
+}
+
+// JWKS lists the asymmetric verification keys; HMAC secrets are never
+// published.
+func (ks *KeySet) JWKS() JWKSet {
+	set := JWKSet{Keys: []JWK{}}
+	for _, key := range ks.keys {
+		switch pub := key.public.(type) {
+		case *rsa.PublicKey:
+			set.Keys = append(set.Keys, JWK{
+				Kty: "RSA", Kid: key.ID, Use: "sig", Alg: AlgRS256,
+				N: base64.RawURLEncoding.EncodeToString(pub.N.Bytes()),
+				E: base64.RawURLEncoding.EncodeToString(big.NewInt(int64(pub.E)).Bytes()),
+			})
+		case ed25519.PublicKey:
+			set.Keys = append(set.Keys, JWK{Kty: "OKP", Kid: key.ID, Use: "sig", Alg: AlgEdDSA, Crv: "Ed25519", X: base64.RawURLEncoding.EncodeToString(pub)})
+		}
+	}
+	sort.Slice(set.Keys, func(i, j int) bool { return set.Keys[i].Kid < set.Keys[j].Kid })
+	return set
+}
@@ -0,0 +1,89 @@
+package security
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"strings"
+
+	"golang.org/x/crypto/argon2"
+)
+
+// LegacyPasswordSalt is the salt shared by all pre-argon2id password hashes.
+const LegacyPasswordSalt = "maison-salt"
+
+// Argon2id parameters of new hashes. Hashes made with other parameters still
+// verify and are reported for rehash.
+const (
+	argon2Memory  uint32 = 19 * 1024
+	argon2Time    uint32 = 2
+	argon2Threads uint8  = 1
+	argon2KeyLen  uint32 = 32
+	argon2SaltLen        = 16
+)
+
+const argon2Prefix = "$argon2id$"
+
+// HashPassword hashes a password with argon2id and a random per-user salt in
+// the PHC string format: $argon2id$v=19$m=...,t=...,p=...$salt$hash.
+func HashPassword(password string) string {
+	salt := make([]byte, argon2SaltLen)
+	_, _ = rand.Read(salt)
+	key := argon2.IDKey([]byte(password), salt, argon2Time, argon2Memory, argon2Threads, argon2KeyLen)
+	return fmt.Sprintf("%sv=%d$m=%d,t=%d,p=%d$%s$%s", argon2Prefix, argon2.Version, argon2Memory, argon2Time, argon2Threads,
+		base64.RawStdEncoding.EncodeToString(salt), base64.RawStdEncoding.EncodeToString(key))
+}
+
+// VerifyPassword checks a password against an argon2id hash or a legacy
+// salted SHA-256 hash. needsRehash reports a match whose hash should be
+// replaced with HashPassword.
+func VerifyPassword(password, hash string) (ok bool, needsRehash bool) {
+	if strings.HasPrefix(hash, argon2Prefix) {
+		return verifyArgon2id(password, hash)
+	}
+	expected := LegacyHashPassword(password, LegacyPasswordSalt)
+	if subtle.ConstantTimeCompare([]byte(expected), []byte(hash)) == 1 {
+		return true, true
+	}
+	return false, false
+}
+
+// LegacyHashPassword is the single-round SHA-256 scheme used before argon2id.
+// It is kept only to verify existing hashes.
+func LegacyHashPassword(password, salt string) string {
+	hash := sha256.Sum256([]byte(salt + ":" + password))
+	return hex.EncodeToString(hash[:])
+}
+
+func verifyArgon2id(password, hash string) (bool, bool) {
+	parts := strings.Split(hash, "$")
+	if len(parts) != 6 {
+		return false, false
+	}
+	var version int
+	if _, err := fmt.Sscanf(parts[2], "v=%d", &version); err != nil || version != argon2.Version {
+		return false, false
+	}
+	var memory, time uint32
+	var threads uint8
+	if _, err := fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &memory, &time, &threads); err != nil || memory == 0 || time == 0 || threads == 0 {
+		return false, false
+	}
+	salt, err := base64.RawStdEncoding.DecodeString(parts[4])
+	if err != nil {
+		return false, false
+	}
+	key, err := base64.RawStdEncoding.DecodeString(parts[5])
+	if err != nil || len(key) == 0 {
+		return false, false
+	}
+	actual := argon2.IDKey([]byte(password), salt, time, memory, threads, uint32(len(key)))
+	if subtle.ConstantTimeCompare(actual, key) != 1 {
+		return false, false
+	}
+	outdated := memory != argon2Memory || time != argon2Time || threads != argon2Threads || uint32(len(key)) != argon2KeyLen || len(salt) != argon2SaltLen
+	return true, outdated
+}
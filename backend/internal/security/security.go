@@ -1,74 +1,63 @@
 package security
 
 import (
-	"crypto/hmac"
-	"crypto/sha256"
-	"encoding/base64"
-	"encoding/hex"
 	"encoding/json"
-	"errors"
-	"fmt"
-	"strings"
-	"time"
 
 	"backend/internal/models"
 )
 
+// Claims are the RFC 7519 registered claims plus the user claims the API
+// authorizes on.
 type Claims struct {
 	UserID string          `json:"uid"`
 	Email  string          `json:"email"`
 	Role   models.RoleName `json:"role"`
 	Exp    int64           `json:"exp"`
+	// SessionID ties the token to a server-side session that can be revoked.
+	SessionID string `json:"sid,omitempty"`
+	// APIKeyID and Scopes are set, instead of a user and role, for requests
+	// authenticated with an API key; they never appear in tokens.
+	APIKeyID string   `json:"-"`
+	Scopes   []string `json:"-"`
+
+	Issuer    string   `json:"iss,omitempty"`
+	Subject   string   `json:"sub,omitempty"`
+	Audience  Audience `json:"aud,omitempty"`
+	IssuedAt  int64    `json:"iat,omitempty"`
+	NotBefore int64    `json:"nbf,omitempty"`
+	ID        string   `json:"jti,omitempty"`
 }
 
-func HashPassword(password, salt string) string {
-	hash := sha256.Sum256([]byte(salt + ":" + password))
-	return hex.EncodeToString(hash[:])
-}
-
-func VerifyPassword(password, hash, salt string) bool {
-	return HashPassword(password, salt) == hash
-}
+// Audience is the aud claim, which RFC 7519 allows as a single string or an
+// array of strings.
+type Audience []string
 
-func SignToken(secret string, claims Claims) (string, error) {
-	payload, err := json.Marshal(claims)
-	if err != nil {
-		return "", err
+func (a Audience) MarshalJSON() ([]byte, error) {
+	if len(a) == 1 {
+		return json.Marshal(a[0])
 	}
-	payloadEnc := base64.RawURLEncoding.EncodeToString(payload)
-	signature := sign(secret, payloadEnc)
-	return payloadEnc + "." + signature, nil
+	return json.Marshal([]string(a))
 }
 
-func ParseToken(secret, token string) (Claims, error) {
-	parts := strings.Split(token, ".")
-	if len(parts) != 2 {
-		return Claims{}, errors.New("invalid token format")
-	}
-	payloadEnc := parts[0]
-	expected := sign(secret, payloadEnc)
-	if !hmac.Equal([]byte(expected), []byte(parts[1])) {
-		return Claims{}, errors.New("invalid token signature")
-	}
-
-	payload, err := base64.RawURLEncoding.DecodeString(payloadEnc)
-	if err != nil {
-		return Claims{}, fmt.Errorf("invalid token payload: %w", err)
+func (a *Audience) UnmarshalJSON(data []byte) error {
+	var single string
+	if err := json.Unmarshal(data, &single); err == nil {
+		*a = Audience{single}
+		return nil
 	}
-
-	var claims Claims
-	if err := json.Unmarshal(payload, &claims); err != nil {
-		return Claims{}, fmt.Errorf("invalid token claims: %w", err)
+	var many []string
+	if err := json.Unmarshal(data, &many); err != nil {
+		return err
 	}
-
-	if claims.Exp > 0 && time.Now().UTC().Unix() > claims.Exp {
-		return Claims{}, errors.New("token expired")
-	}
-	return claims, nil
+	*a = many
+	return nil
 }
 
-func sign(secret, payload string) string {
-	mac := hmac.New(sha256.New, []byte(secret))
-	_, _ = mac.Write([]byte(payload))
-	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+func (a Audience) Contains(value string) bool {
+	for _, item := range a {
+		if item == value {
+			return true
+		}
+	}
+	return false
 }
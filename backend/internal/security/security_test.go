@@ -1,52 +1,280 @@
 package security
 
 import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 
 	"backend/internal/models"
 )
 
-func TestHashPasswordDeterministic(t *testing.T) {
-	h1 := HashPassword("pass123", "salt")
-	h2 := HashPassword("pass123", "salt")
-	if h1 != h2 {
-		t.Fatalf("expected same hash, got %s vs %s", h1, h2)
+func TestHashPasswordUsesRandomSalt(t *testing.T) {
+	h1 := HashPassword("pass123")
+	h2 := HashPassword("pass123")
+	if h1 == h2 {
+		t.Fatalf("expected different hashes for the same password, got %s twice", h1)
+	}
+	if !strings.HasPrefix(h1, "$argon2id$v=19$") {
+		t.Fatalf("expected a versioned argon2id hash, got %s", h1)
 	}
 }
 
 func TestVerifyPassword(t *testing.T) {
-	h := HashPassword("pass123", "salt")
-	if !VerifyPassword("pass123", h, "salt") {
-		t.Fatalf("expected password to verify")
+	h := HashPassword("pass123")
+	if ok, rehash := VerifyPassword("pass123", h); !ok || rehash {
+		t.Fatalf("expected password to verify without rehash, got ok=%v rehash=%v", ok, rehash)
 	}
-	if VerifyPassword("wrong", h, "salt") {
+	if ok, _ := VerifyPassword("wrong", h); ok {
 		t.Fatalf("expected wrong password to fail")
 	}
+	if ok, _ := VerifyPassword("pass123", "$argon2id$v=19$m=bad"); ok {
+		t.Fatalf("expected malformed hash to fail")
+	}
+}
+
+func TestVerifyLegacyPasswordNeedsRehash(t *testing.T) {
+	legacy := LegacyHashPassword("pass123", LegacyPasswordSalt)
+	if ok, rehash := VerifyPassword("pass123", legacy); !ok || !rehash {
+		t.Fatalf("expected legacy hash to verify and need rehash, got ok=%v rehash=%v", ok, rehash)
+	}
+	if ok, _ := VerifyPassword("wrong", legacy); ok {
+		t.Fatalf("expected wrong password to fail against legacy hash")
+	}
+}
+
+func newTestKeySet(t *testing.T, opts KeySetOptions) *KeySet {
+	t.Helper()
+	if opts.Issuer == "" {
+		opts.Issuer = "test-issuer"
+	}
+	if opts.Audience == "" {
+		opts.Audience = "test-audience"
+	}
+	keys, err := NewKeySet(opts)
+	if err != nil {
+		t.Fatalf("key set: %v", err)
+	}
+	return keys
+}
+
+func writePEM(t *testing.T, dir, name, blockType string, der []byte) {
+	t.Helper()
+	data := pem.EncodeToMemory(&pem.Block{Type: blockType, Bytes: der})
+	if err := os.WriteFile(filepath.Join(dir, name), data, 0o600); err != nil {
+		t.Fatalf("write key: %v", err)
+	}
 }
 
 func TestSignAndParseToken(t *testing.T) {
+	keys := newTestKeySet(t, KeySetOptions{Secret: "secret"})
 	claims := Claims{UserID: "u1", Email: "a@b.c", Role: models.RoleAdmin, Exp: time.Now().Add(1 * time.Hour).Unix()}
-	token, err := SignToken("secret", claims)
+	token, err := keys.Sign(claims)
 	if err != nil {
 		t.Fatalf("sign failed: %v", err)
 	}
-	parsed, err := ParseToken("secret", token)
+	if parts := strings.Split(token, "."); len(parts) != 3 {
+		t.Fatalf("expected a three part JWT, got %s", token)
+	}
+	parsed, err := keys.Parse(token)
 	if err != nil {
 		t.Fatalf("parse failed: %v", err)
 	}
 	if parsed.UserID != claims.UserID || parsed.Role != claims.Role {
 		t.Fatalf("claims mismatch")
 	}
+	if parsed.Issuer != "test-issuer" || !parsed.Audience.Contains("test-audience") || parsed.Subject != "u1" || parsed.ID == "" || parsed.IssuedAt == 0 || parsed.NotBefore == 0 {
+		t.Fatalf("expected registered claims to be set, got %+v", parsed)
+	}
 }
 
 func TestExpiredToken(t *testing.T) {
+	keys := newTestKeySet(t, KeySetOptions{Secret: "secret"})
 	claims := Claims{UserID: "u1", Email: "a@b.c", Role: models.RoleAdmin, Exp: time.Now().Add(-1 * time.Hour).Unix()}
-	token, err := SignToken("secret", claims)
+	token, err := keys.Sign(claims)
 	if err != nil {
 		t.Fatalf("sign failed: %v", err)
 	}
-	if _, err := ParseToken("secret", token); err == nil {
+	if _, err := keys.Parse(token); err == nil {
 		t.Fatalf("expected expired token error")
 	}
 }
+
+func TestTokenRejectedForOtherIssuerOrAudience(t *testing.T) {
+	keys := newTestKeySet(t, KeySetOptions{Secret: "secret"})
+	claims := Claims{UserID: "u1", Exp: time.Now().Add(time.Hour).Unix()}
+	for _, other := range []*KeySet{
+		newTestKeySet(t, KeySetOptions{Secret: "secret", Issuer: "other-issuer"}),
+		newTestKeySet(t, KeySetOptions{Secret: "secret", Audience: "other-audience"}),
+	} {
+		token, err := other.Sign(claims)
+		if err != nil {
+			t.Fatalf("sign failed: %v", err)
+		}
+		if _, err := keys.Parse(token); err == nil {
+			t.Fatalf("expected a token for another issuer or audience to be rejected")
+		}
+	}
+}
+
+func TestRotatedSecretStillVerifies(t *testing.T) {
+	claims := Claims{UserID: "u1", Exp: time.Now().Add(time.Hour).Unix()}
+	old, err := newTestKeySet(t, KeySetOptions{Secret: "old-secret"}).Sign(claims)
+	if err != nil {
+		t.Fatalf("sign failed: %v", err)
+	}
+	rotated := newTestKeySet(t, KeySetOptions{Secret: "new-secret", PreviousSecrets: []string{"old-secret"}})
+	if _, err := rotated.Parse(old); err != nil {
+		t.Fatalf("expected a token of the previous secret to verify: %v", err)
+	}
+	if _, err := newTestKeySet(t, KeySetOptions{Secret: "new-secret"}).Parse(old); err == nil {
+		t.Fatalf("expected a token of a dropped secret to be rejected")
+	}
+}
+
+func TestAsymmetricKeysSignVerifyAndPublishJWKS(t *testing.T) {
+	dir := t.TempDir()
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("generate rsa: %v", err)
+	}
+	rsaDER, _ := x509.MarshalPKCS8PrivateKey(rsaKey)
+	writePEM(t, dir, "rsa-1.pem", "PRIVATE KEY", rsaDER)
+	edPublic, edPrivate, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("generate ed25519: %v", err)
+	}
+	edDER, _ := x509.MarshalPKCS8PrivateKey(edPrivate)
+	writePEM(t, dir, "ed-1.pem", "PRIVATE KEY", edDER)
+
+	claims := Claims{UserID: "u1", Role: models.RoleManager, Exp: time.Now().Add(time.Hour).Unix()}
+	rsaToken, err := newTestKeySet(t, KeySetOptions{Secret: "secret", KeysDir: dir, SigningKeyID: "rsa-1"}).Sign(claims)
+	if err != nil {
+		t.Fatalf("sign rs256: %v", err)
+	}
+
+	// Rotate to Ed25519, keeping only the public half of the RSA key.
+	verifyDir := t.TempDir()
+	rsaPublic, _ := x509.MarshalPKIXPublicKey(&rsaKey.PublicKey)
+	writePEM(t, verifyDir, "rsa-1.pem", "PUBLIC KEY", rsaPublic)
+	writePEM(t, verifyDir, "ed-1.pem", "PRIVATE KEY", edDER)
+	keys := newTestKeySet(t, KeySetOptions{Secret: "secret", KeysDir: verifyDir, SigningKeyID: "ed-1"})
+	edToken, err := keys.Sign(claims)
+	if err != nil {
+		t.Fatalf("sign eddsa: %v", err)
+	}
+	for _, token := range []string{rsaToken, edToken} {
+		if parsed, err := keys.Parse(token); err != nil || parsed.Role != models.RoleManager {
+			t.Fatalf("expected token to verify after rotation: %v", err)
+		}
+	}
+	if _, err := NewKeySet(KeySetOptions{Issuer: "i", Audience: "a", KeysDir: verifyDir, SigningKeyID: "rsa-1"}); err == nil {
+		t.Fatalf("expected a public key to be refused for signing")
+	}
+
+	jwks := keys.JWKS()
+	if len(jwks.Keys) != 2 {
+		t.Fatalf("expected two public keys and no HMAC secret, got %+v", jwks.Keys)
+	}
+	for _, jwk := range jwks.Keys {
+		switch jwk.Kid {
+		case "ed-1":
+			if jwk.Kty != "OKP" || jwk.Crv != "Ed25519" || jwk.X != base64.RawURLEncoding.EncodeToString(edPublic) {
+				t.Fatalf("unexpected Ed25519 JWK %+v", jwk)
+			}
+		case "rsa-1":
+			if jwk.Kty != "RSA" || jwk.Alg != AlgRS256 || jwk.E != "AQAB" {
+				t.Fatalf("unexpected RSA JWK %+v", jwk)
+			}
+		default:
+			t.Fatalf("unexpected JWK %+v", jwk)
+		}
+	}
+}
+
+func TestPublishedJWKsVerifyTokens(t *testing.T) {
+	dir := t.TempDir()
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("generate rsa: %v", err)
+	}
+	rsaDER, _ := x509.MarshalPKCS8PrivateKey(rsaKey)
+	writePEM(t, dir, "rsa-1.pem", "PRIVATE KEY", rsaDER)
+	keys := newTestKeySet(t, KeySetOptions{KeysDir: dir, SigningKeyID: "rsa-1"})
+	token, err := keys.Sign(Claims{UserID: "u1", Exp: time.Now().Add(time.Hour).Unix()})
+	if err != nil {
+		t.Fatalf("sign rs256: %v", err)
+	}
+
+	key, err := keys.JWKS().Keys[0].Key()
+	if err != nil || key.CanSign() {
+		t.Fatalf("expected a verify-only key from the JWK, got %+v: %v", key, err)
+	}
+	lookup := func(kid string) (Key, bool) { return key, kid == key.ID }
+	if _, err := VerifyJWT(token, lookup); err != nil {
+		t.Fatalf("expected the published key to verify the token: %v", err)
+	}
+	if _, err := VerifyJWT(token[:len(token)-4]+"AAAA", lookup); err == nil {
+		t.Fatalf("expected a tampered signature to be rejected")
+	}
+	if _, err := (JWK{Kty: "RSA", Kid: "x", N: "AQAB", E: "AQAB"}).Key(); err == nil {
+		t.Fatalf("expected a short RSA modulus to be rejected")
+	}
+	if _, err := (JWK{Kty: "oct", Kid: "x"}).Key(); err == nil {
+		t.Fatalf("expected symmetric JWKs to be rejected")
+	}
+}
+
+func TestTokenAlgorithmMustMatchKey(t *testing.T) {
+	keys := newTestKeySet(t, KeySetOptions{Secret: "secret"})
+	token, err := keys.Sign(Claims{UserID: "u1", Exp: time.Now().Add(time.Hour).Unix()})
+	if err != nil {
+		t.Fatalf("sign failed: %v", err)
+	}
+	parts := strings.Split(token, ".")
+	header, _ := base64.RawURLEncoding.DecodeString(parts[0])
+	forged := strings.Replace(string(header), `"alg":"HS256"`, `"alg":"none"`, 1)
+	parts[0] = base64.RawURLEncoding.EncodeToString([]byte(forged))
+	if _, err := keys.Parse(strings.Join(parts[:2], ".") + "."); err == nil {
+		t.Fatalf("expected alg none to be rejected")
+	}
+}
+
+func TestTOTPMatchesRFC6238Vectors(t *testing.T) {
+	// RFC 6238 appendix B SHA-1 seed "12345678901234567890", last six digits.
+	secret := "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+	vectors := map[int64]string{59: "287082", 1111111109: "081804", 1234567890: "005924", 2000000000: "279037"}
+	for unix, want := range vectors {
+		got, err := TOTPCode(secret, TOTPStep(time.Unix(unix, 0)))
+		if err != nil || got != want {
+			t.Fatalf("expected code %s at %d, got %s (%v)", want, unix, got, err)
+		}
+	}
+}
+
+func TestVerifyTOTPAllowsOneStepOfDrift(t *testing.T) {
+	secret := GenerateTOTPSecret()
+	now := time.Unix(1_700_000_000, 0)
+	previous, _ := TOTPCode(secret, TOTPStep(now)-1)
+	if step, ok := VerifyTOTP(secret, previous, now); !ok || step != TOTPStep(now)-1 {
+		t.Fatalf("expected code of the previous step to verify, got %d %v", step, ok)
+	}
+	stale, _ := TOTPCode(secret, TOTPStep(now)-3)
+	if _, ok := VerifyTOTP(secret, stale, now); ok {
+		t.Fatalf("expected a code three steps old to be rejected")
+	}
+	if _, ok := VerifyTOTP(secret, "12345", now); ok {
+		t.Fatalf("expected a short code to be rejected")
+	}
+	uri := TOTPURI("Maison & Co.", "admin@maison.co", secret)
+	if !strings.HasPrefix(uri, "otpauth://totp/Maison%20&%20Co.:admin@maison.co?") || !strings.Contains(uri, "secret="+secret) {
+		t.Fatalf("unexpected otpauth uri %s", uri)
+	}
+}
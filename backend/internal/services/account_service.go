@@ -0,0 +1,164 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net/url"
+	"strings"
+	"time"
+
+	"backend/internal/models"
+	"backend/internal/repositories"
+	"backend/internal/security"
+
+	"gorm.io/gorm"
+)
+
+var ErrInvalidAccountToken = errors.New("invalid or expired token")
+
+// AccountService mails password reset and email verification links and
+// redeems their tokens.
+type AccountService struct {
+	users  *repositories.UserRepository
+	tokens *repositories.AccountTokenRepository
+	auth   *AuthService
+	mailer Mailer
+	// resetTTL and verificationTTL are how long the mailed links work.
+	resetTTL        time.Duration
+	verificationTTL time.Duration
+	// publicURL is the storefront address the links point to.
+	publicURL string
+}
+
+func NewAccountService(users *repositories.UserRepository, tokens *repositories.AccountTokenRepository, auth *AuthService, mailer Mailer, resetTTL, verificationTTL time.Duration, publicURL string) *AccountService {
+	return &AccountService{users: users, tokens: tokens, auth: auth, mailer: mailer, resetTTL: resetTTL, verificationTTL: verificationTTL, publicURL: publicURL}
+}
+
+// ForgotPassword mails a reset link. Unknown and blocked addresses are
+// ignored without an error so the endpoint does not reveal which accounts
+// exist; the returned user is empty in that case.
+func (s *AccountService) ForgotPassword(email string) (models.User, error) {
+	email = strings.TrimSpace(strings.ToLower(email))
+	if email == "" {
+		return models.User{}, errors.New("email is required")
+	}
+	user, err := s.users.FindByEmail(email)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return models.User{}, nil
+		}
+		return models.User{}, err
+	}
+	if user.IsBlocked {
+		return models.User{}, nil
+	}
+
+	token, err := s.issue(user, models.AccountTokenPasswordReset, s.resetTTL)
+	if err != nil {
+		return models.User{}, err
+	}
+	link := s.publicURL + "/reset-password?token=" + url.QueryEscape(token)
+	body := fmt.Sprintf("Hello %s,\n\nuse this link within %s to choose a new password:\n%s\n\nIf you did not ask for a reset, ignore this message.", user.Name, s.resetTTL, link)
+	return user, s.mailer.Send(user.Email, "Password reset", body)
+}
+
+// ResetPassword sets a new password with a reset token and signs the user
+// out of every session.
+func (s *AccountService) ResetPassword(token, password string) (models.User, error) {
+	if strings.TrimSpace(password) == "" {
+		return models.User{}, errors.New("password is required")
+	}
+	stored, user, err := s.find(token, models.AccountTokenPasswordReset)
+	if err != nil {
+		return models.User{}, err
+	}
+	ok, err := s.tokens.ResetPassword(stored, security.HashPassword(password), time.Now().UTC())
+	if err != nil {
+		return models.User{}, err
+	}
+	if !ok {
+		return models.User{}, ErrInvalidAccountToken
+	}
+	if _, err := s.auth.RevokeUserSessions(user.ID, "password reset"); err != nil {
+		log.Printf("account: revoke sessions of %s: %v", user.Email, err)
+	}
+	return user, nil
+}
+
+// SendVerification mails a verification link unless the email is verified.
+func (s *AccountService) SendVerification(user models.User) error {
+	if user.EmailVerifiedAt != nil {
+		return errors.New("email is already verified")
+	}
+	token, err := s.issue(user, models.AccountTokenEmailVerification, s.verificationTTL)
+	if err != nil {
+		return err
+	}
+	link := s.publicURL + "/verify-email?token=" + url.QueryEscape(token)
+	body := fmt.Sprintf("Hello %s,\n\nconfirm your email address with this link:\n%s", user.Name, link)
+	return s.mailer.Send(user.Email, "Confirm your email", body)
+}
+
+// ResendVerification mails a fresh verification link to a signed-in user.
+func (s *AccountService) ResendVerification(userID string) (models.User, error) {
+	user, err := s.users.FindByID(userID)
+	if err != nil {
+		return models.User{}, err
+	}
+	return user, s.SendVerification(user)
+}
+
+// AllowSingleSignOnLink records whether single sign-on may link the user's
+// account when it is an Admin or Client account.
+func (s *AccountService) AllowSingleSignOnLink(userID string, allowed bool) (models.User, error) {
+	if err := s.users.SetSSOLinkAllowed(userID, allowed); err != nil {
+		return models.User{}, err
+	}
+	return s.users.FindByID(userID)
+}
+
+// VerifyEmail marks the email of the token's user as verified.
+func (s *AccountService) VerifyEmail(token string) (models.User, error) {
+	stored, user, err := s.find(token, models.AccountTokenEmailVerification)
+	if err != nil {
+		return models.User{}, err
+	}
+	ok, err := s.tokens.VerifyEmail(stored, time.Now().UTC())
+	if err != nil {
+		return models.User{}, err
+	}
+	if !ok {
+		return models.User{}, ErrInvalidAccountToken
+	}
+	return user, nil
+}
+
+func (s *AccountService) issue(user models.User, purpose models.AccountTokenPurpose, ttl time.Duration) (string, error) {
+	token, hash := newOpaqueToken()
+	now := time.Now().UTC()
+	err := s.tokens.Create(&models.AccountToken{UserID: user.ID, Purpose: purpose, TokenHash: hash, ExpiresAt: now.Add(ttl), CreatedAt: now})
+	return token, err
+}
+
+func (s *AccountService) find(token string, purpose models.AccountTokenPurpose) (models.AccountToken, models.User, error) {
+	token = strings.TrimSpace(token)
+	if token == "" {
+		return models.AccountToken{}, models.User{}, ErrInvalidAccountToken
+	}
+	stored, err := s.tokens.Find(hashOpaqueToken(token), purpose)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return models.AccountToken{}, models.User{}, ErrInvalidAccountToken
+		}
+		return models.AccountToken{}, models.User{}, err
+	}
+	if stored.UsedAt != nil || !time.Now().UTC().Before(stored.ExpiresAt) {
+		return models.AccountToken{}, models.User{}, ErrInvalidAccountToken
+	}
+	user, err := s.users.FindByID(stored.UserID)
+	if err != nil {
+		return models.AccountToken{}, models.User{}, err
+	}
+	return stored, user, nil
+}
@@ -0,0 +1,179 @@
+package services
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"time"
+
+	"backend/internal/models"
+	"backend/internal/repositories"
+	"backend/internal/security"
+
+	"gorm.io/gorm"
+)
+
+const (
+	// apiKeyPrefixLength covers models.APIKeyPrefix and eight hex digits.
+	apiKeyPrefixLength  = len(models.APIKeyPrefix) + 8
+	apiKeyTouchInterval = time.Minute
+)
+
+var ErrInvalidAPIKey = errors.New("invalid api key")
+
+type APIKeyInput struct {
+	Name      string     `json:"name"`
+	Scopes    []string   `json:"scopes"`
+	ExpiresAt *time.Time `json:"expires_at"`
+}
+
+// APIKeyService issues scoped keys for integrations and authenticates the
+// requests made with them.
+type APIKeyService struct {
+	repo        *repositories.APIKeyRepository
+	permissions *repositories.PermissionRepository
+	users       *repositories.UserRepository
+	// access decides what the creator's role allows; a key never exceeds it.
+	access *PermissionService
+	// ttl is how long a key lives when it is created without an expiry.
+	ttl time.Duration
+}
+
+func NewAPIKeyService(repo *repositories.APIKeyRepository, permissions *repositories.PermissionRepository, users *repositories.UserRepository, access *PermissionService, ttl time.Duration) *APIKeyService {
+	return &APIKeyService{repo: repo, permissions: permissions, users: users, access: access, ttl: ttl}
+}
+
+func (s *APIKeyService) List() ([]models.APIKey, error) {
+	return s.repo.List()
+}
+
+// Create issues a key allowed exactly the given permissions, each of which
+// the creator's role must be allowed. The returned key is the only copy;
+// just its hash is kept.
+func (s *APIKeyService) Create(input APIKeyInput, createdBy string, role models.RoleName) (models.APIKeyCreated, error) {
+	name := strings.TrimSpace(input.Name)
+	if name == "" || len(name) > 120 {
+		return models.APIKeyCreated{}, errors.New("name must be 1 to 120 characters")
+	}
+	scopes, err := s.validScopes(input.Scopes, role)
+	if err != nil {
+		return models.APIKeyCreated{}, err
+	}
+	now := time.Now().UTC()
+	expiresAt := now.Add(s.ttl)
+	if input.ExpiresAt != nil {
+		if !input.ExpiresAt.After(now) {
+			return models.APIKeyCreated{}, errors.New("expires_at must be in the future")
+		}
+		expiresAt = input.ExpiresAt.UTC()
+	}
+
+	prefixBytes := make([]byte, 4)
+	if _, err := rand.Read(prefixBytes); err != nil {
+		return models.APIKeyCreated{}, err
+	}
+	prefix := models.APIKeyPrefix + hex.EncodeToString(prefixBytes)
+	secret, _ := newOpaqueToken()
+	plain := prefix + "_" + secret
+
+	key := models.APIKey{
+		ID:        repositories.GenerateID("key"),
+		Name:      name,
+		Prefix:    prefix,
+		KeyHash:   hashOpaqueToken(plain),
+		Scopes:    scopes,
+		CreatedBy: createdBy,
+		ExpiresAt: expiresAt,
+	}
+	if err := s.repo.Create(&key); err != nil {
+		return models.APIKeyCreated{}, err
+	}
+	return models.APIKeyCreated{APIKey: key, Key: plain}, nil
+}
+
+func (s *APIKeyService) Revoke(id string) (models.APIKey, error) {
+	return s.repo.Revoke(strings.TrimSpace(id), time.Now().UTC())
+}
+
+// AuthenticateAPIKey resolves an active key to the claims its request runs
+// with: the key's scopes, bounded by the current role of the user who
+// created it. Keys of blocked or missing creators stop working.
+func (s *APIKeyService) AuthenticateAPIKey(plain string) (security.Claims, error) {
+	if len(plain) <= apiKeyPrefixLength || !strings.HasPrefix(plain, models.APIKeyPrefix) {
+		return security.Claims{}, ErrInvalidAPIKey
+	}
+	key, err := s.repo.FindByPrefix(plain[:apiKeyPrefixLength])
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return security.Claims{}, ErrInvalidAPIKey
+		}
+		return security.Claims{}, err
+	}
+	now := time.Now().UTC()
+	if subtle.ConstantTimeCompare([]byte(hashOpaqueToken(plain)), []byte(key.KeyHash)) != 1 || !key.IsActive(now) {
+		return security.Claims{}, ErrInvalidAPIKey
+	}
+	creator, err := s.users.FindByEmail(key.CreatedBy)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return security.Claims{}, ErrInvalidAPIKey
+		}
+		return security.Claims{}, err
+	}
+	if creator.IsBlocked {
+		return security.Claims{}, ErrInvalidAPIKey
+	}
+	if err := s.repo.Touch(key.ID, now, apiKeyTouchInterval); err != nil {
+		log.Printf("api keys: record use of %s: %v", key.Prefix, err)
+	}
+	return security.Claims{
+		Email:    key.Principal(),
+		Role:     creator.Role.Name,
+		Exp:      key.ExpiresAt.Unix(),
+		APIKeyID: key.ID,
+		Scopes:   key.Scopes,
+	}, nil
+}
+
+// validScopes normalizes scopes and requires each to name an existing allow
+// permission that role is allowed.
+func (s *APIKeyService) validScopes(scopes []string, role models.RoleName) (models.ScopeList, error) {
+	seen := map[string]struct{}{}
+	list := models.ScopeList{}
+	for _, scope := range scopes {
+		action, resource, ok := strings.Cut(strings.ToLower(strings.TrimSpace(scope)), ":")
+		if !ok || action == "" || resource == "" {
+			return nil, fmt.Errorf("scope %q must be action:resource", scope)
+		}
+		key := action + ":" + resource
+		if _, dup := seen[key]; dup {
+			continue
+		}
+		exists, err := s.permissions.PermissionExists(action, resource, models.PermissionAllow)
+		if err != nil {
+			return nil, err
+		}
+		if !exists {
+			return nil, fmt.Errorf("unknown scope %s", key)
+		}
+		allowed, err := s.access.Allowed(role, action, resource)
+		if err != nil {
+			return nil, err
+		}
+		if !allowed {
+			return nil, fmt.Errorf("scope %s is not allowed to your role", key)
+		}
+		seen[key] = struct{}{}
+		list = append(list, key)
+	}
+	if len(list) == 0 {
+		return nil, errors.New("at least one scope is required")
+	}
+	sort.Strings(list)
+	return list, nil
+}
@@ -1,7 +1,12 @@
 package services
 
 import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
 	"errors"
+	"log"
 	"strings"
 	"time"
 
@@ -13,13 +18,37 @@ import (
 )
 
 type AuthService struct {
-	users  *repositories.UserRepository
-	secret string
-	salt   string
+	users     *repositories.UserRepository
+	sessions  *repositories.SessionRepository
+	twoFactor *repositories.TwoFactorRepository
+	keys      *security.KeySet
+	lifetimes SessionLifetimes
 }
 
-func NewAuthService(users *repositories.UserRepository, secret string) *AuthService {
-	return &AuthService{users: users, secret: secret, salt: "maison-salt"}
+var (
+	ErrInvalidRefreshToken = errors.New("invalid refresh token")
+	// ErrRefreshTokenReused means a rotated refresh token was presented again;
+	// the session it belongs to is revoked.
+	ErrRefreshTokenReused = errors.New("refresh token reuse detected, session revoked")
+	ErrSessionRevoked     = errors.New("session is revoked or expired")
+	// ErrEmailNotVerified refuses actions that trust the account's email
+	// before its owner followed the verification link.
+	ErrEmailNotVerified = errors.New("email is not verified")
+)
+
+// SessionLifetimes bounds the tokens an AuthService issues.
+type SessionLifetimes struct {
+	AccessToken time.Duration
+	// RefreshToken bounds the lifetime of a session. Rotation does not
+	// extend it, so users sign in again at least this often.
+	RefreshToken time.Duration
+	// ReuseGrace is how long the token that was just rotated may be
+	// presented again without revoking the session.
+	ReuseGrace time.Duration
+}
+
+func NewAuthService(users *repositories.UserRepository, sessions *repositories.SessionRepository, twoFactor *repositories.TwoFactorRepository, keys *security.KeySet, lifetimes SessionLifetimes) *AuthService {
+	return &AuthService{users: users, sessions: sessions, twoFactor: twoFactor, keys: keys, lifetimes: lifetimes}
 }
 
 func (s *AuthService) Login(email, password string) (models.LoginResponse, error) {
@@ -38,27 +67,261 @@ func (s *AuthService) Login(email, password string) (models.LoginResponse, error
 	if user.IsBlocked {
 		return models.LoginResponse{}, errors.New("user is blocked")
 	}
-	if !security.VerifyPassword(password, user.PasswordHash, s.salt) {
+	ok, needsRehash := security.VerifyPassword(password, user.PasswordHash)
+	if !ok {
 		return models.LoginResponse{}, errors.New("invalid email or password")
 	}
+	// Legacy and outdated hashes are replaced while the password is at hand.
+	if needsRehash {
+		if err := s.users.UpdatePasswordHash(user.ID, security.HashPassword(password)); err != nil {
+			log.Printf("auth: rehash password of %s: %v", user.Email, err)
+		}
+	}
+
+	return s.secondFactorOrSession(user)
+}
+
+// secondFactorOrSession starts a session for a user who proved who they
+// are. With two-factor authentication on, or required by the role, that
+// only earns a challenge for the second step.
+func (s *AuthService) secondFactorOrSession(user models.User) (models.LoginResponse, error) {
+	enrolled, err := s.twoFactor.IsEnabled(user.ID)
+	if err != nil {
+		return models.LoginResponse{}, err
+	}
+	if enrolled {
+		return s.challenge(user, models.TwoFactorRequired)
+	}
+	if user.Role.RequireTwoFactor {
+		return s.challenge(user, models.TwoFactorEnrollmentRequired)
+	}
+	return s.startSession(user)
+}
+
+func (s *AuthService) challenge(user models.User, purpose string) (models.LoginResponse, error) {
+	token, hash := newOpaqueToken()
+	now := time.Now().UTC()
+	err := s.twoFactor.CreateChallenge(&models.LoginChallenge{UserID: user.ID, Purpose: purpose, TokenHash: hash, ExpiresAt: now.Add(loginChallengeTTL), CreatedAt: now})
+	if err != nil {
+		return models.LoginResponse{}, err
+	}
+	return models.LoginResponse{User: adminUser(user), TwoFactor: purpose, Challenge: token}, nil
+}
+
+// startSession opens a session for a fully authenticated user and returns
+// its first token pair.
+func (s *AuthService) startSession(user models.User) (models.LoginResponse, error) {
+	now := time.Now().UTC()
+	refreshToken, hash := newOpaqueToken()
+	session := models.Session{
+		ID:         repositories.GenerateID("ses"),
+		UserID:     user.ID,
+		CreatedAt:  now,
+		LastUsedAt: now,
+		ExpiresAt:  now.Add(s.lifetimes.RefreshToken),
+	}
+	if err := s.sessions.Create(&session, &models.RefreshToken{TokenHash: hash, ExpiresAt: session.ExpiresAt}); err != nil {
+		return models.LoginResponse{}, err
+	}
+	return s.tokenPair(user, session.ID, refreshToken)
+}
+
+// Refresh exchanges a refresh token for a new access and refresh token pair.
+// Each refresh token works once; presenting a used one revokes the session,
+// since either the client or an attacker holds a stolen copy. The token that
+// was just rotated is still exchanged within the reuse grace, so two tabs
+// refreshing at once do not sign the user out.
+func (s *AuthService) Refresh(refreshToken string) (models.LoginResponse, error) {
+	refreshToken = strings.TrimSpace(refreshToken)
+	if refreshToken == "" {
+		return models.LoginResponse{}, ErrInvalidRefreshToken
+	}
+	stored, err := s.sessions.FindRefreshToken(hashOpaqueToken(refreshToken))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return models.LoginResponse{}, ErrInvalidRefreshToken
+		}
+		return models.LoginResponse{}, err
+	}
+	session, err := s.sessions.Get(stored.SessionID)
+	if err != nil {
+		return models.LoginResponse{}, err
+	}
+	now := time.Now().UTC()
+	if stored.UsedAt == nil {
+		if err := s.checkRefresh(session, stored, now); err != nil {
+			return models.LoginResponse{}, err
+		}
+		next, hash := newOpaqueToken()
+		rotated, err := s.sessions.Rotate(stored.ID, &models.RefreshToken{SessionID: session.ID, TokenHash: hash, ExpiresAt: session.ExpiresAt}, now)
+		if err != nil {
+			return models.LoginResponse{}, err
+		}
+		if rotated {
+			return s.tokenPair(session.User, session.ID, next)
+		}
+		// A concurrent refresh rotated it first.
+		if stored, err = s.sessions.FindRefreshToken(stored.TokenHash); err != nil {
+			return models.LoginResponse{}, err
+		}
+	}
+
+	graced, err := s.inReuseGrace(stored, now)
+	if err != nil {
+		return models.LoginResponse{}, err
+	}
+	if !graced {
+		return models.LoginResponse{User: adminUser(session.User)}, s.reuse(session, now)
+	}
+	if err := s.checkRefresh(session, stored, now); err != nil {
+		return models.LoginResponse{}, err
+	}
+	next, hash := newOpaqueToken()
+	if err := s.sessions.AddRefreshToken(&models.RefreshToken{SessionID: session.ID, TokenHash: hash, ExpiresAt: session.ExpiresAt}, now); err != nil {
+		return models.LoginResponse{}, err
+	}
+	return s.tokenPair(session.User, session.ID, next)
+}
+
+func (s *AuthService) checkRefresh(session models.Session, stored models.RefreshToken, now time.Time) error {
+	if !session.IsActive(now) || !now.Before(stored.ExpiresAt) {
+		return ErrSessionRevoked
+	}
+	if session.User.IsBlocked {
+		return errors.New("user is blocked")
+	}
+	return nil
+}
+
+// inReuseGrace reports whether a used refresh token was rotated within the
+// reuse grace and none of the tokens issued after it has been used yet.
+func (s *AuthService) inReuseGrace(stored models.RefreshToken, now time.Time) (bool, error) {
+	if stored.UsedAt == nil || now.Sub(*stored.UsedAt) > s.lifetimes.ReuseGrace {
+		return false, nil
+	}
+	used, err := s.sessions.HasUsedSuccessor(stored)
+	return !used, err
+}
+
+func (s *AuthService) reuse(session models.Session, at time.Time) error {
+	if err := s.sessions.Revoke(session.ID, "refresh token reuse", at); err != nil {
+		return err
+	}
+	return ErrRefreshTokenReused
+}
+
+// Logout revokes the session of a refresh token, or the session named by the
+// sid claim of an access token when no refresh token is given. It returns
+// the session's user.
+func (s *AuthService) Logout(refreshToken, sessionID string) (models.User, error) {
+	if refreshToken = strings.TrimSpace(refreshToken); refreshToken != "" {
+		stored, err := s.sessions.FindRefreshToken(hashOpaqueToken(refreshToken))
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return models.User{}, ErrInvalidRefreshToken
+			}
+			return models.User{}, err
+		}
+		sessionID = stored.SessionID
+	}
+	if strings.TrimSpace(sessionID) == "" {
+		return models.User{}, errors.New("refresh token or session is required")
+	}
+	session, err := s.sessions.Get(sessionID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return models.User{}, ErrSessionRevoked
+		}
+		return models.User{}, err
+	}
+	if err := s.sessions.Revoke(session.ID, "logout", time.Now().UTC()); err != nil {
+		return models.User{}, err
+	}
+	return session.User, nil
+}
+
+// RevokeUserSessions logs a user out everywhere.
+func (s *AuthService) RevokeUserSessions(userID, reason string) (int64, error) {
+	return s.sessions.RevokeForUser(userID, reason, time.Now().UTC())
+}
+
+// CheckSession reports whether the session behind verified access token
+// claims is still usable: not revoked or expired and its user not blocked.
+func (s *AuthService) CheckSession(claims security.Claims) error {
+	if claims.SessionID == "" {
+		return ErrSessionRevoked
+	}
+	session, err := s.sessions.Get(claims.SessionID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrSessionRevoked
+		}
+		return err
+	}
+	if session.UserID != claims.UserID || !session.IsActive(time.Now().UTC()) {
+		return ErrSessionRevoked
+	}
+	if session.User.IsBlocked {
+		return errors.New("user is blocked")
+	}
+	return nil
+}
+
+// CheckEmailVerified reports ErrEmailNotVerified until the user has
+// verified their email.
+func (s *AuthService) CheckEmailVerified(userID string) error {
+	user, err := s.users.FindByID(userID)
+	if err != nil {
+		return err
+	}
+	if user.EmailVerifiedAt == nil {
+		return ErrEmailNotVerified
+	}
+	return nil
+}
 
+func (s *AuthService) tokenPair(user models.User, sessionID, refreshToken string) (models.LoginResponse, error) {
+	ttl := s.lifetimes.AccessToken
 	claims := security.Claims{
-		UserID: user.ID,
-		Email:  user.Email,
-		Role:   user.Role.Name,
-		Exp:    time.Now().UTC().Add(12 * time.Hour).Unix(),
+		UserID:    user.ID,
+		Email:     user.Email,
+		Role:      user.Role.Name,
+		Exp:       time.Now().UTC().Add(ttl).Unix(),
+		SessionID: sessionID,
 	}
-	token, err := security.SignToken(s.secret, claims)
+	token, err := s.keys.Sign(claims)
 	if err != nil {
 		return models.LoginResponse{}, err
 	}
 
 	return models.LoginResponse{
-		User: models.AdminUser{ID: user.ID, Email: user.Email, Name: user.Name, Role: user.Role.Name},
-		Token: token,
+		User:         adminUser(user),
+		Token:        token,
+		RefreshToken: refreshToken,
+		ExpiresIn:    int64(ttl / time.Second),
 	}, nil
 }
 
+func adminUser(user models.User) models.AdminUser {
+	return models.AdminUser{ID: user.ID, Email: user.Email, Name: user.Name, Role: user.Role.Name}
+}
+
+// newOpaqueToken returns a random token for refresh and account links and
+// the hash under which it is stored.
+func newOpaqueToken() (string, string) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		panic(err)
+	}
+	token := base64.RawURLEncoding.EncodeToString(buf)
+	return token, hashOpaqueToken(token)
+}
+
+func hashOpaqueToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
 func (s *AuthService) CreateUser(email, password, name string, role models.RoleName) (models.User, error) {
 	email = strings.TrimSpace(strings.ToLower(email))
 	if email == "" || strings.TrimSpace(password) == "" || strings.TrimSpace(name) == "" {
@@ -72,11 +335,16 @@ func (s *AuthService) CreateUser(email, password, name string, role models.RoleN
 	user := models.User{
 		ID:           repositories.GenerateID("u"),
 		Email:        email,
-		PasswordHash: security.HashPassword(password, s.salt),
+		PasswordHash: security.HashPassword(password),
 		Name:         strings.TrimSpace(name),
 		RoleID:       roleRow.ID,
 		IsBlocked:    false,
 	}
+	// Clients prove their address through the verification mail.
+	if role != models.RoleClient {
+		verifiedAt := time.Now().UTC()
+		user.EmailVerifiedAt = &verifiedAt
+	}
 	if err := s.users.Create(&user); err != nil {
 		return models.User{}, err
 	}
@@ -0,0 +1,139 @@
+package services
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image/png"
+	"strings"
+
+	"backend/internal/models"
+
+	"github.com/boombuler/barcode"
+	"github.com/boombuler/barcode/code128"
+	"github.com/boombuler/barcode/ean"
+	"github.com/boombuler/barcode/qr"
+)
+
+const maxCode128Length = 48
+
+// OrderURL is the admin page of an order under the storefront address,
+// encoded in QR codes on packing slips.
+func OrderURL(publicURL, orderID string) string {
+	return publicURL + "/admin/orders/" + orderID
+}
+
+// NormalizeBarcode validates a product barcode. Without a type, 13 digits are
+// taken as EAN-13 and anything else as Code128.
+func NormalizeBarcode(value string, typ models.BarcodeType) (string, models.BarcodeType, error) {
+	value = strings.TrimSpace(value)
+	typ = models.BarcodeType(strings.ToLower(strings.TrimSpace(string(typ))))
+	if value == "" {
+		if typ != "" {
+			return "", "", errors.New("barcode is required for barcode_type")
+		}
+		return "", "", nil
+	}
+	if typ == "" {
+		typ = models.BarcodeCode128
+		if len(value) == 13 && isDigits(value) {
+			typ = models.BarcodeEAN13
+		}
+	}
+	switch typ {
+	case models.BarcodeEAN13:
+		if len(value) != 13 || !isDigits(value) {
+			return "", "", errors.New("ean13 barcode must be 13 digits")
+		}
+		if want := EAN13CheckDigit(value[:12]); value[12] != want {
+			return "", "", fmt.Errorf("invalid ean13 check digit, expected %c", want)
+		}
+	case models.BarcodeCode128:
+		if len(value) > maxCode128Length {
+			return "", "", fmt.Errorf("code128 barcode must be at most %d characters", maxCode128Length)
+		}
+		for _, r := range value {
+			if r < 32 || r > 126 {
+				return "", "", errors.New("code128 barcode must be printable ASCII")
+			}
+		}
+	default:
+		return "", "", errors.New("barcode_type must be ean13 or code128")
+	}
+	return value, typ, nil
+}
+
+// EAN13CheckDigit computes the check digit for the first 12 digits of an
+// EAN-13: digits are weighted 1 and 3 alternately from the left.
+func EAN13CheckDigit(digits string) byte {
+	sum := 0
+	for i, r := range digits {
+		d := int(r - '0')
+		if i%2 == 1 {
+			d *= 3
+		}
+		sum += d
+	}
+	return byte('0' + (10-sum%10)%10)
+}
+
+func isDigits(value string) bool {
+	for _, r := range value {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return value != ""
+}
+
+// ProductBarcodeValue returns what a product's label encodes: its barcode, or
+// its SKU as Code128.
+func ProductBarcodeValue(p models.Product) (string, models.BarcodeType) {
+	if p.Barcode != "" && p.BarcodeType != "" {
+		return p.Barcode, p.BarcodeType
+	}
+	return p.SKU, models.BarcodeCode128
+}
+
+// RenderBarcodePNG renders a linear barcode scaled to width x height pixels.
+func RenderBarcodePNG(value string, typ models.BarcodeType, width, height int) ([]byte, error) {
+	var code barcode.Barcode
+	var err error
+	switch typ {
+	case models.BarcodeEAN13:
+		code, err = ean.Encode(value)
+	case models.BarcodeCode128:
+		code, err = code128.Encode(value)
+	default:
+		return nil, errors.New("unsupported barcode type")
+	}
+	if err != nil {
+		return nil, err
+	}
+	return scaledPNG(code, width, height)
+}
+
+// RenderQRPNG renders content as a square QR code of size pixels.
+func RenderQRPNG(content string, size int) ([]byte, error) {
+	code, err := qr.Encode(content, qr.M, qr.Auto)
+	if err != nil {
+		return nil, err
+	}
+	return scaledPNG(code, size, size)
+}
+
+func scaledPNG(code barcode.Barcode, width, height int) ([]byte, error) {
+	bounds := code.Bounds()
+	// Scaling below the module count would drop bars.
+	width = max(width, bounds.Dx())
+	height = max(height, bounds.Dy())
+	scaled, err := barcode.Scale(code, width, height)
+	if err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, scaled); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
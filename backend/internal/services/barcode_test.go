@@ -0,0 +1,43 @@
+package services
+
+import (
+	"testing"
+
+	"backend/internal/models"
+)
+
+func TestNormalizeBarcode(t *testing.T) {
+	cases := []struct {
+		value    string
+		typ      models.BarcodeType
+		wantType models.BarcodeType
+		wantErr  bool
+	}{
+		{value: "4006381333931", wantType: models.BarcodeEAN13},
+		{value: "4006381333932", wantErr: true},
+		{value: "400638133393", typ: models.BarcodeEAN13, wantErr: true},
+		{value: "4006381333932", typ: models.BarcodeCode128, wantType: models.BarcodeCode128},
+		{value: "LMP-SOLB-BRS", wantType: models.BarcodeCode128},
+		{value: "", typ: models.BarcodeEAN13, wantErr: true},
+		{value: "ABC", typ: "upc", wantErr: true},
+		{value: "", wantType: ""},
+	}
+	for _, tc := range cases {
+		_, typ, err := NormalizeBarcode(tc.value, tc.typ)
+		if tc.wantErr {
+			if err == nil {
+				t.Errorf("NormalizeBarcode(%q, %q) expected an error", tc.value, tc.typ)
+			}
+			continue
+		}
+		if err != nil || typ != tc.wantType {
+			t.Errorf("NormalizeBarcode(%q, %q) = %q, %v; want %q", tc.value, tc.typ, typ, err, tc.wantType)
+		}
+	}
+}
+
+func TestEAN13CheckDigit(t *testing.T) {
+	if got := EAN13CheckDigit("590123412345"); got != '7' {
+		t.Fatalf("expected check digit 7, got %c", got)
+	}
+}
@@ -0,0 +1,122 @@
+package services
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+
+	"backend/internal/repositories"
+)
+
+// CounterStore keeps fixed-window counters for rate limits and login
+// throttling. Hit and Get return the count and when the window ends.
+type CounterStore interface {
+	Hit(key string, window time.Duration, now time.Time) (int, time.Time, error)
+	Get(key string, now time.Time) (int, time.Time, error)
+	Delete(keys ...string) error
+}
+
+// NewCounterStore returns the store of the given kind: "database" shares
+// counters between instances, anything else keeps them in memory.
+func NewCounterStore(repo *repositories.RateCounterRepository, kind string) CounterStore {
+	if kind == "database" {
+		return DatabaseCounterStore{repo: repo}
+	}
+	return NewMemoryCounterStore()
+}
+
+type DatabaseCounterStore struct {
+	repo *repositories.RateCounterRepository
+}
+
+func (s DatabaseCounterStore) Hit(key string, window time.Duration, now time.Time) (int, time.Time, error) {
+	counter, err := s.repo.Hit(key, window, now)
+	return counter.Count, counter.ResetAt, err
+}
+
+func (s DatabaseCounterStore) Get(key string, now time.Time) (int, time.Time, error) {
+	counter, err := s.repo.Get(key, now)
+	return counter.Count, counter.ResetAt, err
+}
+
+func (s DatabaseCounterStore) Delete(keys ...string) error {
+	return s.repo.Delete(keys...)
+}
+
+type memoryCounter struct {
+	count   int
+	resetAt time.Time
+}
+
+type MemoryCounterStore struct {
+	mu       sync.Mutex
+	counters map[string]memoryCounter
+	sweepAt  time.Time
+}
+
+func NewMemoryCounterStore() *MemoryCounterStore {
+	return &MemoryCounterStore{counters: map[string]memoryCounter{}}
+}
+
+func (s *MemoryCounterStore) Hit(key string, window time.Duration, now time.Time) (int, time.Time, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.sweep(now)
+	counter, ok := s.counters[key]
+	if !ok || !now.Before(counter.resetAt) {
+		counter = memoryCounter{resetAt: now.Add(window)}
+	}
+	counter.count++
+	s.counters[key] = counter
+	return counter.count, counter.resetAt, nil
+}
+
+func (s *MemoryCounterStore) Get(key string, now time.Time) (int, time.Time, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	counter, ok := s.counters[key]
+	if !ok || !now.Before(counter.resetAt) {
+		return 0, time.Time{}, nil
+	}
+	return counter.count, counter.resetAt, nil
+}
+
+func (s *MemoryCounterStore) Delete(keys ...string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, key := range keys {
+		delete(s.counters, key)
+	}
+	return nil
+}
+
+// sweep drops ended windows about once a minute so the map stays bounded.
+func (s *MemoryCounterStore) sweep(now time.Time) {
+	if now.Before(s.sweepAt) {
+		return
+	}
+	for key, counter := range s.counters {
+		if !now.Before(counter.resetAt) {
+			delete(s.counters, key)
+		}
+	}
+	s.sweepAt = now.Add(time.Minute)
+}
+
+// PurgeExpiredCounters periodically deletes ended windows from the database
+// store.
+func PurgeExpiredCounters(ctx context.Context, repo *repositories.RateCounterRepository, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		if _, err := repo.DeleteExpired(time.Now().UTC()); err != nil {
+			log.Printf("rate counters: %v", err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,212 @@
+package services
+
+import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"backend/internal/models"
+	"backend/internal/repositories"
+)
+
+var currencyPattern = regexp.MustCompile(`^[A-Z]{3}$`)
+
+type ExchangeRateInput struct {
+	Currency string  `json:"currency"`
+	Rate     float64 `json:"rate"`
+	Rounding int64   `json:"rounding"`
+}
+
+type CurrencyService struct {
+	repo *repositories.ExchangeRateRepository
+	// baseCurrency is the currency product prices and order totals are
+	// stored in.
+	baseCurrency string
+}
+
+func NewCurrencyService(repo *repositories.ExchangeRateRepository, baseCurrency string) *CurrencyService {
+	return &CurrencyService{repo: repo, baseCurrency: baseCurrency}
+}
+
+// BaseCurrency is the currency product prices and order totals are stored in.
+func (s *CurrencyService) BaseCurrency() string {
+	return s.baseCurrency
+}
+
+// Rates lists all rates, starting with the base currency at rate 1.
+func (s *CurrencyService) Rates() ([]models.ExchangeRate, error) {
+	items, err := s.repo.List()
+	if err != nil {
+		return nil, err
+	}
+	return append([]models.ExchangeRate{s.base()}, items...), nil
+}
+
+// Rate returns the rate for a currency; an empty currency means the base one.
+func (s *CurrencyService) Rate(currency string) (models.ExchangeRate, error) {
+	currency = strings.ToUpper(strings.TrimSpace(currency))
+	if currency == "" || currency == s.baseCurrency {
+		return s.base(), nil
+	}
+	if !currencyPattern.MatchString(currency) {
+		return models.ExchangeRate{}, errors.New("currency must be a 3-letter ISO 4217 code")
+	}
+	rate, err := s.repo.Get(currency)
+	if IsNotFound(err) {
+		return models.ExchangeRate{}, fmt.Errorf("unsupported currency %s", currency)
+	}
+	return rate, err
+}
+
+func (s *CurrencyService) SetRates(inputs []ExchangeRateInput, actor string) ([]models.ExchangeRate, error) {
+	if len(inputs) == 0 {
+		return nil, errors.New("rates are required")
+	}
+	now := time.Now().UTC()
+	items := make([]models.ExchangeRate, 0, len(inputs))
+	seen := map[string]bool{}
+	for _, input := range inputs {
+		item, err := s.validateExchangeRate(input)
+		if err != nil {
+			return nil, err
+		}
+		if seen[item.Currency] {
+			return nil, fmt.Errorf("currency %s is listed more than once", item.Currency)
+		}
+		seen[item.Currency] = true
+		item.UpdatedBy = strings.TrimSpace(actor)
+		item.UpdatedAt = now
+		items = append(items, item)
+	}
+	if err := s.repo.Save(items); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+// Import reads rates from a CSV file with currency, rate and optional
+// rounding columns.
+func (s *CurrencyService) Import(data []byte, actor string) ([]models.ExchangeRate, error) {
+	r := csv.NewReader(bytes.NewReader(bytes.TrimPrefix(data, []byte("\xef\xbb\xbf"))))
+	r.FieldsPerRecord = -1
+	r.TrimLeadingSpace = true
+	records, err := r.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("invalid csv: %w", err)
+	}
+	if len(records) < 2 {
+		return nil, errors.New("file has no data rows")
+	}
+
+	columns := map[string]int{}
+	for i, name := range records[0] {
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	for _, required := range []string{"currency", "rate"} {
+		if _, ok := columns[required]; !ok {
+			return nil, fmt.Errorf("missing required column %q", required)
+		}
+	}
+
+	inputs := []ExchangeRateInput{}
+	for i, record := range records[1:] {
+		if isBlankRecord(record) {
+			continue
+		}
+		value := func(name string) string {
+			idx, ok := columns[name]
+			if !ok || idx >= len(record) {
+				return ""
+			}
+			return strings.TrimSpace(record[idx])
+		}
+		input := ExchangeRateInput{Currency: value("currency")}
+		if input.Rate, err = strconv.ParseFloat(value("rate"), 64); err != nil {
+			return nil, fmt.Errorf("row %d: rate must be a number", i+2)
+		}
+		if raw := value("rounding"); raw != "" {
+			if input.Rounding, err = strconv.ParseInt(raw, 10, 64); err != nil {
+				return nil, fmt.Errorf("row %d: rounding must be an integer", i+2)
+			}
+		}
+		inputs = append(inputs, input)
+	}
+	return s.SetRates(inputs, actor)
+}
+
+func (s *CurrencyService) Delete(currency string) error {
+	currency = strings.ToUpper(strings.TrimSpace(currency))
+	if currency == s.baseCurrency {
+		return errors.New("base currency cannot be removed")
+	}
+	return s.repo.Delete(currency)
+}
+
+// ConvertCatalog converts storefront prices from the base currency.
+func (s *CurrencyService) ConvertCatalog(items []*models.CatalogProduct, rate models.ExchangeRate) {
+	for _, item := range items {
+		item.Currency = rate.Currency
+		item.Price = rate.Convert(item.Price)
+		if item.OriginalPrice != nil {
+			originalPrice := rate.Convert(*item.OriginalPrice)
+			item.OriginalPrice = &originalPrice
+		}
+		item.ComponentsPrice = rate.Convert(item.ComponentsPrice)
+	}
+}
+
+// ConvertOrders converts order totals and item prices from the base currency.
+// Orders checked out in the requested currency keep the rate and total the
+// customer was charged; others use the current rate.
+func (s *CurrencyService) ConvertOrders(orders []models.OrderResponse, rate models.ExchangeRate) {
+	for i := range orders {
+		order := &orders[i]
+		orderRate := rate
+		total := rate.Convert(order.Total)
+		if rate.Currency == order.CheckoutCurrency {
+			orderRate.Rate = order.CheckoutRate
+			total = order.CheckoutTotal
+		}
+		order.Currency = rate.Currency
+		order.Total = total
+		for j := range order.Items {
+			p := &order.Items[j].Product
+			p.Price = orderRate.Convert(p.Price)
+			if p.OriginalPrice != nil {
+				originalPrice := orderRate.Convert(*p.OriginalPrice)
+				p.OriginalPrice = &originalPrice
+			}
+			p.ComponentsPrice = orderRate.Convert(p.ComponentsPrice)
+		}
+	}
+}
+
+func (s *CurrencyService) base() models.ExchangeRate {
+	return models.ExchangeRate{Currency: s.baseCurrency, Rate: 1, Rounding: 1}
+}
+
+func (s *CurrencyService) validateExchangeRate(input ExchangeRateInput) (models.ExchangeRate, error) {
+	currency := strings.ToUpper(strings.TrimSpace(input.Currency))
+	if !currencyPattern.MatchString(currency) {
+		return models.ExchangeRate{}, errors.New("currency must be a 3-letter ISO 4217 code")
+	}
+	if currency == s.baseCurrency {
+		return models.ExchangeRate{}, fmt.Errorf("%s is the base currency, its rate is always 1", currency)
+	}
+	if input.Rate <= 0 {
+		return models.ExchangeRate{}, fmt.Errorf("rate of %s must be greater than 0", currency)
+	}
+	rounding := input.Rounding
+	if rounding == 0 {
+		rounding = 1
+	}
+	if rounding < 0 {
+		return models.ExchangeRate{}, fmt.Errorf("rounding of %s must be greater than 0", currency)
+	}
+	return models.ExchangeRate{Currency: currency, Rate: input.Rate, Rounding: rounding}, nil
+}
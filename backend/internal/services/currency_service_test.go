@@ -0,0 +1,32 @@
+package services
+
+import (
+	"testing"
+
+	"backend/internal/models"
+)
+
+func TestExchangeRateConvertRoundsToStep(t *testing.T) {
+	usd := models.ExchangeRate{Currency: "USD", Rate: 0.011, Rounding: 1}
+	if got := usd.Convert(56990); got != 627 {
+		t.Fatalf("expected 627, got %d", got)
+	}
+	kzt := models.ExchangeRate{Currency: "KZT", Rate: 5.43, Rounding: 100}
+	if got := kzt.Convert(56990); got != 309500 {
+		t.Fatalf("expected rounding to 100, got %d", got)
+	}
+}
+
+func TestValidateExchangeRateRejectsBaseAndBadRates(t *testing.T) {
+	service := NewCurrencyService(nil, "RUB")
+	if _, err := service.validateExchangeRate(ExchangeRateInput{Currency: "RUB", Rate: 1}); err == nil {
+		t.Fatalf("expected base currency to be rejected")
+	}
+	if _, err := service.validateExchangeRate(ExchangeRateInput{Currency: "USD", Rate: 0}); err == nil {
+		t.Fatalf("expected non-positive rate to be rejected")
+	}
+	rate, err := service.validateExchangeRate(ExchangeRateInput{Currency: "usd", Rate: 0.011})
+	if err != nil || rate.Currency != "USD" || rate.Rounding != 1 {
+		t.Fatalf("expected normalized USD rate, got %+v, %v", rate, err)
+	}
+}
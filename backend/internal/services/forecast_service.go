@@ -2,6 +2,7 @@ package services
 
 import (
 	"errors"
+	"fmt"
 	"math"
 	"os"
 	"path/filepath"
@@ -16,6 +17,7 @@ type ForecastRow struct {
 	Category       string  `json:"category"`
 	ForecastQty    int     `json:"forecast_qty"`
 	RecommendedBuy int     `json:"recommended_buy"`
+	PriceTrend     float64 `json:"price_trend"`
 	Factors        string  `json:"factors"`
 	Confidence     float64 `json:"confidence"`
 }
@@ -28,6 +30,8 @@ type ForecastResponse struct {
 	Rows      []ForecastRow `json:"rows"`
 }
 
+const priceTrendWindowDays = 90
+
 type ForecastService struct {
 	repo      *repositories.ForecastRepository
 	modelPath string
@@ -78,13 +82,25 @@ func (s *ForecastService) Forecast(periodMonths int) (ForecastResponse, error) {
 		return ForecastResponse{}, err
 	}
 
+	now := time.Now().UTC()
+	trends, err := s.repo.CategoryPriceTrends(now.AddDate(0, 0, -priceTrendWindowDays))
+	if err != nil {
+		return ForecastResponse{}, err
+	}
+
 	rows := make([]ForecastRow, 0, len(categories))
 	for _, c := range categories {
-		pred := ai.Predict(artifact, c.ID, periodMonths, time.Now().UTC())
+		pred := ai.Predict(artifact, c.ID, periodMonths, now)
 		total := 0.0
 		for _, p := range pred {
 			total += p
 		}
+		factors := "category + seasonality + price bucket trend"
+		trend, hasTrend := trends[c.ID]
+		if hasTrend && trend != 0 {
+			total *= ai.PriceAdjustment(trend)
+			factors += fmt.Sprintf(" + price change %+.0f%% over %d days", trend*100, priceTrendWindowDays)
+		}
 		forecast := int(math.Round(total))
 		reco := int(math.Max(0, float64(forecast)-float64(forecast)*0.2))
 		conf := 1.0
@@ -96,7 +112,8 @@ func (s *ForecastService) Forecast(periodMonths int) (ForecastResponse, error) {
 			Category:       c.Name,
 			ForecastQty:    forecast,
 			RecommendedBuy: reco,
-			Factors:        "category + seasonality + price bucket trend",
+			PriceTrend:     trend,
+			Factors:        factors,
 			Confidence:     conf,
 		})
 	}
@@ -0,0 +1,211 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"backend/internal/models"
+	"backend/internal/repositories"
+)
+
+type FulfillmentService struct {
+	repo   *repositories.FulfillmentRepository
+	orders *repositories.OrderRepository
+	// publicURL is the storefront address packing slip QR codes link to.
+	publicURL string
+}
+
+func NewFulfillmentService(repo *repositories.FulfillmentRepository, orders *repositories.OrderRepository, publicURL string) *FulfillmentService {
+	return &FulfillmentService{repo: repo, orders: orders, publicURL: publicURL}
+}
+
+// PickList batches the lines of processing orders that still have to be
+// picked by product, sorted by location so the warehouse can walk it once.
+// Lines waiting for pre-ordered units are left out until stock arrives.
+func (s *FulfillmentService) PickList(location string) (models.PickList, error) {
+	orders, err := s.repo.ListByStatus(models.OrderStatusProcessing)
+	if err != nil {
+		return models.PickList{}, err
+	}
+	location = strings.TrimSpace(location)
+
+	entries := map[string]*models.PickListEntry{}
+	orderIDs := []string{}
+	for _, order := range orders {
+		included := false
+		for _, item := range order.Items {
+			if item.PickedAt != nil || item.PreorderQty > 0 {
+				continue
+			}
+			for _, unit := range pickUnits(item) {
+				if location != "" && !strings.EqualFold(unit.product.Location, location) {
+					continue
+				}
+				entry, ok := entries[unit.product.ID]
+				if !ok {
+					entry = &models.PickListEntry{
+						ProductID: unit.product.ID,
+						SKU:       unit.product.SKU,
+						Name:      unit.product.Name,
+						Location:  unit.product.Location,
+						Orders:    []models.PickListOrder{},
+					}
+					entries[unit.product.ID] = entry
+				}
+				entry.Qty += unit.qty
+				entry.Orders = append(entry.Orders, models.PickListOrder{OrderID: order.ID, LineID: item.ID, Qty: unit.qty})
+				included = true
+			}
+		}
+		if included {
+			orderIDs = append(orderIDs, order.ID)
+		}
+	}
+
+	list := models.PickList{GeneratedAt: time.Now().UTC(), Orders: orderIDs, Entries: make([]models.PickListEntry, 0, len(entries))}
+	for _, entry := range entries {
+		list.Entries = append(list.Entries, *entry)
+	}
+	sort.Slice(list.Entries, func(i, j int) bool {
+		a, b := list.Entries[i], list.Entries[j]
+		if a.Location != b.Location {
+			// Products without a location go last.
+			if a.Location == "" || b.Location == "" {
+				return b.Location == ""
+			}
+			return a.Location < b.Location
+		}
+		return a.SKU < b.SKU
+	})
+	return list, nil
+}
+
+type pickUnit struct {
+	product models.Product
+	qty     int
+}
+
+// pickUnits returns what has to be taken from the shelves for a line: the
+// product itself, or the components of a bundle.
+func pickUnits(item models.OrderItem) []pickUnit {
+	if !item.Product.IsBundle() {
+		return []pickUnit{{product: item.Product, qty: item.Qty}}
+	}
+	units := make([]pickUnit, 0, len(item.Product.Components))
+	for _, c := range item.Product.Components {
+		units = append(units, pickUnit{product: c.Component, qty: item.Qty * c.Quantity})
+	}
+	return units
+}
+
+func (s *FulfillmentService) Get(orderID string) (models.Fulfillment, error) {
+	order, err := s.repo.Get(strings.TrimSpace(orderID))
+	if err != nil {
+		return models.Fulfillment{}, err
+	}
+	return models.FulfillmentOf(order), nil
+}
+
+// Pick records the given lines, or all lines if none are given, as picked.
+func (s *FulfillmentService) Pick(orderID string, lineIDs []uint, actor string) (models.Fulfillment, error) {
+	order, err := s.processingOrder(orderID)
+	if err != nil {
+		return models.Fulfillment{}, err
+	}
+	lines, err := selectLines(order, lineIDs)
+	if err != nil {
+		return models.Fulfillment{}, err
+	}
+	ids := make([]uint, 0, len(lines))
+	for _, line := range lines {
+		if line.PreorderQty > 0 {
+			return models.Fulfillment{}, fmt.Errorf("line %d is waiting for %d pre-ordered units", line.ID, line.PreorderQty)
+		}
+		ids = append(ids, line.ID)
+	}
+	if err := s.repo.Pick(order.ID, ids, strings.TrimSpace(actor), time.Now().UTC()); err != nil {
+		return models.Fulfillment{}, err
+	}
+	return s.Get(order.ID)
+}
+
+// Pack records the given picked lines, or all lines if none are given, as
+// packed. Packing the last line confirms the order and ships it; the second
+// return value reports whether that happened.
+func (s *FulfillmentService) Pack(orderID string, lineIDs []uint, actor string) (models.Fulfillment, bool, error) {
+	order, err := s.processingOrder(orderID)
+	if err != nil {
+		return models.Fulfillment{}, false, err
+	}
+	lines, err := selectLines(order, lineIDs)
+	if err != nil {
+		return models.Fulfillment{}, false, err
+	}
+	ids := make([]uint, 0, len(lines))
+	for _, line := range lines {
+		if line.PickedAt == nil {
+			return models.Fulfillment{}, false, fmt.Errorf("line %d has not been picked", line.ID)
+		}
+		ids = append(ids, line.ID)
+	}
+	shippedStatus, err := s.orders.FindStatusByCode(string(models.OrderStatusShipped))
+	if err != nil {
+		return models.Fulfillment{}, false, err
+	}
+	shipped, err := s.repo.Pack(order.ID, ids, strings.TrimSpace(actor), time.Now().UTC(), shippedStatus.ID)
+	if err != nil {
+		return models.Fulfillment{}, false, err
+	}
+	fulfillment, err := s.Get(order.ID)
+	return fulfillment, shipped, err
+}
+
+// PackingSlip renders the packing slip of an order as a PDF.
+func (s *FulfillmentService) PackingSlip(orderID string) (models.Fulfillment, []byte, error) {
+	fulfillment, err := s.Get(orderID)
+	if err != nil {
+		return models.Fulfillment{}, nil, err
+	}
+	data, err := PackingSlipPDF(fulfillment, s.publicURL)
+	return fulfillment, data, err
+}
+
+func (s *FulfillmentService) processingOrder(orderID string) (models.Order, error) {
+	order, err := s.repo.Get(strings.TrimSpace(orderID))
+	if err != nil {
+		return models.Order{}, err
+	}
+	if status := models.OrderState(order.StatusRef.Code); status != models.OrderStatusProcessing {
+		return models.Order{}, fmt.Errorf("order is %s, only processing orders can be picked and packed", status)
+	}
+	if len(order.Items) == 0 {
+		return models.Order{}, errors.New("order has no lines")
+	}
+	return order, nil
+}
+
+func selectLines(order models.Order, lineIDs []uint) ([]models.OrderItem, error) {
+	if len(lineIDs) == 0 {
+		return order.Items, nil
+	}
+	byID := map[uint]models.OrderItem{}
+	for _, item := range order.Items {
+		byID[item.ID] = item
+	}
+	lines := make([]models.OrderItem, 0, len(lineIDs))
+	seen := map[uint]bool{}
+	for _, id := range lineIDs {
+		line, ok := byID[id]
+		if !ok {
+			return nil, fmt.Errorf("line %d is not part of order %s", id, order.ID)
+		}
+		if !seen[id] {
+			seen[id] = true
+			lines = append(lines, line)
+		}
+	}
+	return lines, nil
+}
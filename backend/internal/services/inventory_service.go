@@ -0,0 +1,212 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"backend/internal/models"
+	"backend/internal/repositories"
+)
+
+// stockCheckQueueSize is how many order-triggered checks may wait for the
+// worker; beyond that they are left to the next full check.
+const stockCheckQueueSize = 256
+
+type InventoryService struct {
+	repo     *repositories.StockAlertRepository
+	products *repositories.ProductRepository
+	audit    *AuditService
+
+	queue   chan []string
+	started sync.Once
+}
+
+func NewInventoryService(repo *repositories.StockAlertRepository, products *repositories.ProductRepository, audit *AuditService) *InventoryService {
+	return &InventoryService{repo: repo, products: products, audit: audit, queue: make(chan []string, stockCheckQueueSize)}
+}
+
+func (s *InventoryService) Alerts(status models.StockAlertStatus) ([]models.StockAlert, error) {
+	switch status {
+	case "", models.StockAlertOpen, models.StockAlertResolved:
+	default:
+		return nil, errors.New("invalid status")
+	}
+	return s.repo.List(status)
+}
+
+// CheckAll compares every active product with its thresholds and returns the
+// number of alerts raised or escalated. Alerts of products that are back in
+// stock, archived or hidden are resolved.
+func (s *InventoryService) CheckAll(now time.Time) (int, error) {
+	products, err := s.products.ListActive()
+	if err != nil {
+		return 0, err
+	}
+	open, err := s.repo.ListOpen()
+	if err != nil {
+		return 0, err
+	}
+	raised, err := s.check(products, open, now)
+	if err != nil {
+		return raised, err
+	}
+
+	active := map[string]bool{}
+	for _, p := range products {
+		active[p.ID] = true
+	}
+	for productID, alert := range open {
+		if !active[productID] {
+			if err := s.resolve(alert, now); err != nil {
+				return raised, err
+			}
+		}
+	}
+	return raised, nil
+}
+
+// Queue hands products an order has just taken stock from to a background
+// worker, so checkout does not wait for the check. It never blocks: when the
+// worker is behind, the products are left to the next full check.
+func (s *InventoryService) Queue(ids []string) {
+	if len(ids) == 0 {
+		return
+	}
+	s.started.Do(func() { go s.work() })
+	select {
+	case s.queue <- ids:
+	default:
+		log.Printf("stock alerts: check queue is full, %d products left to the next run", len(ids))
+	}
+}
+
+func (s *InventoryService) work() {
+	for ids := range s.queue {
+		if err := s.CheckProducts(ids); err != nil {
+			log.Printf("stock alerts: %v", err)
+		}
+	}
+}
+
+// CheckProducts checks only the given products, e.g. right after an order
+// took them out of stock.
+func (s *InventoryService) CheckProducts(ids []string) error {
+	products := make([]models.Product, 0, len(ids))
+	for _, id := range ids {
+		product, err := s.products.GetByID(id)
+		if err != nil {
+			if IsNotFound(err) {
+				continue
+			}
+			return err
+		}
+		if product.IsActive {
+			products = append(products, product)
+		}
+	}
+	open, err := s.repo.ListOpen()
+	if err != nil {
+		return err
+	}
+	_, err = s.check(products, open, time.Now().UTC())
+	return err
+}
+
+func (s *InventoryService) check(products []models.Product, open map[string]models.StockAlert, now time.Time) (int, error) {
+	raised := 0
+	for _, p := range products {
+		if p.IsBundle() {
+			continue
+		}
+		level := models.StockAlertLevelFor(p)
+		alert, hasOpen := open[p.ID]
+		switch {
+		case level == "" && hasOpen:
+			if err := s.resolve(alert, now); err != nil {
+				return raised, err
+			}
+		case level != "" && !hasOpen:
+			alert = models.StockAlert{
+				ProductID:    p.ID,
+				Level:        level,
+				Status:       models.StockAlertOpen,
+				StockQty:     p.StockQty,
+				ReorderPoint: p.ReorderPoint,
+				SafetyStock:  p.SafetyStock,
+				CreatedAt:    now,
+				UpdatedAt:    now,
+			}
+			created, err := s.repo.Create(&alert)
+			if err != nil {
+				return raised, err
+			}
+			// Another checker raised it first and has already warned.
+			if !created {
+				continue
+			}
+			s.warn(p, level)
+			raised++
+		case level != "" && (alert.Level != level || alert.StockQty != p.StockQty):
+			updated, err := s.repo.Update(alert.ID, alert.Level, map[string]any{
+				"level":         level,
+				"stock_qty":     p.StockQty,
+				"reorder_point": p.ReorderPoint,
+				"safety_stock":  p.SafetyStock,
+				"updated_at":    now,
+			})
+			if err != nil {
+				return raised, err
+			}
+			if updated && level == models.StockAlertCritical && alert.Level != models.StockAlertCritical {
+				s.warn(p, level)
+				raised++
+			}
+		}
+	}
+	return raised, nil
+}
+
+func (s *InventoryService) resolve(alert models.StockAlert, now time.Time) error {
+	_, err := s.repo.Update(alert.ID, alert.Level, map[string]any{"status": models.StockAlertResolved, "resolved_at": now, "updated_at": now})
+	return err
+}
+
+func (s *InventoryService) warn(p models.Product, level models.StockAlertLevel) {
+	details := fmt.Sprintf("%s stock %d is at or below the reorder point %d", p.SKU, p.StockQty, p.ReorderPoint)
+	if level == models.StockAlertCritical {
+		details = fmt.Sprintf("%s stock %d is at or below the safety stock %d", p.SKU, p.StockQty, p.SafetyStock)
+	}
+	_, err := s.audit.Create(models.AuditLog{
+		Action:   "Low Stock",
+		Category: models.AuditCategoryProduct,
+		User:     "system",
+		Entity:   "product",
+		EntityID: p.ID,
+		Details:  details,
+		Severity: models.AuditSeverityWarning,
+		Result:   "ok",
+	})
+	if err != nil {
+		log.Printf("stock alerts: audit: %v", err)
+	}
+}
+
+// Run checks stock levels every interval until ctx is cancelled.
+func (s *InventoryService) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		if _, err := s.CheckAll(time.Now().UTC()); err != nil {
+			log.Printf("stock alerts: %v", err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,98 @@
+package services
+
+import (
+	"strings"
+	"time"
+)
+
+const (
+	loginFailureWindow = time.Hour
+	// accountFreeFailures and ipFreeFailures are the failed logins allowed
+	// per window before lockouts start; an IP may front many users.
+	accountFreeFailures = 10
+	ipFreeFailures      = 50
+	lockoutBase         = 30 * time.Second
+	lockoutMax          = 15 * time.Minute
+)
+
+// LoginThrottle counts failed logins per account and per client IP and
+// locks either out for an exponentially growing time once they exceed
+// their allowance.
+type LoginThrottle struct {
+	store CounterStore
+}
+
+func NewLoginThrottle(store CounterStore) *LoginThrottle {
+	return &LoginThrottle{store: store}
+}
+
+type throttleSubject struct {
+	key          string
+	freeFailures int
+}
+
+func (t *LoginThrottle) subjects(email, ip string) []throttleSubject {
+	subjects := make([]throttleSubject, 0, 2)
+	if email = strings.TrimSpace(strings.ToLower(email)); email != "" {
+		subjects = append(subjects, throttleSubject{key: "account:" + email, freeFailures: accountFreeFailures})
+	}
+	if ip != "" {
+		subjects = append(subjects, throttleSubject{key: "ip:" + ip, freeFailures: ipFreeFailures})
+	}
+	return subjects
+}
+
+// Locked returns how much longer logins for the account or from the IP are
+// refused, or zero.
+func (t *LoginThrottle) Locked(email, ip string, now time.Time) (time.Duration, error) {
+	var wait time.Duration
+	for _, subject := range t.subjects(email, ip) {
+		count, resetAt, err := t.store.Get("login-lock:"+subject.key, now)
+		if err != nil {
+			return 0, err
+		}
+		if count > 0 {
+			wait = max(wait, resetAt.Sub(now))
+		}
+	}
+	return wait, nil
+}
+
+// Failed records a failed login and returns the lockout it started, if any.
+func (t *LoginThrottle) Failed(email, ip string, now time.Time) (time.Duration, error) {
+	var wait time.Duration
+	for _, subject := range t.subjects(email, ip) {
+		failures, _, err := t.store.Hit("login-fail:"+subject.key, loginFailureWindow, now)
+		if err != nil {
+			return 0, err
+		}
+		if failures <= subject.freeFailures {
+			continue
+		}
+		lockout := LockoutFor(failures - subject.freeFailures)
+		if _, _, err := t.store.Hit("login-lock:"+subject.key, lockout, now); err != nil {
+			return 0, err
+		}
+		wait = max(wait, lockout)
+	}
+	return wait, nil
+}
+
+// Succeeded clears the account's failures. The IP counter is kept so one
+// valid account cannot be used to reset guessing against others.
+func (t *LoginThrottle) Succeeded(email string) error {
+	key := "account:" + strings.TrimSpace(strings.ToLower(email))
+	return t.store.Delete("login-fail:"+key, "login-lock:"+key)
+}
+
+// LockoutFor doubles the lockout with every failure past the allowance,
+// from 30 seconds up to 15 minutes.
+func LockoutFor(excess int) time.Duration {
+	if excess < 1 {
+		return 0
+	}
+	if excess > 10 {
+		return lockoutMax
+	}
+	return min(lockoutBase<<(excess-1), lockoutMax)
+}
@@ -0,0 +1,61 @@
+package services
+
+import (
+	"testing"
+	"time"
+)
+
+func TestLockoutDoublesUpToCap(t *testing.T) {
+	cases := map[int]time.Duration{0: 0, 1: 30 * time.Second, 2: time.Minute, 5: 8 * time.Minute, 6: 15 * time.Minute, 40: 15 * time.Minute}
+	for excess, want := range cases {
+		if got := LockoutFor(excess); got != want {
+			t.Fatalf("expected lockout %s after %d extra failures, got %s", want, excess, got)
+		}
+	}
+}
+
+func TestLoginThrottleLocksAccountAndClearsOnSuccess(t *testing.T) {
+	throttle := NewLoginThrottle(NewMemoryCounterStore())
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	for i := 0; i < accountFreeFailures; i++ {
+		if wait, _ := throttle.Failed("Admin@Maison.co", "10.0.0.1", now); wait != 0 {
+			t.Fatalf("expected failure %d to be free, got lockout %s", i+1, wait)
+		}
+	}
+	if wait, _ := throttle.Failed("admin@maison.co", "10.0.0.2", now); wait != 30*time.Second {
+		t.Fatalf("expected a 30s lockout across IPs, got %s", wait)
+	}
+	if wait, _ := throttle.Locked("admin@maison.co", "10.0.0.3", now.Add(10*time.Second)); wait != 20*time.Second {
+		t.Fatalf("expected 20s of lockout left, got %s", wait)
+	}
+	if wait, _ := throttle.Locked("manager@maison.co", "10.0.0.3", now); wait != 0 {
+		t.Fatalf("expected other accounts not to be locked, got %s", wait)
+	}
+	if wait, _ := throttle.Locked("admin@maison.co", "10.0.0.3", now.Add(31*time.Second)); wait != 0 {
+		t.Fatalf("expected the lockout to end, got %s", wait)
+	}
+	if wait, _ := throttle.Failed("admin@maison.co", "10.0.0.2", now.Add(31*time.Second)); wait != time.Minute {
+		t.Fatalf("expected the next lockout to double, got %s", wait)
+	}
+
+	if err := throttle.Succeeded("ADMIN@maison.co"); err != nil {
+		t.Fatalf("clear failures: %v", err)
+	}
+	if wait, _ := throttle.Locked("admin@maison.co", "10.0.0.3", now.Add(40*time.Second)); wait != 0 {
+		t.Fatalf("expected success to clear the lockout, got %s", wait)
+	}
+}
+
+func TestMemoryCounterStoreStartsNewWindow(t *testing.T) {
+	store := NewMemoryCounterStore()
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	store.Hit("k", time.Minute, now)
+	count, resetAt, _ := store.Hit("k", time.Minute, now.Add(30*time.Second))
+	if count != 2 || !resetAt.Equal(now.Add(time.Minute)) {
+		t.Fatalf("expected 2 hits in the first window, got %d until %s", count, resetAt)
+	}
+	if count, _, _ := store.Hit("k", time.Minute, now.Add(time.Minute)); count != 1 {
+		t.Fatalf("expected a new window to start at 1, got %d", count)
+	}
+}
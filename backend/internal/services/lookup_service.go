@@ -0,0 +1,68 @@
+package services
+
+import (
+	"net/url"
+	"strings"
+
+	"backend/internal/models"
+	"backend/internal/repositories"
+
+	"gorm.io/gorm"
+)
+
+type LookupService struct {
+	products     *repositories.ProductRepository
+	orders       *repositories.OrderRepository
+	baseCurrency string
+}
+
+func NewLookupService(products *repositories.ProductRepository, orders *repositories.OrderRepository, baseCurrency string) *LookupService {
+	return &LookupService{products: products, orders: orders, baseCurrency: baseCurrency}
+}
+
+// Lookup resolves a scanned code: a product barcode, SKU or ID, an order ID,
+// or the order link from a packing slip QR code.
+func (s *LookupService) Lookup(code string) (models.LookupResult, error) {
+	code = strings.TrimSpace(code)
+	if code == "" {
+		return models.LookupResult{}, gorm.ErrRecordNotFound
+	}
+
+	if orderID, ok := orderIDFromURL(code); ok {
+		return s.lookupOrder(code, orderID)
+	}
+
+	finders := []func(string) (models.Product, error){s.products.FindByBarcode, s.products.FindBySKU, s.products.GetByID}
+	for _, find := range finders {
+		product, err := find(code)
+		if err == nil {
+			return models.LookupResult{Type: models.LookupProduct, Code: code, Product: &product}, nil
+		}
+		if !IsNotFound(err) {
+			return models.LookupResult{}, err
+		}
+	}
+	return s.lookupOrder(code, code)
+}
+
+func (s *LookupService) lookupOrder(code, orderID string) (models.LookupResult, error) {
+	order, err := s.orders.GetByID(orderID)
+	if err != nil {
+		return models.LookupResult{}, err
+	}
+	response := mapOrderResponse(order, s.baseCurrency)
+	return models.LookupResult{Type: models.LookupOrder, Code: code, Order: &response}, nil
+}
+
+// orderIDFromURL extracts the order ID from links made by OrderURL.
+func orderIDFromURL(code string) (string, bool) {
+	u, err := url.Parse(code)
+	if err != nil || u.Host == "" {
+		return "", false
+	}
+	id, ok := strings.CutPrefix(strings.TrimRight(u.Path, "/"), "/admin/orders/")
+	if !ok || id == "" || strings.Contains(id, "/") {
+		return "", false
+	}
+	return id, true
+}
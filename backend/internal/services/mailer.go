@@ -0,0 +1,51 @@
+package services
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"regexp"
+	"time"
+)
+
+// Mailer sends plain-text email to customers.
+type Mailer interface {
+	Send(to, subject, body string) error
+}
+
+// NewMailer returns a FileMailer writing to dir, or a LogMailer when dir is
+// empty.
+func NewMailer(dir string) Mailer {
+	if dir != "" {
+		return FileMailer{Dir: dir}
+	}
+	return LogMailer{}
+}
+
+// LogMailer writes messages to the application log instead of sending them;
+// it stands in until an SMTP relay is configured.
+type LogMailer struct{}
+
+func (LogMailer) Send(to, subject, body string) error {
+	log.Printf("mail to %s: %s\n%s", to, subject, body)
+	return nil
+}
+
+// FileMailer drops every message into Dir as an .eml file, so local setups
+// and tests can open the links that would have been mailed.
+type FileMailer struct {
+	Dir string
+}
+
+var unsafeFileChars = regexp.MustCompile(`[^A-Za-z0-9@._-]+`)
+
+func (m FileMailer) Send(to, subject, body string) error {
+	if err := os.MkdirAll(m.Dir, 0o755); err != nil {
+		return err
+	}
+	now := time.Now().UTC()
+	name := fmt.Sprintf("%s-%s.eml", now.Format("20060102T150405.000000000"), unsafeFileChars.ReplaceAllString(to, "_"))
+	message := fmt.Sprintf("To: %s\r\nSubject: %s\r\nDate: %s\r\nContent-Type: text/plain; charset=utf-8\r\n\r\n%s\r\n", to, subject, now.Format(time.RFC1123Z), body)
+	return os.WriteFile(filepath.Join(m.Dir, name), []byte(message), 0o600)
+}
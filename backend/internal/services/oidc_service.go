@@ -0,0 +1,544 @@
+package services
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"backend/internal/models"
+	"backend/internal/repositories"
+	"backend/internal/security"
+
+	"gorm.io/gorm"
+)
+
+const (
+	// OIDCLoginTTL is how long a started sign-in can be completed.
+	OIDCLoginTTL = 10 * time.Minute
+	// oidcDiscoveryTTL is how long the provider's metadata is reused;
+	// oidcKeysRefreshInterval bounds how often an unknown kid refetches
+	// its JWKS.
+	oidcDiscoveryTTL        = time.Hour
+	oidcKeysRefreshInterval = time.Minute
+	oidcResponseLimit       = 1 << 20
+)
+
+var (
+	ErrOIDCDisabled = errors.New("single sign-on is not configured")
+	// ErrOIDCInvalidState means the callback does not belong to an open
+	// sign-in: it expired, was used already or was never started here.
+	ErrOIDCInvalidState = errors.New("single sign-on attempt is invalid or expired")
+	// ErrOIDCProvider wraps failures to reach or understand the identity
+	// provider.
+	ErrOIDCProvider = errors.New("identity provider request failed")
+	ErrOIDCNoRole   = errors.New("none of your groups grants access to the back office")
+	// ErrOIDCLinkNotAllowed refuses to link an Admin or Client account whose
+	// owner has not allowed single sign-on for it.
+	ErrOIDCLinkNotAllowed = errors.New("sign in with your password and allow single sign-on for this account first")
+)
+
+// OIDCRoleMapping gives the members of an identity provider group a role.
+type OIDCRoleMapping struct {
+	Group string
+	Role  models.RoleName
+}
+
+// OIDCConfig describes the identity provider staff sign in with. Single
+// sign-on is off unless Issuer and ClientID are set.
+type OIDCConfig struct {
+	Issuer       string
+	ClientID     string
+	ClientSecret string
+	RedirectURL  string
+	Scopes       []string
+	GroupsClaim  string
+	// RoleMap is checked in order and the first group the user is in picks
+	// the role; DefaultRole applies when none matches and may be empty to
+	// refuse such users.
+	RoleMap     []OIDCRoleMapping
+	DefaultRole models.RoleName
+}
+
+func (c OIDCConfig) Enabled() bool {
+	return c.Issuer != "" && c.ClientID != ""
+}
+
+// Origin is the scheme and host of RedirectURL, the page that posts the
+// callback, or empty when it has none.
+func (c OIDCConfig) Origin() string {
+	redirect, err := url.Parse(c.RedirectURL)
+	if err != nil || redirect.Scheme == "" || redirect.Host == "" {
+		return ""
+	}
+	return redirect.Scheme + "://" + redirect.Host
+}
+
+// Role picks the role for a user in groups, or false when none applies.
+// Single sign-on is for staff, so it never hands out the Client role.
+func (c OIDCConfig) Role(groups []string) (models.RoleName, bool) {
+	member := map[string]struct{}{}
+	for _, group := range groups {
+		member[group] = struct{}{}
+	}
+	for _, mapping := range c.RoleMap {
+		if _, ok := member[mapping.Group]; ok && mapping.Role != models.RoleClient {
+			return mapping.Role, true
+		}
+	}
+	if c.DefaultRole != "" && c.DefaultRole != models.RoleClient {
+		return c.DefaultRole, true
+	}
+	return "", false
+}
+
+// OIDCLoginResult is a completed single sign-on. Provisioned is set when
+// the login created the user and PreviousRole when it changed their role.
+type OIDCLoginResult struct {
+	Login        models.LoginResponse
+	Provisioned  bool
+	Linked       bool
+	PreviousRole models.RoleName
+}
+
+// OIDCService signs staff in with an OpenID Connect provider using the
+// authorization code flow with PKCE. Users are created on their first
+// login and their role follows their groups at the provider.
+type OIDCService struct {
+	config OIDCConfig
+	repo   *repositories.OIDCRepository
+	users  *repositories.UserRepository
+	auth   *AuthService
+	client *http.Client
+
+	mu         sync.Mutex
+	provider   oidcProvider
+	providerAt time.Time
+	keys       map[string]security.Key
+	keysAt     time.Time
+}
+
+type oidcProvider struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	JWKSURI               string `json:"jwks_uri"`
+}
+
+type oidcTokenResponse struct {
+	IDToken          string `json:"id_token"`
+	Error            string `json:"error"`
+	ErrorDescription string `json:"error_description"`
+}
+
+type oidcIDToken struct {
+	Issuer            string            `json:"iss"`
+	Subject           string            `json:"sub"`
+	Audience          security.Audience `json:"aud"`
+	AuthorizedParty   string            `json:"azp"`
+	Exp               int64             `json:"exp"`
+	NotBefore         int64             `json:"nbf"`
+	Nonce             string            `json:"nonce"`
+	Email             string            `json:"email"`
+	EmailVerified     *bool             `json:"email_verified"`
+	Name              string            `json:"name"`
+	PreferredUsername string            `json:"preferred_username"`
+}
+
+func NewOIDCService(config OIDCConfig, repo *repositories.OIDCRepository, users *repositories.UserRepository, auth *AuthService) *OIDCService {
+	return &OIDCService{config: config, repo: repo, users: users, auth: auth, client: &http.Client{Timeout: 10 * time.Second}}
+}
+
+func (s *OIDCService) Enabled() bool {
+	return s.config.Enabled()
+}
+
+func (s *OIDCService) Origin() string {
+	return s.config.Origin()
+}
+
+// Start opens a sign-in attempt and returns the provider URL to send the
+// browser to, along with its state, which the browser must present again
+// to complete it.
+func (s *OIDCService) Start() (string, string, error) {
+	if !s.Enabled() {
+		return "", "", ErrOIDCDisabled
+	}
+	provider, err := s.discover()
+	if err != nil {
+		return "", "", err
+	}
+	now := time.Now().UTC()
+	if err := s.repo.DeleteExpiredLogins(now); err != nil {
+		log.Printf("oidc: delete expired logins: %v", err)
+	}
+	state, stateHash := newOpaqueToken()
+	nonce, _ := newOpaqueToken()
+	verifier, _ := newOpaqueToken()
+	login := models.OIDCLogin{StateHash: stateHash, Nonce: nonce, CodeVerifier: verifier, ExpiresAt: now.Add(OIDCLoginTTL), CreatedAt: now}
+	if err := s.repo.CreateLogin(&login); err != nil {
+		return "", "", err
+	}
+
+	challenge := sha256.Sum256([]byte(verifier))
+	query := url.Values{
+		"response_type":         {"code"},
+		"client_id":             {s.config.ClientID},
+		"redirect_uri":          {s.config.RedirectURL},
+		"scope":                 {strings.Join(s.config.Scopes, " ")},
+		"state":                 {state},
+		"nonce":                 {nonce},
+		"code_challenge":        {base64.RawURLEncoding.EncodeToString(challenge[:])},
+		"code_challenge_method": {"S256"},
+	}
+	separator := "?"
+	if strings.Contains(provider.AuthorizationEndpoint, "?") {
+		separator = "&"
+	}
+	return provider.AuthorizationEndpoint + separator + query.Encode(), state, nil
+}
+
+// Complete finishes the sign-in the provider redirected back with: it
+// redeems the code, verifies the ID token and signs the matching user in,
+// creating or linking them first when needed. browserState is the state
+// Start handed to the browser that posts the callback; a callback started
+// in another browser is refused, so nobody can sign a victim in to an
+// account of theirs.
+func (s *OIDCService) Complete(code, state, browserState string) (OIDCLoginResult, error) {
+	if !s.Enabled() {
+		return OIDCLoginResult{}, ErrOIDCDisabled
+	}
+	code, state = strings.TrimSpace(code), strings.TrimSpace(state)
+	if code == "" || state == "" {
+		return OIDCLoginResult{}, errors.New("code and state are required")
+	}
+	if subtle.ConstantTimeCompare([]byte(state), []byte(browserState)) != 1 {
+		return OIDCLoginResult{}, ErrOIDCInvalidState
+	}
+	login, err := s.repo.UseLogin(hashOpaqueToken(state), time.Now().UTC())
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return OIDCLoginResult{}, ErrOIDCInvalidState
+		}
+		return OIDCLoginResult{}, err
+	}
+	rawToken, err := s.exchange(code, login.CodeVerifier)
+	if err != nil {
+		return OIDCLoginResult{}, err
+	}
+	token, groups, err := s.verifyIDToken(rawToken, login.Nonce)
+	if err != nil {
+		return OIDCLoginResult{}, err
+	}
+	role, ok := s.config.Role(groups)
+	if !ok {
+		return OIDCLoginResult{}, ErrOIDCNoRole
+	}
+	roleRow, err := s.users.FindRoleByName(role)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return OIDCLoginResult{}, fmt.Errorf("role %s mapped for single sign-on does not exist", role)
+		}
+		return OIDCLoginResult{}, err
+	}
+
+	result, user, err := s.resolveUser(token, roleRow)
+	if err != nil {
+		return OIDCLoginResult{}, err
+	}
+	if user.IsBlocked {
+		return OIDCLoginResult{}, errors.New("user is blocked")
+	}
+	if user.RoleID != roleRow.ID {
+		// The groups may not take away the last user who can manage access,
+		// and sessions opened with the old role end with it.
+		if err := s.users.SetRole(user.ID, roleRow.ID, keepAccessManager); err != nil {
+			return OIDCLoginResult{}, err
+		}
+		if _, err := s.auth.RevokeUserSessions(user.ID, "role changed"); err != nil {
+			return OIDCLoginResult{}, err
+		}
+		result.PreviousRole = user.Role.Name
+		user.RoleID, user.Role = roleRow.ID, roleRow
+	}
+	// The local two-factor policy applies on top of whatever the provider
+	// checked.
+	result.Login, err = s.auth.secondFactorOrSession(user)
+	return result, err
+}
+
+// resolveUser finds the user behind the provider account: by identity, or
+// by verified email for an existing account, which is then linked. Anyone
+// else is created with the role their groups map to.
+func (s *OIDCService) resolveUser(token oidcIDToken, role models.Role) (OIDCLoginResult, models.User, error) {
+	now := time.Now().UTC()
+	email := strings.ToLower(strings.TrimSpace(token.Email))
+	identity, err := s.repo.FindIdentity(token.Issuer, token.Subject)
+	if err == nil {
+		if err := s.repo.TouchIdentity(identity.ID, email, now); err != nil {
+			log.Printf("oidc: record login of %s: %v", identity.UserID, err)
+		}
+		user, err := s.users.FindByID(identity.UserID)
+		return OIDCLoginResult{}, user, err
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return OIDCLoginResult{}, models.User{}, err
+	}
+
+	identity = models.UserIdentity{Issuer: token.Issuer, Subject: token.Subject, Email: email, CreatedAt: now, LastLoginAt: &now}
+	user, err := s.users.FindByEmail(email)
+	if err == nil {
+		// Only an address the provider vouches for may take over an
+		// existing account.
+		if token.EmailVerified == nil || !*token.EmailVerified {
+			return OIDCLoginResult{}, models.User{}, errors.New("email is not verified by the identity provider")
+		}
+		// Nor may it take over an account whose owner never proved the
+		// address; whoever signed up with it could still use the password.
+		if user.EmailVerifiedAt == nil {
+			return OIDCLoginResult{}, models.User{}, ErrEmailNotVerified
+		}
+		// Administrators hold every permission and clients would turn into
+		// staff, so either is linked only when its owner opted in.
+		if (user.Role.Name == models.RoleAdmin || user.Role.Name == models.RoleClient) && !user.SSOLinkAllowed {
+			return OIDCLoginResult{}, models.User{}, ErrOIDCLinkNotAllowed
+		}
+		identity.UserID = user.ID
+		if err := s.repo.CreateIdentity(&identity); err != nil {
+			return OIDCLoginResult{}, models.User{}, err
+		}
+		return OIDCLoginResult{Linked: true}, user, nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return OIDCLoginResult{}, models.User{}, err
+	}
+
+	// The password is random and never shown, so the account signs in
+	// through the provider until someone resets it.
+	password, _ := newOpaqueToken()
+	user = models.User{
+		ID:              repositories.GenerateID("u"),
+		Email:           email,
+		PasswordHash:    security.HashPassword(password),
+		Name:            token.displayName(),
+		RoleID:          role.ID,
+		EmailVerifiedAt: &now,
+	}
+	if err := s.repo.Provision(&user, &identity); err != nil {
+		return OIDCLoginResult{}, models.User{}, err
+	}
+	user.Role = role
+	return OIDCLoginResult{Provisioned: true}, user, nil
+}
+
+func (t oidcIDToken) displayName() string {
+	for _, name := range []string{t.Name, t.PreferredUsername} {
+		if name = strings.TrimSpace(name); name != "" {
+			if len(name) > 120 {
+				name = name[:120]
+			}
+			return name
+		}
+	}
+	return strings.ToLower(strings.TrimSpace(t.Email))
+}
+
+// exchange redeems an authorization code for the ID token, proving with
+// the PKCE verifier that this server started the sign-in.
+func (s *OIDCService) exchange(code, verifier string) (string, error) {
+	provider, err := s.discover()
+	if err != nil {
+		return "", err
+	}
+	form := url.Values{
+		"grant_type":    {"authorization_code"},
+		"code":          {code},
+		"redirect_uri":  {s.config.RedirectURL},
+		"client_id":     {s.config.ClientID},
+		"code_verifier": {verifier},
+	}
+	req, err := http.NewRequest(http.MethodPost, provider.TokenEndpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", fmt.Errorf("%w: %v", ErrOIDCProvider, err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	if s.config.ClientSecret != "" {
+		req.SetBasicAuth(url.QueryEscape(s.config.ClientID), url.QueryEscape(s.config.ClientSecret))
+	}
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("%w: %v", ErrOIDCProvider, err)
+	}
+	defer resp.Body.Close()
+
+	var body oidcTokenResponse
+	if err := json.NewDecoder(io.LimitReader(resp.Body, oidcResponseLimit)).Decode(&body); err != nil {
+		return "", fmt.Errorf("%w: token response: %v", ErrOIDCProvider, err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		// A rejected code is the user's problem, not the provider's.
+		if body.Error != "" {
+			return "", fmt.Errorf("identity provider refused the code: %s", strings.TrimSpace(body.Error+" "+body.ErrorDescription))
+		}
+		return "", fmt.Errorf("%w: token endpoint returned %d", ErrOIDCProvider, resp.StatusCode)
+	}
+	if body.IDToken == "" {
+		return "", fmt.Errorf("%w: no id_token in token response", ErrOIDCProvider)
+	}
+	return body.IDToken, nil
+}
+
+// verifyIDToken checks the ID token's signature and claims against the
+// provider and the sign-in attempt, and returns it with the user's groups.
+func (s *OIDCService) verifyIDToken(raw, nonce string) (oidcIDToken, []string, error) {
+	payload, err := security.VerifyJWT(raw, s.lookupKey)
+	if err != nil {
+		return oidcIDToken{}, nil, fmt.Errorf("invalid id token: %w", err)
+	}
+	var token oidcIDToken
+	if err := json.Unmarshal(payload, &token); err != nil {
+		return oidcIDToken{}, nil, fmt.Errorf("invalid id token claims: %w", err)
+	}
+	if err := security.CheckLifetime(token.Exp, token.NotBefore, time.Now().UTC()); err != nil {
+		return oidcIDToken{}, nil, fmt.Errorf("invalid id token: %w", err)
+	}
+	if token.Issuer != s.config.Issuer {
+		return oidcIDToken{}, nil, errors.New("invalid id token issuer")
+	}
+	if !token.Audience.Contains(s.config.ClientID) || (len(token.Audience) > 1 && token.AuthorizedParty != s.config.ClientID) {
+		return oidcIDToken{}, nil, errors.New("invalid id token audience")
+	}
+	if subtle.ConstantTimeCompare([]byte(token.Nonce), []byte(nonce)) != 1 {
+		return oidcIDToken{}, nil, errors.New("invalid id token nonce")
+	}
+	if strings.TrimSpace(token.Subject) == "" || !strings.Contains(token.Email, "@") {
+		return oidcIDToken{}, nil, errors.New("id token must name the subject and email")
+	}
+	if token.EmailVerified != nil && !*token.EmailVerified {
+		return oidcIDToken{}, nil, errors.New("email is not verified by the identity provider")
+	}
+
+	var claims map[string]json.RawMessage
+	if err := json.Unmarshal(payload, &claims); err != nil {
+		return oidcIDToken{}, nil, fmt.Errorf("invalid id token claims: %w", err)
+	}
+	return token, groupsClaim(claims[s.config.GroupsClaim]), nil
+}
+
+// groupsClaim reads a groups claim given as an array or a single string.
+func groupsClaim(raw json.RawMessage) []string {
+	var groups []string
+	if err := json.Unmarshal(raw, &groups); err == nil {
+		return groups
+	}
+	var single string
+	if err := json.Unmarshal(raw, &single); err == nil && single != "" {
+		return []string{single}
+	}
+	return nil
+}
+
+// discover returns the provider's metadata, fetching it when the cached copy
+// is missing or stale.
+func (s *OIDCService) discover() (oidcProvider, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.provider.TokenEndpoint != "" && time.Since(s.providerAt) < oidcDiscoveryTTL {
+		return s.provider, nil
+	}
+	var provider oidcProvider
+	if err := s.getJSON(s.config.Issuer+"/.well-known/openid-configuration", &provider); err != nil {
+		return oidcProvider{}, err
+	}
+	if strings.TrimRight(provider.Issuer, "/") != s.config.Issuer {
+		return oidcProvider{}, fmt.Errorf("%w: discovery document is for issuer %q", ErrOIDCProvider, provider.Issuer)
+	}
+	if provider.AuthorizationEndpoint == "" || provider.TokenEndpoint == "" || provider.JWKSURI == "" {
+		return oidcProvider{}, fmt.Errorf("%w: discovery document lacks endpoints", ErrOIDCProvider)
+	}
+	s.provider, s.providerAt = provider, time.Now()
+	return provider, nil
+}
+
+// lookupKey finds the provider key that signed an ID token. An unknown kid
+// refetches the JWKS, since the provider may have rotated its keys.
+func (s *OIDCService) lookupKey(kid string) (security.Key, bool) {
+	if key, ok := s.cachedKey(kid); ok {
+		return key, true
+	}
+	s.mu.Lock()
+	stale := time.Since(s.keysAt) >= oidcKeysRefreshInterval
+	s.mu.Unlock()
+	if !stale {
+		return security.Key{}, false
+	}
+	if err := s.refreshKeys(); err != nil {
+		log.Printf("oidc: fetch signing keys: %v", err)
+		return security.Key{}, false
+	}
+	return s.cachedKey(kid)
+}
+
+// cachedKey looks kid up in the fetched keys. Tokens without a kid are
+// accepted when the provider publishes a single key.
+func (s *OIDCService) cachedKey(kid string) (security.Key, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if kid == "" && len(s.keys) == 1 {
+		for _, key := range s.keys {
+			return key, true
+		}
+	}
+	key, ok := s.keys[kid]
+	return key, ok
+}
+
+func (s *OIDCService) refreshKeys() error {
+	provider, err := s.discover()
+	if err != nil {
+		return err
+	}
+	var set security.JWKSet
+	if err := s.getJSON(provider.JWKSURI, &set); err != nil {
+		return err
+	}
+	keys := map[string]security.Key{}
+	for _, jwk := range set.Keys {
+		key, err := jwk.Key()
+		if err != nil {
+			// Providers publish encryption and other key types next to
+			// their signing keys.
+			continue
+		}
+		keys[key.ID] = key
+	}
+	s.mu.Lock()
+	s.keys, s.keysAt = keys, time.Now()
+	s.mu.Unlock()
+	return nil
+}
+
+func (s *OIDCService) getJSON(target string, v any) error {
+	resp, err := s.client.Get(target)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrOIDCProvider, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%w: %s returned %d", ErrOIDCProvider, target, resp.StatusCode)
+	}
+	if err := json.NewDecoder(io.LimitReader(resp.Body, oidcResponseLimit)).Decode(v); err != nil {
+		return fmt.Errorf("%w: %s: %v", ErrOIDCProvider, target, err)
+	}
+	return nil
+}
@@ -8,14 +8,18 @@ import (
 
 	"backend/internal/models"
 	"backend/internal/repositories"
+
+	"gorm.io/gorm"
 )
 
 type OrderService struct {
-	repo *repositories.OrderRepository
+	repo       *repositories.OrderRepository
+	currencies *CurrencyService
+	inventory  *InventoryService
 }
 
-func NewOrderService(repo *repositories.OrderRepository) *OrderService {
-	return &OrderService{repo: repo}
+func NewOrderService(repo *repositories.OrderRepository, currencies *CurrencyService, inventory *InventoryService) *OrderService {
+	return &OrderService{repo: repo, currencies: currencies, inventory: inventory}
 }
 
 func (s *OrderService) List() ([]models.OrderResponse, error) {
@@ -25,7 +29,7 @@ func (s *OrderService) List() ([]models.OrderResponse, error) {
 	}
 	result := make([]models.OrderResponse, 0, len(orders))
 	for _, order := range orders {
-		result = append(result, mapOrderResponse(order))
+		result = append(result, mapOrderResponse(order, s.currencies.BaseCurrency()))
 	}
 	return result, nil
 }
@@ -43,7 +47,7 @@ func (s *OrderService) ListByCustomerEmail(email string) ([]models.OrderResponse
 
 	result := make([]models.OrderResponse, 0, len(orders))
 	for _, order := range orders {
-		result = append(result, mapOrderResponse(order))
+		result = append(result, mapOrderResponse(order, s.currencies.BaseCurrency()))
 	}
 	return result, nil
 }
@@ -53,6 +57,8 @@ type CreateOrderInput struct {
 	Email    string
 	Address  string
 	Items    []models.CartItem
+	// Currency the customer checks out in; empty means the base currency.
+	Currency string
 }
 
 type UpdateOrderInput struct {
@@ -82,6 +88,11 @@ func (s *OrderService) Create(input CreateOrderInput) (models.OrderResponse, err
 		return models.OrderResponse{}, errors.New("items are required")
 	}
 
+	rate, err := s.currencies.Rate(input.Currency)
+	if err != nil {
+		return models.OrderResponse{}, err
+	}
+
 	pendingStatus, err := s.repo.FindStatusByCode(string(models.OrderStatusPending))
 	if err != nil {
 		return models.OrderResponse{}, err
@@ -99,6 +110,8 @@ func (s *OrderService) Create(input CreateOrderInput) (models.OrderResponse, err
 	}
 
 	total := int64(0)
+	currencyTotal := int64(0)
+	stocked := []string{}
 	orderItems := make([]models.OrderItem, 0, len(input.Items))
 	for _, item := range input.Items {
 		productID := strings.TrimSpace(item.Product.ID)
@@ -111,33 +124,28 @@ func (s *OrderService) Create(input CreateOrderInput) (models.OrderResponse, err
 			return models.OrderResponse{}, errors.New("quantity must be greater than 0")
 		}
 
-		product, getErr := s.repo.FindProductForUpdate(tx, productID)
+		product, preorderQty, getErr := s.reserveStock(tx, productID, item.Quantity)
 		if getErr != nil {
 			tx.Rollback()
-			return models.OrderResponse{}, fmt.Errorf("product %s not found", productID)
-		}
-		if product.StockQty < item.Quantity {
-			tx.Rollback()
-			return models.OrderResponse{}, fmt.Errorf("insufficient stock for %s", product.Name)
-		}
-
-		product.StockQty -= item.Quantity
-		if saveErr := tx.Save(&product).Error; saveErr != nil {
-			tx.Rollback()
-			return models.OrderResponse{}, saveErr
+			return models.OrderResponse{}, getErr
 		}
 
 		total += int64(item.Quantity) * product.Price
-		orderItems = append(orderItems, models.OrderItem{ProductID: product.ID, Qty: item.Quantity, Price: product.Price})
+		currencyTotal += int64(item.Quantity) * rate.Convert(product.Price)
+		orderItems = append(orderItems, models.OrderItem{ProductID: product.ID, Qty: item.Quantity, Price: product.Price, PreorderQty: preorderQty})
+		stocked = append(stocked, stockedProductIDs(product)...)
 	}
 
 	order := models.Order{
-		ID:         repositories.GenerateID("ORD"),
-		CustomerID: customer.ID,
-		StatusID:   pendingStatus.ID,
-		TotalSum:   total,
-		Address:    input.Address,
-		CreatedAt:  time.Now().UTC(),
+		ID:            repositories.GenerateID("ORD"),
+		CustomerID:    customer.ID,
+		StatusID:      pendingStatus.ID,
+		TotalSum:      total,
+		Currency:      rate.Currency,
+		ExchangeRate:  rate.Rate,
+		CurrencyTotal: currencyTotal,
+		Address:       input.Address,
+		CreatedAt:     time.Now().UTC(),
 	}
 	order.UpdatedAt = order.CreatedAt
 	if err := s.repo.SaveOrder(tx, &order); err != nil {
@@ -156,12 +164,13 @@ func (s *OrderService) Create(input CreateOrderInput) (models.OrderResponse, err
 		tx.Rollback()
 		return models.OrderResponse{}, err
 	}
+	s.checkStock(stocked)
 
 	stored, err := s.repo.GetByID(order.ID)
 	if err != nil {
 		return models.OrderResponse{}, err
 	}
-	return mapOrderResponse(stored), nil
+	return mapOrderResponse(stored, s.currencies.BaseCurrency()), nil
 }
 
 func (s *OrderService) UpdateStatus(orderID string, status models.OrderState) (models.OrderResponse, models.OrderState, error) {
@@ -204,7 +213,7 @@ func (s *OrderService) UpdateStatus(orderID string, status models.OrderState) (m
 		return models.OrderResponse{}, "", err
 	}
 
-	return mapOrderResponse(updated), prev, nil
+	return mapOrderResponse(updated, s.currencies.BaseCurrency()), prev, nil
 }
 
 func (s *OrderService) Update(orderID string, input UpdateOrderInput) (models.OrderResponse, models.OrderState, error) {
@@ -256,20 +265,20 @@ func (s *OrderService) Update(orderID string, input UpdateOrderInput) (models.Or
 		return models.OrderResponse{}, "", err
 	}
 
+	restocked := map[string]bool{}
 	for _, item := range order.Items {
-		product, getErr := s.repo.FindProductForUpdate(tx, item.ProductID)
-		if getErr != nil {
+		// Units still waiting on a pre-order were never taken from stock.
+		if err := s.releaseStock(tx, item.ProductID, item.Qty-item.PreorderQty, restocked); err != nil {
 			tx.Rollback()
-			return models.OrderResponse{}, "", getErr
-		}
-		product.StockQty += item.Qty
-		if saveErr := tx.Save(&product).Error; saveErr != nil {
-			tx.Rollback()
-			return models.OrderResponse{}, "", saveErr
+			return models.OrderResponse{}, "", err
 		}
 	}
 
+	// Edits keep the rate the customer checked out with.
+	rate := s.checkoutRate(order)
 	total := int64(0)
+	currencyTotal := int64(0)
+	stocked := []string{}
 	newItems := make([]models.OrderItem, 0, len(input.Items))
 	for _, item := range input.Items {
 		productID := strings.TrimSpace(item.Product.ID)
@@ -282,29 +291,22 @@ func (s *OrderService) Update(orderID string, input UpdateOrderInput) (models.Or
 			return models.OrderResponse{}, "", errors.New("quantity must be greater than 0")
 		}
 
-		product, getErr := s.repo.FindProductForUpdate(tx, productID)
+		product, preorderQty, getErr := s.reserveStock(tx, productID, item.Quantity)
 		if getErr != nil {
 			tx.Rollback()
-			return models.OrderResponse{}, "", fmt.Errorf("product %s not found", productID)
-		}
-		if product.StockQty < item.Quantity {
-			tx.Rollback()
-			return models.OrderResponse{}, "", fmt.Errorf("insufficient stock for %s", product.Name)
-		}
-
-		product.StockQty -= item.Quantity
-		if saveErr := tx.Save(&product).Error; saveErr != nil {
-			tx.Rollback()
-			return models.OrderResponse{}, "", saveErr
+			return models.OrderResponse{}, "", getErr
 		}
 
 		total += int64(item.Quantity) * product.Price
+		currencyTotal += int64(item.Quantity) * rate.Convert(product.Price)
 		newItems = append(newItems, models.OrderItem{
-			OrderID:   order.ID,
-			ProductID: product.ID,
-			Qty:       item.Quantity,
-			Price:     product.Price,
+			OrderID:     order.ID,
+			ProductID:   product.ID,
+			Qty:         item.Quantity,
+			Price:       product.Price,
+			PreorderQty: preorderQty,
 		})
+		stocked = append(stocked, stockedProductIDs(product)...)
 	}
 
 	if err := s.repo.DeleteOrderItems(tx, order.ID); err != nil {
@@ -315,10 +317,20 @@ func (s *OrderService) Update(orderID string, input UpdateOrderInput) (models.Or
 		tx.Rollback()
 		return models.OrderResponse{}, "", err
 	}
+	// Stock the edit gave back goes to other open pre-orders first.
+	for productID := range restocked {
+		if _, err := s.repo.AllocatePreorders(tx, productID); err != nil {
+			tx.Rollback()
+			return models.OrderResponse{}, "", err
+		}
+	}
 
 	order.CustomerID = customer.ID
 	order.StatusID = statusRef.ID
 	order.TotalSum = total
+	order.Currency = rate.Currency
+	order.ExchangeRate = rate.Rate
+	order.CurrencyTotal = currencyTotal
 	order.Address = input.Address
 	order.CreatedAt = input.Date.UTC()
 	order.UpdatedAt = time.Now().UTC()
@@ -332,30 +344,146 @@ func (s *OrderService) Update(orderID string, input UpdateOrderInput) (models.Or
 		tx.Rollback()
 		return models.OrderResponse{}, "", err
 	}
+	for productID := range restocked {
+		stocked = append(stocked, productID)
+	}
+	s.checkStock(stocked)
 
 	updated, err := s.repo.GetByID(orderID)
 	if err != nil {
 		return models.OrderResponse{}, "", err
 	}
-	return mapOrderResponse(updated), prev, nil
+	return mapOrderResponse(updated, s.currencies.BaseCurrency()), prev, nil
+}
+
+// checkStock queues a low-stock check of the products an order has just
+// taken out of stock. The check runs in the background so checkout does not
+// wait for it; the periodic checker catches up on anything missed.
+func (s *OrderService) checkStock(productIDs []string) {
+	if s.inventory == nil {
+		return
+	}
+	s.inventory.Queue(productIDs)
+}
+
+// stockedProductIDs returns the products whose stock an order line uses: the
+// product itself, or a bundle's components.
+func stockedProductIDs(product models.Product) []string {
+	if !product.IsBundle() {
+		return []string{product.ID}
+	}
+	ids := make([]string, 0, len(product.Components))
+	for _, c := range product.Components {
+		ids = append(ids, c.ComponentID)
+	}
+	return ids
+}
+
+// checkoutRate returns the rate an order was placed with, using the current
+// rounding step of its currency. Orders placed before multi-currency support
+// are in the base currency.
+func (s *OrderService) checkoutRate(order models.Order) models.ExchangeRate {
+	if base := s.currencies.BaseCurrency(); order.Currency == "" || order.Currency == base {
+		return models.ExchangeRate{Currency: base, Rate: 1, Rounding: 1}
+	}
+	rate, err := s.currencies.Rate(order.Currency)
+	if err != nil {
+		rate = models.ExchangeRate{Currency: order.Currency, Rounding: 1}
+	}
+	rate.Rate = order.ExchangeRate
+	return rate
 }
 
-func mapOrderResponse(order models.Order) models.OrderResponse {
+// reserveStock takes qty units of a product out of stock. Ordering a bundle
+// takes its components out of stock instead; the bundle has none of its own.
+// A product open for pre-order takes what stock there is and returns the
+// shortfall as the pre-ordered quantity.
+func (s *OrderService) reserveStock(tx *gorm.DB, productID string, qty int) (models.Product, int, error) {
+	product, err := s.repo.FindProductForUpdate(tx, productID)
+	if err != nil {
+		return models.Product{}, 0, fmt.Errorf("product %s not found", productID)
+	}
+	if !product.IsBundle() {
+		take, preorderQty := qty, 0
+		if product.StockQty < qty {
+			if !product.PreorderEnabled {
+				return models.Product{}, 0, fmt.Errorf("insufficient stock for %s", product.Name)
+			}
+			take = max(product.StockQty, 0)
+			preorderQty = qty - take
+		}
+		return product, preorderQty, s.repo.AdjustStock(tx, product.ID, -take)
+	}
+
+	if len(product.Components) == 0 {
+		return models.Product{}, 0, fmt.Errorf("bundle %s has no components", product.Name)
+	}
+	for _, c := range product.Components {
+		// Re-read each component so that several lines sharing it see earlier decrements.
+		component, err := s.repo.FindProductForUpdate(tx, c.ComponentID)
+		if err != nil || !component.IsActive {
+			return models.Product{}, 0, fmt.Errorf("insufficient stock for %s", product.Name)
+		}
+		need := c.Quantity * qty
+		if component.StockQty < need {
+			return models.Product{}, 0, fmt.Errorf("insufficient stock for %s (%s)", product.Name, component.Name)
+		}
+		if err := s.repo.AdjustStock(tx, component.ID, -need); err != nil {
+			return models.Product{}, 0, err
+		}
+	}
+	return product, 0, nil
+}
+
+// releaseStock returns qty units of a product, or of a bundle's components,
+// to stock and records the restocked product ids.
+func (s *OrderService) releaseStock(tx *gorm.DB, productID string, qty int, restocked map[string]bool) error {
+	bundle, err := s.repo.IsBundle(tx, productID)
+	if err != nil {
+		return err
+	}
+	if !bundle {
+		restocked[productID] = true
+		return s.repo.AdjustStock(tx, productID, qty)
+	}
+	components, err := s.repo.FindComponentsForRestock(tx, productID)
+	if err != nil {
+		return err
+	}
+	for _, c := range components {
+		restocked[c.ComponentID] = true
+		if err := s.repo.AdjustStock(tx, c.ComponentID, c.Quantity*qty); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func mapOrderResponse(order models.Order, baseCurrency string) models.OrderResponse {
 	items := make([]models.CartItem, 0, len(order.Items))
 	for _, item := range order.Items {
 		item.Product.Category = item.Product.CategoryRef.Name
 		item.Product.SyncViewFields()
-		items = append(items, models.CartItem{Product: item.Product, Quantity: item.Qty})
+		items = append(items, models.CartItem{Product: item.Product, Quantity: item.Qty, PreorderQty: item.PreorderQty})
+	}
+
+	currency, rate, currencyTotal := order.Currency, order.ExchangeRate, order.CurrencyTotal
+	if currency == "" {
+		currency, rate, currencyTotal = baseCurrency, 1, order.TotalSum
 	}
 
 	return models.OrderResponse{
-		ID:       order.ID,
-		Customer: order.Customer.FullName,
-		Email:    order.Customer.Email,
-		Items:    items,
-		Total:    order.TotalSum,
-		Status:   models.OrderState(order.StatusRef.Code),
-		Date:     order.CreatedAt,
-		Address:  order.Address,
+		ID:               order.ID,
+		Customer:         order.Customer.FullName,
+		Email:            order.Customer.Email,
+		Items:            items,
+		Total:            order.TotalSum,
+		Status:           models.OrderState(order.StatusRef.Code),
+		Date:             order.CreatedAt,
+		Address:          order.Address,
+		Currency:         baseCurrency,
+		CheckoutCurrency: currency,
+		CheckoutRate:     rate,
+		CheckoutTotal:    currencyTotal,
 	}
 }
@@ -0,0 +1,104 @@
+package services
+
+import (
+	"bytes"
+	"strconv"
+
+	"backend/internal/models"
+
+	"github.com/go-pdf/fpdf"
+	"golang.org/x/image/font/gofont/gobold"
+	"golang.org/x/image/font/gofont/goregular"
+)
+
+// packingSlipFont is embedded with UTF-8 support, since customer names and
+// addresses are often in Cyrillic.
+const packingSlipFont = "Go"
+
+// PackingSlipPDF renders an A4 packing slip listing what goes into the
+// parcel, with a QR code linking to the order under publicURL.
+func PackingSlipPDF(f models.Fulfillment, publicURL string) ([]byte, error) {
+	pdf := fpdf.New("P", "mm", "A4", "")
+	pdf.AddUTF8FontFromBytes(packingSlipFont, "", goregular.TTF)
+	pdf.AddUTF8FontFromBytes(packingSlipFont, "B", gobold.TTF)
+	pdf.SetTitle("Packing slip "+f.OrderID, true)
+	pdf.SetMargins(15, 15, 15)
+	pdf.AddPage()
+
+	// The QR code links to the order so scanning the parcel opens it.
+	qrCode, err := RenderQRPNG(OrderURL(publicURL, f.OrderID), 256)
+	if err != nil {
+		return nil, err
+	}
+	qrOptions := fpdf.ImageOptions{ImageType: "PNG"}
+	pdf.RegisterImageOptionsReader("order-qr", qrOptions, bytes.NewReader(qrCode))
+	pdf.ImageOptions("order-qr", 160, 12, 35, 35, false, qrOptions, 0, "")
+
+	pdf.SetFont(packingSlipFont, "B", 18)
+	pdf.CellFormat(0, 10, "Packing slip", "", 1, "L", false, 0, "")
+	pdf.SetFont(packingSlipFont, "", 10)
+	pdf.CellFormat(0, 6, "Order "+f.OrderID, "", 1, "L", false, 0, "")
+	pdf.CellFormat(0, 6, "Placed "+f.Date.UTC().Format("2006-01-02"), "", 1, "L", false, 0, "")
+	pdf.Ln(4)
+
+	pdf.SetFont(packingSlipFont, "B", 11)
+	pdf.CellFormat(0, 6, "Ship to", "", 1, "L", false, 0, "")
+	pdf.SetFont(packingSlipFont, "", 10)
+	pdf.MultiCell(0, 5, f.Customer+"\n"+f.Address, "", "L", false)
+	pdf.Ln(6)
+
+	widths := []float64{30, 80, 22, 16, 16, 16}
+	headers := []string{"SKU", "Item", "Location", "Qty", "Picked", "Packed"}
+	pdf.SetFont(packingSlipFont, "B", 10)
+	pdf.SetFillColor(235, 235, 235)
+	for i, header := range headers {
+		pdf.CellFormat(widths[i], 7, header, "1", 0, "L", true, 0, "")
+	}
+	pdf.Ln(-1)
+
+	pdf.SetFont(packingSlipFont, "", 10)
+	total := 0
+	for _, line := range f.Lines {
+		total += line.Qty
+		cells := []string{
+			line.SKU,
+			line.Name,
+			line.Location,
+			strconv.Itoa(line.Qty),
+			checkMark(line.PickedAt != nil),
+			checkMark(line.PackedAt != nil),
+		}
+		for i, cell := range cells {
+			pdf.CellFormat(widths[i], 7, fitText(pdf, cell, widths[i]-2), "1", 0, "L", false, 0, "")
+		}
+		pdf.Ln(-1)
+	}
+	pdf.SetFont(packingSlipFont, "B", 10)
+	pdf.CellFormat(widths[0]+widths[1]+widths[2], 7, "Total items", "1", 0, "R", false, 0, "")
+	pdf.CellFormat(widths[3], 7, strconv.Itoa(total), "1", 1, "L", false, 0, "")
+
+	var buf bytes.Buffer
+	if err := pdf.Output(&buf); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func checkMark(done bool) string {
+	if done {
+		return "yes"
+	}
+	return ""
+}
+
+// fitText shortens text that would overflow a table cell.
+func fitText(pdf *fpdf.Fpdf, text string, width float64) string {
+	if pdf.GetStringWidth(text) <= width {
+		return text
+	}
+	runes := []rune(text)
+	for len(runes) > 0 && pdf.GetStringWidth(string(runes)+"...") > width {
+		runes = runes[:len(runes)-1]
+	}
+	return string(runes) + "..."
+}
@@ -0,0 +1,117 @@
+package services
+
+import (
+	"strings"
+	"sync"
+	"time"
+
+	"backend/internal/models"
+	"backend/internal/repositories"
+)
+
+// PermissionService answers authorization questions from the role and
+// permission tables, caching each role's grants until they expire or are
+// invalidated.
+type PermissionService struct {
+	repo *repositories.PermissionRepository
+	// ttl bounds how long a role's permissions are served from memory, and
+	// so how long a change made by another instance takes to apply.
+	ttl   time.Duration
+	mu    sync.RWMutex
+	cache map[models.RoleName]roleGrants
+	// generation changes on every invalidation so a load that raced with
+	// one is not cached.
+	generation uint64
+	// enforced holds the action:resource pairs that routes check.
+	enforced map[string]struct{}
+}
+
+type roleGrants struct {
+	allow    map[string]struct{}
+	deny     map[string]struct{}
+	loadedAt time.Time
+}
+
+func NewPermissionService(repo *repositories.PermissionRepository, ttl time.Duration) *PermissionService {
+	return &PermissionService{
+		repo:     repo,
+		ttl:      ttl,
+		cache:    map[models.RoleName]roleGrants{},
+		enforced: map[string]struct{}{},
+	}
+}
+
+func permissionKey(action, resource string) string {
+	return strings.ToLower(strings.TrimSpace(action)) + ":" + strings.ToLower(strings.TrimSpace(resource))
+}
+
+// Allowed reports whether the role may perform action on resource: it needs
+// an allow for the pair and no deny.
+func (s *PermissionService) Allowed(role models.RoleName, action, resource string) (bool, error) {
+	grants, err := s.grants(role)
+	if err != nil {
+		return false, err
+	}
+	key := permissionKey(action, resource)
+	if _, denied := grants.deny[key]; denied {
+		return false, nil
+	}
+	_, allowed := grants.allow[key]
+	return allowed, nil
+}
+
+// Enforce records that a route checks action on resource.
+func (s *PermissionService) Enforce(action, resource string) {
+	s.mu.Lock()
+	s.enforced[permissionKey(action, resource)] = struct{}{}
+	s.mu.Unlock()
+}
+
+// IsEnforced reports whether any route checks action on resource.
+func (s *PermissionService) IsEnforced(action, resource string) bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	_, ok := s.enforced[permissionKey(action, resource)]
+	return ok
+}
+
+// Invalidate drops every cached role so the next check reads the tables.
+func (s *PermissionService) Invalidate() {
+	s.mu.Lock()
+	s.cache = map[models.RoleName]roleGrants{}
+	s.generation++
+	s.mu.Unlock()
+}
+
+func (s *PermissionService) grants(role models.RoleName) (roleGrants, error) {
+	now := time.Now()
+	s.mu.RLock()
+	grants, ok := s.cache[role]
+	generation := s.generation
+	s.mu.RUnlock()
+	if ok && now.Sub(grants.loadedAt) < s.ttl {
+		return grants, nil
+	}
+
+	permissions, err := s.repo.ForRole(role)
+	if err != nil {
+		return roleGrants{}, err
+	}
+	grants = roleGrants{allow: map[string]struct{}{}, deny: map[string]struct{}{}, loadedAt: now}
+	for _, permission := range permissions {
+		key := permissionKey(permission.Action, permission.Resource)
+		switch strings.ToLower(permission.Effect) {
+		case models.PermissionDeny:
+			grants.deny[key] = struct{}{}
+		case models.PermissionAllow:
+			grants.allow[key] = struct{}{}
+		}
+	}
+
+	s.mu.Lock()
+	if s.generation == generation {
+		s.cache[role] = grants
+	}
+	s.mu.Unlock()
+	return grants, nil
+}
@@ -0,0 +1,334 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"log"
+	"strings"
+	"time"
+
+	"backend/internal/models"
+	"backend/internal/repositories"
+
+	"gorm.io/gorm"
+)
+
+type SchedulePriceInput struct {
+	Price         int64
+	OriginalPrice *int64
+	StartsAt      time.Time
+	EndsAt        *time.Time
+}
+
+type PriceService struct {
+	products *repositories.ProductRepository
+	repo     *repositories.PriceRepository
+}
+
+func NewPriceService(products *repositories.ProductRepository, repo *repositories.PriceRepository) *PriceService {
+	return &PriceService{products: products, repo: repo}
+}
+
+func (s *PriceService) Prices(productID string) (models.ProductPricesResponse, error) {
+	productID = strings.TrimSpace(productID)
+	if productID == "" {
+		return models.ProductPricesResponse{}, errors.New("invalid product id")
+	}
+	product, err := s.products.GetByID(productID)
+	if err != nil {
+		return models.ProductPricesResponse{}, err
+	}
+	history, err := s.repo.ListHistory(productID)
+	if err != nil {
+		return models.ProductPricesResponse{}, err
+	}
+	scheduled, err := s.repo.ListSchedules(productID)
+	if err != nil {
+		return models.ProductPricesResponse{}, err
+	}
+	return models.ProductPricesResponse{ProductID: product.ID, Price: product.Price, History: history, Scheduled: scheduled}, nil
+}
+
+func (s *PriceService) Schedule(productID string, input SchedulePriceInput, actor string) (models.ScheduledPrice, error) {
+	productID = strings.TrimSpace(productID)
+	if productID == "" {
+		return models.ScheduledPrice{}, errors.New("invalid product id")
+	}
+	if _, err := s.products.GetByID(productID); err != nil {
+		return models.ScheduledPrice{}, err
+	}
+	if input.Price < 0 {
+		return models.ScheduledPrice{}, errors.New("price must be >= 0")
+	}
+	if input.OriginalPrice != nil && *input.OriginalPrice < input.Price {
+		return models.ScheduledPrice{}, errors.New("originalPrice must be >= price")
+	}
+	if input.StartsAt.IsZero() {
+		return models.ScheduledPrice{}, errors.New("starts_at is required")
+	}
+	// A start in the past means "now"; keeping the past date would record a
+	// schedule that claims to have started before it existed.
+	if now := time.Now().UTC(); input.StartsAt.Before(now) {
+		input.StartsAt = now
+	}
+	if input.EndsAt != nil && !input.EndsAt.After(input.StartsAt) {
+		return models.ScheduledPrice{}, errors.New("ends_at must be after starts_at")
+	}
+
+	open, err := s.repo.ListOpenSchedules(productID)
+	if err != nil {
+		return models.ScheduledPrice{}, err
+	}
+	for _, other := range open {
+		if schedulesOverlap(input.StartsAt, input.EndsAt, other.StartsAt, other.EndsAt) {
+			return models.ScheduledPrice{}, errors.New("schedule overlaps an existing price change")
+		}
+	}
+
+	item := models.ScheduledPrice{
+		ProductID:     productID,
+		Price:         input.Price,
+		OriginalPrice: input.OriginalPrice,
+		StartsAt:      input.StartsAt.UTC(),
+		EndsAt:        input.EndsAt,
+		Status:        models.ScheduledPricePending,
+		CreatedBy:     strings.TrimSpace(actor),
+	}
+	if item.EndsAt != nil {
+		endsAt := item.EndsAt.UTC()
+		item.EndsAt = &endsAt
+	}
+	if err := s.repo.CreateSchedule(&item); err != nil {
+		return models.ScheduledPrice{}, err
+	}
+	return item, nil
+}
+
+// CancelSchedule cancels a pending schedule, or ends an active one early
+// and restores the price it replaced.
+func (s *PriceService) CancelSchedule(productID string, scheduleID uint, actor string) (models.ScheduledPrice, error) {
+	item, err := s.repo.GetSchedule(scheduleID)
+	if err != nil {
+		return models.ScheduledPrice{}, err
+	}
+	if item.ProductID != strings.TrimSpace(productID) {
+		return models.ScheduledPrice{}, errors.New("schedule does not belong to product")
+	}
+	from := item.Status
+	if from != models.ScheduledPricePending && from != models.ScheduledPriceActive {
+		return models.ScheduledPrice{}, errors.New("only pending or active schedules can be cancelled")
+	}
+	tx := s.repo.Begin()
+	if tx.Error != nil {
+		return models.ScheduledPrice{}, tx.Error
+	}
+
+	item.Status = models.ScheduledPriceCancelled
+	moved, err := s.repo.MoveSchedule(tx, &item, from)
+	if err != nil {
+		tx.Rollback()
+		return models.ScheduledPrice{}, err
+	}
+	if !moved {
+		tx.Rollback()
+		return models.ScheduledPrice{}, errors.New("schedule changed in the meantime, try again")
+	}
+	if from == models.ScheduledPriceActive {
+		product, err := s.repo.FindProduct(tx, item.ProductID)
+		if err != nil && !IsNotFound(err) {
+			tx.Rollback()
+			return models.ScheduledPrice{}, err
+		}
+		// An archived product has no price left to restore.
+		if err == nil {
+			if err := s.restore(tx, item, product, strings.TrimSpace(actor), time.Now().UTC()); err != nil {
+				tx.Rollback()
+				return models.ScheduledPrice{}, err
+			}
+		}
+	}
+	if err := tx.Commit().Error; err != nil {
+		return models.ScheduledPrice{}, err
+	}
+	return item, nil
+}
+
+// ApplyDue starts pending schedules whose start date has passed and reverts
+// active ones whose end date has passed. It returns the number of changes made.
+func (s *PriceService) ApplyDue(now time.Time) (int, error) {
+	applied := 0
+
+	ending, err := s.repo.DueToEnd(now)
+	if err != nil {
+		return applied, err
+	}
+	for _, item := range ending {
+		finished, err := s.finish(item, now)
+		if err != nil {
+			return applied, err
+		}
+		if finished {
+			applied++
+		}
+	}
+
+	starting, err := s.repo.DueToStart(now)
+	if err != nil {
+		return applied, err
+	}
+	for _, item := range starting {
+		started, err := s.start(item, now)
+		if err != nil {
+			return applied, err
+		}
+		if started {
+			applied++
+		}
+	}
+	return applied, nil
+}
+
+// Run applies due schedules every interval until ctx is cancelled.
+func (s *PriceService) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		if _, err := s.ApplyDue(time.Now().UTC()); err != nil {
+			log.Printf("price scheduler: %v", err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// start puts a pending schedule's price live. It reports false, changing
+// nothing, when the schedule is no longer pending, e.g. because it was
+// cancelled after the scheduler read it.
+func (s *PriceService) start(item models.ScheduledPrice, now time.Time) (bool, error) {
+	tx := s.repo.Begin()
+	if tx.Error != nil {
+		return false, tx.Error
+	}
+
+	product, err := s.repo.FindProduct(tx, item.ProductID)
+	if IsNotFound(err) {
+		// The product was archived; drop the schedule instead of retrying forever.
+		item.Status = models.ScheduledPriceCancelled
+		return s.commitMove(tx, &item, models.ScheduledPricePending)
+	}
+	if err != nil {
+		tx.Rollback()
+		return false, err
+	}
+
+	prevPrice := product.Price
+	item.RestorePrice = &prevPrice
+	item.RestoreOriginalPrice = product.OriginalPrice
+	item.Status = models.ScheduledPriceActive
+	if item.EndsAt == nil {
+		item.Status = models.ScheduledPriceCompleted
+	}
+	moved, err := s.repo.MoveSchedule(tx, &item, models.ScheduledPricePending)
+	if err != nil || !moved {
+		tx.Rollback()
+		return false, err
+	}
+
+	if err := s.repo.SetProductPrice(tx, item.ProductID, item.Price, item.OriginalPrice); err != nil {
+		tx.Rollback()
+		return false, err
+	}
+	if err := s.repo.CreateHistory(tx, &models.PriceHistory{
+		ProductID:         item.ProductID,
+		Price:             item.Price,
+		OriginalPrice:     item.OriginalPrice,
+		PrevPrice:         &prevPrice,
+		PrevOriginalPrice: product.OriginalPrice,
+		Source:            models.PriceSourceSchedule,
+		ScheduleID:        &item.ID,
+		ChangedBy:         item.CreatedBy,
+		ChangedAt:         now,
+	}); err != nil {
+		tx.Rollback()
+		return false, err
+	}
+	return true, tx.Commit().Error
+}
+
+// finish ends an active schedule and restores the price it replaced. It
+// reports false, changing nothing, when the schedule is no longer active.
+func (s *PriceService) finish(item models.ScheduledPrice, now time.Time) (bool, error) {
+	tx := s.repo.Begin()
+	if tx.Error != nil {
+		return false, tx.Error
+	}
+
+	product, err := s.repo.FindProduct(tx, item.ProductID)
+	if IsNotFound(err) {
+		// The product was archived; drop the schedule instead of retrying forever.
+		item.Status = models.ScheduledPriceCancelled
+		return s.commitMove(tx, &item, models.ScheduledPriceActive)
+	}
+	if err != nil {
+		tx.Rollback()
+		return false, err
+	}
+
+	item.Status = models.ScheduledPriceCompleted
+	moved, err := s.repo.MoveSchedule(tx, &item, models.ScheduledPriceActive)
+	if err != nil || !moved {
+		tx.Rollback()
+		return false, err
+	}
+	if err := s.restore(tx, item, product, item.CreatedBy, now); err != nil {
+		tx.Rollback()
+		return false, err
+	}
+	return true, tx.Commit().Error
+}
+
+// restore puts back the price an active schedule replaced and records it.
+// A manual edit made while the schedule was active wins over the restore.
+func (s *PriceService) restore(tx *gorm.DB, item models.ScheduledPrice, product models.Product, changedBy string, now time.Time) error {
+	if item.RestorePrice == nil || product.Price != item.Price {
+		return nil
+	}
+	if err := s.repo.SetProductPrice(tx, item.ProductID, *item.RestorePrice, item.RestoreOriginalPrice); err != nil {
+		return err
+	}
+	prevPrice := product.Price
+	return s.repo.CreateHistory(tx, &models.PriceHistory{
+		ProductID:         item.ProductID,
+		Price:             *item.RestorePrice,
+		OriginalPrice:     item.RestoreOriginalPrice,
+		PrevPrice:         &prevPrice,
+		PrevOriginalPrice: product.OriginalPrice,
+		Source:            models.PriceSourceSchedule,
+		ScheduleID:        &item.ID,
+		ChangedBy:         changedBy,
+		ChangedAt:         now,
+	})
+}
+
+// commitMove moves the schedule out of status from and commits, or rolls
+// back when it has left that status already.
+func (s *PriceService) commitMove(tx *gorm.DB, item *models.ScheduledPrice, from models.ScheduledPriceStatus) (bool, error) {
+	moved, err := s.repo.MoveSchedule(tx, item, from)
+	if err != nil || !moved {
+		tx.Rollback()
+		return false, err
+	}
+	return true, tx.Commit().Error
+}
+
+// schedulesOverlap treats a change without an end date as a single instant,
+// so consecutive permanent changes never conflict with each other.
+func schedulesOverlap(aStart time.Time, aEnd *time.Time, bStart time.Time, bEnd *time.Time) bool {
+	within := func(t, start time.Time, end *time.Time) bool {
+		return !t.Before(start) && (end == nil && t.Equal(start) || end != nil && t.Before(*end))
+	}
+	return within(aStart, bStart, bEnd) || within(bStart, aStart, aEnd)
+}
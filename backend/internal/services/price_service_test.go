@@ -0,0 +1,109 @@
+package services
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"backend/internal/config"
+	"backend/internal/database"
+	"backend/internal/models"
+	"backend/internal/repositories"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func newPriceService(t *testing.T) (*PriceService, *gorm.DB) {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open(fmt.Sprintf("file:%s?mode=memory&cache=shared", t.Name())), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("open sqlite: %v", err)
+	}
+	if err := database.ConnectSeedOnlyForTests(db, config.Config{}); err != nil {
+		t.Fatalf("seed database: %v", err)
+	}
+	return NewPriceService(repositories.NewProductRepository(db), repositories.NewPriceRepository(db)), db
+}
+
+func TestSchedulesOverlap(t *testing.T) {
+	day := func(d int) time.Time { return time.Date(2026, 3, d, 0, 0, 0, 0, time.UTC) }
+	end := func(d int) *time.Time { v := day(d); return &v }
+
+	if !schedulesOverlap(day(1), end(10), day(5), end(15)) {
+		t.Fatalf("expected overlapping sale windows to conflict")
+	}
+	if schedulesOverlap(day(1), end(10), day(10), end(15)) {
+		t.Fatalf("expected adjacent sale windows not to conflict")
+	}
+	if !schedulesOverlap(day(1), end(10), day(3), nil) {
+		t.Fatalf("expected permanent change inside a sale window to conflict")
+	}
+	if schedulesOverlap(day(1), nil, day(3), nil) {
+		t.Fatalf("expected consecutive permanent changes not to conflict")
+	}
+}
+
+func TestScheduleCancelledAfterTheSchedulerReadItStaysCancelled(t *testing.T) {
+	service, db := newPriceService(t)
+	var chair models.Product
+	if err := db.First(&chair, "sku = ?", "CHR-ARIA-TER").Error; err != nil {
+		t.Fatalf("fetch product: %v", err)
+	}
+	ends := time.Now().Add(time.Hour)
+	item, err := service.Schedule(chair.ID, SchedulePriceInput{Price: 9990, StartsAt: time.Now(), EndsAt: &ends}, "manager@maison.co")
+	if err != nil {
+		t.Fatalf("schedule: %v", err)
+	}
+
+	now := time.Now().UTC().Add(time.Second)
+	due, err := service.repo.DueToStart(now)
+	if err != nil || len(due) != 1 {
+		t.Fatalf("expected one due schedule, got %d: %v", len(due), err)
+	}
+	if _, err := service.CancelSchedule(chair.ID, item.ID, "manager@maison.co"); err != nil {
+		t.Fatalf("cancel: %v", err)
+	}
+	started, err := service.start(due[0], now)
+	if err != nil || started {
+		t.Fatalf("expected the stale start to be skipped, got started=%v err=%v", started, err)
+	}
+	stored, _ := service.repo.GetSchedule(item.ID)
+	if err := db.First(&chair, "id = ?", chair.ID).Error; err != nil || chair.Price != 14990 || stored.Status != models.ScheduledPriceCancelled {
+		t.Fatalf("expected the cancelled sale not to go live, got price %d status %s: %v", chair.Price, stored.Status, err)
+	}
+}
+
+func TestCancelActiveScheduleRestoresThePrice(t *testing.T) {
+	service, db := newPriceService(t)
+	var chair models.Product
+	if err := db.First(&chair, "sku = ?", "CHR-ARIA-TER").Error; err != nil {
+		t.Fatalf("fetch product: %v", err)
+	}
+	ends := time.Now().Add(time.Hour)
+	item, err := service.Schedule(chair.ID, SchedulePriceInput{Price: 9990, StartsAt: time.Now(), EndsAt: &ends}, "manager@maison.co")
+	if err != nil {
+		t.Fatalf("schedule: %v", err)
+	}
+	if applied, err := service.ApplyDue(time.Now().UTC().Add(time.Second)); err != nil || applied != 1 {
+		t.Fatalf("expected the sale to start, got %d: %v", applied, err)
+	}
+
+	cancelled, err := service.CancelSchedule(chair.ID, item.ID, "admin@maison.co")
+	if err != nil || cancelled.Status != models.ScheduledPriceCancelled {
+		t.Fatalf("expected the running sale to be cancelled, got %+v: %v", cancelled, err)
+	}
+	if err := db.First(&chair, "id = ?", chair.ID).Error; err != nil || chair.Price != 14990 {
+		t.Fatalf("expected the price to be restored to 14990, got %d: %v", chair.Price, err)
+	}
+	var entry models.PriceHistory
+	if err := db.Where("schedule_id = ?", item.ID).Order("id desc").First(&entry).Error; err != nil || entry.Price != 14990 || entry.ChangedBy != "admin@maison.co" {
+		t.Fatalf("expected the restore to be recorded, got %+v: %v", entry, err)
+	}
+	if applied, err := service.ApplyDue(time.Now().UTC().Add(2 * time.Hour)); err != nil || applied != 0 {
+		t.Fatalf("expected nothing left to finish, got %d: %v", applied, err)
+	}
+	if _, err := service.CancelSchedule(chair.ID, item.ID, "admin@maison.co"); err == nil {
+		t.Fatalf("expected a cancelled schedule not to be cancelled again")
+	}
+}
@@ -0,0 +1,483 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"net/mail"
+	"sort"
+	"strings"
+	"time"
+
+	"backend/internal/models"
+	"backend/internal/repositories"
+)
+
+// forecastSalesWindowDays is how far back sales are looked at to split a
+// category's forecast between its products.
+const forecastSalesWindowDays = 90
+
+const (
+	defaultReorderLeadTimeDays = 14
+	defaultSafetyDays          = 7
+)
+
+type ProcurementService struct {
+	repo     *repositories.ProcurementRepository
+	products *repositories.ProductRepository
+	forecast *ForecastService
+}
+
+func NewProcurementService(repo *repositories.ProcurementRepository, products *repositories.ProductRepository, forecast *ForecastService) *ProcurementService {
+	return &ProcurementService{repo: repo, products: products, forecast: forecast}
+}
+
+type SupplierInput struct {
+	Name         string `json:"name"`
+	Email        string `json:"email"`
+	Phone        string `json:"phone"`
+	LeadTimeDays int    `json:"lead_time_days"`
+	IsActive     *bool  `json:"is_active"`
+}
+
+type PurchaseOrderLineInput struct {
+	ProductID string `json:"product_id"`
+	Qty       int    `json:"qty"`
+	UnitCost  int64  `json:"unit_cost"`
+}
+
+type PurchaseOrderInput struct {
+	SupplierID uint                     `json:"supplier_id"`
+	ExpectedAt *time.Time               `json:"expected_at"`
+	Notes      string                   `json:"notes"`
+	Lines      []PurchaseOrderLineInput `json:"lines"`
+}
+
+type ReceiptLineInput struct {
+	ProductID string `json:"product_id"`
+	Qty       int    `json:"qty"`
+}
+
+type ForecastPurchaseInput struct {
+	SupplierID  uint   `json:"supplier_id"`
+	Months      int    `json:"months"`
+	CategoryIDs []uint `json:"category_ids"`
+}
+
+type ReorderForecastInput struct {
+	Months       int    `json:"months"`
+	LeadTimeDays int    `json:"lead_time_days"`
+	SafetyDays   int    `json:"safety_days"`
+	CategoryIDs  []uint `json:"category_ids"`
+}
+
+type ReorderLevels struct {
+	ProductID    string `json:"product_id"`
+	SKU          string `json:"sku"`
+	ReorderPoint int    `json:"reorder_point"`
+	SafetyStock  int    `json:"safety_stock"`
+}
+
+func (s *ProcurementService) ListSuppliers() ([]models.Supplier, error) {
+	return s.repo.ListSuppliers()
+}
+
+func (s *ProcurementService) CreateSupplier(input SupplierInput) (models.Supplier, error) {
+	supplier := models.Supplier{IsActive: true}
+	if err := s.applySupplier(&supplier, input); err != nil {
+		return models.Supplier{}, err
+	}
+	if err := s.repo.SaveSupplier(&supplier); err != nil {
+		return models.Supplier{}, err
+	}
+	return supplier, nil
+}
+
+func (s *ProcurementService) UpdateSupplier(id uint, input SupplierInput) (models.Supplier, error) {
+	supplier, err := s.repo.GetSupplier(id)
+	if err != nil {
+		return models.Supplier{}, err
+	}
+	if err := s.applySupplier(&supplier, input); err != nil {
+		return models.Supplier{}, err
+	}
+	if err := s.repo.SaveSupplier(&supplier); err != nil {
+		return models.Supplier{}, err
+	}
+	return supplier, nil
+}
+
+func (s *ProcurementService) applySupplier(supplier *models.Supplier, input SupplierInput) error {
+	input.Name = strings.TrimSpace(input.Name)
+	input.Email = strings.TrimSpace(strings.ToLower(input.Email))
+	if input.Name == "" {
+		return errors.New("name is required")
+	}
+	if input.Email != "" {
+		if _, err := mail.ParseAddress(input.Email); err != nil {
+			return errors.New("email is invalid")
+		}
+	}
+	if input.LeadTimeDays < 0 {
+		return errors.New("lead_time_days must be >= 0")
+	}
+	taken, err := s.repo.SupplierNameTaken(input.Name, supplier.ID)
+	if err != nil {
+		return err
+	}
+	if taken {
+		return fmt.Errorf("supplier %s already exists", input.Name)
+	}
+
+	supplier.Name = input.Name
+	supplier.Email = input.Email
+	supplier.Phone = strings.TrimSpace(input.Phone)
+	supplier.LeadTimeDays = input.LeadTimeDays
+	if input.IsActive != nil {
+		supplier.IsActive = *input.IsActive
+	}
+	return nil
+}
+
+func (s *ProcurementService) List(status models.PurchaseOrderStatus) ([]models.PurchaseOrder, error) {
+	if status != "" && !models.IsValidPurchaseOrderStatus(status) {
+		return nil, errors.New("invalid status")
+	}
+	return s.repo.ListPurchaseOrders(status)
+}
+
+func (s *ProcurementService) Get(id string) (models.PurchaseOrder, error) {
+	return s.repo.GetPurchaseOrder(strings.TrimSpace(id))
+}
+
+// Create makes a draft purchase order. Without an expected date the
+// supplier's lead time is used.
+func (s *ProcurementService) Create(input PurchaseOrderInput, actor string) (models.PurchaseOrder, error) {
+	now := time.Now().UTC()
+	order := models.PurchaseOrder{
+		ID:        repositories.GenerateID("PO"),
+		Status:    models.PurchaseOrderDraft,
+		CreatedBy: strings.TrimSpace(actor),
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	if err := s.applyPurchaseOrder(&order, input); err != nil {
+		return models.PurchaseOrder{}, err
+	}
+	if err := s.repo.SavePurchaseOrder(&order, true); err != nil {
+		return models.PurchaseOrder{}, err
+	}
+	return s.repo.GetPurchaseOrder(order.ID)
+}
+
+// Update replaces the supplier, date, notes and lines of a draft.
+func (s *ProcurementService) Update(id string, input PurchaseOrderInput) (models.PurchaseOrder, error) {
+	order, err := s.Get(id)
+	if err != nil {
+		return models.PurchaseOrder{}, err
+	}
+	if order.Status != models.PurchaseOrderDraft {
+		return models.PurchaseOrder{}, errors.New("only draft purchase orders can be edited")
+	}
+	if err := s.applyPurchaseOrder(&order, input); err != nil {
+		return models.PurchaseOrder{}, err
+	}
+	order.UpdatedAt = time.Now().UTC()
+	if err := s.repo.SavePurchaseOrder(&order, false); err != nil {
+		return models.PurchaseOrder{}, err
+	}
+	return s.repo.GetPurchaseOrder(order.ID)
+}
+
+func (s *ProcurementService) applyPurchaseOrder(order *models.PurchaseOrder, input PurchaseOrderInput) error {
+	supplier, err := s.repo.GetSupplier(input.SupplierID)
+	if err != nil {
+		if IsNotFound(err) {
+			return errors.New("supplier not found")
+		}
+		return err
+	}
+	if !supplier.IsActive {
+		return fmt.Errorf("supplier %s is inactive", supplier.Name)
+	}
+	if len(input.Lines) == 0 {
+		return errors.New("lines are required")
+	}
+
+	lines := make([]models.PurchaseOrderLine, 0, len(input.Lines))
+	seen := map[string]bool{}
+	for _, line := range input.Lines {
+		productID := strings.TrimSpace(line.ProductID)
+		if productID == "" {
+			return errors.New("product_id is required for each line")
+		}
+		if seen[productID] {
+			return fmt.Errorf("product %s is listed more than once", productID)
+		}
+		if line.Qty <= 0 {
+			return errors.New("qty must be greater than 0")
+		}
+		if line.UnitCost < 0 {
+			return errors.New("unit_cost must be >= 0")
+		}
+		product, err := s.products.GetByID(productID)
+		if err != nil {
+			if IsNotFound(err) {
+				return fmt.Errorf("product %s not found", productID)
+			}
+			return err
+		}
+		if product.IsBundle() {
+			return fmt.Errorf("bundle %s cannot be purchased, order its components", product.Name)
+		}
+		seen[productID] = true
+		lines = append(lines, models.PurchaseOrderLine{ProductID: productID, Qty: line.Qty, UnitCost: line.UnitCost})
+	}
+
+	expectedAt := input.ExpectedAt
+	if expectedAt == nil && supplier.LeadTimeDays > 0 {
+		at := time.Now().UTC().AddDate(0, 0, supplier.LeadTimeDays)
+		expectedAt = &at
+	}
+
+	order.SupplierID = supplier.ID
+	order.ExpectedAt = expectedAt
+	order.Notes = strings.TrimSpace(input.Notes)
+	order.Lines = lines
+	return nil
+}
+
+// Send marks a draft as sent to the supplier.
+func (s *ProcurementService) Send(id string) (models.PurchaseOrder, error) {
+	order, err := s.Get(id)
+	if err != nil {
+		return models.PurchaseOrder{}, err
+	}
+	if order.Status != models.PurchaseOrderDraft {
+		return models.PurchaseOrder{}, errors.New("only draft purchase orders can be sent")
+	}
+	now := time.Now().UTC()
+	if err := s.repo.UpdateStatus(order.ID, map[string]any{"status": models.PurchaseOrderSent, "sent_at": now, "updated_at": now}); err != nil {
+		return models.PurchaseOrder{}, err
+	}
+	return s.repo.GetPurchaseOrder(order.ID)
+}
+
+// Cancel cancels a purchase order nothing has been received for yet.
+func (s *ProcurementService) Cancel(id string) (models.PurchaseOrder, error) {
+	order, err := s.Get(id)
+	if err != nil {
+		return models.PurchaseOrder{}, err
+	}
+	if order.Status != models.PurchaseOrderDraft && order.Status != models.PurchaseOrderSent {
+		return models.PurchaseOrder{}, fmt.Errorf("%s purchase orders cannot be cancelled", order.Status)
+	}
+	now := time.Now().UTC()
+	if err := s.repo.UpdateStatus(order.ID, map[string]any{"status": models.PurchaseOrderCancelled, "updated_at": now}); err != nil {
+		return models.PurchaseOrder{}, err
+	}
+	return s.repo.GetPurchaseOrder(order.ID)
+}
+
+// Receive books a goods receipt against a sent purchase order. Received
+// units go into stock, and the order becomes received once every line is
+// complete.
+func (s *ProcurementService) Receive(id string, lines []ReceiptLineInput, actor string) (models.PurchaseOrder, error) {
+	order, err := s.Get(id)
+	if err != nil {
+		return models.PurchaseOrder{}, err
+	}
+	if order.Status != models.PurchaseOrderSent && order.Status != models.PurchaseOrderPartiallyReceived {
+		return models.PurchaseOrder{}, errors.New("only sent purchase orders can be received")
+	}
+	if len(lines) == 0 {
+		return models.PurchaseOrder{}, errors.New("lines are required")
+	}
+
+	byProduct := map[string]models.PurchaseOrderLine{}
+	for _, line := range order.Lines {
+		byProduct[line.ProductID] = line
+	}
+	received := map[uint]int{}
+	for _, input := range lines {
+		line, ok := byProduct[strings.TrimSpace(input.ProductID)]
+		if !ok {
+			return models.PurchaseOrder{}, fmt.Errorf("product %s is not on this purchase order", input.ProductID)
+		}
+		if input.Qty <= 0 {
+			return models.PurchaseOrder{}, errors.New("qty must be greater than 0")
+		}
+		received[line.ID] += input.Qty
+		if received[line.ID] > line.Remaining() {
+			return models.PurchaseOrder{}, fmt.Errorf("only %d of %s remain to be received", line.Remaining(), line.Name)
+		}
+	}
+
+	booked, err := s.repo.Receive(order.ID, order.Lines, received, strings.TrimSpace(actor), time.Now().UTC())
+	if err != nil {
+		return models.PurchaseOrder{}, err
+	}
+	if !booked {
+		return models.PurchaseOrder{}, errors.New("purchase order was received in the meantime, reload it and try again")
+	}
+	return s.repo.GetPurchaseOrder(order.ID)
+}
+
+// CreateFromForecast drafts a purchase order from the demand forecast. Each
+// category's recommended buy, less the stock already on hand and the units
+// still to come on open purchase orders, is split between its active
+// products by their recent sales.
+func (s *ProcurementService) CreateFromForecast(input ForecastPurchaseInput, actor string) (models.PurchaseOrder, error) {
+	onOrder, err := s.repo.OnOrder()
+	if err != nil {
+		return models.PurchaseOrder{}, err
+	}
+	forecast, shares, err := s.splitForecast(input.Months, input.CategoryIDs, func(row ForecastRow, items []models.Product) int {
+		need := row.RecommendedBuy
+		for _, p := range items {
+			need -= p.Stock + onOrder[p.ID]
+		}
+		return need
+	})
+	if err != nil {
+		return models.PurchaseOrder{}, err
+	}
+
+	lines := []PurchaseOrderLineInput{}
+	for _, share := range shares {
+		lines = append(lines, PurchaseOrderLineInput{ProductID: share.Product.ID, Qty: share.Qty})
+	}
+	if len(lines) == 0 {
+		return models.PurchaseOrder{}, errors.New("forecast recommends no purchases")
+	}
+
+	return s.Create(PurchaseOrderInput{
+		SupplierID: input.SupplierID,
+		Notes:      fmt.Sprintf("Drafted from the %d-month forecast", forecast.Period),
+		Lines:      lines,
+	}, actor)
+}
+
+// ReorderPointsFromForecast sets reorder points and safety stock from the
+// forecast demand of each product: safety stock covers SafetyDays of demand
+// and the reorder point adds the demand expected over LeadTimeDays.
+func (s *ProcurementService) ReorderPointsFromForecast(input ReorderForecastInput) ([]ReorderLevels, error) {
+	if input.LeadTimeDays <= 0 {
+		input.LeadTimeDays = defaultReorderLeadTimeDays
+	}
+	if input.SafetyDays < 0 {
+		return nil, errors.New("safety_days must be >= 0")
+	}
+	if input.SafetyDays == 0 {
+		input.SafetyDays = defaultSafetyDays
+	}
+
+	forecast, shares, err := s.splitForecast(input.Months, input.CategoryIDs, func(row ForecastRow, _ []models.Product) int {
+		return row.ForecastQty
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	days := float64(forecast.Period * 30)
+	result := make([]ReorderLevels, 0, len(shares))
+	for _, share := range shares {
+		daily := float64(share.Qty) / days
+		safety := int(math.Ceil(daily * float64(input.SafetyDays)))
+		reorder := int(math.Ceil(daily*float64(input.LeadTimeDays))) + safety
+		if err := s.products.SetReorderLevels(share.Product.ID, reorder, safety); err != nil {
+			return nil, err
+		}
+		result = append(result, ReorderLevels{ProductID: share.Product.ID, SKU: share.Product.SKU, ReorderPoint: reorder, SafetyStock: safety})
+	}
+	return result, nil
+}
+
+type productShare struct {
+	Product models.Product
+	Qty     int
+}
+
+// splitForecast runs the forecast and splits the quantity that total picks
+// for each category between the category's active single products by units
+// sold in the last forecastSalesWindowDays.
+func (s *ProcurementService) splitForecast(months int, categoryIDs []uint, total func(ForecastRow, []models.Product) int) (ForecastResponse, []productShare, error) {
+	forecast, err := s.forecast.Forecast(months)
+	if err != nil {
+		return ForecastResponse{}, nil, err
+	}
+	products, err := s.products.ListActive()
+	if err != nil {
+		return ForecastResponse{}, nil, err
+	}
+	sold, err := s.repo.UnitsSold(time.Now().UTC().AddDate(0, 0, -forecastSalesWindowDays))
+	if err != nil {
+		return ForecastResponse{}, nil, err
+	}
+
+	wanted := map[uint]bool{}
+	for _, id := range categoryIDs {
+		wanted[id] = true
+	}
+	byCategory := map[uint][]models.Product{}
+	for _, p := range products {
+		if !p.IsBundle() {
+			byCategory[p.CategoryID] = append(byCategory[p.CategoryID], p)
+		}
+	}
+
+	shares := []productShare{}
+	for _, row := range forecast.Rows {
+		if len(wanted) > 0 && !wanted[row.CategoryID] {
+			continue
+		}
+		items := byCategory[row.CategoryID]
+		if len(items) == 0 {
+			continue
+		}
+		qty := total(row, items)
+		if qty <= 0 {
+			continue
+		}
+		sort.Slice(items, func(i, j int) bool { return items[i].SKU < items[j].SKU })
+		weights := make([]int, len(items))
+		for i, p := range items {
+			// Every product gets some share so new ones are not left out.
+			weights[i] = sold[p.ID] + 1
+		}
+		for i, part := range splitQuantity(qty, weights) {
+			if part > 0 {
+				shares = append(shares, productShare{Product: items[i], Qty: part})
+			}
+		}
+	}
+	return forecast, shares, nil
+}
+
+// splitQuantity divides total in proportion to weights, handing leftover
+// units to the largest remainders so that the parts add up to total.
+func splitQuantity(total int, weights []int) []int {
+	parts := make([]int, len(weights))
+	sum := 0
+	for _, w := range weights {
+		sum += w
+	}
+	if total <= 0 || sum <= 0 {
+		return parts
+	}
+
+	remainders := make([]int, len(weights))
+	order := make([]int, len(weights))
+	left := total
+	for i, w := range weights {
+		parts[i] = total * w / sum
+		remainders[i] = total * w % sum
+		order[i] = i
+		left -= parts[i]
+	}
+	sort.SliceStable(order, func(a, b int) bool { return remainders[order[a]] > remainders[order[b]] })
+	for i := 0; i < left; i++ {
+		parts[order[i]]++
+	}
+	return parts
+}
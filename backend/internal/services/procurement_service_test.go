@@ -0,0 +1,19 @@
+package services
+
+import "testing"
+
+func TestSplitQuantityKeepsTotalAndProportions(t *testing.T) {
+	parts := splitQuantity(10, []int{1, 1, 1})
+	if parts[0]+parts[1]+parts[2] != 10 || parts[0] != 4 || parts[1] != 3 || parts[2] != 3 {
+		t.Fatalf("expected 4/3/3, got %v", parts)
+	}
+
+	parts = splitQuantity(7, []int{5, 1, 0})
+	if parts[0] != 6 || parts[1] != 1 || parts[2] != 0 {
+		t.Fatalf("expected split by weight 6/1/0, got %v", parts)
+	}
+
+	if parts := splitQuantity(5, []int{0, 0}); parts[0] != 0 || parts[1] != 0 {
+		t.Fatalf("expected nothing to split without weights, got %v", parts)
+	}
+}
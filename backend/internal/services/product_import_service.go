@@ -0,0 +1,546 @@
+package services
+
+import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"log"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"backend/internal/models"
+	"backend/internal/repositories"
+
+	"github.com/xuri/excelize/v2"
+)
+
+const (
+	ExportFormatCSV  = "csv"
+	ExportFormatXLSX = "xlsx"
+)
+
+var productFileColumns = []string{"sku", "name", "category", "price", "original_price", "stock", "image", "description", "dimensions", "material", "location", "barcode", "barcode_type", "reorder_point", "safety_stock", "is_active", "featured", "preorder", "preorder_available_at"}
+
+type productImportRow struct {
+	Line    int
+	Product models.Product
+	// Columns are the columns of the file: updating an existing product
+	// changes only those fields.
+	Columns map[string]int
+	// Stock is set when the row has a stock value; without one the stock of
+	// an existing product is left alone.
+	Stock *int
+	Err   error
+}
+
+// productImportFields copies the field of each optional column; sku, name,
+// category, price and image are required and always applied.
+var productImportFields = map[string]func(dst *models.Product, src models.Product){
+	"original_price": func(dst *models.Product, src models.Product) { dst.OriginalPrice = src.OriginalPrice },
+	"description":    func(dst *models.Product, src models.Product) { dst.Description = src.Description },
+	"dimensions":     func(dst *models.Product, src models.Product) { dst.Dimensions = src.Dimensions },
+	"material":       func(dst *models.Product, src models.Product) { dst.Material = src.Material },
+	"location":       func(dst *models.Product, src models.Product) { dst.Location = src.Location },
+	"barcode":        func(dst *models.Product, src models.Product) { dst.Barcode = src.Barcode },
+	"barcode_type":   func(dst *models.Product, src models.Product) { dst.BarcodeType = src.BarcodeType },
+	"reorder_point":  func(dst *models.Product, src models.Product) { dst.ReorderPoint = src.ReorderPoint },
+	"safety_stock":   func(dst *models.Product, src models.Product) { dst.SafetyStock = src.SafetyStock },
+	"is_active":      func(dst *models.Product, src models.Product) { dst.IsActive = src.IsActive },
+	"featured":       func(dst *models.Product, src models.Product) { dst.Featured = src.Featured },
+	"preorder":       func(dst *models.Product, src models.Product) { dst.PreorderEnabled = src.PreorderEnabled },
+	"preorder_available_at": func(dst *models.Product, src models.Product) {
+		dst.PreorderAvailableAt = src.PreorderAvailableAt
+	},
+}
+
+// onto applies the row to the stored product. Stock is kept, since the
+// import books a stock change as a movement of its own, and so are the
+// bundle components.
+func (row productImportRow) onto(current models.Product) models.Product {
+	product := current
+	product.SKU = row.Product.SKU
+	product.Name = row.Product.Name
+	product.Category = row.Product.Category
+	product.Price = row.Product.Price
+	product.Image = row.Product.Image
+	for column, apply := range productImportFields {
+		if _, ok := row.Columns[column]; ok {
+			apply(&product, row.Product)
+		}
+	}
+	product.Components = nil
+	return product
+}
+
+type ProductImportService struct {
+	products *ProductService
+	jobs     *repositories.ProductImportRepository
+}
+
+func NewProductImportService(products *ProductService, jobs *repositories.ProductImportRepository) *ProductImportService {
+	return &ProductImportService{products: products, jobs: jobs}
+}
+
+func (s *ProductImportService) DryRun(fileName string, data []byte) (models.ProductImportReport, error) {
+	rows, err := parseProductFile(fileName, data)
+	if err != nil {
+		return models.ProductImportReport{}, err
+	}
+
+	report := models.ProductImportReport{DryRun: true, Total: len(rows), Errors: []models.ImportRowError{}}
+	seen := skuLines{}
+	for _, row := range rows {
+		sku := strings.TrimSpace(row.Product.SKU)
+		if err := seen.duplicate(row); err != nil {
+			report.Errors = append(report.Errors, models.ImportRowError{Row: row.Line, SKU: sku, Message: err.Error()})
+			continue
+		}
+
+		_, exists, err := s.validateRow(row)
+		if err != nil {
+			report.Errors = append(report.Errors, models.ImportRowError{Row: row.Line, SKU: sku, Message: err.Error()})
+			continue
+		}
+		report.Valid++
+		if exists {
+			report.Update++
+		} else {
+			report.Create++
+		}
+	}
+	return report, nil
+}
+
+func (s *ProductImportService) Start(fileName, user string, data []byte) (models.ProductImportJob, error) {
+	rows, err := parseProductFile(fileName, data)
+	if err != nil {
+		return models.ProductImportJob{}, err
+	}
+
+	job := models.ProductImportJob{
+		ID:        repositories.GenerateID("imp"),
+		FileName:  filepath.Base(fileName),
+		Status:    models.ImportJobQueued,
+		Total:     len(rows),
+		Errors:    []models.ImportRowError{},
+		CreatedBy: strings.TrimSpace(user),
+	}
+	if err := s.jobs.Create(&job); err != nil {
+		return models.ProductImportJob{}, err
+	}
+
+	go s.run(job, rows)
+	return job, nil
+}
+
+func (s *ProductImportService) Get(id string) (models.ProductImportJob, error) {
+	id = strings.TrimSpace(id)
+	if id == "" {
+		return models.ProductImportJob{}, errors.New("invalid import job id")
+	}
+	return s.jobs.GetByID(id)
+}
+
+// FailInterruptedImports marks jobs left queued or running by a previous
+// process as failed. Jobs run inside the process that accepted them, so at
+// startup no unfinished job can still make progress.
+func FailInterruptedImports(jobs *repositories.ProductImportRepository) {
+	count, err := jobs.FailUnfinished("import was interrupted by a restart", time.Now().UTC())
+	if err != nil {
+		log.Printf("import jobs: fail interrupted: %v", err)
+		return
+	}
+	if count > 0 {
+		log.Printf("import jobs: marked %d interrupted jobs as failed", count)
+	}
+}
+
+// skuLines remembers the row each SKU of a file was first seen on.
+type skuLines map[string]int
+
+// duplicate reports a row whose SKU an earlier row of the same file already
+// used, so a later row cannot silently overwrite an earlier one.
+func (seen skuLines) duplicate(row productImportRow) error {
+	sku := strings.ToLower(strings.TrimSpace(row.Product.SKU))
+	if sku == "" {
+		return nil
+	}
+	if line, ok := seen[sku]; ok {
+		return fmt.Errorf("duplicate sku, already used on row %d", line)
+	}
+	seen[sku] = row.Line
+	return nil
+}
+
+func (s *ProductImportService) run(job models.ProductImportJob, rows []productImportRow) {
+	// A panic would otherwise leave the job running forever.
+	defer func() {
+		if r := recover(); r != nil {
+			log.Printf("import job %s: panic: %v", job.ID, r)
+			finished := time.Now().UTC()
+			job.Status = models.ImportJobFailed
+			job.FinishedAt = &finished
+			job.Errors = append(job.Errors, models.ImportRowError{Message: fmt.Sprintf("import stopped: %v", r)})
+			if err := s.jobs.Save(&job); err != nil {
+				log.Printf("import job %s: %v", job.ID, err)
+			}
+		}
+	}()
+
+	job.Status = models.ImportJobRunning
+	if err := s.jobs.Save(&job); err != nil {
+		log.Printf("import job %s: %v", job.ID, err)
+		return
+	}
+
+	seen := skuLines{}
+	for _, row := range rows {
+		created, err := false, seen.duplicate(row)
+		if err == nil {
+			created, err = s.upsertRow(row, job.ID, job.CreatedBy)
+		}
+		switch {
+		case err != nil:
+			job.Failed++
+			job.Errors = append(job.Errors, models.ImportRowError{Row: row.Line, SKU: row.Product.SKU, Message: err.Error()})
+		case created:
+			job.Created++
+		default:
+			job.Updated++
+		}
+		job.Processed++
+		if err := s.jobs.Save(&job); err != nil {
+			log.Printf("import job %s: %v", job.ID, err)
+		}
+	}
+
+	finished := time.Now().UTC()
+	job.FinishedAt = &finished
+	job.Status = models.ImportJobCompleted
+	if job.Total > 0 && job.Failed == job.Total {
+		job.Status = models.ImportJobFailed
+	}
+	if err := s.jobs.Save(&job); err != nil {
+		log.Printf("import job %s: %v", job.ID, err)
+	}
+}
+
+// validateRowBarcode checks the barcode of a row, allowing it to stay on the
+// product with the row's SKU.
+func (s *ProductImportService) validateRowBarcode(product models.Product) error {
+	value, _, err := NormalizeBarcode(product.Barcode, product.BarcodeType)
+	if err != nil || value == "" {
+		return err
+	}
+	other, err := s.products.repo.FindByBarcode(value)
+	if err != nil {
+		if IsNotFound(err) {
+			return nil
+		}
+		return err
+	}
+	if !strings.EqualFold(other.SKU, product.SKU) {
+		return fmt.Errorf("barcode is already used by %s", other.SKU)
+	}
+	return nil
+}
+
+// validateRow returns the product the row writes: the row itself for a new
+// SKU, or the stored product with the row's columns applied. It also
+// reports whether the SKU exists.
+func (s *ProductImportService) validateRow(row productImportRow) (models.Product, bool, error) {
+	if row.Err != nil {
+		return models.Product{}, false, row.Err
+	}
+	product, exists := row.Product, false
+	current, err := s.products.repo.FindBySKU(row.Product.SKU)
+	switch {
+	case err == nil:
+		product, exists = row.onto(current), true
+	case !IsNotFound(err):
+		return models.Product{}, false, err
+	default:
+		archived, err := s.products.repo.IsArchivedSKU(row.Product.SKU)
+		if err != nil {
+			return models.Product{}, false, err
+		}
+		if archived {
+			return models.Product{}, false, errors.New("sku belongs to an archived product, restore it first")
+		}
+	}
+
+	checked := product
+	if err := validateProductPayload(&checked); err != nil {
+		return models.Product{}, false, err
+	}
+	if _, err := s.products.repo.FindCategoryIDByName(checked.Category); err != nil {
+		if IsNotFound(err) {
+			return models.Product{}, false, fmt.Errorf("category %q not found", checked.Category)
+		}
+		return models.Product{}, false, err
+	}
+	if err := s.validateRowBarcode(checked); err != nil {
+		return models.Product{}, false, err
+	}
+	return product, exists, nil
+}
+
+// upsertRow creates or updates the row's product. A changed stock of an
+// existing product is booked as an import movement referencing the job.
+func (s *ProductImportService) upsertRow(row productImportRow, jobID, actor string) (bool, error) {
+	product, exists, err := s.validateRow(row)
+	if err != nil {
+		return false, err
+	}
+	if !exists {
+		_, err := s.products.Create(product, actor)
+		return err == nil, err
+	}
+
+	_, updated, err := s.products.Update(product.ID, product, actor)
+	if err != nil {
+		return false, err
+	}
+	if row.Stock == nil || updated.IsBundle() {
+		return false, nil
+	}
+	_, err = s.products.repo.SetStock(updated.ID, *row.Stock, models.StockMovementImport, jobID, actor, time.Now().UTC())
+	return false, err
+}
+
+func (s *ProductImportService) Export(format string) ([]byte, error) {
+	products, err := s.products.List()
+	if err != nil {
+		return nil, err
+	}
+
+	records := make([][]string, 0, len(products)+1)
+	records = append(records, productFileColumns)
+	for _, p := range products {
+		originalPrice := ""
+		if p.OriginalPrice != nil {
+			originalPrice = strconv.FormatInt(*p.OriginalPrice, 10)
+		}
+		preorderAvailableAt := ""
+		if p.PreorderAvailableAt != nil {
+			preorderAvailableAt = p.PreorderAvailableAt.UTC().Format(time.DateOnly)
+		}
+		records = append(records, []string{
+			p.SKU, p.Name, p.Category,
+			strconv.FormatInt(p.Price, 10), originalPrice, strconv.Itoa(p.Stock),
+			p.Image, p.Description, p.Dimensions, p.Material, p.Location,
+			p.Barcode, string(p.BarcodeType),
+			strconv.Itoa(p.ReorderPoint), strconv.Itoa(p.SafetyStock),
+			strconv.FormatBool(p.IsActive), strconv.FormatBool(p.Featured),
+			strconv.FormatBool(p.PreorderEnabled), preorderAvailableAt,
+		})
+	}
+
+	switch strings.ToLower(strings.TrimSpace(format)) {
+	case "", ExportFormatCSV:
+		var buf bytes.Buffer
+		w := csv.NewWriter(&buf)
+		if err := w.WriteAll(records); err != nil {
+			return nil, err
+		}
+		return buf.Bytes(), nil
+	case ExportFormatXLSX:
+		f := excelize.NewFile()
+		defer f.Close()
+		sheet := f.GetSheetName(0)
+		for i, record := range records {
+			values := make([]any, len(record))
+			for j, v := range record {
+				values[j] = v
+			}
+			cell, _ := excelize.CoordinatesToCellName(1, i+1)
+			if err := f.SetSheetRow(sheet, cell, &values); err != nil {
+				return nil, err
+			}
+		}
+		buf, err := f.WriteToBuffer()
+		if err != nil {
+			return nil, err
+		}
+		return buf.Bytes(), nil
+	default:
+		return nil, errors.New("format must be csv or xlsx")
+	}
+}
+
+func parseProductFile(fileName string, data []byte) ([]productImportRow, error) {
+	if len(data) == 0 {
+		return nil, errors.New("file is empty")
+	}
+
+	var records [][]string
+	switch strings.ToLower(filepath.Ext(fileName)) {
+	case ".csv":
+		r := csv.NewReader(bytes.NewReader(bytes.TrimPrefix(data, []byte("\xef\xbb\xbf"))))
+		r.FieldsPerRecord = -1
+		r.TrimLeadingSpace = true
+		parsed, err := r.ReadAll()
+		if err != nil {
+			return nil, fmt.Errorf("invalid csv: %w", err)
+		}
+		records = parsed
+	case ".xlsx":
+		f, err := excelize.OpenReader(bytes.NewReader(data))
+		if err != nil {
+			return nil, fmt.Errorf("invalid xlsx: %w", err)
+		}
+		defer f.Close()
+		parsed, err := f.GetRows(f.GetSheetName(0))
+		if err != nil {
+			return nil, fmt.Errorf("invalid xlsx: %w", err)
+		}
+		records = parsed
+	default:
+		return nil, errors.New("file must be .csv or .xlsx")
+	}
+
+	if len(records) < 2 {
+		return nil, errors.New("file has no data rows")
+	}
+
+	columns := map[string]int{}
+	for i, name := range records[0] {
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	for _, required := range []string{"sku", "name", "category", "price", "image"} {
+		if _, ok := columns[required]; !ok {
+			return nil, fmt.Errorf("missing required column %q", required)
+		}
+	}
+
+	rows := make([]productImportRow, 0, len(records)-1)
+	for i, record := range records[1:] {
+		if isBlankRecord(record) {
+			continue
+		}
+		product, stock, err := productFromRecord(record, columns)
+		rows = append(rows, productImportRow{Line: i + 2, Product: product, Columns: columns, Stock: stock, Err: err})
+	}
+	return rows, nil
+}
+
+// productFromRecord parses a data row. The stock is returned as well when
+// the row has one, so a blank cell does not zero the stock.
+func productFromRecord(record []string, columns map[string]int) (models.Product, *int, error) {
+	value := func(name string) string {
+		idx, ok := columns[name]
+		if !ok || idx >= len(record) {
+			return ""
+		}
+		return strings.TrimSpace(record[idx])
+	}
+
+	product := models.Product{
+		SKU:         value("sku"),
+		Name:        value("name"),
+		Category:    value("category"),
+		Image:       value("image"),
+		Description: value("description"),
+		Dimensions:  value("dimensions"),
+		Material:    value("material"),
+		Location:    value("location"),
+		Barcode:     value("barcode"),
+		BarcodeType: models.BarcodeType(value("barcode_type")),
+		IsActive:    true,
+	}
+
+	price, err := strconv.ParseInt(value("price"), 10, 64)
+	if err != nil {
+		return product, nil, errors.New("price must be an integer")
+	}
+	product.Price = price
+
+	if raw := value("original_price"); raw != "" {
+		originalPrice, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil {
+			return product, nil, errors.New("original_price must be an integer")
+		}
+		product.OriginalPrice = &originalPrice
+	}
+	var stock *int
+	if raw := value("stock"); raw != "" {
+		qty, err := strconv.Atoi(raw)
+		if err != nil {
+			return product, nil, errors.New("stock must be an integer")
+		}
+		product.Stock, stock = qty, &qty
+	}
+	if raw := value("reorder_point"); raw != "" {
+		reorderPoint, err := strconv.Atoi(raw)
+		if err != nil {
+			return product, nil, errors.New("reorder_point must be an integer")
+		}
+		product.ReorderPoint = reorderPoint
+	}
+	if raw := value("safety_stock"); raw != "" {
+		safetyStock, err := strconv.Atoi(raw)
+		if err != nil {
+			return product, nil, errors.New("safety_stock must be an integer")
+		}
+		product.SafetyStock = safetyStock
+	}
+	if raw := value("is_active"); raw != "" {
+		active, err := parseBool(raw)
+		if err != nil {
+			return product, nil, errors.New("is_active must be a boolean")
+		}
+		product.IsActive = active
+	}
+	if raw := value("featured"); raw != "" {
+		featured, err := parseBool(raw)
+		if err != nil {
+			return product, nil, errors.New("featured must be a boolean")
+		}
+		product.Featured = featured
+	}
+	if raw := value("preorder"); raw != "" {
+		preorder, err := parseBool(raw)
+		if err != nil {
+			return product, nil, errors.New("preorder must be a boolean")
+		}
+		product.PreorderEnabled = preorder
+	}
+	if raw := value("preorder_available_at"); raw != "" {
+		availableAt, err := parseDate(raw)
+		if err != nil {
+			return product, nil, errors.New("preorder_available_at must be a date (YYYY-MM-DD)")
+		}
+		product.PreorderAvailableAt = &availableAt
+	}
+	return product, stock, nil
+}
+
+func parseDate(raw string) (time.Time, error) {
+	if t, err := time.Parse(time.DateOnly, raw); err == nil {
+		return t, nil
+	}
+	return time.Parse(time.RFC3339, raw)
+}
+
+func parseBool(raw string) (bool, error) {
+	switch strings.ToLower(strings.TrimSpace(raw)) {
+	case "1", "true", "yes", "y", "да":
+		return true, nil
+	case "0", "false", "no", "n", "нет":
+		return false, nil
+	default:
+		return false, fmt.Errorf("invalid boolean %q", raw)
+	}
+}
+
+func isBlankRecord(record []string) bool {
+	for _, v := range record {
+		if strings.TrimSpace(v) != "" {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,29 @@
+package services
+
+import "testing"
+
+func TestParseProductFileCSV(t *testing.T) {
+	data := []byte("sku,name,category,price,image,stock,is_active\nSKU-1,Desk,Office,100,img,2,yes\nSKU-2,Chair,Office,abc,img,1,no\n")
+	rows, err := parseProductFile("products.csv", data)
+	if err != nil {
+		t.Fatalf("parse failed: %v", err)
+	}
+	if len(rows) != 2 {
+		t.Fatalf("expected 2 rows, got %d", len(rows))
+	}
+	if rows[0].Err != nil || rows[0].Product.Price != 100 || rows[0].Product.Stock != 2 || !rows[0].Product.IsActive {
+		t.Fatalf("unexpected first row: %+v", rows[0])
+	}
+	if rows[1].Err == nil || rows[1].Line != 3 {
+		t.Fatalf("expected price error on line 3, got %+v", rows[1])
+	}
+}
+
+func TestParseProductFileRequiresColumns(t *testing.T) {
+	if _, err := parseProductFile("products.csv", []byte("sku,name\nA,B\n")); err == nil {
+		t.Fatalf("expected missing column error")
+	}
+	if _, err := parseProductFile("products.txt", []byte("sku")); err == nil {
+		t.Fatalf("expected unsupported format error")
+	}
+}
@@ -2,7 +2,9 @@ package services
 
 import (
 	"errors"
+	"fmt"
 	"strings"
+	"time"
 
 	"backend/internal/models"
 	"backend/internal/repositories"
@@ -22,6 +24,14 @@ func (s *ProductService) List() ([]models.Product, error) {
 	return s.repo.List()
 }
 
+func (s *ProductService) ListPublic() ([]models.Product, error) {
+	return s.repo.ListActive()
+}
+
+func (s *ProductService) ListArchived() ([]models.Product, error) {
+	return s.repo.ListArchived()
+}
+
 func (s *ProductService) Get(id string) (models.Product, error) {
 	id = strings.TrimSpace(id)
 	if id == "" {
@@ -30,25 +40,56 @@ func (s *ProductService) Get(id string) (models.Product, error) {
 	return s.repo.GetByID(id)
 }
 
-func (s *ProductService) Create(product models.Product) (models.Product, error) {
+// GetPublic returns a product only if it is visible in the storefront.
+func (s *ProductService) GetPublic(id string) (models.Product, error) {
+	product, err := s.Get(id)
+	if err != nil {
+		return models.Product{}, err
+	}
+	if !product.IsActive {
+		return models.Product{}, gorm.ErrRecordNotFound
+	}
+	return product, nil
+}
+
+func (s *ProductService) Create(product models.Product, actor string) (models.Product, error) {
 	if product.ID == "" {
 		product.ID = repositories.GenerateID("p")
 	}
+	// Rating and review counters are derived from approved reviews only.
+	product.Rating = 0
+	product.Reviews = 0
+	if product.Type == "" {
+		product.Type = models.ProductTypeSingle
+	}
 	if err := validateProductPayload(&product); err != nil {
 		return models.Product{}, err
 	}
+	if err := s.validateComponents(&product); err != nil {
+		return models.Product{}, err
+	}
+	if err := s.validateBarcode(&product); err != nil {
+		return models.Product{}, err
+	}
 	catID, err := s.repo.FindCategoryIDByName(product.Category)
 	if err != nil {
 		return models.Product{}, err
 	}
 	product.CategoryID = catID
-	if err := s.repo.Create(&product); err != nil {
+	entry := models.PriceHistory{
+		Price:         product.Price,
+		OriginalPrice: product.OriginalPrice,
+		Source:        models.PriceSourceCreate,
+		ChangedBy:     strings.TrimSpace(actor),
+		ChangedAt:     time.Now().UTC(),
+	}
+	if err := s.repo.CreateWithPriceHistory(&product, entry); err != nil {
 		return models.Product{}, err
 	}
 	return s.repo.GetByID(product.ID)
 }
 
-func (s *ProductService) Update(id string, payload models.Product) (models.Product, models.Product, error) {
+func (s *ProductService) Update(id string, payload models.Product, actor string) (models.Product, models.Product, error) {
 	current, err := s.Get(id)
 	if err != nil {
 		return models.Product{}, models.Product{}, err
@@ -63,25 +104,62 @@ func (s *ProductService) Update(id string, payload models.Product) (models.Produ
 	current.Description = strings.TrimSpace(payload.Description)
 	current.Dimensions = strings.TrimSpace(payload.Dimensions)
 	current.Material = strings.TrimSpace(payload.Material)
+	current.Location = strings.TrimSpace(payload.Location)
+	current.Barcode = payload.Barcode
+	current.BarcodeType = payload.BarcodeType
+	current.ReorderPoint = payload.ReorderPoint
+	current.SafetyStock = payload.SafetyStock
 	current.Stock = payload.Stock
 	current.SKU = strings.TrimSpace(payload.SKU)
 	current.Featured = payload.Featured
-	current.Rating = payload.Rating
-	current.Reviews = payload.Reviews
 	current.IsActive = payload.IsActive
+	current.PreorderEnabled = payload.PreorderEnabled
+	current.PreorderAvailableAt = payload.PreorderAvailableAt
+	if payload.Type != "" && payload.Type != current.Type {
+		return models.Product{}, models.Product{}, errors.New("product type cannot be changed")
+	}
+	// Omitted components leave the bundle as it is.
+	if payload.Components != nil {
+		current.Components = payload.Components
+	}
 
 	if err := validateProductPayload(&current); err != nil {
 		return models.Product{}, models.Product{}, err
 	}
+	if err := s.validateComponents(&current); err != nil {
+		return models.Product{}, models.Product{}, err
+	}
+	if err := s.validateBarcode(&current); err != nil {
+		return models.Product{}, models.Product{}, err
+	}
 
 	catID, err := s.repo.FindCategoryIDByName(current.Category)
 	if err != nil {
 		return models.Product{}, models.Product{}, err
 	}
 	current.CategoryID = catID
-	if err := s.repo.Update(&current); err != nil {
+	if priceChanged(prev, current) {
+		err = s.repo.UpdateWithPriceHistory(&current, models.PriceHistory{
+			Price:             current.Price,
+			OriginalPrice:     current.OriginalPrice,
+			PrevPrice:         &prev.Price,
+			PrevOriginalPrice: prev.OriginalPrice,
+			Source:            models.PriceSourceManual,
+			ChangedBy:         strings.TrimSpace(actor),
+			ChangedAt:         time.Now().UTC(),
+		})
+	} else {
+		err = s.repo.Update(&current)
+	}
+	if err != nil {
 		return models.Product{}, models.Product{}, err
 	}
+	// Incoming stock goes to open pre-orders before new orders.
+	if current.Stock > prev.Stock {
+		if _, err := s.repo.AllocatePreorders(current.ID); err != nil {
+			return models.Product{}, models.Product{}, err
+		}
+	}
 	updated, err := s.repo.GetByID(current.ID)
 	if err != nil {
 		return models.Product{}, models.Product{}, err
@@ -96,6 +174,106 @@ func (s *ProductService) Delete(id string) error {
 	return s.repo.Delete(id)
 }
 
+func (s *ProductService) Restore(id string) (models.Product, error) {
+	id = strings.TrimSpace(id)
+	if id == "" {
+		return models.Product{}, errors.New("invalid product id")
+	}
+	if err := s.repo.Restore(id); err != nil {
+		return models.Product{}, err
+	}
+	return s.repo.GetByID(id)
+}
+
+// validateComponents checks that a bundle is made of existing single products
+// and that other products carry no components.
+func (s *ProductService) validateComponents(product *models.Product) error {
+	if product.Type != models.ProductTypeSingle && product.Type != models.ProductTypeBundle {
+		return errors.New("type must be single or bundle")
+	}
+	if !product.IsBundle() {
+		if len(product.Components) > 0 {
+			return errors.New("only bundles can have components")
+		}
+		return nil
+	}
+	if len(product.Components) == 0 {
+		return errors.New("bundle must have at least one component")
+	}
+
+	ids := make([]string, 0, len(product.Components))
+	seen := map[string]bool{}
+	for i := range product.Components {
+		c := &product.Components[i]
+		c.ComponentID = strings.TrimSpace(c.ComponentID)
+		if c.ComponentID == "" {
+			return errors.New("component product_id is required")
+		}
+		if c.ComponentID == product.ID {
+			return errors.New("bundle cannot contain itself")
+		}
+		if seen[c.ComponentID] {
+			return fmt.Errorf("component %s is listed more than once", c.ComponentID)
+		}
+		if c.Quantity <= 0 {
+			return errors.New("component quantity must be greater than 0")
+		}
+		seen[c.ComponentID] = true
+		ids = append(ids, c.ComponentID)
+	}
+
+	found, err := s.repo.FindComponents(ids)
+	if err != nil {
+		return err
+	}
+	if len(found) != len(ids) {
+		known := map[string]bool{}
+		for _, p := range found {
+			known[p.ID] = true
+		}
+		for _, id := range ids {
+			if !known[id] {
+				return fmt.Errorf("component %s not found, inactive or is a bundle", id)
+			}
+		}
+	}
+	return nil
+}
+
+func priceChanged(prev, next models.Product) bool {
+	if prev.Price != next.Price {
+		return true
+	}
+	if (prev.OriginalPrice == nil) != (next.OriginalPrice == nil) {
+		return true
+	}
+	return prev.OriginalPrice != nil && *prev.OriginalPrice != *next.OriginalPrice
+}
+
+// validateBarcode normalizes the barcode and makes sure no other product,
+// archived ones included, uses it.
+func (s *ProductService) validateBarcode(product *models.Product) error {
+	value, typ, err := NormalizeBarcode(product.Barcode, product.BarcodeType)
+	if err != nil {
+		return err
+	}
+	product.Barcode, product.BarcodeType = value, typ
+	if value == "" {
+		return nil
+	}
+	other, err := s.repo.FindByBarcode(value)
+	if err != nil {
+		if IsNotFound(err) {
+			return nil
+		}
+		return err
+	}
+	if other.ID != product.ID {
+		return fmt.Errorf("barcode is already used by %s", other.SKU)
+	}
+	return nil
+}
+
 func validateProductPayload(product *models.Product) error {
 	if strings.TrimSpace(product.Name) == "" {
 		return errors.New("name is required")
@@ -115,6 +293,12 @@ func validateProductPayload(product *models.Product) error {
 	if product.Stock < 0 {
 		return errors.New("stock must be >= 0")
 	}
+	if product.ReorderPoint < 0 || product.SafetyStock < 0 {
+		return errors.New("reorder_point and safety_stock must be >= 0")
+	}
+	if product.ReorderPoint > 0 && product.SafetyStock > product.ReorderPoint {
+		return errors.New("safety_stock must not exceed reorder_point")
+	}
 	if product.Reviews < 0 {
 		return errors.New("reviews must be >= 0")
 	}
@@ -124,6 +308,14 @@ func validateProductPayload(product *models.Product) error {
 	if product.OriginalPrice != nil && *product.OriginalPrice < product.Price {
 		return errors.New("originalPrice must be >= price")
 	}
+	if product.PreorderEnabled {
+		if product.IsBundle() {
+			return errors.New("bundles cannot be pre-ordered, enable pre-orders on their components")
+		}
+		if product.PreorderAvailableAt == nil {
+			return errors.New("preorder_available_at is required for pre-orders")
+		}
+	}
 	return nil
 }
 
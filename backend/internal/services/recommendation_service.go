@@ -0,0 +1,208 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"log"
+	"strings"
+	"time"
+
+	"backend/internal/ai"
+	"backend/internal/models"
+	"backend/internal/repositories"
+
+	"gorm.io/gorm"
+)
+
+const (
+	// recommendationMinSupport is how many orders must contain a pair before
+	// it counts as "frequently bought together".
+	recommendationMinSupport = 2
+	// recommendationsPerProduct caps the mined pairs stored per product.
+	recommendationsPerProduct = 20
+
+	DefaultRelatedLimit = 8
+	MaxRelatedLimit     = 50
+
+	// relatedPriceBand is how far, as a share of the product's price, a
+	// similar product's price may be.
+	relatedPriceBand = 0.5
+)
+
+type RecommendationService struct {
+	products *repositories.ProductRepository
+	repo     *repositories.RecommendationRepository
+}
+
+func NewRecommendationService(products *repositories.ProductRepository, repo *repositories.RecommendationRepository) *RecommendationService {
+	return &RecommendationService{products: products, repo: repo}
+}
+
+// Related returns manual links first, then mined "bought together" products
+// and finally same-category products at a similar price, skipping anything
+// that is not visible in the storefront.
+func (s *RecommendationService) Related(productID string, limit int) ([]models.RelatedProduct, error) {
+	productID = strings.TrimSpace(productID)
+	if productID == "" {
+		return nil, errors.New("invalid product id")
+	}
+	if limit <= 0 {
+		limit = DefaultRelatedLimit
+	}
+	if limit > MaxRelatedLimit {
+		limit = MaxRelatedLimit
+	}
+
+	product, err := s.products.GetByID(productID)
+	if err != nil {
+		return nil, err
+	}
+	if !product.IsActive {
+		return nil, gorm.ErrRecordNotFound
+	}
+
+	links, err := s.repo.ListLinks(productID)
+	if err != nil {
+		return nil, err
+	}
+	mined, err := s.repo.ListRecommendations(productID)
+	if err != nil {
+		return nil, err
+	}
+	candidates := make([]string, 0, len(links)+len(mined))
+	for _, link := range links {
+		candidates = append(candidates, link.RelatedID)
+	}
+	for _, item := range mined {
+		candidates = append(candidates, item.RelatedID)
+	}
+	active, err := s.products.ListActiveByIDs(candidates)
+	if err != nil {
+		return nil, err
+	}
+	visible := make(map[string]models.Product, len(active))
+	for _, p := range active {
+		visible[p.ID] = p
+	}
+
+	result := []models.RelatedProduct{}
+	added := map[string]bool{productID: true}
+	add := func(p models.Product, source models.RelatedSource, score float64) {
+		if added[p.ID] || len(result) >= limit {
+			return
+		}
+		added[p.ID] = true
+		result = append(result, models.RelatedProduct{CatalogProduct: models.NewCatalogProduct(p), Source: source, Score: score})
+	}
+
+	for _, link := range links {
+		if p, ok := visible[link.RelatedID]; ok {
+			add(p, models.RelatedSourceManual, 0)
+		}
+	}
+	for _, item := range mined {
+		if p, ok := visible[item.RelatedID]; ok {
+			add(p, models.RelatedSourceBoughtTogether, item.Score)
+		}
+	}
+	if len(result) >= limit {
+		return result, nil
+	}
+
+	exclude := make([]string, 0, len(added))
+	for id := range added {
+		exclude = append(exclude, id)
+	}
+	band := int64(float64(product.Price) * relatedPriceBand)
+	similar, err := s.products.ListSimilar(product.CategoryID, product.Price, product.Price-band, product.Price+band, exclude, limit-len(result))
+	if err != nil {
+		return nil, err
+	}
+	for _, p := range similar {
+		add(p, models.RelatedSourceSimilar, 0)
+	}
+	return result, nil
+}
+
+func (s *RecommendationService) AddLink(productID, relatedID, actor string) (models.ProductLink, error) {
+	productID = strings.TrimSpace(productID)
+	relatedID = strings.TrimSpace(relatedID)
+	if productID == "" {
+		return models.ProductLink{}, errors.New("invalid product id")
+	}
+	if relatedID == "" {
+		return models.ProductLink{}, errors.New("product_id is required")
+	}
+	if productID == relatedID {
+		return models.ProductLink{}, errors.New("product cannot be related to itself")
+	}
+	if _, err := s.products.GetByID(productID); err != nil {
+		return models.ProductLink{}, err
+	}
+	if _, err := s.products.GetByID(relatedID); err != nil {
+		return models.ProductLink{}, err
+	}
+	exists, err := s.repo.LinkExists(productID, relatedID)
+	if err != nil {
+		return models.ProductLink{}, err
+	}
+	if exists {
+		return models.ProductLink{}, errors.New("products are already linked")
+	}
+
+	link := models.ProductLink{ProductID: productID, RelatedID: relatedID, CreatedBy: strings.TrimSpace(actor)}
+	if err := s.repo.CreateLink(&link); err != nil {
+		return models.ProductLink{}, err
+	}
+	return link, nil
+}
+
+func (s *RecommendationService) RemoveLink(productID, relatedID string) error {
+	productID = strings.TrimSpace(productID)
+	relatedID = strings.TrimSpace(relatedID)
+	if productID == "" || relatedID == "" {
+		return errors.New("invalid product id")
+	}
+	return s.repo.DeleteLink(productID, relatedID)
+}
+
+// Recompute rebuilds the "bought together" table from order history and
+// returns the number of pairs stored.
+func (s *RecommendationService) Recompute(now time.Time) (int, error) {
+	baskets, err := s.repo.OrderBaskets()
+	if err != nil {
+		return 0, err
+	}
+	scores := ai.ScoreCooccurrence(baskets, recommendationMinSupport, recommendationsPerProduct)
+
+	items := make([]models.ProductRecommendation, 0, len(scores))
+	for _, score := range scores {
+		items = append(items, models.ProductRecommendation{
+			ProductID:  score.ProductID,
+			RelatedID:  score.RelatedID,
+			Score:      score.Score,
+			Support:    score.Support,
+			ComputedAt: now,
+		})
+	}
+	if err := s.repo.ReplaceRecommendations(items); err != nil {
+		return 0, err
+	}
+	return len(items), nil
+}
+
+// Run recomputes recommendations every interval until ctx is cancelled.
+func (s *RecommendationService) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		if _, err := s.Recompute(time.Now().UTC()); err != nil {
+			log.Printf("recommendations: %v", err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,109 @@
+package services
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"backend/internal/models"
+	"backend/internal/repositories"
+)
+
+var ErrReviewNotEligible = errors.New("reviews can only be left for products from delivered orders")
+
+type ReviewService struct {
+	repo     *repositories.ReviewRepository
+	products *repositories.ProductRepository
+	users    *repositories.UserRepository
+}
+
+func NewReviewService(repo *repositories.ReviewRepository, products *repositories.ProductRepository, users *repositories.UserRepository) *ReviewService {
+	return &ReviewService{repo: repo, products: products, users: users}
+}
+
+func (s *ReviewService) ListApproved(productID string) ([]models.Review, error) {
+	productID = strings.TrimSpace(productID)
+	if productID == "" {
+		return nil, errors.New("invalid product id")
+	}
+	if _, err := s.products.GetByID(productID); err != nil {
+		return nil, err
+	}
+	return s.repo.ListByProduct(productID, models.ReviewStatusApproved)
+}
+
+func (s *ReviewService) ListForModeration(status models.ReviewStatus) ([]models.Review, error) {
+	if status != "" && !models.IsValidReviewStatus(status) {
+		return nil, errors.New("invalid status")
+	}
+	return s.repo.ListByStatus(status)
+}
+
+func (s *ReviewService) Submit(productID, email string, rating int, comment string) (models.Review, error) {
+	productID = strings.TrimSpace(productID)
+	if productID == "" {
+		return models.Review{}, errors.New("invalid product id")
+	}
+	if rating < 1 || rating > 5 {
+		return models.Review{}, errors.New("rating must be between 1 and 5")
+	}
+	if _, err := s.products.GetByID(productID); err != nil {
+		return models.Review{}, err
+	}
+
+	user, err := s.users.FindByEmail(email)
+	if err != nil {
+		return models.Review{}, err
+	}
+	eligible, err := s.repo.HasDeliveredOrder(user.Email, productID)
+	if err != nil {
+		return models.Review{}, err
+	}
+	if !eligible {
+		return models.Review{}, ErrReviewNotEligible
+	}
+	exists, err := s.repo.ExistsForUser(productID, user.ID)
+	if err != nil {
+		return models.Review{}, err
+	}
+	if exists {
+		return models.Review{}, errors.New("product already reviewed")
+	}
+
+	review := models.Review{
+		ID:         repositories.GenerateID("rev"),
+		ProductID:  productID,
+		UserID:     user.ID,
+		AuthorName: user.Name,
+		Rating:     rating,
+		Comment:    strings.TrimSpace(comment),
+		Status:     models.ReviewStatusPending,
+	}
+	if err := s.repo.Create(&review); err != nil {
+		return models.Review{}, err
+	}
+	return review, nil
+}
+
+func (s *ReviewService) Moderate(id string, status models.ReviewStatus, moderator string) (models.Review, error) {
+	id = strings.TrimSpace(id)
+	if id == "" {
+		return models.Review{}, errors.New("invalid review id")
+	}
+	if status != models.ReviewStatusApproved && status != models.ReviewStatusRejected {
+		return models.Review{}, errors.New("status must be approved or rejected")
+	}
+
+	review, err := s.repo.GetByID(id)
+	if err != nil {
+		return models.Review{}, err
+	}
+	now := time.Now().UTC()
+	review.Status = status
+	review.ModeratedBy = strings.TrimSpace(moderator)
+	review.ModeratedAt = &now
+	if err := s.repo.SaveModeration(&review); err != nil {
+		return models.Review{}, err
+	}
+	return review, nil
+}
@@ -0,0 +1,207 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"backend/internal/models"
+	"backend/internal/repositories"
+)
+
+var (
+	ErrLastAccessManager  = errors.New("change would leave no active user able to manage roles and permissions")
+	ErrBuiltinRole        = errors.New("built-in roles cannot be deleted")
+	ErrRoleExists         = errors.New("role already exists")
+	ErrRoleInUse          = errors.New("role is assigned to users")
+	ErrPermissionExists   = errors.New("permission already exists")
+	ErrPermissionEnforced = errors.New("permission is checked by the API and cannot be deleted; revoke it from roles instead")
+)
+
+var permissionNamePattern = regexp.MustCompile(`^[a-z][a-z0-9_]*$`)
+
+type PermissionInput struct {
+	Action      string `json:"action"`
+	Resource    string `json:"resource"`
+	Effect      string `json:"effect"`
+	Description string `json:"description"`
+}
+
+// RoleService lets administrators manage roles and what each may do. Every
+// change drops the shared permission cache so it applies on the next
+// request.
+type RoleService struct {
+	repo        *repositories.PermissionRepository
+	permissions *PermissionService
+}
+
+func NewRoleService(repo *repositories.PermissionRepository, permissions *PermissionService) *RoleService {
+	return &RoleService{repo: repo, permissions: permissions}
+}
+
+func (s *RoleService) List() ([]models.RoleSummary, error) {
+	return s.repo.ListRoles()
+}
+
+func (s *RoleService) Create(name string) (models.Role, error) {
+	roleName := models.RoleName(strings.TrimSpace(name))
+	if roleName == "" || len(roleName) > 64 {
+		return models.Role{}, errors.New("role name must be 1 to 64 characters")
+	}
+	taken, err := s.repo.RoleNameTaken(roleName)
+	if err != nil {
+		return models.Role{}, err
+	}
+	if taken {
+		return models.Role{}, ErrRoleExists
+	}
+	role := models.Role{Name: roleName}
+	if err := s.repo.CreateRole(&role); err != nil {
+		return models.Role{}, err
+	}
+	return role, nil
+}
+
+// Delete removes a custom role nobody is assigned to.
+func (s *RoleService) Delete(id uint) (models.Role, error) {
+	role, err := s.repo.FindRole(id)
+	if err != nil {
+		return models.Role{}, err
+	}
+	if role.Name.IsBuiltin() {
+		return models.Role{}, ErrBuiltinRole
+	}
+	users, err := s.repo.CountRoleUsers(id)
+	if err != nil {
+		return models.Role{}, err
+	}
+	if users > 0 {
+		return models.Role{}, fmt.Errorf("%w: %d", ErrRoleInUse, users)
+	}
+	if err := s.repo.DeleteRole(id, keepAccessManager); err != nil {
+		return models.Role{}, err
+	}
+	s.permissions.Invalidate()
+	return role, nil
+}
+
+func (s *RoleService) Permissions(roleID uint) ([]models.RolePermissionView, error) {
+	if _, err := s.repo.FindRole(roleID); err != nil {
+		return nil, err
+	}
+	return s.repo.RolePermissions(roleID)
+}
+
+// Grant gives a role a permission. Granting a deny can lock administrators
+// out, so it is checked like a revoke.
+func (s *RoleService) Grant(roleID, permissionID uint) (models.Role, models.Permission, error) {
+	role, permission, err := s.rolePermission(roleID, permissionID)
+	if err != nil {
+		return models.Role{}, models.Permission{}, err
+	}
+	if err := s.repo.Grant(roleID, permissionID, keepAccessManager); err != nil {
+		return models.Role{}, models.Permission{}, err
+	}
+	s.permissions.Invalidate()
+	return role, permission, nil
+}
+
+func (s *RoleService) Revoke(roleID, permissionID uint) (models.Role, models.Permission, error) {
+	role, permission, err := s.rolePermission(roleID, permissionID)
+	if err != nil {
+		return models.Role{}, models.Permission{}, err
+	}
+	if err := s.repo.Revoke(roleID, permissionID, keepAccessManager); err != nil {
+		return models.Role{}, models.Permission{}, err
+	}
+	s.permissions.Invalidate()
+	return role, permission, nil
+}
+
+func (s *RoleService) ListPermissions() ([]models.PermissionView, error) {
+	permissions, err := s.repo.List()
+	if err != nil {
+		return nil, err
+	}
+	views := make([]models.PermissionView, 0, len(permissions))
+	for _, permission := range permissions {
+		views = append(views, models.PermissionView{Permission: permission, Enforced: s.enforced(permission)})
+	}
+	return views, nil
+}
+
+func (s *RoleService) CreatePermission(input PermissionInput) (models.Permission, error) {
+	permission := models.Permission{
+		Action:      strings.ToLower(strings.TrimSpace(input.Action)),
+		Resource:    strings.ToLower(strings.TrimSpace(input.Resource)),
+		Effect:      strings.ToLower(strings.TrimSpace(input.Effect)),
+		Description: strings.TrimSpace(input.Description),
+	}
+	if permission.Effect == "" {
+		permission.Effect = models.PermissionAllow
+	}
+	if !permissionNamePattern.MatchString(permission.Action) || !permissionNamePattern.MatchString(permission.Resource) {
+		return models.Permission{}, errors.New("action and resource must be lowercase letters, digits and underscores")
+	}
+	if permission.Effect != models.PermissionAllow && permission.Effect != models.PermissionDeny {
+		return models.Permission{}, errors.New("effect must be allow or deny")
+	}
+	exists, err := s.repo.PermissionExists(permission.Action, permission.Resource, permission.Effect)
+	if err != nil {
+		return models.Permission{}, err
+	}
+	if exists {
+		return models.Permission{}, ErrPermissionExists
+	}
+	if err := s.repo.CreatePermission(&permission); err != nil {
+		return models.Permission{}, err
+	}
+	return permission, nil
+}
+
+// DeletePermission removes a permission and its grants. Allow permissions
+// the API checks stay, since seeding would only recreate them.
+func (s *RoleService) DeletePermission(id uint) (models.Permission, error) {
+	permission, err := s.repo.FindPermission(id)
+	if err != nil {
+		return models.Permission{}, err
+	}
+	if s.enforced(permission) {
+		return models.Permission{}, ErrPermissionEnforced
+	}
+	if err := s.repo.DeletePermission(id, keepAccessManager); err != nil {
+		return models.Permission{}, err
+	}
+	s.permissions.Invalidate()
+	return permission, nil
+}
+
+func (s *RoleService) enforced(permission models.Permission) bool {
+	return permission.Effect == models.PermissionAllow && s.permissions.IsEnforced(permission.Action, permission.Resource)
+}
+
+func (s *RoleService) rolePermission(roleID, permissionID uint) (models.Role, models.Permission, error) {
+	role, err := s.repo.FindRole(roleID)
+	if err != nil {
+		return models.Role{}, models.Permission{}, err
+	}
+	permission, err := s.repo.FindPermission(permissionID)
+	if err != nil {
+		return models.Role{}, models.Permission{}, err
+	}
+	return role, permission, nil
+}
+
+// keepAccessManager refuses a change after which no active user could
+// manage roles and permissions any more.
+func keepAccessManager(repo *repositories.PermissionRepository) error {
+	holders, err := repo.CountHolders(models.ManageAccessAction, models.ManageAccessResource)
+	if err != nil {
+		return err
+	}
+	if holders == 0 {
+		return ErrLastAccessManager
+	}
+	return nil
+}
@@ -0,0 +1,269 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"backend/internal/models"
+	"backend/internal/repositories"
+)
+
+type StockCountService struct {
+	repo       *repositories.StockCountRepository
+	products   *repositories.ProductRepository
+	categories *repositories.CategoryRepository
+}
+
+func NewStockCountService(repo *repositories.StockCountRepository, products *repositories.ProductRepository, categories *repositories.CategoryRepository) *StockCountService {
+	return &StockCountService{repo: repo, products: products, categories: categories}
+}
+
+type StartStockCountInput struct {
+	CategoryID *uint  `json:"category_id"`
+	Location   string `json:"location"`
+	Notes      string `json:"notes"`
+}
+
+// CountedQtyInput is one scanned or entered quantity. The product is found by
+// product_id or, for scanners, by sku.
+type CountedQtyInput struct {
+	ProductID  string `json:"product_id"`
+	SKU        string `json:"sku"`
+	CountedQty int    `json:"counted_qty"`
+}
+
+func (s *StockCountService) List(status models.StockCountStatus) ([]models.StockCount, error) {
+	switch status {
+	case "", models.StockCountOpen, models.StockCountPosted, models.StockCountCancelled:
+	default:
+		return nil, errors.New("invalid status")
+	}
+	return s.repo.List(status)
+}
+
+func (s *StockCountService) Get(id string) (models.StockCount, error) {
+	return s.repo.Get(strings.TrimSpace(id))
+}
+
+// Start opens a count of the active products in a category, a location or
+// both, recording their current stock as the expected quantity.
+func (s *StockCountService) Start(input StartStockCountInput, actor string) (models.StockCount, error) {
+	input.Location = strings.TrimSpace(input.Location)
+	if input.CategoryID == nil && input.Location == "" {
+		return models.StockCount{}, errors.New("category_id or location is required")
+	}
+	if input.CategoryID != nil {
+		if _, err := s.categories.GetByID(*input.CategoryID); err != nil {
+			if IsNotFound(err) {
+				return models.StockCount{}, errors.New("category not found")
+			}
+			return models.StockCount{}, err
+		}
+	}
+
+	products, err := s.products.ListActive()
+	if err != nil {
+		return models.StockCount{}, err
+	}
+	lines := []models.StockCountLine{}
+	for _, p := range products {
+		if p.IsBundle() {
+			continue
+		}
+		if input.CategoryID != nil && p.CategoryID != *input.CategoryID {
+			continue
+		}
+		if input.Location != "" && !strings.EqualFold(p.Location, input.Location) {
+			continue
+		}
+		lines = append(lines, models.StockCountLine{ProductID: p.ID, ExpectedQty: p.StockQty})
+	}
+	if len(lines) == 0 {
+		return models.StockCount{}, errors.New("no products to count")
+	}
+
+	now := time.Now().UTC()
+	count := models.StockCount{
+		ID:         repositories.GenerateID("CNT"),
+		CategoryID: input.CategoryID,
+		Location:   input.Location,
+		Status:     models.StockCountOpen,
+		Notes:      strings.TrimSpace(input.Notes),
+		Lines:      lines,
+		CreatedBy:  strings.TrimSpace(actor),
+		CreatedAt:  now,
+		UpdatedAt:  now,
+	}
+	if err := s.repo.Create(&count); err != nil {
+		return models.StockCount{}, err
+	}
+	return s.repo.Get(count.ID)
+}
+
+// Record stores counted quantities, replacing earlier counts of the same
+// product. A product found that is not on the count is added with its current
+// stock as the expected quantity.
+func (s *StockCountService) Record(id string, inputs []CountedQtyInput) (models.StockCount, error) {
+	count, err := s.openCount(id)
+	if err != nil {
+		return models.StockCount{}, err
+	}
+	if len(inputs) == 0 {
+		return models.StockCount{}, errors.New("lines are required")
+	}
+
+	byProduct := map[string]models.StockCountLine{}
+	bySKU := map[string]string{}
+	for _, line := range count.Lines {
+		byProduct[line.ProductID] = line
+		bySKU[strings.ToLower(line.SKU)] = line.ProductID
+	}
+
+	changed := map[string]models.StockCountLine{}
+	order := []string{}
+	for _, input := range inputs {
+		if input.CountedQty < 0 {
+			return models.StockCount{}, errors.New("counted_qty must be >= 0")
+		}
+		productID := strings.TrimSpace(input.ProductID)
+		sku := strings.TrimSpace(input.SKU)
+		if productID == "" && sku != "" {
+			productID = bySKU[strings.ToLower(sku)]
+		}
+
+		line, ok := byProduct[productID]
+		if !ok {
+			product, err := s.findCountable(productID, sku)
+			if err != nil {
+				return models.StockCount{}, err
+			}
+			line, ok = byProduct[product.ID]
+			if !ok {
+				line = models.StockCountLine{ProductID: product.ID, ExpectedQty: product.StockQty}
+			}
+		}
+		qty := input.CountedQty
+		line.CountedQty = &qty
+		byProduct[line.ProductID] = line
+		if _, seen := changed[line.ProductID]; !seen {
+			order = append(order, line.ProductID)
+		}
+		changed[line.ProductID] = line
+	}
+
+	lines := make([]models.StockCountLine, 0, len(order))
+	for _, productID := range order {
+		lines = append(lines, changed[productID])
+	}
+	if err := s.repo.SaveLines(count.ID, lines, time.Now().UTC()); err != nil {
+		return models.StockCount{}, err
+	}
+	return s.repo.Get(count.ID)
+}
+
+func (s *StockCountService) findCountable(productID, sku string) (models.Product, error) {
+	var product models.Product
+	var err error
+	switch {
+	case productID != "":
+		product, err = s.products.GetByID(productID)
+	case sku != "":
+		product, err = s.products.FindBySKU(sku)
+	default:
+		return models.Product{}, errors.New("product_id or sku is required for each line")
+	}
+	if err != nil {
+		if IsNotFound(err) {
+			return models.Product{}, fmt.Errorf("product %s not found", firstNonEmpty(productID, sku))
+		}
+		return models.Product{}, err
+	}
+	if product.IsBundle() {
+		return models.Product{}, fmt.Errorf("bundle %s holds no stock of its own, count its components", product.Name)
+	}
+	return product, nil
+}
+
+// Post books the variances of all counted lines as stock adjustments.
+// Uncounted lines are left alone.
+func (s *StockCountService) Post(id, actor string) (models.StockCount, error) {
+	count, err := s.openCount(id)
+	if err != nil {
+		return models.StockCount{}, err
+	}
+	actor = strings.TrimSpace(actor)
+
+	now := time.Now().UTC()
+	audits := []models.AuditLog{}
+	counted, adjusted := 0, 0
+	for _, line := range count.Lines {
+		if line.CountedQty == nil {
+			continue
+		}
+		counted++
+		if *line.Variance == 0 {
+			continue
+		}
+		adjusted++
+		audits = append(audits, models.AuditLog{
+			ID:        fmt.Sprintf("%s-%d", repositories.GenerateID("log"), len(audits)),
+			Action:    "Stock Adjusted",
+			Category:  models.AuditCategoryProduct,
+			User:      actor,
+			Entity:    "product",
+			EntityID:  line.ProductID,
+			Details:   fmt.Sprintf("Stock count %s: %s counted %d, expected %d (%+d)", count.ID, line.SKU, *line.CountedQty, line.ExpectedQty, *line.Variance),
+			Timestamp: now,
+			Severity:  models.AuditSeverityWarning,
+			Result:    "ok",
+		})
+	}
+	if counted == 0 {
+		return models.StockCount{}, errors.New("nothing has been counted yet")
+	}
+	audits = append(audits, models.AuditLog{
+		ID:        fmt.Sprintf("%s-%d", repositories.GenerateID("log"), len(audits)),
+		Action:    "Stock Count Posted",
+		Category:  models.AuditCategoryProduct,
+		User:      actor,
+		Entity:    "stock_count",
+		EntityID:  count.ID,
+		Details:   fmt.Sprintf("Stock count %s posted: %d of %d products counted, %d adjusted", count.ID, counted, len(count.Lines), adjusted),
+		Timestamp: now,
+		Severity:  models.AuditSeverityInfo,
+		Result:    "ok",
+	})
+
+	posted, err := s.repo.Post(count, audits, actor, now)
+	if err != nil {
+		return models.StockCount{}, err
+	}
+	if !posted {
+		return models.StockCount{}, errors.New("stock count was posted or cancelled in the meantime")
+	}
+	return s.repo.Get(count.ID)
+}
+
+func (s *StockCountService) Cancel(id string) (models.StockCount, error) {
+	count, err := s.openCount(id)
+	if err != nil {
+		return models.StockCount{}, err
+	}
+	if err := s.repo.UpdateStatus(count.ID, map[string]any{"status": models.StockCountCancelled, "updated_at": time.Now().UTC()}); err != nil {
+		return models.StockCount{}, err
+	}
+	return s.repo.Get(count.ID)
+}
+
+func (s *StockCountService) openCount(id string) (models.StockCount, error) {
+	count, err := s.Get(id)
+	if err != nil {
+		return models.StockCount{}, err
+	}
+	if count.Status != models.StockCountOpen {
+		return models.StockCount{}, fmt.Errorf("stock count is %s", count.Status)
+	}
+	return count, nil
+}
@@ -0,0 +1,96 @@
+package services
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"backend/internal/config"
+	"backend/internal/database"
+	"backend/internal/models"
+	"backend/internal/repositories"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func newStockCountService(t *testing.T) (*StockCountService, *gorm.DB) {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open(fmt.Sprintf("file:%s?mode=memory&cache=shared", t.Name())), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("open sqlite: %v", err)
+	}
+	if err := database.ConnectSeedOnlyForTests(db, config.Config{}); err != nil {
+		t.Fatalf("seed database: %v", err)
+	}
+	service := NewStockCountService(repositories.NewStockCountRepository(db), repositories.NewProductRepository(db), repositories.NewCategoryRepository(db))
+	return service, db
+}
+
+func TestStockCountStartRecordPost(t *testing.T) {
+	service, db := newStockCountService(t)
+	stockOf := func(sku string) int {
+		t.Helper()
+		var product models.Product
+		if err := db.First(&product, "sku = ?", sku).Error; err != nil {
+			t.Fatalf("fetch product: %v", err)
+		}
+		return product.StockQty
+	}
+
+	if _, err := service.Start(StartStockCountInput{}, "warehouse@maison.co"); err == nil {
+		t.Fatalf("expected a count without category or location to be rejected")
+	}
+	count, err := service.Start(StartStockCountInput{Location: "c-01"}, "warehouse@maison.co")
+	if err != nil {
+		t.Fatalf("start: %v", err)
+	}
+	if len(count.Lines) != 1 || count.Lines[0].SKU != "LMP-SOLB-BRS" || count.Lines[0].ExpectedQty != 42 {
+		t.Fatalf("expected the lamp at C-01 with 42 expected, got %+v", count.Lines)
+	}
+	if _, err := service.Post(count.ID, "warehouse@maison.co"); err == nil {
+		t.Fatalf("expected posting an uncounted count to be rejected")
+	}
+
+	if _, err := service.Record(count.ID, []CountedQtyInput{{SKU: "lmp-solb-brs", CountedQty: 40}}); err != nil {
+		t.Fatalf("record: %v", err)
+	}
+	count, err = service.Record(count.ID, []CountedQtyInput{{SKU: "LMP-SOLB-BRS", CountedQty: 39}, {SKU: "RUG-MRKW-CRM", CountedQty: 18}})
+	if err != nil {
+		t.Fatalf("record: %v", err)
+	}
+	if len(count.Lines) != 2 || *count.Lines[0].CountedQty != 39 || *count.Lines[0].Variance != -3 || *count.Lines[1].Variance != 0 {
+		t.Fatalf("expected the recount to replace the lamp and the rug to be added, got %+v", count.Lines)
+	}
+	if _, err := service.Record(count.ID, []CountedQtyInput{{SKU: "SET-NONE", CountedQty: 1}}); err == nil {
+		t.Fatalf("expected an unknown sku to be rejected")
+	}
+
+	stale, err := service.Get(count.ID)
+	if err != nil {
+		t.Fatalf("get: %v", err)
+	}
+	posted, err := service.Post(count.ID, "warehouse@maison.co")
+	if err != nil {
+		t.Fatalf("post: %v", err)
+	}
+	if posted.Status != models.StockCountPosted || posted.PostedAt == nil {
+		t.Fatalf("expected a posted count, got %+v", posted)
+	}
+	if stockOf("LMP-SOLB-BRS") != 39 || stockOf("RUG-MRKW-CRM") != 18 {
+		t.Fatalf("expected stock 39/18, got %d/%d", stockOf("LMP-SOLB-BRS"), stockOf("RUG-MRKW-CRM"))
+	}
+
+	if _, err := service.Post(count.ID, "warehouse@maison.co"); err == nil {
+		t.Fatalf("expected a second post to be rejected")
+	}
+	// A post that read the count before it was posted must not book the
+	// variances again.
+	booked, err := service.repo.Post(stale, nil, "warehouse@maison.co", time.Now().UTC())
+	if err != nil || booked {
+		t.Fatalf("expected the stale post to be refused, got booked=%v err=%v", booked, err)
+	}
+	if stockOf("LMP-SOLB-BRS") != 39 {
+		t.Fatalf("expected stock to stay at 39, got %d", stockOf("LMP-SOLB-BRS"))
+	}
+}
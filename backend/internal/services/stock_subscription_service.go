@@ -0,0 +1,129 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/mail"
+	"strings"
+	"time"
+
+	"backend/internal/models"
+	"backend/internal/repositories"
+
+	"gorm.io/gorm"
+)
+
+type StockSubscriptionService struct {
+	products *repositories.ProductRepository
+	repo     *repositories.StockSubscriptionRepository
+	mailer   Mailer
+}
+
+func NewStockSubscriptionService(products *repositories.ProductRepository, repo *repositories.StockSubscriptionRepository, mailer Mailer) *StockSubscriptionService {
+	return &StockSubscriptionService{products: products, repo: repo, mailer: mailer}
+}
+
+func (s *StockSubscriptionService) List(productID string) ([]models.StockSubscription, error) {
+	if _, err := s.products.GetByID(strings.TrimSpace(productID)); err != nil {
+		return nil, err
+	}
+	return s.repo.ListByProduct(strings.TrimSpace(productID))
+}
+
+// Subscribe asks to notify email once the product is back in stock. An
+// existing pending subscription is returned as is; created reports whether a
+// new one was made.
+func (s *StockSubscriptionService) Subscribe(productID, email string) (models.StockSubscription, bool, error) {
+	productID = strings.TrimSpace(productID)
+	email = strings.TrimSpace(strings.ToLower(email))
+	if _, err := mail.ParseAddress(email); err != nil {
+		return models.StockSubscription{}, false, errors.New("valid email is required")
+	}
+
+	product, err := s.products.GetByID(productID)
+	if err != nil {
+		return models.StockSubscription{}, false, err
+	}
+	if !product.IsActive {
+		return models.StockSubscription{}, false, gorm.ErrRecordNotFound
+	}
+	if product.Stock > 0 {
+		return models.StockSubscription{}, false, errors.New("product is in stock")
+	}
+
+	existing, err := s.repo.FindPending(productID, email)
+	if err == nil {
+		return existing, false, nil
+	}
+	if !IsNotFound(err) {
+		return models.StockSubscription{}, false, err
+	}
+
+	item := models.StockSubscription{ProductID: productID, Email: email, CreatedAt: time.Now().UTC()}
+	if err := s.repo.Create(&item); err != nil {
+		return models.StockSubscription{}, false, err
+	}
+	return item, true, nil
+}
+
+func (s *StockSubscriptionService) Unsubscribe(productID, email string) error {
+	return s.repo.DeletePending(strings.TrimSpace(productID), strings.TrimSpace(strings.ToLower(email)))
+}
+
+// NotifyAvailable emails pending subscribers of products that are back in
+// stock and returns the number of notifications sent. Subscriptions to
+// archived or hidden products stay pending.
+func (s *StockSubscriptionService) NotifyAvailable(now time.Time) (int, error) {
+	pending, err := s.repo.ListPending()
+	if err != nil {
+		return 0, err
+	}
+
+	sent := 0
+	products := map[string]*models.Product{}
+	for _, item := range pending {
+		product, ok := products[item.ProductID]
+		if !ok {
+			found, err := s.products.GetByID(item.ProductID)
+			if err != nil && !IsNotFound(err) {
+				return sent, err
+			}
+			if err == nil {
+				product = &found
+			}
+			products[item.ProductID] = product
+		}
+		if product == nil || !product.IsActive || product.Stock <= 0 {
+			continue
+		}
+
+		subject := fmt.Sprintf("%s is back in stock", product.Name)
+		body := fmt.Sprintf("%s (%s) is available to order again.", product.Name, product.SKU)
+		if err := s.mailer.Send(item.Email, subject, body); err != nil {
+			return sent, err
+		}
+		if err := s.repo.MarkNotified(item.ID, now); err != nil {
+			return sent, err
+		}
+		sent++
+	}
+	return sent, nil
+}
+
+// Run sends back-in-stock notifications every interval until ctx is cancelled.
+func (s *StockSubscriptionService) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		if _, err := s.NotifyAvailable(time.Now().UTC()); err != nil {
+			log.Printf("stock notifications: %v", err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
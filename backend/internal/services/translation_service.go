@@ -0,0 +1,344 @@
+package services
+
+import (
+	"errors"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"backend/internal/models"
+	"backend/internal/repositories"
+)
+
+var localePattern = regexp.MustCompile(`^[a-z]{2,3}(-[a-z0-9]{2,8})?$`)
+
+type ProductTranslationInput struct {
+	Name        string
+	Description string
+	Material    string
+}
+
+type TranslationService struct {
+	repo       *repositories.TranslationRepository
+	products   *repositories.ProductRepository
+	categories *repositories.CategoryRepository
+}
+
+func NewTranslationService(repo *repositories.TranslationRepository, products *repositories.ProductRepository, categories *repositories.CategoryRepository) *TranslationService {
+	return &TranslationService{repo: repo, products: products, categories: categories}
+}
+
+// NormalizeLocale lower-cases a language tag such as "en_US" to "en-us" and
+// reports whether it looks like a valid tag.
+func NormalizeLocale(raw string) (string, bool) {
+	locale := strings.ReplaceAll(strings.ToLower(strings.TrimSpace(raw)), "_", "-")
+	return locale, localePattern.MatchString(locale)
+}
+
+// ParseLocales returns the locales to try, most preferred first. An explicit
+// lang wins over Accept-Language; regional tags fall back to their language
+// ("en-gb" then "en"). The list stops at the default locale, since the base
+// content already is in that language.
+func ParseLocales(lang, acceptLanguage string) []string {
+	type weighted struct {
+		locale string
+		q      float64
+	}
+
+	var candidates []weighted
+	if locale, ok := NormalizeLocale(lang); ok {
+		candidates = append(candidates, weighted{locale: locale, q: 2})
+	}
+	for _, part := range strings.Split(acceptLanguage, ",") {
+		fields := strings.Split(part, ";")
+		locale, ok := NormalizeLocale(fields[0])
+		if !ok {
+			continue
+		}
+		q := 1.0
+		for _, param := range fields[1:] {
+			param = strings.TrimSpace(param)
+			if value, found := strings.CutPrefix(param, "q="); found {
+				if parsed, err := strconv.ParseFloat(value, 64); err == nil {
+					q = parsed
+				}
+			}
+		}
+		if q <= 0 {
+			continue
+		}
+		candidates = append(candidates, weighted{locale: locale, q: q})
+	}
+	sort.SliceStable(candidates, func(i, j int) bool { return candidates[i].q > candidates[j].q })
+
+	result := []string{}
+	seen := map[string]bool{}
+	for _, c := range candidates {
+		base, _, regional := strings.Cut(c.locale, "-")
+		if base == models.DefaultLocale {
+			return result
+		}
+		tags := []string{c.locale}
+		if regional {
+			tags = append(tags, base)
+		}
+		for _, tag := range tags {
+			if !seen[tag] {
+				seen[tag] = true
+				result = append(result, tag)
+			}
+		}
+	}
+	return result
+}
+
+// LocalizeCatalog replaces names, descriptions, materials and category names
+// of storefront products with the best available translation. Each field
+// falls back on its own, so a partial translation never blanks out content.
+func (s *TranslationService) LocalizeCatalog(items []*models.CatalogProduct, locales []string) error {
+	if len(items) == 0 || len(locales) == 0 {
+		return nil
+	}
+
+	productIDs := []string{}
+	categoryIDs := []uint{}
+	for _, item := range items {
+		productIDs = append(productIDs, item.ID)
+		categoryIDs = append(categoryIDs, item.CategoryID)
+		for _, c := range item.Components {
+			productIDs = append(productIDs, c.ComponentID)
+		}
+	}
+
+	productRows, err := s.repo.FindProducts(productIDs, locales)
+	if err != nil {
+		return err
+	}
+	categoryRows, err := s.repo.FindCategories(categoryIDs, locales)
+	if err != nil {
+		return err
+	}
+
+	rank := map[string]int{}
+	for i, locale := range locales {
+		rank[locale] = i
+	}
+	pick := func(current *string, currentRank *int, value, locale string) {
+		if strings.TrimSpace(value) == "" || rank[locale] >= *currentRank {
+			return
+		}
+		*current = value
+		*currentRank = rank[locale]
+	}
+
+	type productText struct {
+		name, description, material             string
+		nameRank, descriptionRank, materialRank int
+	}
+	products := map[string]*productText{}
+	for _, row := range productRows {
+		t := products[row.ProductID]
+		if t == nil {
+			t = &productText{nameRank: len(locales), descriptionRank: len(locales), materialRank: len(locales)}
+			products[row.ProductID] = t
+		}
+		pick(&t.name, &t.nameRank, row.Name, row.Locale)
+		pick(&t.description, &t.descriptionRank, row.Description, row.Locale)
+		pick(&t.material, &t.materialRank, row.Material, row.Locale)
+	}
+
+	type categoryText struct {
+		name string
+		rank int
+	}
+	categories := map[uint]*categoryText{}
+	for _, row := range categoryRows {
+		t := categories[row.CategoryID]
+		if t == nil {
+			t = &categoryText{rank: len(locales)}
+			categories[row.CategoryID] = t
+		}
+		pick(&t.name, &t.rank, row.Name, row.Locale)
+	}
+
+	for _, item := range items {
+		if t := products[item.ID]; t != nil {
+			item.Name = firstNonEmpty(t.name, item.Name)
+			item.Description = firstNonEmpty(t.description, item.Description)
+			item.Material = firstNonEmpty(t.material, item.Material)
+		}
+		if t := categories[item.CategoryID]; t != nil {
+			item.Category = firstNonEmpty(t.name, item.Category)
+		}
+		if len(item.Components) > 0 {
+			// Copy so that the product the catalog view was built from is not modified.
+			components := append([]models.BundleComponent(nil), item.Components...)
+			for i := range components {
+				if t := products[components[i].ComponentID]; t != nil {
+					components[i].Name = firstNonEmpty(t.name, components[i].Name)
+				}
+			}
+			item.Components = components
+		}
+	}
+	return nil
+}
+
+// LocalizeCategories replaces category names with the best available translation.
+func (s *TranslationService) LocalizeCategories(items []models.Category, locales []string) error {
+	if len(items) == 0 || len(locales) == 0 {
+		return nil
+	}
+	ids := make([]uint, 0, len(items))
+	for _, item := range items {
+		ids = append(ids, item.ID)
+	}
+	rows, err := s.repo.FindCategories(ids, locales)
+	if err != nil {
+		return err
+	}
+
+	rank := map[string]int{}
+	for i, locale := range locales {
+		rank[locale] = i
+	}
+	best := map[uint]models.CategoryTranslation{}
+	for _, row := range rows {
+		current, ok := best[row.CategoryID]
+		if strings.TrimSpace(row.Name) != "" && (!ok || rank[row.Locale] < rank[current.Locale]) {
+			best[row.CategoryID] = row
+		}
+	}
+	for i := range items {
+		if row, ok := best[items[i].ID]; ok {
+			items[i].Name = row.Name
+		}
+	}
+	return nil
+}
+
+func (s *TranslationService) ProductTranslations(productID string) ([]models.ProductTranslation, error) {
+	productID = strings.TrimSpace(productID)
+	if productID == "" {
+		return nil, errors.New("invalid product id")
+	}
+	if _, err := s.products.GetByID(productID); err != nil {
+		return nil, err
+	}
+	return s.repo.ListProduct(productID)
+}
+
+func (s *TranslationService) SetProductTranslation(productID, locale string, input ProductTranslationInput, actor string) (models.ProductTranslation, error) {
+	productID = strings.TrimSpace(productID)
+	if productID == "" {
+		return models.ProductTranslation{}, errors.New("invalid product id")
+	}
+	locale, err := translationLocale(locale)
+	if err != nil {
+		return models.ProductTranslation{}, err
+	}
+	item := models.ProductTranslation{
+		ProductID:   productID,
+		Locale:      locale,
+		Name:        strings.TrimSpace(input.Name),
+		Description: strings.TrimSpace(input.Description),
+		Material:    strings.TrimSpace(input.Material),
+		UpdatedBy:   strings.TrimSpace(actor),
+	}
+	if item.Name == "" && item.Description == "" && item.Material == "" {
+		return models.ProductTranslation{}, errors.New("at least one of name, description or material is required")
+	}
+	if _, err := s.products.GetByID(productID); err != nil {
+		return models.ProductTranslation{}, err
+	}
+	if err := s.repo.SaveProduct(&item); err != nil {
+		return models.ProductTranslation{}, err
+	}
+
+	stored, err := s.repo.ListProduct(productID)
+	if err != nil {
+		return models.ProductTranslation{}, err
+	}
+	for _, t := range stored {
+		if t.Locale == locale {
+			return t, nil
+		}
+	}
+	return item, nil
+}
+
+func (s *TranslationService) DeleteProductTranslation(productID, locale string) error {
+	productID = strings.TrimSpace(productID)
+	if productID == "" {
+		return errors.New("invalid product id")
+	}
+	locale, err := translationLocale(locale)
+	if err != nil {
+		return err
+	}
+	return s.repo.DeleteProduct(productID, locale)
+}
+
+func (s *TranslationService) CategoryTranslations(categoryID uint) ([]models.CategoryTranslation, error) {
+	if _, err := s.categories.GetByID(categoryID); err != nil {
+		return nil, err
+	}
+	return s.repo.ListCategory(categoryID)
+}
+
+func (s *TranslationService) SetCategoryTranslation(categoryID uint, locale, name, actor string) (models.CategoryTranslation, error) {
+	locale, err := translationLocale(locale)
+	if err != nil {
+		return models.CategoryTranslation{}, err
+	}
+	item := models.CategoryTranslation{CategoryID: categoryID, Locale: locale, Name: strings.TrimSpace(name), UpdatedBy: strings.TrimSpace(actor)}
+	if item.Name == "" {
+		return models.CategoryTranslation{}, errors.New("name is required")
+	}
+	if _, err := s.categories.GetByID(categoryID); err != nil {
+		return models.CategoryTranslation{}, err
+	}
+	if err := s.repo.SaveCategory(&item); err != nil {
+		return models.CategoryTranslation{}, err
+	}
+
+	stored, err := s.repo.ListCategory(categoryID)
+	if err != nil {
+		return models.CategoryTranslation{}, err
+	}
+	for _, t := range stored {
+		if t.Locale == locale {
+			return t, nil
+		}
+	}
+	return item, nil
+}
+
+func (s *TranslationService) DeleteCategoryTranslation(categoryID uint, locale string) error {
+	locale, err := translationLocale(locale)
+	if err != nil {
+		return err
+	}
+	return s.repo.DeleteCategory(categoryID, locale)
+}
+
+func translationLocale(raw string) (string, error) {
+	locale, ok := NormalizeLocale(raw)
+	if !ok {
+		return "", errors.New("invalid locale")
+	}
+	if locale == models.DefaultLocale {
+		return "", errors.New("base content is already in " + models.DefaultLocale + ", edit the product or category instead")
+	}
+	return locale, nil
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if strings.TrimSpace(v) != "" {
+			return v
+		}
+	}
+	return ""
+}
@@ -0,0 +1,25 @@
+package services
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseLocalesOrdersByQualityAndAddsBaseLanguage(t *testing.T) {
+	got := ParseLocales("", "de;q=0.5, en-GB, fr;q=0.8")
+	want := []string{"en-gb", "en", "fr", "de"}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("expected %v, got %v", want, got)
+	}
+}
+
+func TestParseLocalesPrefersLangAndStopsAtDefaultLocale(t *testing.T) {
+	got := ParseLocales("EN_us", "ru, en;q=0.9")
+	want := []string{"en-us", "en"}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("expected %v, got %v", want, got)
+	}
+	if got := ParseLocales("", "ru-RU, en;q=0.5"); len(got) != 0 {
+		t.Fatalf("expected base content for russian speakers, got %v", got)
+	}
+}
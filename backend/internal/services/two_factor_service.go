@@ -0,0 +1,341 @@
+package services
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base32"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"backend/internal/models"
+	"backend/internal/repositories"
+	"backend/internal/security"
+
+	"gorm.io/gorm"
+)
+
+const (
+	// totpIssuer names the account in authenticator apps.
+	totpIssuer           = "Maison & Co."
+	loginChallengeTTL    = 5 * time.Minute
+	maxChallengeAttempts = 5
+	recoveryCodeCount    = 10
+)
+
+var (
+	ErrInvalidChallenge     = errors.New("invalid or expired login challenge")
+	ErrInvalidTwoFactorCode = errors.New("invalid two-factor code")
+)
+
+// TwoFactorService manages TOTP enrollment, recovery codes, the second login
+// step and the roles that must use two-factor authentication.
+type TwoFactorService struct {
+	users *repositories.UserRepository
+	repo  *repositories.TwoFactorRepository
+	auth  *AuthService
+}
+
+func NewTwoFactorService(users *repositories.UserRepository, repo *repositories.TwoFactorRepository, auth *AuthService) *TwoFactorService {
+	return &TwoFactorService{users: users, repo: repo, auth: auth}
+}
+
+func (s *TwoFactorService) Status(userID string) (models.TwoFactorStatus, error) {
+	user, err := s.users.FindByID(userID)
+	if err != nil {
+		return models.TwoFactorStatus{}, err
+	}
+	enabled, err := s.repo.IsEnabled(user.ID)
+	if err != nil {
+		return models.TwoFactorStatus{}, err
+	}
+	left, err := s.repo.CountRecoveryCodes(user.ID)
+	if err != nil {
+		return models.TwoFactorStatus{}, err
+	}
+	return models.TwoFactorStatus{Enabled: enabled, Required: user.Role.RequireTwoFactor, RecoveryCodesLeft: left}, nil
+}
+
+// Setup creates a pending secret. It protects logins only after Enable
+// confirms that the authenticator produces valid codes.
+func (s *TwoFactorService) Setup(userID string) (models.TOTPSetup, error) {
+	user, err := s.users.FindByID(userID)
+	if err != nil {
+		return models.TOTPSetup{}, err
+	}
+	return s.setup(user)
+}
+
+func (s *TwoFactorService) setup(user models.User) (models.TOTPSetup, error) {
+	enabled, err := s.repo.IsEnabled(user.ID)
+	if err != nil {
+		return models.TOTPSetup{}, err
+	}
+	if enabled {
+		return models.TOTPSetup{}, errors.New("two-factor authentication is already enabled")
+	}
+	secret := security.GenerateTOTPSecret()
+	if err := s.repo.SavePending(&models.UserTOTP{UserID: user.ID, Secret: secret}); err != nil {
+		return models.TOTPSetup{}, err
+	}
+	uri := security.TOTPURI(totpIssuer, user.Email, secret)
+	png, err := RenderQRPNG(uri, 256)
+	if err != nil {
+		return models.TOTPSetup{}, err
+	}
+	return models.TOTPSetup{
+		Secret:     secret,
+		OTPAuthURI: uri,
+		QRCode:     "data:image/png;base64," + base64.StdEncoding.EncodeToString(png),
+	}, nil
+}
+
+// Enable confirms the pending secret with a current code and returns the
+// recovery codes, which are shown only this once.
+func (s *TwoFactorService) Enable(userID, code string) ([]string, error) {
+	totp, err := s.repo.GetTOTP(userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("start two-factor setup first")
+		}
+		return nil, err
+	}
+	if totp.ConfirmedAt != nil {
+		return nil, errors.New("two-factor authentication is already enabled")
+	}
+	step, ok := security.VerifyTOTP(totp.Secret, code, time.Now().UTC())
+	if !ok {
+		return nil, ErrInvalidTwoFactorCode
+	}
+	codes, rows := newRecoveryCodes(userID)
+	if err := s.repo.Confirm(userID, step, time.Now().UTC(), rows); err != nil {
+		return nil, err
+	}
+	return codes, nil
+}
+
+// Disable turns two-factor authentication off after checking a current code.
+// Roles that require it cannot opt out.
+func (s *TwoFactorService) Disable(userID, code string) error {
+	user, err := s.users.FindByID(userID)
+	if err != nil {
+		return err
+	}
+	if user.Role.RequireTwoFactor {
+		return fmt.Errorf("two-factor authentication is required for role %s", user.Role.Name)
+	}
+	if err := s.verifyCode(user.ID, code); err != nil {
+		return err
+	}
+	return s.repo.Delete(user.ID)
+}
+
+// Reset removes a user's second factor, e.g. after a lost device, and ends
+// their sessions. The user enrolls again on the next login if required.
+func (s *TwoFactorService) Reset(userID string) error {
+	if _, err := s.users.FindByID(userID); err != nil {
+		return err
+	}
+	if err := s.repo.Delete(userID); err != nil {
+		return err
+	}
+	_, err := s.auth.RevokeUserSessions(userID, "two-factor reset")
+	return err
+}
+
+func (s *TwoFactorService) RegenerateRecoveryCodes(userID, code string) ([]string, error) {
+	if err := s.verifyCode(userID, code); err != nil {
+		return nil, err
+	}
+	codes, rows := newRecoveryCodes(userID)
+	if err := s.repo.ReplaceRecoveryCodes(userID, rows); err != nil {
+		return nil, err
+	}
+	return codes, nil
+}
+
+// CompleteLogin finishes a login challenge with a TOTP or recovery code. On
+// failure the response still names the user for auditing.
+func (s *TwoFactorService) CompleteLogin(challenge, code string) (models.LoginResponse, error) {
+	stored, user, err := s.openChallenge(challenge, models.TwoFactorRequired)
+	if err != nil {
+		return models.LoginResponse{User: adminUser(user)}, err
+	}
+	if err := s.verifyCode(user.ID, code); err != nil {
+		return models.LoginResponse{User: adminUser(user)}, err
+	}
+	if err := s.useChallenge(stored); err != nil {
+		return models.LoginResponse{User: adminUser(user)}, err
+	}
+	return s.auth.startSession(user)
+}
+
+// ChallengeUser returns the user a login challenge was issued to without
+// checking or counting it, so the second step can be throttled per account.
+func (s *TwoFactorService) ChallengeUser(token string) (models.User, error) {
+	stored, err := s.repo.FindChallenge(hashOpaqueToken(strings.TrimSpace(token)))
+	if err != nil {
+		return models.User{}, err
+	}
+	return s.users.FindByID(stored.UserID)
+}
+
+// SetupForChallenge starts enrollment for a user whose role requires
+// two-factor authentication, authenticated by the login challenge. Each
+// setup counts as an attempt on the challenge, like a code.
+func (s *TwoFactorService) SetupForChallenge(challenge string) (models.TOTPSetup, error) {
+	_, user, err := s.openChallenge(challenge, models.TwoFactorEnrollmentRequired)
+	if err != nil {
+		return models.TOTPSetup{}, err
+	}
+	return s.setup(user)
+}
+
+// EnableForChallenge confirms enrollment started with SetupForChallenge and
+// completes the login.
+func (s *TwoFactorService) EnableForChallenge(challenge, code string) (models.TwoFactorEnabled, error) {
+	stored, user, err := s.openChallenge(challenge, models.TwoFactorEnrollmentRequired)
+	if err != nil {
+		return models.TwoFactorEnabled{}, err
+	}
+	codes, err := s.Enable(user.ID, code)
+	if err != nil {
+		return models.TwoFactorEnabled{}, err
+	}
+	if err := s.useChallenge(stored); err != nil {
+		return models.TwoFactorEnabled{}, err
+	}
+	login, err := s.auth.startSession(user)
+	if err != nil {
+		return models.TwoFactorEnabled{}, err
+	}
+	return models.TwoFactorEnabled{RecoveryCodes: codes, Login: &login}, nil
+}
+
+func (s *TwoFactorService) Policy() (models.TwoFactorPolicy, error) {
+	roles, err := s.repo.RequiredRoles()
+	if roles == nil {
+		roles = []models.RoleName{}
+	}
+	return models.TwoFactorPolicy{RequiredRoles: roles}, err
+}
+
+func (s *TwoFactorService) SetPolicy(policy models.TwoFactorPolicy) (models.TwoFactorPolicy, error) {
+	for _, role := range policy.RequiredRoles {
+		if _, err := s.users.FindRoleByName(role); err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return models.TwoFactorPolicy{}, fmt.Errorf("unknown role %s", role)
+			}
+			return models.TwoFactorPolicy{}, err
+		}
+	}
+	if err := s.repo.SetRequiredRoles(policy.RequiredRoles); err != nil {
+		return models.TwoFactorPolicy{}, err
+	}
+	return s.Policy()
+}
+
+// openChallenge loads an unused, unexpired challenge of the given purpose and
+// its user. Every attempt is counted and capped.
+func (s *TwoFactorService) openChallenge(token, purpose string) (models.LoginChallenge, models.User, error) {
+	token = strings.TrimSpace(token)
+	if token == "" {
+		return models.LoginChallenge{}, models.User{}, ErrInvalidChallenge
+	}
+	stored, err := s.repo.FindChallenge(hashOpaqueToken(token))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return models.LoginChallenge{}, models.User{}, ErrInvalidChallenge
+		}
+		return models.LoginChallenge{}, models.User{}, err
+	}
+	user, err := s.users.FindByID(stored.UserID)
+	if err != nil {
+		return models.LoginChallenge{}, models.User{}, err
+	}
+	if stored.Purpose != purpose || stored.UsedAt != nil || !time.Now().UTC().Before(stored.ExpiresAt) {
+		return models.LoginChallenge{}, user, ErrInvalidChallenge
+	}
+	if user.IsBlocked {
+		return models.LoginChallenge{}, user, errors.New("user is blocked")
+	}
+	ok, err := s.repo.CountChallengeAttempt(stored.ID, maxChallengeAttempts)
+	if err != nil {
+		return models.LoginChallenge{}, user, err
+	}
+	if !ok {
+		return models.LoginChallenge{}, user, ErrInvalidChallenge
+	}
+	return stored, user, nil
+}
+
+func (s *TwoFactorService) useChallenge(challenge models.LoginChallenge) error {
+	ok, err := s.repo.UseChallenge(challenge.ID, time.Now().UTC())
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return ErrInvalidChallenge
+	}
+	return nil
+}
+
+// verifyCode accepts a TOTP code not used before or an unused recovery code.
+func (s *TwoFactorService) verifyCode(userID, code string) error {
+	totp, err := s.repo.GetTOTP(userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return errors.New("two-factor authentication is not enabled")
+		}
+		return err
+	}
+	if totp.ConfirmedAt == nil {
+		return errors.New("two-factor authentication is not enabled")
+	}
+	if step, ok := security.VerifyTOTP(totp.Secret, code, time.Now().UTC()); ok {
+		fresh, err := s.repo.UseStep(userID, step)
+		if err != nil {
+			return err
+		}
+		if !fresh {
+			return ErrInvalidTwoFactorCode
+		}
+		return nil
+	}
+	used, err := s.repo.UseRecoveryCode(userID, hashRecoveryCode(code), time.Now().UTC())
+	if err != nil {
+		return err
+	}
+	if !used {
+		return ErrInvalidTwoFactorCode
+	}
+	return nil
+}
+
+var recoveryEncoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// newRecoveryCodes returns codes formatted as xxxxx-xxxxx and their rows.
+func newRecoveryCodes(userID string) ([]string, []models.RecoveryCode) {
+	codes := make([]string, 0, recoveryCodeCount)
+	rows := make([]models.RecoveryCode, 0, recoveryCodeCount)
+	for range recoveryCodeCount {
+		buf := make([]byte, 7)
+		if _, err := rand.Read(buf); err != nil {
+			panic(err)
+		}
+		raw := strings.ToLower(recoveryEncoding.EncodeToString(buf))[:10]
+		code := raw[:5] + "-" + raw[5:]
+		codes = append(codes, code)
+		rows = append(rows, models.RecoveryCode{UserID: userID, CodeHash: hashRecoveryCode(code)})
+	}
+	return codes, rows
+}
+
+func hashRecoveryCode(code string) string {
+	normalized := strings.ToLower(strings.NewReplacer("-", "", " ", "").Replace(strings.TrimSpace(code)))
+	sum := sha256.Sum256([]byte(normalized))
+	return hex.EncodeToString(sum[:])
+}
@@ -29,5 +29,19 @@ func (s *UserService) SetBlocked(id string, blocked bool) error {
 	if strings.TrimSpace(id) == "" {
 		return errors.New("user id is required")
 	}
-	return s.repo.SetBlocked(id, blocked)
+	// Blocking the last user who can manage access would lock everyone out
+	// of roles and permissions.
+	check := keepAccessManager
+	if !blocked {
+		check = nil
+	}
+	if err := s.repo.SetBlocked(id, blocked, check); err != nil {
+		return err
+	}
+	// A blocked user is logged out everywhere rather than when tokens expire.
+	if blocked {
+		_, err := s.auth.RevokeUserSessions(id, "user blocked")
+		return err
+	}
+	return nil
 }
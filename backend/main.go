@@ -1,12 +1,19 @@
 package main
 
 import (
+	"context"
 	"log"
+	"os"
+	"os/signal"
+	"syscall"
 
 	_ "backend/docs"
 	"backend/internal/config"
 	"backend/internal/database"
+	"backend/internal/repositories"
 	"backend/internal/routes"
+	"backend/internal/security"
+	"backend/internal/services"
 
 	"github.com/gofiber/fiber/v2"
 )
@@ -24,15 +31,60 @@ import (
 // @scope.read Grants read access
 // @scope.write Grants write access
 func main() {
-	cfg := config.Load()
+	cfg, err := config.Load()
+	if err != nil {
+		log.Fatalf("config: %v", err)
+	}
 
 	db, err := database.Connect(cfg)
 	if err != nil {
 		log.Fatalf("database connection failed: %v", err)
 	}
 
-	app := fiber.New(fiber.Config{AppName: "furniture-store"})
-	routes.Register(app, db, cfg.AppSecret)
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	priceService := services.NewPriceService(repositories.NewProductRepository(db), repositories.NewPriceRepository(db))
+	go priceService.Run(ctx, cfg.SchedulerInterval)
+
+	recommendationService := services.NewRecommendationService(repositories.NewProductRepository(db), repositories.NewRecommendationRepository(db))
+	go recommendationService.Run(ctx, cfg.RecommendationsInterval)
+
+	stockSubscriptionService := services.NewStockSubscriptionService(repositories.NewProductRepository(db), repositories.NewStockSubscriptionRepository(db), services.NewMailer(cfg.MailDir))
+	go stockSubscriptionService.Run(ctx, cfg.SchedulerInterval)
+
+	inventoryService := services.NewInventoryService(repositories.NewStockAlertRepository(db), repositories.NewProductRepository(db), services.NewAuditService(repositories.NewAuditRepository(db)))
+	go inventoryService.Run(ctx, cfg.SchedulerInterval)
+
+	services.FailInterruptedImports(repositories.NewProductImportRepository(db))
+
+	go services.PurgeExpiredCounters(ctx, repositories.NewRateCounterRepository(db), cfg.SchedulerInterval)
+
+	keys, err := security.NewKeySet(security.KeySetOptions{
+		Issuer:          cfg.JWTIssuer,
+		Audience:        cfg.JWTAudience,
+		Secret:          cfg.AppSecret,
+		PreviousSecrets: cfg.AppPreviousSecrets,
+		KeysDir:         cfg.JWTKeysDir,
+		SigningKeyID:    cfg.JWTSigningKeyID,
+	})
+	if err != nil {
+		log.Fatalf("jwt keys: %v", err)
+	}
+
+	app := fiber.New(fiber.Config{
+		AppName:                 "furniture-store",
+		ProxyHeader:             cfg.ProxyHeader,
+		EnableTrustedProxyCheck: true,
+		TrustedProxies:          cfg.TrustedProxies,
+		EnableIPValidation:      true,
+	})
+	routes.Register(app, db, keys, cfg)
+
+	go func() {
+		<-ctx.Done()
+		_ = app.Shutdown()
+	}()
 
 	log.Printf("listening on %s", cfg.AppAddress())
 	if err := app.Listen(cfg.AppAddress()); err != nil {
@@ -15,6 +15,7 @@ import {
 } from "lucide-react";
 import AdminLayout from "@/components/AdminLayout";
 import { useAuth } from "@/lib/auth";
+import { isPurchasable, isRunningLow } from "@/lib/availability";
 import { formatPrice } from "@/lib/currency";
 import { usePreferences } from "@/lib/preferences";
 import { adminText } from "@/lib/admin-i18n";
@@ -22,22 +23,23 @@ import { translateCategory } from "@/lib/i18n";
 import { useStore, type Product } from "@/lib/store";
 import { cn } from "@/lib/utils";
 
-function StockBadge({ stock, lowLabel, outOfStockLabel, unitsLabel }: { stock: number; lowLabel: string; outOfStockLabel: string; unitsLabel: string }) {
-  if (stock === 0)
+function StockBadge({ product, lowLabel, outOfStockLabel, unitsLabel, inStockLabel }: { product: Product; lowLabel: string; outOfStockLabel: string; unitsLabel: string; inStockLabel: string }) {
+  const stock = product.stock;
+  if (stock === undefined ? !isPurchasable(product) : stock === 0)
     return (
       <span className="text-xs font-medium px-2 py-0.5 rounded-full bg-red-100 text-red-700">
         {outOfStockLabel}
       </span>
     );
-  if (stock <= 10)
+  if (isRunningLow(product))
     return (
       <span className="text-xs font-medium px-2 py-0.5 rounded-full bg-yellow-100 text-yellow-700">
-        {lowLabel}: {stock}
+        {stock === undefined ? lowLabel : `${lowLabel}: ${stock}`}
       </span>
     );
   return (
     <span className="text-xs font-medium px-2 py-0.5 rounded-full bg-green-100 text-green-700">
-      {stock} {unitsLabel}
+      {stock === undefined ? inStockLabel : `${stock} ${unitsLabel}`}
     </span>
   );
 }
@@ -86,8 +88,8 @@ export default function InventoryPage() {
     setEditForm({});
   };
 
-  const totalValue = products.reduce((s, p) => s + p.price * p.stock, 0);
-  const lowStockCount = products.filter((p) => p.stock <= 10).length;
+  const totalValue = products.reduce((s, p) => s + p.price * (p.stock ?? 0), 0);
+  const lowStockCount = products.filter((p) => isRunningLow(p)).length;
   const canCreate = currentUser?.role === "Administrator" || currentUser?.role === "Manager";
   const canDelete = currentUser?.role === "Administrator";
 
@@ -263,14 +265,14 @@ export default function InventoryPage() {
                         {isEditing ? (
                           <input
                             type="number"
-                            value={editForm.stock ?? product.stock}
+                            value={editForm.stock ?? product.stock ?? 0}
                             onChange={(e) =>
                               setEditForm((f) => ({ ...f, stock: Number(e.target.value) }))
                             }
                             className="w-20 border border-input rounded px-2 py-1 text-sm bg-card text-foreground focus:outline-none focus:ring-1 focus:ring-ring"
                           />
                         ) : (
-                          <StockBadge stock={product.stock} lowLabel={t.low} outOfStockLabel={t.outOfStock} unitsLabel={t.units} />
+                          <StockBadge product={product} lowLabel={t.low} outOfStockLabel={t.outOfStock} unitsLabel={t.units} inStockLabel={t.inStock} />
                         )}
                       </td>
 
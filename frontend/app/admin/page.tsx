@@ -12,6 +12,7 @@ import {
   ArrowRight,
 } from "lucide-react";
 import AdminLayout from "@/components/AdminLayout";
+import { isRunningLow } from "@/lib/availability";
 import { formatPrice } from "@/lib/currency";
 import { usePreferences } from "@/lib/preferences";
 import { adminText, translateOrderStatus } from "@/lib/admin-i18n";
@@ -42,7 +43,7 @@ export default function AdminDashboardPage() {
       (o) => o.status === "pending" || o.status === "processing"
     ).length;
 
-    const lowStockProducts = products.filter((p) => p.stock <= 10);
+    const lowStockProducts = products.filter((p) => isRunningLow(p));
     const totalProducts = products.length;
 
     return { totalRevenue, pendingOrders, lowStockProducts, totalProducts };
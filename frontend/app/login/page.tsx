@@ -10,6 +10,8 @@ import {usePreferences} from "@/lib/preferences";
 import {siteText} from "@/lib/i18n";
 import {useStore} from "@/lib/store";
 import {cn} from "@/lib/utils";
+import TwoFactorStep from "@/components/TwoFactorStep";
+import {getSingleSignOnStatus, singleSignOnURL} from "@/services/auth";
 
 export default function LoginPage() {
     const [email, setEmail] = useState("");
@@ -17,8 +19,9 @@ export default function LoginPage() {
     const [showPassword, setShowPassword] = useState(false);
     const [isLoading, setIsLoading] = useState(false);
     const [fieldErrors, setFieldErrors] = useState<{ email?: string; password?: string }>({});
+    const [singleSignOn, setSingleSignOn] = useState(false);
 
-    const {login, loginError, currentUser} = useAuth();
+    const {login, loginError, currentUser, twoFactor, recoveryCodes, dismissRecoveryCodes} = useAuth();
     const bootstrap = useStore((s) => s.bootstrap);
     const locale = usePreferences((s) => s.locale);
     const t = siteText[locale].login;
@@ -33,10 +36,14 @@ export default function LoginPage() {
     const router = useRouter();
 
     useEffect(() => {
-        if (currentUser) {
+        if (currentUser && !recoveryCodes) {
             router.replace(currentUser.role === "Client" ? "/account/orders" : "/admin");
         }
-    }, [currentUser, router]);
+    }, [currentUser, recoveryCodes, router]);
+
+    useEffect(() => {
+        getSingleSignOnStatus().then(setSingleSignOn).catch(() => setSingleSignOn(false));
+    }, []);
 
     function validate() {
         const errors: { email?: string; password?: string } = {};
@@ -140,6 +147,26 @@ export default function LoginPage() {
                         </div>
                     </div>
 
+                    {recoveryCodes ? (
+                        <div className="space-y-4">
+                            <div className="space-y-1.5">
+                                <h2 className="font-serif text-xl font-bold text-foreground">{t.recoveryTitle}</h2>
+                                <p className="text-sm text-muted-foreground">{t.recoveryHint}</p>
+                            </div>
+                            <div className="grid grid-cols-2 gap-2 rounded-lg border border-border bg-card p-4 font-mono text-sm">
+                                {recoveryCodes.map((code) => <span key={code}>{code}</span>)}
+                            </div>
+                            <button
+                                type="button"
+                                onClick={dismissRecoveryCodes}
+                                className="w-full py-2.5 rounded-lg bg-primary text-primary-foreground font-medium text-sm hover:opacity-90"
+                            >
+                                {t.continue}
+                            </button>
+                        </div>
+                    ) : twoFactor ? (
+                        <TwoFactorStep onSuccess={() => bootstrap(true)}/>
+                    ) : (
                     <form onSubmit={handleSubmit} className="space-y-5" noValidate>
                         {loginError && !isLoading && (
                             <div
@@ -224,6 +251,14 @@ export default function LoginPage() {
                                     <AlertCircle className="w-3 h-3"/> {fieldErrors.password}
                                 </p>
                             )}
+                            <div className="flex justify-end">
+                                <Link
+                                    href="/reset-password"
+                                    className="text-xs text-muted-foreground hover:text-foreground transition-colors"
+                                >
+                                    {t.forgot}
+                                </Link>
+                            </div>
                         </div>
 
                         <button
@@ -249,6 +284,23 @@ export default function LoginPage() {
                             )}
                         </button>
                     </form>
+                    )}
+
+                    {singleSignOn && !twoFactor && !recoveryCodes && (
+                        <div className="space-y-5">
+                            <div className="flex items-center gap-3 text-xs text-muted-foreground">
+                                <div className="h-px flex-1 bg-border"/>
+                                {t.or}
+                                <div className="h-px flex-1 bg-border"/>
+                            </div>
+                            <a
+                                href={singleSignOnURL()}
+                                className="flex w-full items-center justify-center py-2.5 rounded-lg border border-input bg-card text-foreground font-medium text-sm hover:bg-secondary transition-colors"
+                            >
+                                {t.singleSignOn}
+                            </a>
+                        </div>
+                    )}
 
                     <div className="pt-2 border-t border-border">
                         <div className="flex flex-wrap items-center justify-between gap-3">
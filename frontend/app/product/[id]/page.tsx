@@ -8,6 +8,7 @@ import {Minus, Plus, ShoppingCart, Package, RotateCcw, Shield} from "lucide-reac
 import Navbar from "@/components/Navbar";
 import Footer from "@/components/Footer";
 import ProductCard from "@/components/ProductCard";
+import {availabilityLabel, isPurchasable, maxOrderQuantity} from "@/lib/availability";
 import {formatPrice} from "@/lib/currency";
 import {useStore} from "@/lib/store";
 import {usePreferences} from "@/lib/preferences";
@@ -159,11 +160,8 @@ export default function ProductPage({params}: Props) {
                                     {
                                         label: t.details,
                                         value:
-                                            product.stock > 5
-                                                ? t.stock.replace("{count}", String(product.stock))
-                                                : product.stock > 0
-                                                    ? siteText[locale].productCard.onlyLeft.replace("{count}", String(product.stock))
-                                                    : siteText[locale].productCard.outOfStock,
+                                            availabilityLabel(product, locale) ??
+                                            (product.stock === undefined ? t.inStock : t.stock.replace("{count}", String(product.stock))),
                                     },
                                 ].map(({label, value}) => (
                                     <div key={label} className="bg-muted rounded-lg px-4 py-3">
@@ -189,7 +187,7 @@ export default function ProductPage({params}: Props) {
                     {qty}
                   </span>
                                     <button
-                                        onClick={() => setQty((q) => Math.min(product.stock, q + 1))}
+                                        onClick={() => setQty((q) => Math.min(maxOrderQuantity(product), q + 1))}
                                         className="p-3 text-muted-foreground hover:text-foreground transition-colors"
                                         aria-label={siteText[locale].cart.increase}
                                     >
@@ -199,7 +197,7 @@ export default function ProductPage({params}: Props) {
 
                                 <button
                                     onClick={handleAddToCart}
-                                    disabled={product.stock === 0}
+                                    disabled={!isPurchasable(product)}
                                     className={cn(
                                         "flex-1 flex items-center justify-center gap-2 py-3.5 rounded font-medium text-sm transition-all",
                                         added
@@ -0,0 +1,100 @@
+"use client";
+
+import {Suspense, useState} from "react";
+import Link from "next/link";
+import {useSearchParams} from "next/navigation";
+import {AlertCircle, ArrowLeft, CheckCircle} from "lucide-react";
+import {usePreferences} from "@/lib/preferences";
+import {siteText} from "@/lib/i18n";
+import {cn} from "@/lib/utils";
+import {requestPasswordReset, resetPassword} from "@/services/auth";
+import {getApiErrorMessage} from "@/services/http";
+
+function ResetPasswordForm() {
+    const token = useSearchParams().get("token") ?? "";
+    const locale = usePreferences((s) => s.locale);
+    const t = siteText[locale].accountAccess;
+    const [value, setValue] = useState("");
+    const [done, setDone] = useState(false);
+    const [error, setError] = useState<string | null>(null);
+    const [isLoading, setIsLoading] = useState(false);
+
+    async function handleSubmit(e: React.FormEvent) {
+        e.preventDefault();
+        if (!value.trim()) return;
+        setIsLoading(true);
+        setError(null);
+        try {
+            if (token) await resetPassword(token, value);
+            else await requestPasswordReset(value.trim());
+            setDone(true);
+        } catch (err) {
+            setError(getApiErrorMessage(err));
+        }
+        setIsLoading(false);
+    }
+
+    return (
+        <div className="w-full max-w-sm mx-auto space-y-8">
+            <div className="space-y-1.5">
+                <h1 className="font-serif text-3xl font-bold text-foreground text-balance">
+                    {token ? t.resetTitle : t.forgotTitle}
+                </h1>
+                {!token && <p className="text-muted-foreground text-sm">{t.forgotSubtitle}</p>}
+            </div>
+
+            {done ? (
+                <div className="flex items-center gap-2.5 rounded-lg bg-secondary border border-border px-4 py-3">
+                    <CheckCircle className="w-4 h-4 text-accent flex-shrink-0"/>
+                    <p className="text-sm text-foreground">{token ? t.passwordSaved : t.linkSent}</p>
+                </div>
+            ) : (
+                <form onSubmit={handleSubmit} className="space-y-5" noValidate>
+                    {error && (
+                        <div
+                            className="flex items-center gap-2.5 rounded-lg bg-destructive/10 border border-destructive/20 px-4 py-3">
+                            <AlertCircle className="w-4 h-4 text-destructive flex-shrink-0"/>
+                            <p className="text-sm text-destructive font-medium">{error}</p>
+                        </div>
+                    )}
+                    <input
+                        type={token ? "password" : "email"}
+                        autoComplete={token ? "new-password" : "email"}
+                        value={value}
+                        onChange={(e) => setValue(e.target.value)}
+                        placeholder={token ? t.newPassword : "client@example.com"}
+                        className="w-full px-4 py-2.5 rounded-lg border border-input bg-card text-foreground placeholder:text-muted-foreground/50 text-sm outline-none transition-all focus:ring-2 focus:ring-ring focus:border-transparent"
+                    />
+                    <button
+                        type="submit"
+                        disabled={isLoading}
+                        className={cn(
+                            "w-full py-2.5 rounded-lg bg-primary text-primary-foreground font-medium text-sm transition-all",
+                            isLoading ? "opacity-70 cursor-not-allowed" : "hover:opacity-90 active:scale-[0.99]"
+                        )}
+                    >
+                        {token ? t.savePassword : t.sendLink}
+                    </button>
+                </form>
+            )}
+
+            <Link
+                href="/login"
+                className="inline-flex items-center gap-1.5 text-sm text-muted-foreground hover:text-foreground transition-colors"
+            >
+                <ArrowLeft className="w-3.5 h-3.5"/>
+                {t.toLogin}
+            </Link>
+        </div>
+    );
+}
+
+export default function ResetPasswordPage() {
+    return (
+        <div className="min-h-screen flex flex-col justify-center px-6 py-12 bg-background">
+            <Suspense>
+                <ResetPasswordForm/>
+            </Suspense>
+        </div>
+    );
+}
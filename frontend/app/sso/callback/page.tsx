@@ -0,0 +1,72 @@
+"use client";
+
+import {Suspense, useEffect, useRef, useState} from "react";
+import Link from "next/link";
+import {useRouter, useSearchParams} from "next/navigation";
+import {AlertCircle, ArrowLeft} from "lucide-react";
+import {useAuth} from "@/lib/auth";
+import {usePreferences} from "@/lib/preferences";
+import {siteText} from "@/lib/i18n";
+import {useStore} from "@/lib/store";
+
+function SingleSignOnCallback() {
+    const params = useSearchParams();
+    const code = params.get("code") ?? "";
+    const state = params.get("state") ?? "";
+    const locale = usePreferences((s) => s.locale);
+    const t = siteText[locale].login;
+    const {loginWithSingleSignOn, loginError} = useAuth();
+    const bootstrap = useStore((s) => s.bootstrap);
+    const router = useRouter();
+    // The identity provider reports a cancelled or refused sign-in in error.
+    const [failed, setFailed] = useState(!code || !state || params.has("error"));
+    const started = useRef(false);
+
+    useEffect(() => {
+        // The state is single-use, so it is submitted once even in strict mode.
+        if (failed || started.current) return;
+        started.current = true;
+        loginWithSingleSignOn(code, state).then(async (success) => {
+            if (success) {
+                await bootstrap(true);
+                router.replace("/admin");
+            } else if (useAuth.getState().twoFactor) {
+                // The login page asks for the second factor.
+                router.replace("/login");
+            } else {
+                setFailed(true);
+            }
+        });
+    }, [code, state, failed, loginWithSingleSignOn, bootstrap, router]);
+
+    return (
+        <div className="w-full max-w-sm mx-auto space-y-8">
+            <h1 className="font-serif text-3xl font-bold text-foreground text-balance">{t.ssoTitle}</h1>
+            {!failed && <p className="text-sm text-muted-foreground">{t.ssoPending}</p>}
+            {failed && (
+                <div
+                    className="flex items-center gap-2.5 rounded-lg bg-destructive/10 border border-destructive/20 px-4 py-3">
+                    <AlertCircle className="w-4 h-4 text-destructive flex-shrink-0"/>
+                    <p className="text-sm text-destructive font-medium">{loginError ?? t.ssoFailed}</p>
+                </div>
+            )}
+            <Link
+                href="/login"
+                className="inline-flex items-center gap-1.5 text-sm text-muted-foreground hover:text-foreground transition-colors"
+            >
+                <ArrowLeft className="w-3.5 h-3.5"/>
+                {siteText[locale].accountAccess.toLogin}
+            </Link>
+        </div>
+    );
+}
+
+export default function SingleSignOnCallbackPage() {
+    return (
+        <div className="min-h-screen flex flex-col justify-center px-6 py-12 bg-background">
+            <Suspense>
+                <SingleSignOnCallback/>
+            </Suspense>
+        </div>
+    );
+}
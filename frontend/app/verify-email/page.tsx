@@ -0,0 +1,68 @@
+"use client";
+
+import {Suspense, useEffect, useRef, useState} from "react";
+import Link from "next/link";
+import {useSearchParams} from "next/navigation";
+import {AlertCircle, ArrowLeft, CheckCircle} from "lucide-react";
+import {usePreferences} from "@/lib/preferences";
+import {siteText} from "@/lib/i18n";
+import {verifyEmail} from "@/services/auth";
+import {getApiErrorMessage} from "@/services/http";
+
+function VerifyEmailStatus() {
+    const token = useSearchParams().get("token") ?? "";
+    const locale = usePreferences((s) => s.locale);
+    const t = siteText[locale].accountAccess;
+    const [state, setState] = useState<"pending" | "verified" | "failed">(token ? "pending" : "failed");
+    const [error, setError] = useState<string | null>(token ? null : t.missingToken);
+    const started = useRef(false);
+
+    useEffect(() => {
+        // The token is single-use, so it is submitted once even in strict mode.
+        if (!token || started.current) return;
+        started.current = true;
+        verifyEmail(token)
+            .then(() => setState("verified"))
+            .catch((err) => {
+                setError(getApiErrorMessage(err));
+                setState("failed");
+            });
+    }, [token]);
+
+    return (
+        <div className="w-full max-w-sm mx-auto space-y-8">
+            <h1 className="font-serif text-3xl font-bold text-foreground text-balance">{t.verifyTitle}</h1>
+            {state === "pending" && <p className="text-sm text-muted-foreground">{t.verifying}</p>}
+            {state === "verified" && (
+                <div className="flex items-center gap-2.5 rounded-lg bg-secondary border border-border px-4 py-3">
+                    <CheckCircle className="w-4 h-4 text-accent flex-shrink-0"/>
+                    <p className="text-sm text-foreground">{t.verified}</p>
+                </div>
+            )}
+            {state === "failed" && (
+                <div
+                    className="flex items-center gap-2.5 rounded-lg bg-destructive/10 border border-destructive/20 px-4 py-3">
+                    <AlertCircle className="w-4 h-4 text-destructive flex-shrink-0"/>
+                    <p className="text-sm text-destructive font-medium">{error}</p>
+                </div>
+            )}
+            <Link
+                href="/login"
+                className="inline-flex items-center gap-1.5 text-sm text-muted-foreground hover:text-foreground transition-colors"
+            >
+                <ArrowLeft className="w-3.5 h-3.5"/>
+                {t.toLogin}
+            </Link>
+        </div>
+    );
+}
+
+export default function VerifyEmailPage() {
+    return (
+        <div className="min-h-screen flex flex-col justify-center px-6 py-12 bg-background">
+            <Suspense>
+                <VerifyEmailStatus/>
+            </Suspense>
+        </div>
+    );
+}
@@ -3,6 +3,7 @@
 import Image from "next/image";
 import Link from "next/link";
 import { Minus, Plus, ShoppingCart } from "lucide-react";
+import { availabilityLabel, availabilityOf, isPurchasable, maxOrderQuantity } from "@/lib/availability";
 import { formatPrice } from "@/lib/currency";
 import { useStore, type Product } from "@/lib/store";
 import { usePreferences } from "@/lib/preferences";
@@ -22,6 +23,8 @@ export default function ProductCard({ product, className }: Props) {
   const t = siteText[locale].productCard;
   const cartItem = cart.find((item) => item.product.id === product.id);
   const quantityInCart = cartItem?.quantity ?? 0;
+  const availability = availabilityOf(product);
+  const stockLabel = availabilityLabel(product, locale);
 
   return (
     <article
@@ -65,14 +68,16 @@ export default function ProductCard({ product, className }: Props) {
             </span>
           )}
         </div>
-        {product.stock <= 5 && product.stock > 0 && (
-          <p className="mt-2 text-xs text-accent font-medium">
-            {t.onlyLeft.replace("{count}", String(product.stock))}
+        {stockLabel && (
+          <p
+            className={cn(
+              "mt-2 text-xs",
+              availability === "out_of_stock" ? "text-muted-foreground" : "text-accent font-medium"
+            )}
+          >
+            {stockLabel}
           </p>
         )}
-        {product.stock === 0 && (
-          <p className="mt-2 text-xs text-muted-foreground">{t.outOfStock}</p>
-        )}
 
         <div className="mt-auto pt-4">
           {quantityInCart > 0 ? (
@@ -93,7 +98,7 @@ export default function ProductCard({ product, className }: Props) {
               <button
                 type="button"
                 onClick={() => updateCartQuantity(product.id, quantityInCart + 1)}
-                disabled={quantityInCart >= product.stock}
+                disabled={quantityInCart >= maxOrderQuantity(product)}
                 className="inline-flex h-8 w-8 items-center justify-center rounded-md text-primary transition-colors hover:bg-primary/10 disabled:cursor-not-allowed disabled:opacity-40"
                 aria-label={siteText[locale].cart.increase}
               >
@@ -104,7 +109,7 @@ export default function ProductCard({ product, className }: Props) {
             <button
               type="button"
               onClick={() => addToCart(product)}
-              disabled={product.stock === 0}
+              disabled={!isPurchasable(product)}
               className="flex w-full items-center justify-center gap-2 rounded bg-primary px-3.5 py-2 text-xs font-medium text-primary-foreground transition-opacity hover:opacity-90 disabled:cursor-not-allowed disabled:opacity-50"
               aria-label={t.addToCartAria.replace("{name}", product.name)}
             >
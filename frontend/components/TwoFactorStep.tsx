@@ -0,0 +1,94 @@
+"use client";
+
+import {useEffect, useState} from "react";
+import {AlertCircle, KeyRound} from "lucide-react";
+import {useAuth} from "@/lib/auth";
+import {usePreferences} from "@/lib/preferences";
+import {siteText} from "@/lib/i18n";
+import {cn} from "@/lib/utils";
+import {setupTwoFactor, type TOTPSetup} from "@/services/auth";
+import {getApiErrorMessage} from "@/services/http";
+
+// TwoFactorStep is the second login step: a code prompt, preceded by a QR
+// code when the user's role requires enrolling first.
+export default function TwoFactorStep({onSuccess}: { onSuccess: () => Promise<void> }) {
+    const {twoFactor, completeTwoFactor, loginError} = useAuth();
+    const locale = usePreferences((s) => s.locale);
+    const t = siteText[locale].login;
+    const [code, setCode] = useState("");
+    const [setup, setSetup] = useState<TOTPSetup | null>(null);
+    const [setupError, setSetupError] = useState<string | null>(null);
+    const [isLoading, setIsLoading] = useState(false);
+    const enrolling = twoFactor?.mode === "enrollment_required";
+
+    useEffect(() => {
+        if (!enrolling || !twoFactor) return;
+        setupTwoFactor(twoFactor.challenge)
+            .then(setSetup)
+            .catch((error) => setSetupError(getApiErrorMessage(error)));
+    }, [enrolling, twoFactor]);
+
+    async function handleSubmit(e: React.FormEvent) {
+        e.preventDefault();
+        if (!code.trim()) return;
+        setIsLoading(true);
+        if (await completeTwoFactor(code.trim())) {
+            await onSuccess();
+        }
+        setIsLoading(false);
+    }
+
+    const error = setupError ?? loginError;
+
+    return (
+        <form onSubmit={handleSubmit} className="space-y-5" noValidate>
+            <div className="space-y-1.5">
+                <h2 className="font-serif text-xl font-bold text-foreground">
+                    {enrolling ? t.enrollTitle : t.twoFactorTitle}
+                </h2>
+                <p className="text-sm text-muted-foreground">{enrolling ? t.enrollHint : t.twoFactorHint}</p>
+            </div>
+
+            {enrolling && setup && (
+                <div className="flex flex-col items-center gap-3 rounded-lg border border-border bg-card p-4">
+                    {/* eslint-disable-next-line @next/next/no-img-element */}
+                    <img src={setup.qr_code} alt="otpauth QR code" width={192} height={192}/>
+                    <code className="text-xs text-muted-foreground break-all">{setup.secret}</code>
+                </div>
+            )}
+
+            {error && !isLoading && (
+                <div
+                    className="flex items-center gap-2.5 rounded-lg bg-destructive/10 border border-destructive/20 px-4 py-3">
+                    <AlertCircle className="w-4 h-4 text-destructive flex-shrink-0"/>
+                    <p className="text-sm text-destructive font-medium">{error}</p>
+                </div>
+            )}
+
+            <div className="relative">
+                <KeyRound
+                    className="absolute left-3.5 top-1/2 -translate-y-1/2 w-4 h-4 text-muted-foreground pointer-events-none"/>
+                <input
+                    autoFocus
+                    autoComplete="one-time-code"
+                    inputMode={enrolling ? "numeric" : "text"}
+                    value={code}
+                    onChange={(e) => setCode(e.target.value)}
+                    placeholder={t.code}
+                    className="w-full pl-10 pr-4 py-2.5 rounded-lg border border-input bg-card text-foreground placeholder:text-muted-foreground/50 text-sm outline-none transition-all focus:ring-2 focus:ring-ring focus:border-transparent tracking-widest"
+                />
+            </div>
+
+            <button
+                type="submit"
+                disabled={isLoading}
+                className={cn(
+                    "w-full py-2.5 rounded-lg bg-primary text-primary-foreground font-medium text-sm transition-all",
+                    isLoading ? "opacity-70 cursor-not-allowed" : "hover:opacity-90 active:scale-[0.99]"
+                )}
+            >
+                {t.verify}
+            </button>
+        </form>
+    );
+}
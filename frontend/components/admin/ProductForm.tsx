@@ -43,7 +43,7 @@ function toDraft(product: Product): ProductDraft {
     description: product.description,
     dimensions: product.dimensions,
     material: product.material,
-    stock: String(product.stock),
+    stock: String(product.stock ?? 0),
     sku: product.sku,
     featured: product.featured,
   };
@@ -106,6 +106,7 @@ export const adminText = {
       outOfStock: "Out of Stock",
       low: "Low",
       units: "units",
+      inStock: "In stock",
     },
     inventoryEdit: {
       newTitle: "New Product",
@@ -340,6 +341,7 @@ export const adminText = {
       outOfStock: "Нет в наличии",
       low: "Мало",
       units: "шт.",
+      inStock: "В наличии",
     },
     inventoryEdit: {
       newTitle: "Новый товар",
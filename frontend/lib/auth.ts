@@ -3,16 +3,31 @@
 import { create } from "zustand";
 import { persist } from "zustand/middleware";
 import type { AdminUser, RoleName } from "./types";
-import { login as loginRequest } from "@/services/auth";
-import { getApiErrorMessage } from "@/services/http";
+import {
+  completeSingleSignOn,
+  completeTwoFactorLogin,
+  enableTwoFactor,
+  login as loginRequest,
+  logout as logoutRequest,
+  type LoginResponse,
+} from "@/services/auth";
+import { getApiErrorMessage, registerSessionHooks } from "@/services/http";
 
 export type { AdminUser };
 
 type AuthState = {
   currentUser: AdminUser | null;
   token: string | null;
+  refreshToken: string | null;
   loginError: string | null;
+  // twoFactor holds the challenge between the password and the code step.
+  twoFactor: { mode: "required" | "enrollment_required"; challenge: string } | null;
+  // recoveryCodes are shown once after enrolling during login.
+  recoveryCodes: string[] | null;
   login: (email: string, password: string) => Promise<boolean>;
+  loginWithSingleSignOn: (code: string, state: string) => Promise<boolean>;
+  completeTwoFactor: (code: string) => Promise<boolean>;
+  dismissRecoveryCodes: () => void;
   logout: () => void;
   hasAnyRole: (roles: RoleName[]) => boolean;
 };
@@ -22,13 +37,14 @@ export const useAuth = create<AuthState>()(
     (set, get) => ({
       currentUser: null,
       token: null,
+      refreshToken: null,
       loginError: null,
+      twoFactor: null,
+      recoveryCodes: null,
 
       login: async (email, password) => {
         try {
-          const result = await loginRequest(email, password);
-          set({ currentUser: result.user, token: result.token, loginError: null });
-          return true;
+          return startSession(await loginRequest(email, password), set);
         } catch (error) {
           set({
             loginError: getApiErrorMessage(error, "Invalid email or password."),
@@ -37,7 +53,40 @@ export const useAuth = create<AuthState>()(
         }
       },
 
-      logout: () => set({ currentUser: null, token: null, loginError: null }),
+      loginWithSingleSignOn: async (code, state) => {
+        try {
+          return startSession(await completeSingleSignOn(code, state), set);
+        } catch (error) {
+          set({ loginError: getApiErrorMessage(error, "Single sign-on failed.") });
+          return false;
+        }
+      },
+
+      completeTwoFactor: async (code) => {
+        const pending = get().twoFactor;
+        if (!pending) return false;
+        try {
+          if (pending.mode === "required") {
+            set(sessionFrom(await completeTwoFactorLogin(pending.challenge, code)));
+            return true;
+          }
+          const result = await enableTwoFactor(code, pending.challenge);
+          if (!result.login) return false;
+          set({ ...sessionFrom(result.login), recoveryCodes: result.recovery_codes });
+          return true;
+        } catch (error) {
+          set({ loginError: getApiErrorMessage(error, "Invalid code.") });
+          return false;
+        }
+      },
+
+      dismissRecoveryCodes: () => set({ recoveryCodes: null }),
+
+      logout: () => {
+        const refreshToken = get().refreshToken;
+        if (refreshToken) void logoutRequest(refreshToken).catch(() => undefined);
+        set({ currentUser: null, token: null, refreshToken: null, loginError: null, twoFactor: null });
+      },
 
       hasAnyRole: (roles) => {
         const role = get().currentUser?.role;
@@ -46,7 +95,38 @@ export const useAuth = create<AuthState>()(
     }),
     {
       name: "maison-auth",
-      partialize: (state) => ({ currentUser: state.currentUser, token: state.token }),
+      partialize: (state) => ({
+        currentUser: state.currentUser,
+        token: state.token,
+        refreshToken: state.refreshToken,
+      }),
     }
   )
 );
+
+// startSession signs in with a login response, or keeps its challenge when
+// a second factor is due and reports false.
+function startSession(result: LoginResponse, set: (state: Partial<AuthState>) => void) {
+  if (result.two_factor && result.challenge) {
+    set({ twoFactor: { mode: result.two_factor, challenge: result.challenge }, loginError: null });
+    return false;
+  }
+  set(sessionFrom(result));
+  return true;
+}
+
+function sessionFrom(result: LoginResponse) {
+  return {
+    currentUser: result.user,
+    token: result.token,
+    refreshToken: result.refresh_token,
+    loginError: null,
+    twoFactor: null,
+  };
+}
+
+registerSessionHooks({
+  getRefreshToken: () => useAuth.getState().refreshToken,
+  onRefreshed: ({ token, refresh_token }) => useAuth.setState({ token, refreshToken: refresh_token }),
+  onExpired: () => useAuth.setState({ currentUser: null, token: null, refreshToken: null }),
+});
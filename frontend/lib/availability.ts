@@ -0,0 +1,62 @@
+import type { Availability, Product } from "./types";
+import type { SiteLocale } from "./preferences";
+import { siteText } from "./i18n";
+
+// Matches models.LowStockThreshold on the backend.
+export const LOW_STOCK_THRESHOLD = 5;
+
+// The storefront catalog sends availability without exact stock; the staff
+// view sends stock. Either one is enough to tell what a shopper can buy.
+export function availabilityOf(product: Product): Availability {
+  if (product.availability) return product.availability;
+  const stock = product.stock ?? 0;
+  if (stock <= 0) return product.preorder_enabled ? "preorder" : "out_of_stock";
+  if (stock <= LOW_STOCK_THRESHOLD) return "low_stock";
+  return "in_stock";
+}
+
+// isRunningLow flags products for restocking in the admin. Roles that only
+// get the catalog view fall back to its availability.
+export function isRunningLow(product: Product, threshold = 10) {
+  if (product.stock !== undefined) return product.stock <= threshold;
+  return availabilityOf(product) !== "in_stock";
+}
+
+export function isPurchasable(product: Product) {
+  return availabilityOf(product) !== "out_of_stock";
+}
+
+// maxOrderQuantity caps cart quantities. Without exact stock a low-stock
+// product is capped at the threshold and the server has the final say.
+export function maxOrderQuantity(product: Product) {
+  switch (availabilityOf(product)) {
+    case "out_of_stock":
+      return 0;
+    case "preorder":
+      return Number.POSITIVE_INFINITY;
+    case "low_stock":
+      return product.stock ?? LOW_STOCK_THRESHOLD;
+    default:
+      return product.stock ?? Number.POSITIVE_INFINITY;
+  }
+}
+
+// availabilityLabel describes stock for shoppers, or null when a product is
+// plainly in stock and the card has nothing to say.
+export function availabilityLabel(product: Product, locale: SiteLocale) {
+  const t = siteText[locale].productCard;
+  switch (availabilityOf(product)) {
+    case "out_of_stock":
+      return t.outOfStock;
+    case "preorder":
+      if (!product.preorder_available_at) return t.preorder;
+      return t.preorderUntil.replace(
+        "{date}",
+        new Date(product.preorder_available_at).toLocaleDateString(locale === "ru" ? "ru-RU" : "en-US")
+      );
+    case "low_stock":
+      return product.stock === undefined ? t.fewLeft : t.onlyLeft.replace("{count}", String(product.stock));
+    default:
+      return null;
+  }
+}
@@ -137,6 +137,36 @@ export const siteText = {
       requiredEmail: "Email is required.",
       invalidEmail: "Enter a valid email.",
       requiredPassword: "Password is required.",
+      forgot: "Forgot password?",
+      twoFactorTitle: "Two-factor authentication",
+      twoFactorHint: "Enter the 6-digit code from your authenticator app or one of your recovery codes.",
+      enrollTitle: "Set up two-factor authentication",
+      enrollHint: "Your role requires two-factor authentication. Scan the QR code with an authenticator app, then enter the code it shows.",
+      code: "Code",
+      verify: "Verify",
+      recoveryTitle: "Save your recovery codes",
+      recoveryHint: "Each code signs you in once if you lose your authenticator. They will not be shown again.",
+      continue: "Continue",
+      or: "or",
+      singleSignOn: "Sign in with company account",
+      ssoTitle: "Company sign-in",
+      ssoPending: "Completing sign-in...",
+      ssoFailed: "Sign-in with your company account did not complete.",
+    },
+    accountAccess: {
+      forgotTitle: "Reset your password",
+      forgotSubtitle: "Enter your email and we will send you a link to choose a new password.",
+      sendLink: "Send reset link",
+      linkSent: "If an account exists for this email, a reset link is on its way.",
+      resetTitle: "Choose a new password",
+      newPassword: "New password",
+      savePassword: "Save password",
+      passwordSaved: "Your password was changed. Sign in with the new password.",
+      verifyTitle: "Email verification",
+      verifying: "Verifying your email...",
+      verified: "Your email is verified. Thank you!",
+      missingToken: "The link is incomplete. Open it from the email again.",
+      toLogin: "Go to sign in",
     },
     settings: {
       back: "Back",
@@ -264,7 +294,10 @@ export const siteText = {
       add: "Add",
       inCart: "In cart",
       onlyLeft: "Only {count} left in stock",
+      fewLeft: "Only a few left in stock",
       outOfStock: "Out of stock",
+      preorder: "Pre-order",
+      preorderUntil: "Pre-order, expected {date}",
       addToCartAria: "Add {name} to cart",
     },
     cart: {
@@ -296,6 +329,7 @@ export const siteText = {
       material: "Material",
       sku: "SKU",
       stock: "In stock: {count}",
+      inStock: "In stock",
       shippingTitle: "Shipping & returns",
       shippingText: "White-glove delivery available. Returns accepted within 30 days for unused items.",
       warrantyTitle: "Warranty",
@@ -568,6 +602,36 @@ export const siteText = {
       requiredEmail: "Email обязателен.",
       invalidEmail: "Введите корректный email.",
       requiredPassword: "Пароль обязателен.",
+      forgot: "Забыли пароль?",
+      twoFactorTitle: "Двухфакторная аутентификация",
+      twoFactorHint: "Введите 6-значный код из приложения-аутентификатора или один из резервных кодов.",
+      enrollTitle: "Настройка двухфакторной аутентификации",
+      enrollHint: "Для вашей роли обязательна двухфакторная аутентификация. Отсканируйте QR-код в приложении-аутентификаторе и введите показанный код.",
+      code: "Код",
+      verify: "Подтвердить",
+      recoveryTitle: "Сохраните резервные коды",
+      recoveryHint: "Каждый код позволяет войти один раз, если аутентификатор утерян. Больше они показаны не будут.",
+      continue: "Продолжить",
+      or: "или",
+      singleSignOn: "Войти через корпоративный аккаунт",
+      ssoTitle: "Корпоративный вход",
+      ssoPending: "Завершаем вход...",
+      ssoFailed: "Не удалось войти через корпоративный аккаунт.",
+    },
+    accountAccess: {
+      forgotTitle: "Восстановление пароля",
+      forgotSubtitle: "Укажите email, и мы отправим ссылку для выбора нового пароля.",
+      sendLink: "Отправить ссылку",
+      linkSent: "Если аккаунт с этим email существует, ссылка уже отправлена.",
+      resetTitle: "Новый пароль",
+      newPassword: "Новый пароль",
+      savePassword: "Сохранить пароль",
+      passwordSaved: "Пароль изменен. Войдите с новым паролем.",
+      verifyTitle: "Подтверждение email",
+      verifying: "Подтверждаем email...",
+      verified: "Email подтвержден. Спасибо!",
+      missingToken: "Ссылка неполная. Откройте ее из письма еще раз.",
+      toLogin: "Перейти ко входу",
     },
     settings: {
       back: "Назад",
@@ -695,7 +759,10 @@ export const siteText = {
       add: "В корзину",
       inCart: "В корзине",
       onlyLeft: "Осталось только {count} шт.",
+      fewLeft: "Осталось совсем немного",
       outOfStock: "Нет в наличии",
+      preorder: "Предзаказ",
+      preorderUntil: "Предзаказ, поступление {date}",
       addToCartAria: "Добавить {name} в корзину",
     },
     cart: {
@@ -727,6 +794,7 @@ export const siteText = {
       material: "Материал",
       sku: "Артикул",
       stock: "В наличии: {count}",
+      inStock: "В наличии",
       shippingTitle: "Доставка и возврат",
       shippingText: "Доступна бережная доставка. Возврат возможен в течение 30 дней для неиспользованных товаров.",
       warrantyTitle: "Гарантия",
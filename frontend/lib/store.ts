@@ -17,6 +17,7 @@ import {
 } from "@/services/orders";
 import { listAuditLogs, createAuditLog as createAuditLogRequest } from "@/services/auditLogs";
 import { getApiErrorMessage } from "@/services/http";
+import { maxOrderQuantity } from "./availability";
 
 export type { Product, CartItem, Order, AuditLog };
 
@@ -47,8 +48,8 @@ type StoreState = {
 
 let bootstrapPromise: Promise<void> | null = null;
 
-function clampCartQuantity(quantity: number, stock: number) {
-  return Math.max(0, Math.min(quantity, stock));
+function clampCartQuantity(quantity: number, product: Product) {
+  return Math.max(0, Math.min(quantity, maxOrderQuantity(product)));
 }
 
 export const useStore = create<StoreState>()(
@@ -93,7 +94,7 @@ export const useStore = create<StoreState>()(
       addToCart: (product, quantity = 1) => {
         set((state) => {
           const existing = state.cart.find((i) => i.product.id === product.id);
-          const nextQuantity = clampCartQuantity((existing?.quantity ?? 0) + quantity, product.stock);
+          const nextQuantity = clampCartQuantity((existing?.quantity ?? 0) + quantity, product);
 
           if (nextQuantity <= 0) {
             return state;
@@ -120,7 +121,7 @@ export const useStore = create<StoreState>()(
         const item = get().cart.find((cartItem) => cartItem.product.id === productId);
         if (!item) return;
 
-        const nextQuantity = clampCartQuantity(quantity, item.product.stock);
+        const nextQuantity = clampCartQuantity(quantity, item.product);
         if (nextQuantity <= 0) {
           get().removeFromCart(productId);
           return;
@@ -1,3 +1,5 @@
+export type Availability = "in_stock" | "low_stock" | "out_of_stock" | "preorder";
+
 export type Product = {
   id: string;
   name: string;
@@ -9,7 +11,11 @@ export type Product = {
   description: string;
   dimensions: string;
   material: string;
-  stock: number;
+  // Exact stock is only sent to staff; shoppers get availability instead.
+  stock?: number;
+  availability?: Availability;
+  preorder_enabled?: boolean;
+  preorder_available_at?: string;
   sku: string;
   is_active?: boolean;
   featured: boolean;
@@ -1,9 +1,24 @@
 import type { AdminUser, RoleName } from "@/lib/types";
 import { api } from "./http";
 
-type LoginResponse = {
+export type LoginResponse = {
   user: AdminUser;
   token: string;
+  refresh_token: string;
+  expires_in: number;
+  two_factor?: "required" | "enrollment_required";
+  challenge?: string;
+};
+
+export type TOTPSetup = {
+  secret: string;
+  otpauth_uri: string;
+  qr_code: string;
+};
+
+type TwoFactorEnabled = {
+  recovery_codes: string[];
+  login?: LoginResponse;
 };
 
 export async function login(email: string, password: string) {
@@ -11,6 +26,55 @@ export async function login(email: string, password: string) {
   return data;
 }
 
+// singleSignOnURL starts staff single sign-on; the browser navigates there
+// and comes back to /sso/callback.
+export function singleSignOnURL() {
+  return `${api.defaults.baseURL}/auth/oidc/login`;
+}
+
+export async function getSingleSignOnStatus() {
+  const { data } = await api.get<{ enabled: boolean }>("/auth/oidc");
+  return data.enabled;
+}
+
+// The callback must carry the state cookie the API set when the sign-in
+// started in this browser.
+export async function completeSingleSignOn(code: string, state: string) {
+  const { data } = await api.post<LoginResponse>("/auth/oidc/callback", { code, state }, { withCredentials: true });
+  return data;
+}
+
+export async function completeTwoFactorLogin(challenge: string, code: string) {
+  const { data } = await api.post<LoginResponse>("/auth/login/2fa", { challenge, code });
+  return data;
+}
+
+export async function setupTwoFactor(challenge?: string) {
+  const { data } = await api.post<TOTPSetup>("/auth/2fa/setup", challenge ? { challenge } : undefined);
+  return data;
+}
+
+export async function enableTwoFactor(code: string, challenge?: string) {
+  const { data } = await api.post<TwoFactorEnabled>("/auth/2fa/enable", { code, challenge });
+  return data;
+}
+
+export async function logout(refreshToken: string) {
+  await api.post("/auth/logout", { refresh_token: refreshToken });
+}
+
+export async function requestPasswordReset(email: string) {
+  await api.post("/auth/password/forgot", { email });
+}
+
+export async function resetPassword(token: string, password: string) {
+  await api.post("/auth/password/reset", { token, password });
+}
+
+export async function verifyEmail(token: string) {
+  await api.post("/auth/verify-email", { token });
+}
+
 export async function register(email: string, password: string, name: string, role: RoleName) {
   const { data } = await api.post<AdminUser>("/auth/register", { email, password, name, role });
   return data;
@@ -1,4 +1,4 @@
-import axios from "axios";
+import axios, { type InternalAxiosRequestConfig } from "axios";
 
 export const api = axios.create({
   baseURL: process.env.NEXT_PUBLIC_API_URL ?? "http://localhost:8080/api",
@@ -27,6 +27,56 @@ api.interceptors.request.use((config) => {
   return config;
 });
 
+type TokenPair = { token: string; refresh_token: string };
+
+type SessionHooks = {
+  getRefreshToken: () => string | null;
+  onRefreshed: (pair: TokenPair) => void;
+  onExpired: () => void;
+};
+
+let sessionHooks: SessionHooks | null = null;
+let refreshing: Promise<string | null> | null = null;
+
+// The auth store registers here so that expired access tokens are refreshed
+// once and the failed request is retried with the new token.
+export function registerSessionHooks(hooks: SessionHooks) {
+  sessionHooks = hooks;
+}
+
+function refreshAccessToken() {
+  const refreshToken = sessionHooks?.getRefreshToken();
+  if (!refreshToken) return Promise.resolve(null);
+  refreshing ??= axios
+    .post<TokenPair>(`${api.defaults.baseURL}/auth/refresh`, { refresh_token: refreshToken })
+    .then(({ data }) => {
+      sessionHooks?.onRefreshed(data);
+      return data.token;
+    })
+    .catch(() => {
+      sessionHooks?.onExpired();
+      return null;
+    })
+    .finally(() => {
+      refreshing = null;
+    });
+  return refreshing;
+}
+
+api.interceptors.response.use(undefined, async (error: unknown) => {
+  if (!axios.isAxiosError(error) || error.response?.status !== 401 || !error.config) {
+    return Promise.reject(error);
+  }
+  const config = error.config as InternalAxiosRequestConfig & { _retried?: boolean };
+  if (config._retried || config.url?.startsWith("/auth/")) return Promise.reject(error);
+
+  const token = await refreshAccessToken();
+  if (!token) return Promise.reject(error);
+  config._retried = true;
+  config.headers.Authorization = `Bearer ${token}`;
+  return api.request(config);
+});
+
 export function getApiErrorMessage(error: unknown, fallback = "Request failed") {
   if (axios.isAxiosError(error)) {
     const payload = error.response?.data;